@@ -0,0 +1,1852 @@
+// Code generated by cmd/openapi gen. DO NOT EDIT.
+
+// Package api OpenAPI仕様（api/）から生成したモデルとサーバーインターフェース
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/pkg/problem"
+)
+
+// APIKey 個人用APIキー（キー本体は作成時のみ返す）
+type APIKey struct {
+	// CreatedAt 作成日時
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt 有効期限（無期限ならnull）
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// ID APIキーID
+	ID int64 `json:"id"`
+	// LastUsedAt 最後に使用した日時（未使用ならnull）
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	// Name 名前
+	Name string `json:"name"`
+	// Prefix キーの先頭部分（一覧での識別用）
+	Prefix string `json:"prefix"`
+	// RevokedAt 失効日時
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	// Scopes 許可する操作
+	Scopes []string `json:"scopes"`
+	// UserID 所有するユーザーのID
+	UserID int64 `json:"user_id"`
+}
+
+// APIKeysResponse APIキー一覧レスポンス
+type APIKeysResponse struct {
+	// APIKeys 失効していないAPIキー（期限切れを含む）
+	APIKeys []APIKey `json:"api_keys"`
+}
+
+// AcceptInvitationRequest 招待承諾リクエスト（既存のアカウントがない場合は name と password でアカウントを作成する）
+type AcceptInvitationRequest struct {
+	// Name 作成するアカウントのユーザー名
+	Name *string `json:"name,omitempty"`
+	// Password 作成するアカウントのパスワード（8文字以上）
+	Password *string `json:"password,omitempty"`
+	// Token 招待トークン
+	Token string `json:"token"`
+}
+
+// AddOrganizationMemberRequest メンバー追加リクエスト（登録済みのユーザーをメールアドレスで追加する）
+type AddOrganizationMemberRequest struct {
+	// Email 追加するユーザーのメールアドレス
+	Email string `json:"email"`
+	// Role 組織でのロール（省略時は member） (owner, admin, member)
+	Role *string `json:"role,omitempty"`
+}
+
+// AuditLog 監査ログ（追記のみ）
+type AuditLog struct {
+	// Action アクション（user.login.success, user.role_change, webhook.create など）
+	Action string `json:"action"`
+	// ActorID 操作したユーザー（未認証の操作はnull）
+	ActorID *int64 `json:"actor_id,omitempty"`
+	// Changes 更新されたフィールドの変更前後
+	Changes map[string]FieldChange `json:"changes,omitempty"`
+	// CreatedAt 記録日時
+	CreatedAt time.Time `json:"created_at"`
+	// ID 監査ログID
+	ID int64 `json:"id"`
+	// Ip クライアントのIPアドレス
+	Ip string `json:"ip"`
+	// Metadata アクション固有の補足情報
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// RequestID リクエストID（X-Request-ID）
+	RequestID string `json:"request_id"`
+	// TargetUserID 操作対象のユーザー
+	TargetUserID *int64 `json:"target_user_id,omitempty"`
+	// UserAgent User-Agent（512文字まで）
+	UserAgent string `json:"user_agent"`
+}
+
+// AuditLogsResponse 監査ログ一覧レスポンス（新しい順）
+type AuditLogsResponse struct {
+	// AuditLogs 監査ログ
+	AuditLogs []AuditLog `json:"audit_logs"`
+	// Pagination ページネーション情報
+	Pagination Pagination `json:"pagination"`
+}
+
+// AuthResponse 認証レスポンス
+type AuthResponse struct {
+	// ChallengeToken MFAチャレンジトークン（POST /api/v1/auth/mfa/verify で本トークンに交換）
+	ChallengeToken *string `json:"challenge_token,omitempty"`
+	// CSRFToken CSRFトークン（AUTH_MODE=cookie の場合のみ。変更系のリクエストの X-CSRF-Token ヘッダーに指定する。csrf_token Cookie と同じ値）
+	CSRFToken *string `json:"csrf_token,omitempty"`
+	// MFAMethods 利用できる二要素認証の方式
+	MFAMethods []string `json:"mfa_methods,omitempty"`
+	// MFARequired 二要素認証が必要か
+	MFARequired *bool `json:"mfa_required,omitempty"`
+	// Token JWTアクセストークン（二要素認証が必要な場合と AUTH_MODE=cookie の場合は省略）
+	Token *string `json:"token,omitempty"`
+	// User ユーザー情報
+	User *User `json:"user,omitempty"`
+}
+
+// ChangeRoleRequest ロール変更リクエスト
+type ChangeRoleRequest struct {
+	// Role 新しいロール (user, admin)
+	Role string `json:"role"`
+}
+
+// CreateAPIKeyRequest APIキー作成リクエスト
+type CreateAPIKeyRequest struct {
+	// ExpiresAt 有効期限（省略時は無期限）
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Name 名前
+	Name string `json:"name"`
+	// Scopes 許可する操作（自分が持つ権限の範囲内）
+	Scopes []string `json:"scopes"`
+}
+
+// CreateInvitationRequest 招待作成リクエスト（X-Organization などでテナントを指定した場合はその組織への招待になる）
+type CreateInvitationRequest struct {
+	// Email 招待するメールアドレス
+	Email string `json:"email"`
+	// ExpiresInHours 有効期間（時間、省略時は INVITATION_TTL）
+	ExpiresInHours *int `json:"expires_in_hours,omitempty"`
+	// OrganizationRole 組織への招待の場合の組織でのロール（省略時は member） (owner, admin, member)
+	OrganizationRole *string `json:"organization_role,omitempty"`
+	// Role 承諾で作成するユーザーのロール（省略時は user。admin の指定は管理者のみ） (user, admin)
+	Role *string `json:"role,omitempty"`
+}
+
+// CreateOrganizationRequest 組織作成リクエスト（作成したユーザーが owner になる）
+type CreateOrganizationRequest struct {
+	// Name 組織名
+	Name string `json:"name"`
+	// Slug 英小文字・数字・ハイフン（3〜63文字）
+	Slug string `json:"slug"`
+}
+
+// CreateUserRequest ユーザー作成リクエスト
+type CreateUserRequest struct {
+	// Email メールアドレス
+	Email string `json:"email"`
+	// Name ユーザー名
+	Name string `json:"name"`
+	// Password パスワード（8文字以上）
+	Password string `json:"password"`
+}
+
+// CreateWebhookRequest Webhook作成リクエスト
+type CreateWebhookRequest struct {
+	// Active 有効か（省略時は有効）
+	Active *bool `json:"active,omitempty"`
+	// Description 説明
+	Description *string `json:"description,omitempty"`
+	// Events 購読するイベント（user.created, user.updated, user.deleted）
+	Events []string `json:"events"`
+	// URL 送信先URL（http / https）
+	URL string `json:"url"`
+}
+
+// CreatedAPIKey 作成直後のAPIキー（平文のキーはこのレスポンスでのみ返す）
+type CreatedAPIKey struct {
+	// CreatedAt 作成日時
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt 有効期限（無期限ならnull）
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// ID APIキーID
+	ID int64 `json:"id"`
+	// Key APIキー（Authorization ヘッダーに Bearer として指定する）
+	Key string `json:"key"`
+	// LastUsedAt 最後に使用した日時（未使用ならnull）
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	// Name 名前
+	Name string `json:"name"`
+	// Prefix キーの先頭部分（一覧での識別用）
+	Prefix string `json:"prefix"`
+	// Scopes 許可する操作
+	Scopes []string `json:"scopes"`
+	// UserID 所有するユーザーのID
+	UserID int64 `json:"user_id"`
+}
+
+// CreatedInvitation 作成した招待（トークンはこのレスポンスでのみ返す）
+type CreatedInvitation struct {
+	// CreatedAt 作成日時
+	CreatedAt time.Time `json:"created_at"`
+	// Email 招待したメールアドレス
+	Email string `json:"email"`
+	// ExpiresAt 招待リンクの有効期限
+	ExpiresAt time.Time `json:"expires_at"`
+	// ID 招待ID
+	ID int64 `json:"id"`
+	// InvitedBy 招待したユーザー
+	InvitedBy *int64 `json:"invited_by,omitempty"`
+	// OrganizationID 組織への招待の場合の組織
+	OrganizationID *int64 `json:"organization_id,omitempty"`
+	// OrganizationRole 組織への招待の場合の組織でのロール (owner, admin, member)
+	OrganizationRole *string `json:"organization_role,omitempty"`
+	// Role 承諾で作成するユーザーのロール (user, admin)
+	Role string `json:"role"`
+	// Status 招待の状態 (pending)
+	Status string `json:"status"`
+	// Token 招待トークン（招待リンクに含める）
+	Token string `json:"token"`
+	// UpdatedAt 更新日時
+	UpdatedAt time.Time `json:"updated_at"`
+	// URL 招待を承諾する画面のURL（INVITATION_ACCEPT_URL が設定されている場合）
+	URL *string `json:"url,omitempty"`
+}
+
+// CreatedWebhook 作成直後のWebhook（署名用の秘密鍵はこのレスポンスでのみ返す）
+type CreatedWebhook struct {
+	// Active 有効か
+	Active bool `json:"active"`
+	// CreatedAt 作成日時
+	CreatedAt time.Time `json:"created_at"`
+	// Description 説明
+	Description string `json:"description"`
+	// Events 購読するイベント
+	Events []string `json:"events"`
+	// ID WebhookID
+	ID int64 `json:"id"`
+	// Secret 署名用の秘密鍵（X-Webhook-Signature のHMAC-SHA256の鍵）
+	Secret string `json:"secret"`
+	// UpdatedAt 更新日時
+	UpdatedAt time.Time `json:"updated_at"`
+	// URL 送信先URL
+	URL string `json:"url"`
+}
+
+// DeadJobsResponse デッドレター一覧レスポンス（新しい順）
+type DeadJobsResponse struct {
+	// Jobs 再試行の上限に達したジョブ
+	Jobs []Job `json:"jobs"`
+}
+
+// DeclineInvitationRequest 招待辞退リクエスト
+type DeclineInvitationRequest struct {
+	// Token 招待トークン
+	Token string `json:"token"`
+}
+
+// DisableUserRequest アカウント無効化リクエスト
+type DisableUserRequest struct {
+	// Reason 無効化の理由
+	Reason string `json:"reason"`
+}
+
+// ErrorResponse エラーレスポンス
+type ErrorResponse struct {
+	// Code エラーコード（VALIDATION_ERROR, MISSING_AUTH_HEADER, TOKEN_EXPIRED, ACCOUNT_SUSPENDED など）
+	Code string `json:"code"`
+	// Error エラーメッセージ
+	Error string `json:"error"`
+}
+
+// FieldChange フィールドの変更前後の値
+type FieldChange struct {
+	// After 変更後の値
+	After interface{} `json:"after,omitempty"`
+	// Before 変更前の値
+	Before interface{} `json:"before,omitempty"`
+}
+
+// FieldError 項目ごとの検証エラー
+type FieldError struct {
+	// Field 項目名（ネストした項目は . 区切り）
+	Field string `json:"field"`
+	// Message エラーの内容
+	Message string `json:"message"`
+}
+
+// HealthResponse ヘルスチェックレスポンス
+type HealthResponse struct {
+	// Status ステータス
+	Status *string `json:"status,omitempty"`
+	// Timestamp 現在時刻
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+// ImportRowResult 一括取り込みの行ごとの結果
+type ImportRowResult struct {
+	// Email メールアドレス
+	Email *string `json:"email,omitempty"`
+	// Error スキップ・失敗の理由
+	Error *string `json:"error,omitempty"`
+	// Line 入力での行番号（CSVはヘッダーを1行目として数える）
+	Line int `json:"line"`
+	// Status 結果 (created, skipped, failed)
+	Status string `json:"status"`
+}
+
+// ImportUserRow 一括取り込みの1行（CSVのヘッダー名・NDJSONのキー）
+type ImportUserRow struct {
+	// Email メールアドレス
+	Email string `json:"email"`
+	// Name ユーザー名
+	Name string `json:"name"`
+	// Password パスワード（省略時はパスワードなし。OAuth・パスキーでのみログインできる）
+	Password *string `json:"password,omitempty"`
+	// Role ロール（省略時は user） (user, admin)
+	Role *string `json:"role,omitempty"`
+}
+
+// ImportUsersResponse 一括取り込みの結果（ドライランでは何も書き込まず、created は作成される予定の件数を表す）
+type ImportUsersResponse struct {
+	// Created 作成した件数
+	Created int `json:"created"`
+	// DryRun ドライランか
+	DryRun bool `json:"dry_run"`
+	// Failed 失敗した件数
+	Failed int `json:"failed"`
+	// Rows 行ごとの結果
+	Rows []ImportRowResult `json:"rows"`
+	// Skipped スキップした件数（登録済みのメールアドレスなど）
+	Skipped int `json:"skipped"`
+}
+
+// Invitation メールアドレス宛ての招待
+type Invitation struct {
+	// AcceptedUserID 承諾したユーザー
+	AcceptedUserID *int64 `json:"accepted_user_id,omitempty"`
+	// CreatedAt 作成日時
+	CreatedAt time.Time `json:"created_at"`
+	// Email 招待したメールアドレス
+	Email string `json:"email"`
+	// ExpiresAt 招待リンクの有効期限
+	ExpiresAt time.Time `json:"expires_at"`
+	// ID 招待ID
+	ID int64 `json:"id"`
+	// InvitedBy 招待したユーザー（削除済みならnull）
+	InvitedBy *int64 `json:"invited_by,omitempty"`
+	// OrganizationID 組織への招待の場合の組織（承諾でメンバーに追加する）
+	OrganizationID *int64 `json:"organization_id,omitempty"`
+	// OrganizationRole 組織への招待の場合の組織でのロール (owner, admin, member)
+	OrganizationRole *string `json:"organization_role,omitempty"`
+	// RespondedAt 承諾・辞退・取り消しの日時
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	// Role 承諾で作成するユーザーのロール (user, admin)
+	Role string `json:"role"`
+	// Status 招待の状態 (pending, accepted, declined, revoked)
+	Status string `json:"status"`
+	// UpdatedAt 更新日時
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// InvitationPreview 招待リンクを開いたときの表示内容
+type InvitationPreview struct {
+	// AccountExists 既存のアカウントがあるか（あればそのアカウントでログインして承諾する）
+	AccountExists bool `json:"account_exists"`
+	// Email 招待されたメールアドレス
+	Email string `json:"email"`
+	// ExpiresAt 招待リンクの有効期限
+	ExpiresAt time.Time `json:"expires_at"`
+	// OrganizationName 組織への招待の場合の組織名
+	OrganizationName *string `json:"organization_name,omitempty"`
+}
+
+// InvitationsResponse 招待一覧レスポンス
+type InvitationsResponse struct {
+	// Invitations 招待
+	Invitations []Invitation `json:"invitations"`
+	// Pagination ページネーション情報
+	Pagination Pagination `json:"pagination"`
+}
+
+// Job バックグラウンドジョブ
+type Job struct {
+	// Attempts 実行を開始した回数
+	Attempts int `json:"attempts"`
+	// CreatedAt 登録日時
+	CreatedAt time.Time `json:"created_at"`
+	// ID ジョブID
+	ID string `json:"id"`
+	// LastError 直近の失敗の理由
+	LastError *string `json:"last_error,omitempty"`
+	// MaxAttempts 最大試行回数
+	MaxAttempts int `json:"max_attempts"`
+	// Payload ジョブの引数（JSON）
+	Payload interface{} `json:"payload,omitempty"`
+	// RunAt この日時以降に実行する
+	RunAt time.Time `json:"run_at"`
+	// Type ジョブの種類
+	Type string `json:"type"`
+}
+
+// LoginRequest ログインリクエスト
+type LoginRequest struct {
+	// Email メールアドレス
+	Email string `json:"email"`
+	// Password パスワード
+	Password string `json:"password"`
+}
+
+// MFACodeRequest TOTPコードまたはリカバリーコードを伴うリクエスト
+type MFACodeRequest struct {
+	// Code TOTPコードまたはリカバリーコード
+	Code string `json:"code"`
+}
+
+// MFAStatusResponse 二要素認証の設定状況
+type MFAStatusResponse struct {
+	// Methods 有効な二要素認証の方式
+	Methods []string `json:"methods"`
+	// RecoveryCodesRemaining 未使用のリカバリーコードの数
+	RecoveryCodesRemaining int `json:"recovery_codes_remaining"`
+	// TOTPEnabled TOTPが有効か
+	TOTPEnabled bool `json:"totp_enabled"`
+}
+
+// MFAVerifyRequest 二段階ログインの検証リクエスト
+type MFAVerifyRequest struct {
+	// ChallengeToken ログインで返されたMFAチャレンジトークン
+	ChallengeToken string `json:"challenge_token"`
+	// Code TOTPコードまたはリカバリーコード
+	Code string `json:"code"`
+}
+
+// OAuthAuthorization 外部プロバイダーの認可開始レスポンス（認可リクエストの検証情報は oauth_state Cookie に設定する）
+type OAuthAuthorization struct {
+	// AuthorizationURL ブラウザで開く認可URL
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// OAuthCallbackResult 外部プロバイダーのコールバック処理結果（ログイン時は auth、アカウント連携時は identity）
+type OAuthCallbackResult struct {
+	// Auth 認証レスポンス
+	Auth *AuthResponse `json:"auth,omitempty"`
+	// Identity 外部認証プロバイダーのアカウントとの連携
+	Identity *UserIdentity `json:"identity,omitempty"`
+}
+
+// Organization 組織（テナント）
+type Organization struct {
+	// CreatedAt 作成日時
+	CreatedAt time.Time `json:"created_at"`
+	// ID 組織ID
+	ID int64 `json:"id"`
+	// Name 組織名
+	Name string `json:"name"`
+	// Slug サブドメイン・X-Organization ヘッダーで指定する識別子
+	Slug string `json:"slug"`
+	// UpdatedAt 更新日時
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OrganizationMember 組織のメンバー
+type OrganizationMember struct {
+	// JoinedAt 参加日時
+	JoinedAt time.Time `json:"joined_at"`
+	// Role 組織でのロール (owner, admin, member)
+	Role string `json:"role"`
+	// User ユーザー情報
+	User User `json:"user"`
+}
+
+// OrganizationMembersResponse メンバー一覧レスポンス
+type OrganizationMembersResponse struct {
+	// Members メンバー
+	Members []OrganizationMember `json:"members"`
+	// Pagination ページネーション情報
+	Pagination Pagination `json:"pagination"`
+}
+
+// OrganizationMembership ユーザーが所属する組織とそのロール
+type OrganizationMembership struct {
+	// CreatedAt 作成日時
+	CreatedAt time.Time `json:"created_at"`
+	// ID 組織ID
+	ID int64 `json:"id"`
+	// Name 組織名
+	Name string `json:"name"`
+	// Role 組織でのロール (owner, admin, member)
+	Role string `json:"role"`
+	// Slug サブドメイン・X-Organization ヘッダーで指定する識別子
+	Slug string `json:"slug"`
+	// UpdatedAt 更新日時
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OrganizationTokenResponse 組織を指定したアクセストークンのレスポンス（org_id クレームを含む）
+type OrganizationTokenResponse struct {
+	// Organization 組織（テナント）
+	Organization Organization `json:"organization"`
+	// Role 組織でのロール (owner, admin, member)
+	Role string `json:"role"`
+	// Token 組織を指定したJWTアクセストークン
+	Token string `json:"token"`
+}
+
+// OrganizationsResponse 所属する組織の一覧レスポンス
+type OrganizationsResponse struct {
+	// Organizations 所属する組織
+	Organizations []OrganizationMembership `json:"organizations"`
+}
+
+// Pagination ページネーション情報
+type Pagination struct {
+	// Limit 1ページあたりの件数
+	Limit int `json:"limit"`
+	// Page 現在のページ番号
+	Page int `json:"page"`
+	// Total 総件数
+	Total int `json:"total"`
+	// TotalPages 総ページ数
+	TotalPages int `json:"total_pages"`
+}
+
+// Passkey 登録済みのパスキー
+type Passkey struct {
+	// CreatedAt 登録日時
+	CreatedAt time.Time `json:"created_at"`
+	// ID パスキーID
+	ID int64 `json:"id"`
+	// LastUsedAt 最後に使用した日時（未使用ならnull）
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	// Name 表示名
+	Name string `json:"name"`
+	// UserID ユーザーID
+	UserID int64 `json:"user_id"`
+}
+
+// PasskeyBeginRequest 二要素認証としてのパスキー認証開始リクエスト
+type PasskeyBeginRequest struct {
+	// ChallengeToken ログインで返されたMFAチャレンジトークン
+	ChallengeToken string `json:"challenge_token"`
+}
+
+// PasskeyCeremony WebAuthnセレモニー開始レスポンス（options は navigator.credentials.create() / get() にそのまま渡す）
+type PasskeyCeremony struct {
+	// Options PublicKeyCredentialCreationOptions / PublicKeyCredentialRequestOptions
+	Options map[string]interface{} `json:"options"`
+	// SessionID 完了リクエストで指定するセレモニーのID
+	SessionID string `json:"session_id"`
+}
+
+// PasskeyFinishRequest WebAuthnセレモニー完了リクエスト
+type PasskeyFinishRequest struct {
+	// ChallengeToken 二要素認証として使う場合のMFAチャレンジトークン
+	ChallengeToken *string `json:"challenge_token,omitempty"`
+	// Credential ブラウザが返した PublicKeyCredential
+	Credential map[string]interface{} `json:"credential"`
+	// Name 登録時のパスキー表示名
+	Name *string `json:"name,omitempty"`
+	// SessionID 開始レスポンスの session_id
+	SessionID string `json:"session_id"`
+}
+
+// PasskeysResponse 登録済みパスキー一覧レスポンス
+type PasskeysResponse struct {
+	// Passkeys 登録済みのパスキー
+	Passkeys []Passkey `json:"passkeys"`
+}
+
+// ProblemDetails RFC 7807 形式のエラーレスポンス（application/problem+json）。
+// Accept: application/problem+json を指定するか、サーバーで ERROR_FORMAT=problem を設定した場合に返されます。
+type ProblemDetails struct {
+	// Code エラーコード（ErrorResponse の code と同じ）
+	Code string `json:"code"`
+	// Detail エラーメッセージ
+	Detail *string `json:"detail,omitempty"`
+	// Errors 項目ごとの検証エラー
+	Errors []FieldError `json:"errors,omitempty"`
+	// Instance リクエストID（X-Request-ID）
+	Instance *string `json:"instance,omitempty"`
+	// Status HTTPステータスコード
+	Status int `json:"status"`
+	// Title HTTPステータスの説明
+	Title string `json:"title"`
+	// Type 問題の種類を表すURI（PROBLEM_TYPE_BASE_URL 未設定なら about:blank）
+	Type string `json:"type"`
+}
+
+// RecoveryCodesResponse リカバリーコード発行レスポンス（一度だけ表示する）
+type RecoveryCodesResponse struct {
+	// RecoveryCodes リカバリーコード（それぞれ一度だけ使える）
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// RoutePermission ルートと必要な権限
+type RoutePermission struct {
+	// Authenticated 認証が必要か（false は認証不要、または認証が任意のルート）
+	Authenticated bool `json:"authenticated"`
+	// Method HTTPメソッド
+	Method string `json:"method"`
+	// Others 本人以外を対象にする場合に追加で必要な権限
+	Others []string `json:"others,omitempty"`
+	// Path ルートのパス
+	Path string `json:"path"`
+	// Permissions 常に必要な権限（認証だけが必要なルート・認証不要のルートは空）
+	Permissions []string `json:"permissions"`
+	// SelfParam 本人のIDでない場合に others も必要になるパスパラメータ
+	SelfParam *string `json:"self_param,omitempty"`
+}
+
+// RoutesResponse 登録されたルートと必要な権限の一覧
+type RoutesResponse struct {
+	// Routes ルート
+	Routes []RoutePermission `json:"routes"`
+}
+
+// SuspendUserRequest アカウント一時停止リクエスト
+type SuspendUserRequest struct {
+	// Reason 停止理由
+	Reason string `json:"reason"`
+	// Until 解除日時（省略時は再開されるまで無期限）
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// TOTPEnrollment TOTP登録開始レスポンス（確認するまで有効にならない）
+type TOTPEnrollment struct {
+	// ProvisioningUri QRコードにするotpauth URI
+	ProvisioningUri string `json:"provisioning_uri"`
+	// Secret Base32のシークレット（認証アプリに手入力する場合）
+	Secret string `json:"secret"`
+}
+
+// UpdateOrganizationMemberRequest メンバーのロール変更リクエスト
+type UpdateOrganizationMemberRequest struct {
+	// Role 組織でのロール (owner, admin, member)
+	Role string `json:"role"`
+}
+
+// UpdateUserRequest ユーザー更新リクエスト
+type UpdateUserRequest struct {
+	// Bio 自己紹介（空文字列で未設定に戻す）
+	Bio *string `json:"bio,omitempty"`
+	// DisplayName 表示名（空文字列で未設定に戻す）
+	DisplayName *string `json:"display_name,omitempty"`
+	// Email メールアドレス
+	Email *string `json:"email,omitempty"`
+	// Locale BCP 47 の言語タグ（空文字列で未設定に戻す）
+	Locale *string `json:"locale,omitempty"`
+	// Name ユーザー名
+	Name *string `json:"name,omitempty"`
+	// Timezone IANA タイムゾーン名（空文字列で未設定に戻す）
+	Timezone *string `json:"timezone,omitempty"`
+}
+
+// UpdateWebhookRequest Webhook更新リクエスト（省略した項目は変更しない）
+type UpdateWebhookRequest struct {
+	// Active 有効か
+	Active *bool `json:"active,omitempty"`
+	// Description 説明
+	Description *string `json:"description,omitempty"`
+	// Events 購読するイベント（user.created, user.updated, user.deleted）
+	Events []string `json:"events,omitempty"`
+	// URL 送信先URL（http / https）
+	URL *string `json:"url,omitempty"`
+}
+
+// User ユーザー情報
+type User struct {
+	// AvatarURL アバター画像のURL（size クエリで 64 / 128 / 256 px を選べる。未設定なら省略）
+	AvatarURL *string `json:"avatar_url,omitempty"`
+	// Bio 自己紹介
+	Bio *string `json:"bio,omitempty"`
+	// CreatedAt 作成日時
+	CreatedAt time.Time `json:"created_at"`
+	// DisplayName 表示名（未設定なら name を表示する）
+	DisplayName *string `json:"display_name,omitempty"`
+	// Email メールアドレス
+	Email string `json:"email"`
+	// ID ユーザーID
+	ID int64 `json:"id"`
+	// Locale BCP 47 の言語タグ
+	Locale *string `json:"locale,omitempty"`
+	// Name ユーザー名
+	Name string `json:"name"`
+	// Role ロール (user, admin)
+	Role string `json:"role"`
+	// Status アカウントの状態 (active, suspended, disabled)
+	Status string `json:"status"`
+	// StatusReason 停止・無効化の理由
+	StatusReason *string `json:"status_reason,omitempty"`
+	// SuspendedUntil 一時停止の解除日時（省略時は無期限）
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	// Timezone IANA タイムゾーン名
+	Timezone *string `json:"timezone,omitempty"`
+	// UpdatedAt 更新日時
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UserIdentitiesResponse 連携済みプロバイダー一覧レスポンス
+type UserIdentitiesResponse struct {
+	// Identities 連携済みのプロバイダー
+	Identities []UserIdentity `json:"identities"`
+}
+
+// UserIdentity 外部認証プロバイダーのアカウントとの連携
+type UserIdentity struct {
+	// CreatedAt 連携日時
+	CreatedAt time.Time `json:"created_at"`
+	// Email プロバイダーから取得したメールアドレス
+	Email string `json:"email"`
+	// ID 連携ID
+	ID int64 `json:"id"`
+	// Provider プロバイダー名
+	Provider string `json:"provider"`
+	// Subject プロバイダーでのユーザーID
+	Subject string `json:"subject"`
+	// UpdatedAt 更新日時
+	UpdatedAt time.Time `json:"updated_at"`
+	// UserID ユーザーID
+	UserID int64 `json:"user_id"`
+}
+
+// UsersResponse ユーザー一覧レスポンス
+type UsersResponse struct {
+	// Pagination ページネーション情報
+	Pagination Pagination `json:"pagination"`
+	// Users ユーザーリスト
+	Users []User `json:"users"`
+}
+
+// Webhook Webhookの送信先（署名用の秘密鍵は作成時のみ返す）
+type Webhook struct {
+	// Active 有効か
+	Active bool `json:"active"`
+	// CreatedAt 作成日時
+	CreatedAt time.Time `json:"created_at"`
+	// Description 説明
+	Description string `json:"description"`
+	// Events 購読するイベント
+	Events []string `json:"events"`
+	// ID WebhookID
+	ID int64 `json:"id"`
+	// UpdatedAt 更新日時
+	UpdatedAt time.Time `json:"updated_at"`
+	// URL 送信先URL
+	URL string `json:"url"`
+}
+
+// WebhookDeliveriesResponse 配信履歴一覧レスポンス（新しい順）
+type WebhookDeliveriesResponse struct {
+	// Deliveries 配信履歴
+	Deliveries []WebhookDelivery `json:"deliveries"`
+	// Pagination ページネーション情報
+	Pagination Pagination `json:"pagination"`
+}
+
+// WebhookDelivery Webhookの配信履歴（イベントと送信先の組ごとに1件、再送は別の配信として記録）
+type WebhookDelivery struct {
+	// Attempts 試行回数
+	Attempts int `json:"attempts"`
+	// CreatedAt 作成日時
+	CreatedAt time.Time `json:"created_at"`
+	// DeliveredAt 送信に成功した日時
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	// DurationMs 直近の試行の所要時間（ミリ秒）
+	DurationMs int64 `json:"duration_ms"`
+	// Error 直近の試行の失敗理由
+	Error *string `json:"error,omitempty"`
+	// Event イベントの種類
+	Event string `json:"event"`
+	// EventID イベントID（再送しても変わらないため受信側の重複排除に使える）
+	EventID string `json:"event_id"`
+	// ID 配信ID
+	ID int64 `json:"id"`
+	// Payload 送信する本文
+	Payload interface{} `json:"payload,omitempty"`
+	// RedeliveryOf 再送元の配信ID
+	RedeliveryOf *int64 `json:"redelivery_of,omitempty"`
+	// ResponseBody 直近の試行の応答本文（先頭の一部のみ）
+	ResponseBody *string `json:"response_body,omitempty"`
+	// ResponseStatus 直近の試行の応答ステータス（接続できなかった場合はnull）
+	ResponseStatus *int `json:"response_status,omitempty"`
+	// Status pending（送信待ち・再試行中）/ succeeded / failed（再試行の上限に達した） (pending, succeeded, failed)
+	Status string `json:"status"`
+	// UpdatedAt 更新日時
+	UpdatedAt time.Time `json:"updated_at"`
+	// WebhookID WebhookID
+	WebhookID int64 `json:"webhook_id"`
+}
+
+// WebhooksResponse Webhook一覧レスポンス
+type WebhooksResponse struct {
+	// Webhooks 登録済みのWebhook
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// GetAuditLogsParams GetAuditLogs のクエリパラメータ
+type GetAuditLogsParams struct {
+	// Action アクション
+	Action *string `form:"action"`
+	// ActorID 操作者のユーザーID
+	ActorID *int64 `form:"actor_id"`
+	// TargetUserID 対象ユーザーID
+	TargetUserID *int64 `form:"target_user_id"`
+	// RequestID リクエストID
+	RequestID *string `form:"request_id"`
+	// From 開始日時
+	From *time.Time `form:"from"`
+	// To 終了日時（この日時を含まない）
+	To *time.Time `form:"to"`
+	// Page ページ番号
+	Page *int `form:"page"`
+	// Limit 1ページあたりの件数（最大200）
+	Limit *int `form:"limit"`
+}
+
+// GetAvatarParams GetAvatar のクエリパラメータ
+type GetAvatarParams struct {
+	// Size 一辺の大きさ（px）。64 / 128 / 256 のうちこの値以上で最も小さいもの（省略時は256）
+	Size *int `form:"size"`
+	// V 画像の版（User.avatar_url に含まれる）
+	V *string `form:"v"`
+}
+
+// GetInvitationsParams GetInvitations のクエリパラメータ
+type GetInvitationsParams struct {
+	// Status 状態
+	Status *string `form:"status"`
+	// Page ページ番号
+	Page *int `form:"page"`
+	// Limit 1ページあたりの件数
+	Limit *int `form:"limit"`
+}
+
+// PreviewInvitationParams PreviewInvitation のクエリパラメータ
+type PreviewInvitationParams struct {
+	// Token 招待トークン
+	Token *string `form:"token"`
+}
+
+// GetDeadJobsParams GetDeadJobs のクエリパラメータ
+type GetDeadJobsParams struct {
+	// Limit 取得件数
+	Limit *int `form:"limit"`
+}
+
+// CompleteOAuthLoginParams CompleteOAuthLogin のクエリパラメータ
+type CompleteOAuthLoginParams struct {
+	// Code 認可コード
+	Code *string `form:"code"`
+	// State 認可リクエストで送った state
+	State *string `form:"state"`
+	// Error プロバイダーが返したエラー（ユーザーが同意しなかった場合など）
+	Error *string `form:"error"`
+}
+
+// GetOrganizationMembersParams GetOrganizationMembers のクエリパラメータ
+type GetOrganizationMembersParams struct {
+	// Page ページ番号
+	Page *int `form:"page"`
+	// Limit 1ページあたりの件数
+	Limit *int `form:"limit"`
+}
+
+// ExportUsersParams ExportUsers のクエリパラメータ
+type ExportUsersParams struct {
+	// Format 出力形式（省略時はAcceptで判定し、既定はcsv）
+	Format *string `form:"format"`
+}
+
+// ImportUsersParams ImportUsers のクエリパラメータ
+type ImportUsersParams struct {
+	// Format 入力形式（省略時はContent-Typeで判定）
+	Format *string `form:"format"`
+	// DryRun trueなら検証のみ行い、作成しない
+	DryRun *bool `form:"dry_run"`
+}
+
+// GetUsersParams GetUsers のクエリパラメータ
+type GetUsersParams struct {
+	// Page ページ番号
+	Page *int `form:"page"`
+	// Limit 1ページあたりの件数
+	Limit *int `form:"limit"`
+}
+
+// GetWebhookDeliveriesParams GetWebhookDeliveries のクエリパラメータ
+type GetWebhookDeliveriesParams struct {
+	// Page ページ番号
+	Page *int `form:"page"`
+	// Limit 1ページあたりの件数
+	Limit *int `form:"limit"`
+}
+
+// APIKeysServer api-keys タグの操作を実装するサーバー
+// パス・クエリパラメータは仕様の型に変換して渡す。リクエストボディは実装側で読み込む
+type APIKeysServer interface {
+	// GetAPIKeys APIキー一覧
+	// GET /api/v1/me/api-keys
+	GetAPIKeys(c *gin.Context)
+	// CreateAPIKey APIキー作成
+	// POST /api/v1/me/api-keys
+	CreateAPIKey(c *gin.Context)
+	// RevokeAPIKey APIキー失効
+	// DELETE /api/v1/me/api-keys/{id}
+	RevokeAPIKey(c *gin.Context, id int64)
+}
+
+// APIKeysHandlers APIKeysServer の各操作をパラメータを解析するgin.HandlerFuncとして提供する
+type APIKeysHandlers struct {
+	Server APIKeysServer
+}
+
+// GetAPIKeys GET /api/v1/me/api-keys
+func (h APIKeysHandlers) GetAPIKeys(c *gin.Context) {
+	h.Server.GetAPIKeys(c)
+}
+
+// CreateAPIKey POST /api/v1/me/api-keys
+func (h APIKeysHandlers) CreateAPIKey(c *gin.Context) {
+	h.Server.CreateAPIKey(c)
+}
+
+// RevokeAPIKey DELETE /api/v1/me/api-keys/{id}
+func (h APIKeysHandlers) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	h.Server.RevokeAPIKey(c, id)
+}
+
+// AuditLogsServer audit-logs タグの操作を実装するサーバー
+// パス・クエリパラメータは仕様の型に変換して渡す。リクエストボディは実装側で読み込む
+type AuditLogsServer interface {
+	// GetAuditLogs 監査ログ一覧
+	// GET /api/v1/admin/audit-logs
+	GetAuditLogs(c *gin.Context, params GetAuditLogsParams)
+}
+
+// AuditLogsHandlers AuditLogsServer の各操作をパラメータを解析するgin.HandlerFuncとして提供する
+type AuditLogsHandlers struct {
+	Server AuditLogsServer
+}
+
+// GetAuditLogs GET /api/v1/admin/audit-logs
+func (h AuditLogsHandlers) GetAuditLogs(c *gin.Context) {
+	var params GetAuditLogsParams
+	if value, ok := c.GetQuery("action"); ok {
+		v := value
+		params.Action = &v
+	}
+	if value, ok := c.GetQuery("actor_id"); ok {
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			invalidParameter(c, "actor_id", err)
+			return
+		}
+		params.ActorID = &v
+	}
+	if value, ok := c.GetQuery("target_user_id"); ok {
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			invalidParameter(c, "target_user_id", err)
+			return
+		}
+		params.TargetUserID = &v
+	}
+	if value, ok := c.GetQuery("request_id"); ok {
+		v := value
+		params.RequestID = &v
+	}
+	if value, ok := c.GetQuery("from"); ok {
+		v, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			invalidParameter(c, "from", err)
+			return
+		}
+		params.From = &v
+	}
+	if value, ok := c.GetQuery("to"); ok {
+		v, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			invalidParameter(c, "to", err)
+			return
+		}
+		params.To = &v
+	}
+	if value, ok := c.GetQuery("page"); ok {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			invalidParameter(c, "page", err)
+			return
+		}
+		params.Page = &v
+	}
+	if value, ok := c.GetQuery("limit"); ok {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			invalidParameter(c, "limit", err)
+			return
+		}
+		params.Limit = &v
+	}
+	h.Server.GetAuditLogs(c, params)
+}
+
+// AuthServer auth タグの操作を実装するサーバー
+// パス・クエリパラメータは仕様の型に変換して渡す。リクエストボディは実装側で読み込む
+type AuthServer interface {
+	// LoginUser ログイン
+	// POST /api/v1/auth/login
+	LoginUser(c *gin.Context)
+	// LogoutUser ログアウト
+	// POST /api/v1/auth/logout
+	LogoutUser(c *gin.Context)
+	// RegisterUser ユーザー登録
+	// POST /api/v1/auth/register
+	RegisterUser(c *gin.Context)
+}
+
+// AuthHandlers AuthServer の各操作をパラメータを解析するgin.HandlerFuncとして提供する
+type AuthHandlers struct {
+	Server AuthServer
+}
+
+// LoginUser POST /api/v1/auth/login
+func (h AuthHandlers) LoginUser(c *gin.Context) {
+	h.Server.LoginUser(c)
+}
+
+// LogoutUser POST /api/v1/auth/logout
+func (h AuthHandlers) LogoutUser(c *gin.Context) {
+	h.Server.LogoutUser(c)
+}
+
+// RegisterUser POST /api/v1/auth/register
+func (h AuthHandlers) RegisterUser(c *gin.Context) {
+	h.Server.RegisterUser(c)
+}
+
+// AvatarsServer avatars タグの操作を実装するサーバー
+// パス・クエリパラメータは仕様の型に変換して渡す。リクエストボディは実装側で読み込む
+type AvatarsServer interface {
+	// UploadAvatar アバター画像アップロード
+	// PUT /api/v1/me/avatar
+	UploadAvatar(c *gin.Context)
+	// DeleteAvatar アバター画像削除
+	// DELETE /api/v1/me/avatar
+	DeleteAvatar(c *gin.Context)
+	// GetAvatar アバター画像取得
+	// GET /api/v1/users/{id}/avatar
+	GetAvatar(c *gin.Context, id int64, params GetAvatarParams)
+}
+
+// AvatarsHandlers AvatarsServer の各操作をパラメータを解析するgin.HandlerFuncとして提供する
+type AvatarsHandlers struct {
+	Server AvatarsServer
+}
+
+// UploadAvatar PUT /api/v1/me/avatar
+func (h AvatarsHandlers) UploadAvatar(c *gin.Context) {
+	h.Server.UploadAvatar(c)
+}
+
+// DeleteAvatar DELETE /api/v1/me/avatar
+func (h AvatarsHandlers) DeleteAvatar(c *gin.Context) {
+	h.Server.DeleteAvatar(c)
+}
+
+// GetAvatar GET /api/v1/users/{id}/avatar
+func (h AvatarsHandlers) GetAvatar(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	var params GetAvatarParams
+	if value, ok := c.GetQuery("size"); ok {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			invalidParameter(c, "size", err)
+			return
+		}
+		params.Size = &v
+	}
+	if value, ok := c.GetQuery("v"); ok {
+		v := value
+		params.V = &v
+	}
+	h.Server.GetAvatar(c, id, params)
+}
+
+// HealthServer health タグの操作を実装するサーバー
+// パス・クエリパラメータは仕様の型に変換して渡す。リクエストボディは実装側で読み込む
+type HealthServer interface {
+	// HealthCheck ヘルスチェック
+	// GET /health
+	HealthCheck(c *gin.Context)
+}
+
+// HealthHandlers HealthServer の各操作をパラメータを解析するgin.HandlerFuncとして提供する
+type HealthHandlers struct {
+	Server HealthServer
+}
+
+// HealthCheck GET /health
+func (h HealthHandlers) HealthCheck(c *gin.Context) {
+	h.Server.HealthCheck(c)
+}
+
+// InvitationsServer invitations タグの操作を実装するサーバー
+// パス・クエリパラメータは仕様の型に変換して渡す。リクエストボディは実装側で読み込む
+type InvitationsServer interface {
+	// GetInvitations 招待一覧
+	// GET /api/v1/invitations
+	GetInvitations(c *gin.Context, params GetInvitationsParams)
+	// CreateInvitation 招待作成
+	// POST /api/v1/invitations
+	CreateInvitation(c *gin.Context)
+	// AcceptInvitation 招待承諾
+	// POST /api/v1/invitations/accept
+	AcceptInvitation(c *gin.Context)
+	// DeclineInvitation 招待辞退
+	// POST /api/v1/invitations/decline
+	DeclineInvitation(c *gin.Context)
+	// PreviewInvitation 招待内容
+	// GET /api/v1/invitations/preview
+	PreviewInvitation(c *gin.Context, params PreviewInvitationParams)
+	// RevokeInvitation 招待取り消し
+	// DELETE /api/v1/invitations/{id}
+	RevokeInvitation(c *gin.Context, id int64)
+}
+
+// InvitationsHandlers InvitationsServer の各操作をパラメータを解析するgin.HandlerFuncとして提供する
+type InvitationsHandlers struct {
+	Server InvitationsServer
+}
+
+// GetInvitations GET /api/v1/invitations
+func (h InvitationsHandlers) GetInvitations(c *gin.Context) {
+	var params GetInvitationsParams
+	if value, ok := c.GetQuery("status"); ok {
+		v := value
+		params.Status = &v
+	}
+	if value, ok := c.GetQuery("page"); ok {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			invalidParameter(c, "page", err)
+			return
+		}
+		params.Page = &v
+	}
+	if value, ok := c.GetQuery("limit"); ok {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			invalidParameter(c, "limit", err)
+			return
+		}
+		params.Limit = &v
+	}
+	h.Server.GetInvitations(c, params)
+}
+
+// CreateInvitation POST /api/v1/invitations
+func (h InvitationsHandlers) CreateInvitation(c *gin.Context) {
+	h.Server.CreateInvitation(c)
+}
+
+// AcceptInvitation POST /api/v1/invitations/accept
+func (h InvitationsHandlers) AcceptInvitation(c *gin.Context) {
+	h.Server.AcceptInvitation(c)
+}
+
+// DeclineInvitation POST /api/v1/invitations/decline
+func (h InvitationsHandlers) DeclineInvitation(c *gin.Context) {
+	h.Server.DeclineInvitation(c)
+}
+
+// PreviewInvitation GET /api/v1/invitations/preview
+func (h InvitationsHandlers) PreviewInvitation(c *gin.Context) {
+	var params PreviewInvitationParams
+	if value, ok := c.GetQuery("token"); ok {
+		v := value
+		params.Token = &v
+	}
+	h.Server.PreviewInvitation(c, params)
+}
+
+// RevokeInvitation DELETE /api/v1/invitations/{id}
+func (h InvitationsHandlers) RevokeInvitation(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	h.Server.RevokeInvitation(c, id)
+}
+
+// JobsServer jobs タグの操作を実装するサーバー
+// パス・クエリパラメータは仕様の型に変換して渡す。リクエストボディは実装側で読み込む
+type JobsServer interface {
+	// GetDeadJobs デッドレター一覧
+	// GET /api/v1/admin/jobs/dead
+	GetDeadJobs(c *gin.Context, params GetDeadJobsParams)
+	// RequeueDeadJob デッドレター再実行
+	// POST /api/v1/admin/jobs/dead/{id}/requeue
+	RequeueDeadJob(c *gin.Context, id string)
+}
+
+// JobsHandlers JobsServer の各操作をパラメータを解析するgin.HandlerFuncとして提供する
+type JobsHandlers struct {
+	Server JobsServer
+}
+
+// GetDeadJobs GET /api/v1/admin/jobs/dead
+func (h JobsHandlers) GetDeadJobs(c *gin.Context) {
+	var params GetDeadJobsParams
+	if value, ok := c.GetQuery("limit"); ok {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			invalidParameter(c, "limit", err)
+			return
+		}
+		params.Limit = &v
+	}
+	h.Server.GetDeadJobs(c, params)
+}
+
+// RequeueDeadJob POST /api/v1/admin/jobs/dead/{id}/requeue
+func (h JobsHandlers) RequeueDeadJob(c *gin.Context) {
+	id := c.Param("id")
+	h.Server.RequeueDeadJob(c, id)
+}
+
+// MFAServer mfa タグの操作を実装するサーバー
+// パス・クエリパラメータは仕様の型に変換して渡す。リクエストボディは実装側で読み込む
+type MFAServer interface {
+	// VerifyMFA 二段階ログイン検証
+	// POST /api/v1/auth/mfa/verify
+	VerifyMFA(c *gin.Context)
+	// GetMFAStatus 二要素認証設定状況
+	// GET /api/v1/me/mfa
+	GetMFAStatus(c *gin.Context)
+	// RegenerateRecoveryCodes リカバリーコード再発行
+	// POST /api/v1/me/mfa/recovery-codes
+	RegenerateRecoveryCodes(c *gin.Context)
+	// EnrollTOTP TOTP登録開始
+	// POST /api/v1/me/mfa/totp
+	EnrollTOTP(c *gin.Context)
+	// DisableTOTP 二要素認証無効化
+	// DELETE /api/v1/me/mfa/totp
+	DisableTOTP(c *gin.Context)
+	// ConfirmTOTP TOTP登録確認
+	// POST /api/v1/me/mfa/totp/confirm
+	ConfirmTOTP(c *gin.Context)
+}
+
+// MFAHandlers MFAServer の各操作をパラメータを解析するgin.HandlerFuncとして提供する
+type MFAHandlers struct {
+	Server MFAServer
+}
+
+// VerifyMFA POST /api/v1/auth/mfa/verify
+func (h MFAHandlers) VerifyMFA(c *gin.Context) {
+	h.Server.VerifyMFA(c)
+}
+
+// GetMFAStatus GET /api/v1/me/mfa
+func (h MFAHandlers) GetMFAStatus(c *gin.Context) {
+	h.Server.GetMFAStatus(c)
+}
+
+// RegenerateRecoveryCodes POST /api/v1/me/mfa/recovery-codes
+func (h MFAHandlers) RegenerateRecoveryCodes(c *gin.Context) {
+	h.Server.RegenerateRecoveryCodes(c)
+}
+
+// EnrollTOTP POST /api/v1/me/mfa/totp
+func (h MFAHandlers) EnrollTOTP(c *gin.Context) {
+	h.Server.EnrollTOTP(c)
+}
+
+// DisableTOTP DELETE /api/v1/me/mfa/totp
+func (h MFAHandlers) DisableTOTP(c *gin.Context) {
+	h.Server.DisableTOTP(c)
+}
+
+// ConfirmTOTP POST /api/v1/me/mfa/totp/confirm
+func (h MFAHandlers) ConfirmTOTP(c *gin.Context) {
+	h.Server.ConfirmTOTP(c)
+}
+
+// OAuthServer oauth タグの操作を実装するサーバー
+// パス・クエリパラメータは仕様の型に変換して渡す。リクエストボディは実装側で読み込む
+type OAuthServer interface {
+	// AuthorizeOAuth 外部プロバイダーログイン開始
+	// GET /api/v1/auth/oauth/{provider}
+	AuthorizeOAuth(c *gin.Context, provider string)
+	// CompleteOAuthLogin 外部プロバイダーコールバック
+	// GET /api/v1/auth/oauth/{provider}/callback
+	CompleteOAuthLogin(c *gin.Context, provider string, params CompleteOAuthLoginParams)
+	// GetIdentities 連携済みプロバイダー一覧
+	// GET /api/v1/me/identities
+	GetIdentities(c *gin.Context)
+	// LinkIdentity アカウント連携開始
+	// POST /api/v1/me/identities/{provider}
+	LinkIdentity(c *gin.Context, provider string)
+	// UnlinkIdentity アカウント連携解除
+	// DELETE /api/v1/me/identities/{provider}
+	UnlinkIdentity(c *gin.Context, provider string)
+}
+
+// OAuthHandlers OAuthServer の各操作をパラメータを解析するgin.HandlerFuncとして提供する
+type OAuthHandlers struct {
+	Server OAuthServer
+}
+
+// AuthorizeOAuth GET /api/v1/auth/oauth/{provider}
+func (h OAuthHandlers) AuthorizeOAuth(c *gin.Context) {
+	provider := c.Param("provider")
+	h.Server.AuthorizeOAuth(c, provider)
+}
+
+// CompleteOAuthLogin GET /api/v1/auth/oauth/{provider}/callback
+func (h OAuthHandlers) CompleteOAuthLogin(c *gin.Context) {
+	provider := c.Param("provider")
+	var params CompleteOAuthLoginParams
+	if value, ok := c.GetQuery("code"); ok {
+		v := value
+		params.Code = &v
+	}
+	if value, ok := c.GetQuery("state"); ok {
+		v := value
+		params.State = &v
+	}
+	if value, ok := c.GetQuery("error"); ok {
+		v := value
+		params.Error = &v
+	}
+	h.Server.CompleteOAuthLogin(c, provider, params)
+}
+
+// GetIdentities GET /api/v1/me/identities
+func (h OAuthHandlers) GetIdentities(c *gin.Context) {
+	h.Server.GetIdentities(c)
+}
+
+// LinkIdentity POST /api/v1/me/identities/{provider}
+func (h OAuthHandlers) LinkIdentity(c *gin.Context) {
+	provider := c.Param("provider")
+	h.Server.LinkIdentity(c, provider)
+}
+
+// UnlinkIdentity DELETE /api/v1/me/identities/{provider}
+func (h OAuthHandlers) UnlinkIdentity(c *gin.Context) {
+	provider := c.Param("provider")
+	h.Server.UnlinkIdentity(c, provider)
+}
+
+// OrganizationsServer organizations タグの操作を実装するサーバー
+// パス・クエリパラメータは仕様の型に変換して渡す。リクエストボディは実装側で読み込む
+type OrganizationsServer interface {
+	// GetOrganizations 所属組織一覧
+	// GET /api/v1/organizations
+	GetOrganizations(c *gin.Context)
+	// CreateOrganization 組織作成
+	// POST /api/v1/organizations
+	CreateOrganization(c *gin.Context)
+	// GetOrganization 組織詳細取得
+	// GET /api/v1/organizations/{id}
+	GetOrganization(c *gin.Context, id int64)
+	// GetOrganizationMembers メンバー一覧
+	// GET /api/v1/organizations/{id}/members
+	GetOrganizationMembers(c *gin.Context, id int64, params GetOrganizationMembersParams)
+	// AddOrganizationMember メンバー追加
+	// POST /api/v1/organizations/{id}/members
+	AddOrganizationMember(c *gin.Context, id int64)
+	// UpdateOrganizationMember メンバーのロール変更
+	// PUT /api/v1/organizations/{id}/members/{user_id}
+	UpdateOrganizationMember(c *gin.Context, id int64, userID int64)
+	// RemoveOrganizationMember メンバー削除
+	// DELETE /api/v1/organizations/{id}/members/{user_id}
+	RemoveOrganizationMember(c *gin.Context, id int64, userID int64)
+	// IssueOrganizationToken 組織トークン発行
+	// POST /api/v1/organizations/{id}/token
+	IssueOrganizationToken(c *gin.Context, id int64)
+}
+
+// OrganizationsHandlers OrganizationsServer の各操作をパラメータを解析するgin.HandlerFuncとして提供する
+type OrganizationsHandlers struct {
+	Server OrganizationsServer
+}
+
+// GetOrganizations GET /api/v1/organizations
+func (h OrganizationsHandlers) GetOrganizations(c *gin.Context) {
+	h.Server.GetOrganizations(c)
+}
+
+// CreateOrganization POST /api/v1/organizations
+func (h OrganizationsHandlers) CreateOrganization(c *gin.Context) {
+	h.Server.CreateOrganization(c)
+}
+
+// GetOrganization GET /api/v1/organizations/{id}
+func (h OrganizationsHandlers) GetOrganization(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	h.Server.GetOrganization(c, id)
+}
+
+// GetOrganizationMembers GET /api/v1/organizations/{id}/members
+func (h OrganizationsHandlers) GetOrganizationMembers(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	var params GetOrganizationMembersParams
+	if value, ok := c.GetQuery("page"); ok {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			invalidParameter(c, "page", err)
+			return
+		}
+		params.Page = &v
+	}
+	if value, ok := c.GetQuery("limit"); ok {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			invalidParameter(c, "limit", err)
+			return
+		}
+		params.Limit = &v
+	}
+	h.Server.GetOrganizationMembers(c, id, params)
+}
+
+// AddOrganizationMember POST /api/v1/organizations/{id}/members
+func (h OrganizationsHandlers) AddOrganizationMember(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	h.Server.AddOrganizationMember(c, id)
+}
+
+// UpdateOrganizationMember PUT /api/v1/organizations/{id}/members/{user_id}
+func (h OrganizationsHandlers) UpdateOrganizationMember(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "user_id", err)
+		return
+	}
+	h.Server.UpdateOrganizationMember(c, id, userID)
+}
+
+// RemoveOrganizationMember DELETE /api/v1/organizations/{id}/members/{user_id}
+func (h OrganizationsHandlers) RemoveOrganizationMember(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "user_id", err)
+		return
+	}
+	h.Server.RemoveOrganizationMember(c, id, userID)
+}
+
+// IssueOrganizationToken POST /api/v1/organizations/{id}/token
+func (h OrganizationsHandlers) IssueOrganizationToken(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	h.Server.IssueOrganizationToken(c, id)
+}
+
+// PasskeysServer passkeys タグの操作を実装するサーバー
+// パス・クエリパラメータは仕様の型に変換して渡す。リクエストボディは実装側で読み込む
+type PasskeysServer interface {
+	// BeginPasskeyMFA 二要素認証としてのパスキー認証開始
+	// POST /api/v1/auth/mfa/passkey/begin
+	BeginPasskeyMFA(c *gin.Context)
+	// FinishPasskeyMFA 二要素認証としてのパスキー認証完了
+	// POST /api/v1/auth/mfa/passkey/finish
+	FinishPasskeyMFA(c *gin.Context)
+	// BeginPasskeyLogin パスキーログイン開始
+	// POST /api/v1/auth/passkey/login/begin
+	BeginPasskeyLogin(c *gin.Context)
+	// FinishPasskeyLogin パスキーログイン完了
+	// POST /api/v1/auth/passkey/login/finish
+	FinishPasskeyLogin(c *gin.Context)
+	// GetPasskeys 登録済みパスキー一覧
+	// GET /api/v1/me/passkeys
+	GetPasskeys(c *gin.Context)
+	// BeginPasskeyRegistration パスキー登録開始
+	// POST /api/v1/me/passkeys/register/begin
+	BeginPasskeyRegistration(c *gin.Context)
+	// FinishPasskeyRegistration パスキー登録完了
+	// POST /api/v1/me/passkeys/register/finish
+	FinishPasskeyRegistration(c *gin.Context)
+	// DeletePasskey パスキー削除
+	// DELETE /api/v1/me/passkeys/{id}
+	DeletePasskey(c *gin.Context, id int64)
+}
+
+// PasskeysHandlers PasskeysServer の各操作をパラメータを解析するgin.HandlerFuncとして提供する
+type PasskeysHandlers struct {
+	Server PasskeysServer
+}
+
+// BeginPasskeyMFA POST /api/v1/auth/mfa/passkey/begin
+func (h PasskeysHandlers) BeginPasskeyMFA(c *gin.Context) {
+	h.Server.BeginPasskeyMFA(c)
+}
+
+// FinishPasskeyMFA POST /api/v1/auth/mfa/passkey/finish
+func (h PasskeysHandlers) FinishPasskeyMFA(c *gin.Context) {
+	h.Server.FinishPasskeyMFA(c)
+}
+
+// BeginPasskeyLogin POST /api/v1/auth/passkey/login/begin
+func (h PasskeysHandlers) BeginPasskeyLogin(c *gin.Context) {
+	h.Server.BeginPasskeyLogin(c)
+}
+
+// FinishPasskeyLogin POST /api/v1/auth/passkey/login/finish
+func (h PasskeysHandlers) FinishPasskeyLogin(c *gin.Context) {
+	h.Server.FinishPasskeyLogin(c)
+}
+
+// GetPasskeys GET /api/v1/me/passkeys
+func (h PasskeysHandlers) GetPasskeys(c *gin.Context) {
+	h.Server.GetPasskeys(c)
+}
+
+// BeginPasskeyRegistration POST /api/v1/me/passkeys/register/begin
+func (h PasskeysHandlers) BeginPasskeyRegistration(c *gin.Context) {
+	h.Server.BeginPasskeyRegistration(c)
+}
+
+// FinishPasskeyRegistration POST /api/v1/me/passkeys/register/finish
+func (h PasskeysHandlers) FinishPasskeyRegistration(c *gin.Context) {
+	h.Server.FinishPasskeyRegistration(c)
+}
+
+// DeletePasskey DELETE /api/v1/me/passkeys/{id}
+func (h PasskeysHandlers) DeletePasskey(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	h.Server.DeletePasskey(c, id)
+}
+
+// UserBulkServer user-bulk タグの操作を実装するサーバー
+// パス・クエリパラメータは仕様の型に変換して渡す。リクエストボディは実装側で読み込む
+type UserBulkServer interface {
+	// ExportUsers ユーザー一括出力
+	// GET /api/v1/admin/users/export
+	ExportUsers(c *gin.Context, params ExportUsersParams)
+	// ImportUsers ユーザー一括取り込み
+	// POST /api/v1/admin/users/import
+	ImportUsers(c *gin.Context, params ImportUsersParams)
+}
+
+// UserBulkHandlers UserBulkServer の各操作をパラメータを解析するgin.HandlerFuncとして提供する
+type UserBulkHandlers struct {
+	Server UserBulkServer
+}
+
+// ExportUsers GET /api/v1/admin/users/export
+func (h UserBulkHandlers) ExportUsers(c *gin.Context) {
+	var params ExportUsersParams
+	if value, ok := c.GetQuery("format"); ok {
+		v := value
+		params.Format = &v
+	}
+	h.Server.ExportUsers(c, params)
+}
+
+// ImportUsers POST /api/v1/admin/users/import
+func (h UserBulkHandlers) ImportUsers(c *gin.Context) {
+	var params ImportUsersParams
+	if value, ok := c.GetQuery("format"); ok {
+		v := value
+		params.Format = &v
+	}
+	if value, ok := c.GetQuery("dry_run"); ok {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			invalidParameter(c, "dry_run", err)
+			return
+		}
+		params.DryRun = &v
+	}
+	h.Server.ImportUsers(c, params)
+}
+
+// UsersServer users タグの操作を実装するサーバー
+// パス・クエリパラメータは仕様の型に変換して渡す。リクエストボディは実装側で読み込む
+type UsersServer interface {
+	// GetUsers ユーザー一覧取得
+	// GET /api/v1/users
+	GetUsers(c *gin.Context, params GetUsersParams)
+	// GetUserByID ユーザー詳細取得
+	// GET /api/v1/users/{id}
+	GetUserByID(c *gin.Context, id int64)
+	// UpdateUser ユーザー更新
+	// PUT /api/v1/users/{id}
+	UpdateUser(c *gin.Context, id int64)
+	// DeleteUser ユーザー削除
+	// DELETE /api/v1/users/{id}
+	DeleteUser(c *gin.Context, id int64)
+	// DisableUser アカウント無効化
+	// POST /api/v1/users/{id}/disable
+	DisableUser(c *gin.Context, id int64)
+	// ReinstateUser アカウント再開
+	// POST /api/v1/users/{id}/reinstate
+	ReinstateUser(c *gin.Context, id int64)
+	// ChangeUserRole ロール変更
+	// PUT /api/v1/users/{id}/role
+	ChangeUserRole(c *gin.Context, id int64)
+	// SuspendUser アカウント一時停止
+	// POST /api/v1/users/{id}/suspend
+	SuspendUser(c *gin.Context, id int64)
+}
+
+// UsersHandlers UsersServer の各操作をパラメータを解析するgin.HandlerFuncとして提供する
+type UsersHandlers struct {
+	Server UsersServer
+}
+
+// GetUsers GET /api/v1/users
+func (h UsersHandlers) GetUsers(c *gin.Context) {
+	var params GetUsersParams
+	if value, ok := c.GetQuery("page"); ok {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			invalidParameter(c, "page", err)
+			return
+		}
+		params.Page = &v
+	}
+	if value, ok := c.GetQuery("limit"); ok {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			invalidParameter(c, "limit", err)
+			return
+		}
+		params.Limit = &v
+	}
+	h.Server.GetUsers(c, params)
+}
+
+// GetUserByID GET /api/v1/users/{id}
+func (h UsersHandlers) GetUserByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	h.Server.GetUserByID(c, id)
+}
+
+// UpdateUser PUT /api/v1/users/{id}
+func (h UsersHandlers) UpdateUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	h.Server.UpdateUser(c, id)
+}
+
+// DeleteUser DELETE /api/v1/users/{id}
+func (h UsersHandlers) DeleteUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	h.Server.DeleteUser(c, id)
+}
+
+// DisableUser POST /api/v1/users/{id}/disable
+func (h UsersHandlers) DisableUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	h.Server.DisableUser(c, id)
+}
+
+// ReinstateUser POST /api/v1/users/{id}/reinstate
+func (h UsersHandlers) ReinstateUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	h.Server.ReinstateUser(c, id)
+}
+
+// ChangeUserRole PUT /api/v1/users/{id}/role
+func (h UsersHandlers) ChangeUserRole(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	h.Server.ChangeUserRole(c, id)
+}
+
+// SuspendUser POST /api/v1/users/{id}/suspend
+func (h UsersHandlers) SuspendUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	h.Server.SuspendUser(c, id)
+}
+
+// WebhooksServer webhooks タグの操作を実装するサーバー
+// パス・クエリパラメータは仕様の型に変換して渡す。リクエストボディは実装側で読み込む
+type WebhooksServer interface {
+	// GetWebhooks Webhook一覧
+	// GET /api/v1/admin/webhooks
+	GetWebhooks(c *gin.Context)
+	// CreateWebhook Webhook作成
+	// POST /api/v1/admin/webhooks
+	CreateWebhook(c *gin.Context)
+	// GetWebhook Webhook詳細
+	// GET /api/v1/admin/webhooks/{id}
+	GetWebhook(c *gin.Context, id int64)
+	// UpdateWebhook Webhook更新
+	// PUT /api/v1/admin/webhooks/{id}
+	UpdateWebhook(c *gin.Context, id int64)
+	// DeleteWebhook Webhook削除
+	// DELETE /api/v1/admin/webhooks/{id}
+	DeleteWebhook(c *gin.Context, id int64)
+	// GetWebhookDeliveries Webhook配信履歴
+	// GET /api/v1/admin/webhooks/{id}/deliveries
+	GetWebhookDeliveries(c *gin.Context, id int64, params GetWebhookDeliveriesParams)
+	// RedeliverWebhookDelivery Webhook再送
+	// POST /api/v1/admin/webhooks/{id}/deliveries/{delivery_id}/redeliver
+	RedeliverWebhookDelivery(c *gin.Context, id int64, deliveryID int64)
+}
+
+// WebhooksHandlers WebhooksServer の各操作をパラメータを解析するgin.HandlerFuncとして提供する
+type WebhooksHandlers struct {
+	Server WebhooksServer
+}
+
+// GetWebhooks GET /api/v1/admin/webhooks
+func (h WebhooksHandlers) GetWebhooks(c *gin.Context) {
+	h.Server.GetWebhooks(c)
+}
+
+// CreateWebhook POST /api/v1/admin/webhooks
+func (h WebhooksHandlers) CreateWebhook(c *gin.Context) {
+	h.Server.CreateWebhook(c)
+}
+
+// GetWebhook GET /api/v1/admin/webhooks/{id}
+func (h WebhooksHandlers) GetWebhook(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	h.Server.GetWebhook(c, id)
+}
+
+// UpdateWebhook PUT /api/v1/admin/webhooks/{id}
+func (h WebhooksHandlers) UpdateWebhook(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	h.Server.UpdateWebhook(c, id)
+}
+
+// DeleteWebhook DELETE /api/v1/admin/webhooks/{id}
+func (h WebhooksHandlers) DeleteWebhook(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	h.Server.DeleteWebhook(c, id)
+}
+
+// GetWebhookDeliveries GET /api/v1/admin/webhooks/{id}/deliveries
+func (h WebhooksHandlers) GetWebhookDeliveries(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	var params GetWebhookDeliveriesParams
+	if value, ok := c.GetQuery("page"); ok {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			invalidParameter(c, "page", err)
+			return
+		}
+		params.Page = &v
+	}
+	if value, ok := c.GetQuery("limit"); ok {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			invalidParameter(c, "limit", err)
+			return
+		}
+		params.Limit = &v
+	}
+	h.Server.GetWebhookDeliveries(c, id, params)
+}
+
+// RedeliverWebhookDelivery POST /api/v1/admin/webhooks/{id}/deliveries/{delivery_id}/redeliver
+func (h WebhooksHandlers) RedeliverWebhookDelivery(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "id", err)
+		return
+	}
+	deliveryID, err := strconv.ParseInt(c.Param("delivery_id"), 10, 64)
+	if err != nil {
+		invalidParameter(c, "delivery_id", err)
+		return
+	}
+	h.Server.RedeliverWebhookDelivery(c, id, deliveryID)
+}
+
+// invalidParameter パラメータの型が仕様と一致しない場合のレスポンス
+func invalidParameter(c *gin.Context, name string, err error) {
+	problem.Write(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid parameter "+strconv.Quote(name)+": "+err.Error(),
+		problem.FieldError{Field: name, Message: err.Error()})
+}
@@ -0,0 +1,3 @@
+package api
+
+//go:generate go run ../cmd/openapi gen -in ../pkg/openapi/openapi.json -out api.gen.go -package api
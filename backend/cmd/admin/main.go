@@ -0,0 +1,477 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/user"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/joho/godotenv"
+
+	"app-template/internal/entity"
+	"app-template/internal/repository"
+	"app-template/internal/usecase"
+	"app-template/pkg/auth"
+	"app-template/pkg/database"
+	"app-template/pkg/kvstore"
+	"app-template/pkg/requestinfo"
+	"app-template/pkg/session"
+)
+
+const usage = `Usage: admin [-output table|json] [-dry-run] <command> [flags]
+
+Commands:
+  create-admin    -email EMAIL -name NAME [-password PASSWORD]
+  reset-password  (-id ID | -email EMAIL) [-password PASSWORD]
+  set-role        (-id ID | -email EMAIL) -role user|admin
+  suspend         (-id ID | -email EMAIL) -reason REASON [-until RFC3339]
+  disable         (-id ID | -email EMAIL) -reason REASON
+  reinstate       (-id ID | -email EMAIL)
+  list            [-q QUERY] [-role ROLE] [-status STATUS] [-page N] [-limit N]
+  export          [-format json|csv]
+
+パスワードを省略した場合はランダムに生成して一度だけ表示します。
+-dry-run を指定すると検証のみ行い、データベースは変更しません。
+`
+
+// options 全コマンド共通のオプション
+type options struct {
+	output string
+	dryRun bool
+}
+
+// app コマンドの実行に必要な依存関係
+type app struct {
+	opts     *options
+	out      io.Writer
+	userRepo repository.UserRepository
+	users    usecase.UserUseCase
+}
+
+func main() {
+	// 環境変数を読み込み
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	opts := &options{}
+	global := flag.NewFlagSet("admin", flag.ExitOnError)
+	global.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	bindOptions(global, opts)
+	global.Parse(os.Args[1:])
+
+	args := global.Args()
+	if len(args) == 0 {
+		global.Usage()
+		os.Exit(2)
+	}
+
+	// データベース接続
+	db, err := database.Connect()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// 停止したユーザーの認証キャッシュを破棄するためサーバーと同じストアに接続
+	store, err := kvstore.Connect(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to connect to session store: %v", err)
+	}
+
+	// パスワードを再設定したユーザーのセッションを失効させるためサーバーと同じ保存先に接続
+	sessionBackend, err := session.Connect(context.Background(), db)
+	if err != nil {
+		log.Fatalf("Failed to configure sessions: %v", err)
+	}
+
+	userRepo := repository.NewUserRepository(db)
+	audit := usecase.NewAuditUseCase(repository.NewAuditLogRepository(db))
+	tokens := auth.NewJWTManager(auth.LoadJWTConfig())
+	// 失効させるだけでCookieは発行しないため、CSRFトークンの鍵は不要
+	sessions := session.NewManager(sessionBackend, "", session.Config{})
+
+	a := &app{
+		opts:     opts,
+		out:      os.Stdout,
+		userRepo: userRepo,
+		users:    usecase.NewUserUseCase(userRepo, repository.NewMFARepository(db), tokens, audit, usecase.NewAccountStateCache(userRepo, store), sessions, usecase.RegistrationOpen),
+	}
+
+	if err := a.run(cliContext(), args[0], args[1:]); err != nil {
+		log.Fatalf("%s: %v", args[0], err)
+	}
+}
+
+// run サブコマンドを実行
+func (a *app) run(ctx context.Context, command string, args []string) error {
+	if a.opts.output != "table" && a.opts.output != "json" {
+		return fmt.Errorf("-output must be table or json")
+	}
+
+	switch command {
+	case "create-admin":
+		return a.createAdmin(ctx, args)
+	case "reset-password":
+		return a.resetPassword(ctx, args)
+	case "set-role":
+		return a.setRole(ctx, args)
+	case "suspend":
+		return a.suspend(ctx, args)
+	case "disable":
+		return a.disable(ctx, args)
+	case "reinstate":
+		return a.reinstate(ctx, args)
+	case "list":
+		return a.list(ctx, args)
+	case "export":
+		return a.export(ctx, args)
+	default:
+		return fmt.Errorf("unknown command. Available commands: create-admin, reset-password, set-role, suspend, disable, reinstate, list, export")
+	}
+}
+
+// createAdmin 管理者ユーザーを作成
+func (a *app) createAdmin(ctx context.Context, args []string) error {
+	fs := a.flagSet("create-admin")
+	email := fs.String("email", "", "メールアドレス")
+	name := fs.String("name", "", "名前")
+	password := fs.String("password", "", "パスワード（省略時は自動生成）")
+	fs.Parse(args)
+
+	if *email == "" || *name == "" {
+		return fmt.Errorf("-email and -name are required")
+	}
+
+	generated, err := passwordOrGenerate(password, a.opts.dryRun)
+	if err != nil {
+		return err
+	}
+
+	existing, err := a.userRepo.GetByEmail(ctx, *email)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("email already exists: %s", *email)
+	}
+
+	result := &result{Action: "create-admin", DryRun: a.opts.dryRun, Password: generated}
+	if a.opts.dryRun {
+		result.User = &entity.User{Email: *email, Name: *name, Role: auth.RoleAdmin}
+		return a.print(result)
+	}
+
+	created, err := a.users.CreateUser(ctx, &entity.CreateUserRequest{
+		Email:    *email,
+		Name:     *name,
+		Password: *password,
+	}, auth.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	result.User = created
+	return a.print(result)
+}
+
+// resetPassword パスワードを再設定
+func (a *app) resetPassword(ctx context.Context, args []string) error {
+	fs := a.flagSet("reset-password")
+	id := fs.Int64("id", 0, "ユーザーID")
+	email := fs.String("email", "", "メールアドレス")
+	password := fs.String("password", "", "新しいパスワード（省略時は自動生成）")
+	fs.Parse(args)
+
+	target, err := a.findUser(ctx, *id, *email)
+	if err != nil {
+		return err
+	}
+
+	generated, err := passwordOrGenerate(password, a.opts.dryRun)
+	if err != nil {
+		return err
+	}
+
+	result := &result{Action: "reset-password", DryRun: a.opts.dryRun, User: target, Password: generated}
+	if a.opts.dryRun {
+		return a.print(result)
+	}
+
+	if err := a.users.ResetPassword(ctx, target.ID, *password); err != nil {
+		return err
+	}
+
+	return a.print(result)
+}
+
+// setRole ロールを変更（昇格・降格）
+func (a *app) setRole(ctx context.Context, args []string) error {
+	fs := a.flagSet("set-role")
+	id := fs.Int64("id", 0, "ユーザーID")
+	email := fs.String("email", "", "メールアドレス")
+	role := fs.String("role", "", "新しいロール (user, admin)")
+	fs.Parse(args)
+
+	if !auth.IsRole(*role) {
+		return fmt.Errorf("-role must be %q or %q", auth.RoleUser, auth.RoleAdmin)
+	}
+
+	target, err := a.findUser(ctx, *id, *email)
+	if err != nil {
+		return err
+	}
+
+	result := &result{
+		Action:  "set-role",
+		DryRun:  a.opts.dryRun,
+		User:    target,
+		Changes: map[string]entity.FieldChange{"role": {Before: target.Role, After: *role}},
+	}
+	if a.opts.dryRun || target.Role == *role {
+		return a.print(result)
+	}
+
+	// CLIからの操作には操作者ユーザーがいないため actorID は0
+	updated, err := a.users.ChangeRole(ctx, 0, target.ID, *role)
+	if err != nil {
+		return err
+	}
+
+	result.User = updated
+	return a.print(result)
+}
+
+// suspend アカウントを一時停止
+func (a *app) suspend(ctx context.Context, args []string) error {
+	fs := a.flagSet("suspend")
+	id := fs.Int64("id", 0, "ユーザーID")
+	email := fs.String("email", "", "メールアドレス")
+	reason := fs.String("reason", "", "停止理由")
+	until := fs.String("until", "", "解除日時（RFC3339、省略時は無期限）")
+	fs.Parse(args)
+
+	if *reason == "" {
+		return fmt.Errorf("-reason is required")
+	}
+
+	req := &entity.SuspendUserRequest{Reason: *reason}
+	if *until != "" {
+		t, err := time.Parse(time.RFC3339, *until)
+		if err != nil {
+			return fmt.Errorf("-until must be RFC3339: %w", err)
+		}
+		if !t.After(time.Now()) {
+			return usecase.ErrInvalidSuspendUntil
+		}
+		req.Until = &t
+	}
+
+	return a.changeStatus(ctx, "suspend", *id, *email, entity.UserStatusSuspended, func(target *entity.User) (*entity.User, error) {
+		return a.users.Suspend(ctx, 0, target.ID, req)
+	})
+}
+
+// disable アカウントを無効化
+func (a *app) disable(ctx context.Context, args []string) error {
+	fs := a.flagSet("disable")
+	id := fs.Int64("id", 0, "ユーザーID")
+	email := fs.String("email", "", "メールアドレス")
+	reason := fs.String("reason", "", "無効化の理由")
+	fs.Parse(args)
+
+	if *reason == "" {
+		return fmt.Errorf("-reason is required")
+	}
+
+	return a.changeStatus(ctx, "disable", *id, *email, entity.UserStatusDisabled, func(target *entity.User) (*entity.User, error) {
+		return a.users.Disable(ctx, 0, target.ID, &entity.DisableUserRequest{Reason: *reason})
+	})
+}
+
+// reinstate 停止・無効化したアカウントを再開
+func (a *app) reinstate(ctx context.Context, args []string) error {
+	fs := a.flagSet("reinstate")
+	id := fs.Int64("id", 0, "ユーザーID")
+	email := fs.String("email", "", "メールアドレス")
+	fs.Parse(args)
+
+	return a.changeStatus(ctx, "reinstate", *id, *email, entity.UserStatusActive, func(target *entity.User) (*entity.User, error) {
+		return a.users.Reinstate(ctx, 0, target.ID)
+	})
+}
+
+// changeStatus 対象ユーザーを取得して状態を変更（-dry-run なら変更内容の表示のみ）
+func (a *app) changeStatus(ctx context.Context, action string, id int64, email, status string, change func(target *entity.User) (*entity.User, error)) error {
+	target, err := a.findUser(ctx, id, email)
+	if err != nil {
+		return err
+	}
+
+	result := &result{
+		Action:  action,
+		DryRun:  a.opts.dryRun,
+		User:    target,
+		Changes: map[string]entity.FieldChange{"status": {Before: target.Status, After: status}},
+	}
+	if a.opts.dryRun {
+		return a.print(result)
+	}
+
+	// CLIからの操作には操作者ユーザーがいないため actorID は0
+	updated, err := change(target)
+	if err != nil {
+		return err
+	}
+
+	result.User = updated
+	return a.print(result)
+}
+
+// list ユーザーを検索して一覧表示
+func (a *app) list(ctx context.Context, args []string) error {
+	fs := a.flagSet("list")
+	query := fs.String("q", "", "メールアドレスまたは名前の部分一致")
+	role := fs.String("role", "", "ロールで絞り込み")
+	status := fs.String("status", "", "状態で絞り込み (active, suspended, disabled)")
+	page := fs.Int("page", 1, "ページ番号")
+	limit := fs.Int("limit", 50, "1ページあたりの件数")
+	fs.Parse(args)
+
+	if *page < 1 || *limit < 1 {
+		return fmt.Errorf("-page and -limit must be positive")
+	}
+
+	users, pagination, err := a.userRepo.Search(ctx, &entity.UserFilter{
+		Query:  *query,
+		Role:   *role,
+		Status: *status,
+		Page:   *page,
+		Limit:  *limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	return a.printUsers(users, pagination)
+}
+
+// export 全ユーザーを書き出す
+func (a *app) export(ctx context.Context, args []string) error {
+	fs := a.flagSet("export")
+	format := fs.String("format", "json", "出力形式 (json, csv)")
+	fs.Parse(args)
+
+	if *format != "json" && *format != "csv" {
+		return fmt.Errorf("-format must be json or csv")
+	}
+
+	var users []*entity.User
+	for page := 1; ; page++ {
+		batch, pagination, err := a.userRepo.Search(ctx, &entity.UserFilter{Page: page, Limit: exportBatchSize})
+		if err != nil {
+			return err
+		}
+		users = append(users, batch...)
+		if page >= pagination.TotalPages {
+			break
+		}
+	}
+
+	if *format == "csv" {
+		return writeUsersCSV(a.out, users)
+	}
+	return writeJSON(a.out, users)
+}
+
+// findUser IDまたはメールアドレスでユーザーを取得
+func (a *app) findUser(ctx context.Context, id int64, email string) (*entity.User, error) {
+	if (id == 0) == (email == "") {
+		return nil, fmt.Errorf("specify exactly one of -id or -email")
+	}
+
+	var target *entity.User
+	var err error
+	if id != 0 {
+		target, err = a.userRepo.GetByID(ctx, id)
+	} else {
+		target, err = a.userRepo.GetByEmail(ctx, email)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	return target, nil
+}
+
+// flagSet 共通オプション付きのサブコマンド用FlagSetを作成
+func (a *app) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	bindOptions(fs, a.opts)
+	return fs
+}
+
+// bindOptions 共通オプションをFlagSetに登録（コマンドの前後どちらでも指定できる）
+func bindOptions(fs *flag.FlagSet, opts *options) {
+	fs.StringVar(&opts.output, "output", defaultString(opts.output, "table"), "出力形式 (table, json)")
+	fs.BoolVar(&opts.dryRun, "dry-run", opts.dryRun, "変更を行わずに実行内容を表示")
+}
+
+// passwordOrGenerate パスワードが未指定なら生成して設定し、生成したパスワードを返す
+// dryRun では保存されないパスワードを渡してしまわないよう生成せず、dryRunPassword を返す
+func passwordOrGenerate(password *string, dryRun bool) (string, error) {
+	if *password != "" {
+		if len(*password) < minPasswordLength {
+			return "", fmt.Errorf("password must be at least %d characters", minPasswordLength)
+		}
+		return "", nil
+	}
+	if dryRun {
+		return dryRunPassword, nil
+	}
+
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	*password = base64.RawURLEncoding.EncodeToString(b)
+	return *password, nil
+}
+
+// cliContext 監査ログに記録するCLIの実行情報を設定したコンテキスト
+func cliContext() context.Context {
+	operator := "unknown"
+	if u, err := user.Current(); err == nil {
+		operator = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		operator += "@" + host
+	}
+
+	b := make([]byte, 8)
+	rand.Read(b)
+
+	return requestinfo.WithInfo(context.Background(), requestinfo.Info{
+		RequestID: "cli-" + hex.EncodeToString(b),
+		UserAgent: "cmd/admin (" + operator + ")",
+	})
+}
+
+// defaultString 空文字列ならfallbackを返す
+func defaultString(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"app-template/internal/entity"
+)
+
+const (
+	// exportBatchSize エクスポート時に1回で読み込む件数
+	exportBatchSize = 500
+
+	// minPasswordLength パスワードの最小文字数
+	minPasswordLength = 8
+
+	// dryRunPassword -dry-run でパスワードを自動生成する場合に表示する値（実際には生成しない）
+	dryRunPassword = "(generated when run without -dry-run)"
+)
+
+// result 更新系コマンドの実行結果
+type result struct {
+	Action  string                        `json:"action"`
+	DryRun  bool                          `json:"dry_run"`
+	User    *entity.User                  `json:"user"`
+	Changes map[string]entity.FieldChange `json:"changes,omitempty"`
+	// Password 自動生成したパスワード（この出力でのみ表示。-dry-run では dryRunPassword）
+	Password string `json:"password,omitempty"`
+}
+
+// print 実行結果を出力
+func (a *app) print(r *result) error {
+	if a.opts.output == "json" {
+		return writeJSON(a.out, r)
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	if r.DryRun {
+		fmt.Fprintln(w, "DRY RUN\t(no changes were made)")
+	}
+	fmt.Fprintf(w, "action\t%s\n", r.Action)
+	if r.User.ID != 0 {
+		fmt.Fprintf(w, "id\t%d\n", r.User.ID)
+	}
+	fmt.Fprintf(w, "email\t%s\n", r.User.Email)
+	fmt.Fprintf(w, "name\t%s\n", r.User.Name)
+	fmt.Fprintf(w, "role\t%s\n", r.User.Role)
+	fmt.Fprintf(w, "status\t%s\n", defaultString(r.User.Status, entity.UserStatusActive))
+	for field, change := range r.Changes {
+		fmt.Fprintf(w, "%s\t%v -> %v\n", field, change.Before, change.After)
+	}
+	if r.Password != "" {
+		fmt.Fprintf(w, "password\t%s\n", r.Password)
+	}
+	return w.Flush()
+}
+
+// printUsers ユーザー一覧を出力
+func (a *app) printUsers(users []*entity.User, pagination *entity.PaginationResponse) error {
+	if a.opts.output == "json" {
+		return writeJSON(a.out, &entity.UsersResponse{Users: users, Pagination: pagination})
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tEMAIL\tNAME\tROLE\tSTATUS\tCREATED_AT")
+	for _, u := range users {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", u.ID, u.Email, u.Name, u.Role, u.Status, u.CreatedAt.Format(time.RFC3339))
+	}
+	fmt.Fprintf(w, "\npage %d/%d (%d users)\n", pagination.Page, pagination.TotalPages, pagination.Total)
+	return w.Flush()
+}
+
+// writeJSON 整形したJSONを出力
+func writeJSON(out io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// writeUsersCSV ユーザーをCSVで出力
+func writeUsersCSV(out io.Writer, users []*entity.User) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"id", "email", "name", "role", "status", "created_at", "updated_at"})
+	for _, u := range users {
+		w.Write([]string{
+			strconv.FormatInt(u.ID, 10),
+			u.Email,
+			u.Name,
+			u.Role,
+			u.Status,
+			u.CreatedAt.Format(time.RFC3339),
+			u.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestPasswordOrGenerate(t *testing.T) {
+	given := "correct horse"
+	generated, err := passwordOrGenerate(&given, false)
+	if err != nil || generated != "" || given != "correct horse" {
+		t.Errorf("given password: generated = %q, password = %q, err = %v", generated, given, err)
+	}
+
+	short := "short"
+	if _, err := passwordOrGenerate(&short, false); err == nil {
+		t.Error("short password was accepted")
+	}
+
+	var empty string
+	generated, err = passwordOrGenerate(&empty, false)
+	if err != nil || generated == "" || generated != empty || len(generated) < minPasswordLength {
+		t.Errorf("generated = %q, password = %q, err = %v", generated, empty, err)
+	}
+
+	// -dry-run では保存されないパスワードを生成・表示しない
+	var dryRun string
+	generated, err = passwordOrGenerate(&dryRun, true)
+	if err != nil || generated != dryRunPassword || dryRun != "" {
+		t.Errorf("dry run: generated = %q, password = %q, err = %v", generated, dryRun, err)
+	}
+}
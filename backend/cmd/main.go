@@ -1,18 +1,45 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/joho/godotenv"
+	"github.com/nats-io/nats.go"
 
+	"app-template/api"
 	"app-template/internal/controller"
+	"app-template/internal/graphqlserver"
+	"app-template/internal/grpcserver"
 	"app-template/internal/repository"
 	"app-template/internal/usecase"
+	"app-template/pkg/auth"
+	"app-template/pkg/cors"
 	"app-template/pkg/database"
+	"app-template/pkg/docs"
+	"app-template/pkg/jobqueue"
+	"app-template/pkg/kvstore"
 	"app-template/pkg/middleware"
+	"app-template/pkg/oauth"
+	"app-template/pkg/openapi"
+	"app-template/pkg/outbox"
+	"app-template/pkg/problem"
+	"app-template/pkg/session"
+	"app-template/pkg/storage"
+	"app-template/pkg/tenant"
+	"app-template/pkg/webhook"
 )
 
 // @title Web Application API
@@ -46,21 +73,148 @@ func main() {
 	}
 	defer db.Close()
 
+	// セッションストア接続（REDIS_HOST未設定ならインメモリ）
+	store, err := kvstore.Connect(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to connect to session store: %v", err)
+	}
+
+	// ログインセッション（SESSION_BACKEND で保存先を選択。AUTH_MODE=cookie のセッションCookieに使う）
+	sessionBackend, err := session.Connect(context.Background(), db)
+	if err != nil {
+		log.Fatalf("Failed to configure sessions: %v", err)
+	}
+
+	// バックグラウンドジョブ（JOB_QUEUE_BACKEND で保存先を選択）
+	jobBackend, err := jobqueue.Connect(context.Background(), db)
+	if err != nil {
+		log.Fatalf("Failed to connect to job queue: %v", err)
+	}
+	jobs := jobqueue.New(jobBackend, jobQueueOptions())
+
+	webAuthn, err := webauthn.New(webAuthnConfig())
+	if err != nil {
+		log.Fatalf("Failed to configure WebAuthn: %v", err)
+	}
+
 	// リポジトリ層の初期化
 	userRepo := repository.NewUserRepository(db)
+	identityRepo := repository.NewUserIdentityRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	credentialRepo := repository.NewWebAuthnCredentialRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	auditRepo := repository.NewAuditLogRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	orgRepo := repository.NewOrganizationRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
 
 	// ユースケース層の初期化
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "default-secret-key"
+	tokens := auth.NewJWTManager(auth.LoadJWTConfig())
+	sessions := session.NewManager(sessionBackend, tokens.Config().Secret, sessionConfig())
+	// ファイルの保存先（STORAGE_BACKEND で local / memory / s3 を選択）。local・memory の署名付きURLはJWTの鍵で署名する
+	fileSigner := storage.LoadURLSigner(tokens.Config().Secret)
+	files, err := storage.Connect(fileSigner)
+	if err != nil {
+		log.Fatalf("Failed to configure storage: %v", err)
+	}
+	auditUseCase := usecase.NewAuditUseCase(auditRepo)
+	accountStates := usecase.NewAccountStateCache(userRepo, store)
+	webhookUseCase := usecase.NewWebhookUseCase(webhookRepo, jobs, webhook.NewSender(nil, "app-template-webhook/1.0"), auditUseCase)
+	jobs.Register(usecase.JobTypeWebhookDelivery, webhookUseCase.Deliver)
+	// 新規登録の受け付け方（REGISTRATION_MODE=invite_only で招待制）
+	registration, err := usecase.ParseRegistrationMode(os.Getenv("REGISTRATION_MODE"))
+	if err != nil {
+		log.Fatalf("Invalid REGISTRATION_MODE: %v", err)
+	}
+	userUseCase := usecase.NewUserUseCase(userRepo, mfaRepo, tokens, auditUseCase, accountStates, sessions, registration)
+	// ドメインイベントのリレー（OUTBOX_SINKS で送信先を選択）
+	sinks, closeSinks, err := outboxSinks(context.Background(), webhookUseCase)
+	if err != nil {
+		log.Fatalf("Failed to configure outbox sinks: %v", err)
 	}
-	userUseCase := usecase.NewUserUseCase(userRepo, jwtSecret)
+	defer closeSinks()
+	relay := outbox.NewRelay(db, sinks, outbox.DefaultOptions)
+	oauthStates := oauth.NewStateCodec(tokens.Config().Secret, 10*time.Minute)
+	mfaUseCase := usecase.NewMFAUseCase(userRepo, mfaRepo, store, tokens, auditUseCase, totpIssuer())
+	oauthUseCase := usecase.NewOAuthUseCase(userRepo, identityRepo, mfaRepo, oauth.LoadRegistry(context.Background()), oauthStates, tokens, auditUseCase, registration)
+	passkeyUseCase := usecase.NewPasskeyUseCase(userRepo, credentialRepo, store, webAuthn, tokens, auditUseCase)
+	apiKeyUseCase := usecase.NewAPIKeyUseCase(apiKeyRepo, userRepo)
+	userBulkUseCase := usecase.NewUserBulkUseCase(userRepo, auditUseCase)
+	orgUseCase := usecase.NewOrganizationUseCase(orgRepo, userRepo, tokens, auditUseCase)
+	tenantOptions := tenant.LoadOptions()
+	invitationUseCase := usecase.NewInvitationUseCase(invitationRepo, orgRepo, userRepo, userUseCase, tokens, auditUseCase, invitationOptions())
+	avatarUseCase := usecase.NewAvatarUseCase(userRepo, files, auditUseCase)
 
 	// コントローラー層の初期化
-	userController := controller.NewUserController(userUseCase)
+	controllers := &controllers{
+		user:     controller.NewUserController(userUseCase, sessions),
+		oauth:    controller.NewOAuthController(oauthUseCase, oauthStates, os.Getenv("APP_ENV") != "development", sessions),
+		mfa:      controller.NewMFAController(mfaUseCase, sessions),
+		passkey:  controller.NewPasskeyController(passkeyUseCase, sessions),
+		apiKey:   controller.NewAPIKeyController(apiKeyUseCase),
+		audit:    controller.NewAuditController(auditUseCase),
+		userBulk: controller.NewUserBulkController(userBulkUseCase),
+		job:      controller.NewJobController(jobs),
+		webhook:  controller.NewWebhookController(webhookUseCase),
+		org:      controller.NewOrganizationController(orgUseCase),
+		invite:   controller.NewInvitationController(invitationUseCase, sessions),
+		avatar:   controller.NewAvatarController(avatarUseCase),
+		files:    storage.DownloadHandler(files, fileSigner),
+	}
+
+	// GraphQL（クエリの深さ・複雑度の上限付き）
+	controllers.graphql, err = graphqlserver.NewHandler(userUseCase, sessions, graphqlLimits())
+	if err != nil {
+		log.Fatalf("Failed to build GraphQL schema: %v", err)
+	}
+
+	// OpenAPI仕様によるリクエスト・レスポンス検証
+	spec, err := openapi.Load(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to load OpenAPI spec: %v", err)
+	}
+	validator, err := middleware.OpenAPIValidator(spec, middleware.OpenAPIOptions{
+		ValidateResponses: validateResponses(),
+	})
+	if err != nil {
+		log.Fatalf("Failed to configure OpenAPI validation: %v", err)
+	}
+
+	// エラーレスポンスの形式
+	errorFormat, err := problem.ParseFormat(os.Getenv("ERROR_FORMAT"))
+	if err != nil {
+		log.Fatalf("Invalid ERROR_FORMAT: %v", err)
+	}
+	errorOptions := problem.Options{
+		Format:      errorFormat,
+		TypeBaseURL: os.Getenv("PROBLEM_TYPE_BASE_URL"),
+	}
+
+	// CORSのポリシー
+	corsEngine, err := cors.New(corsConfig())
+	if err != nil {
+		log.Fatalf("Invalid CORS configuration: %v", err)
+	}
 
 	// Ginルーターの設定
-	r := setupRouter(userController)
+	r := setupRouter(controllers, tokens, apiKeyUseCase, sessions, accountStates, orgUseCase, tenantOptions, validator, corsEngine, errorOptions, docsEnabled())
+
+	// gRPCサーバー起動（REST APIと同じプロセスで別ポート）
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "9090"
+	}
+	listener, err := net.Listen("tcp", ":"+grpcPort)
+	if err != nil {
+		log.Fatalf("Failed to listen on gRPC port: %v", err)
+	}
+	grpcServer := grpcserver.NewServer(userUseCase, sessions, accountStates, orgUseCase, tenantOptions, tokens, apiKeyUseCase)
+	go func() {
+		log.Printf("gRPC server starting on port %s", grpcPort)
+		if err := grpcServer.Serve(listener); err != nil {
+			log.Fatalf("Failed to start gRPC server: %v", err)
+		}
+	}()
 
 	// サーバー起動
 	port := os.Getenv("PORT")
@@ -68,19 +222,86 @@ func main() {
 		port = "8080"
 	}
 
-	log.Printf("Server starting on port %s", port)
-	if err := r.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: r,
 	}
+	go func() {
+		log.Printf("Server starting on port %s", port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// ジョブのワーカー・イベントのリレー起動
+	jobs.Start()
+	relay.Start()
+
+	// SIGINT / SIGTERM を受けたら新しいリクエスト・ジョブの受け付けを止め、処理中のものを待って終了
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+	stop()
+	log.Println("Shutting down server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to shut down server: %v", err)
+	}
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+	}
+	if err := relay.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to wait for outbox relay: %v", err)
+	}
+	if err := jobs.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to wait for running jobs: %v", err)
+	}
+	log.Println("Server stopped")
+}
+
+// controllers ルーティングに使うコントローラー群
+type controllers struct {
+	user     *controller.UserController
+	oauth    *controller.OAuthController
+	mfa      *controller.MFAController
+	passkey  *controller.PasskeyController
+	apiKey   *controller.APIKeyController
+	audit    *controller.AuditController
+	userBulk *controller.UserBulkController
+	job      *controller.JobController
+	webhook  *controller.WebhookController
+	org      *controller.OrganizationController
+	invite   *controller.InvitationController
+	avatar   *controller.AvatarController
+	files    gin.HandlerFunc
+	graphql  *graphqlserver.Handler
 }
 
 // setupRouter ルーターの設定
-func setupRouter(userController *controller.UserController) *gin.Engine {
+// APIキーはユーザーAPIのみで受け付け、/me配下の認証設定はJWTでのみ操作できる
+// 認証後は毎リクエストでアカウントの状態を確認し、停止中のユーザーを拒否する
+// ユーザーAPIとGraphQLではテナント（組織）を解決し、ユーザーの取得・更新をその組織のメンバーに絞り込む
+func setupRouter(c *controllers, tokens *auth.JWTManager, apiKeys auth.Authenticator, sessions auth.CookieAuthenticator, accounts auth.AccountChecker, tenants tenant.Resolver, tenantOptions tenant.Options, validator gin.HandlerFunc, corsEngine *cors.Engine, errorOptions problem.Options, docsEnabled bool) *gin.Engine {
 	r := gin.Default()
 
+	// ルートごとの必要権限を記録（GET /api/v1/admin/routes で一覧を出力）
+	routes := middleware.NewRouteMatrix()
+
 	// ミドルウェアの設定
-	r.Use(middleware.CORS())
+	r.Use(middleware.RequestInfo())
+	r.Use(middleware.ErrorFormat(errorOptions))
+	r.Use(middleware.CORS(corsEngine))
 	r.Use(middleware.RequestLogger())
+	r.Use(validator)
 
 	// ヘルスチェック
 	r.GET("/health", func(c *gin.Context) {
@@ -90,26 +311,398 @@ func setupRouter(userController *controller.UserController) *gin.Engine {
 		})
 	})
 
+	// API仕様（api/ の分割ファイルをマージして埋め込んだもの）
+	r.GET("/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", openapi.JSON())
+	})
+
+	// APIドキュメント（Swagger UI）
+	if docsEnabled {
+		r.GET("/docs/*filepath", docs.Handler(docs.Options{
+			Title:   "Web Application API",
+			SpecURL: "/openapi.json",
+		}))
+	}
+
+	// GraphQL（認証は任意で、操作ごとの認可はリゾルバーで行う）
+	graphqlAuth := middleware.OptionalAuthenticate(accounts, tokens, apiKeys, sessions)
+	graphqlTenant := middleware.Tenant(tenants, tenantOptions)
+	r.GET("/graphql", graphqlAuth, graphqlTenant, c.graphql.Serve)
+	r.POST("/graphql", graphqlAuth, graphqlTenant, c.graphql.Serve)
+
+	// 生成されたハンドラーがパスパラメータを解析してコントローラーを呼び出す
+	authAPI := api.AuthHandlers{Server: c.user}
+	usersAPI := api.UsersHandlers{Server: c.user}
+	oauthAPI := api.OAuthHandlers{Server: c.oauth}
+	mfaAPI := api.MFAHandlers{Server: c.mfa}
+	passkeysAPI := api.PasskeysHandlers{Server: c.passkey}
+	apiKeysAPI := api.APIKeysHandlers{Server: c.apiKey}
+	avatarsAPI := api.AvatarsHandlers{Server: c.avatar}
+	orgsAPI := api.OrganizationsHandlers{Server: c.org}
+	invitationsAPI := api.InvitationsHandlers{Server: c.invite}
+	auditAPI := api.AuditLogsHandlers{Server: c.audit}
+	userBulkAPI := api.UserBulkHandlers{Server: c.userBulk}
+	jobsAPI := api.JobsHandlers{Server: c.job}
+	webhooksAPI := api.WebhooksHandlers{Server: c.webhook}
+
 	// API v1グループ
 	v1 := r.Group("/api/v1")
 	{
 		// 認証関連（認証不要）
-		auth := v1.Group("/auth")
+		authGroup := v1.Group("/auth")
 		{
-			auth.POST("/register", userController.Register)
-			auth.POST("/login", userController.Login)
+			authGroup.POST("/register", authAPI.RegisterUser)
+			authGroup.POST("/login", authAPI.LoginUser)
+			authGroup.POST("/logout", authAPI.LogoutUser)
+			authGroup.GET("/oauth/:provider", oauthAPI.AuthorizeOAuth)
+			authGroup.GET("/oauth/:provider/callback", oauthAPI.CompleteOAuthLogin)
+			authGroup.POST("/mfa/verify", mfaAPI.VerifyMFA)
+			authGroup.POST("/mfa/passkey/begin", passkeysAPI.BeginPasskeyMFA)
+			authGroup.POST("/mfa/passkey/finish", passkeysAPI.FinishPasskeyMFA)
+			authGroup.POST("/passkey/login/begin", passkeysAPI.BeginPasskeyLogin)
+			authGroup.POST("/passkey/login/finish", passkeysAPI.FinishPasskeyLogin)
 		}
 
+		// ログイン中ユーザー自身の設定（認証必要）
+		me := v1.Group("/me")
+		me.Use(middleware.JWTAuth(tokens, accounts, sessions))
+		{
+			routes.Handle(me, http.MethodGet, "/identities", middleware.Authenticated(), oauthAPI.GetIdentities)
+			routes.Handle(me, http.MethodPost, "/identities/:provider", middleware.Authenticated(), oauthAPI.LinkIdentity)
+			routes.Handle(me, http.MethodDelete, "/identities/:provider", middleware.Authenticated(), oauthAPI.UnlinkIdentity)
+			routes.Handle(me, http.MethodGet, "/mfa", middleware.Authenticated(), mfaAPI.GetMFAStatus)
+			routes.Handle(me, http.MethodPost, "/mfa/totp", middleware.Authenticated(), mfaAPI.EnrollTOTP)
+			routes.Handle(me, http.MethodPost, "/mfa/totp/confirm", middleware.Authenticated(), mfaAPI.ConfirmTOTP)
+			routes.Handle(me, http.MethodDelete, "/mfa/totp", middleware.Authenticated(), mfaAPI.DisableTOTP)
+			routes.Handle(me, http.MethodPost, "/mfa/recovery-codes", middleware.Authenticated(), mfaAPI.RegenerateRecoveryCodes)
+			routes.Handle(me, http.MethodGet, "/passkeys", middleware.Authenticated(), passkeysAPI.GetPasskeys)
+			routes.Handle(me, http.MethodPost, "/passkeys/register/begin", middleware.Authenticated(), passkeysAPI.BeginPasskeyRegistration)
+			routes.Handle(me, http.MethodPost, "/passkeys/register/finish", middleware.Authenticated(), passkeysAPI.FinishPasskeyRegistration)
+			routes.Handle(me, http.MethodDelete, "/passkeys/:id", middleware.Authenticated(), passkeysAPI.DeletePasskey)
+			routes.Handle(me, http.MethodGet, "/api-keys", middleware.Authenticated(), apiKeysAPI.GetAPIKeys)
+			routes.Handle(me, http.MethodPost, "/api-keys", middleware.Authenticated(), apiKeysAPI.CreateAPIKey)
+			routes.Handle(me, http.MethodDelete, "/api-keys/:id", middleware.Authenticated(), apiKeysAPI.RevokeAPIKey)
+			routes.Handle(me, http.MethodPut, "/avatar", middleware.Authenticated(), avatarsAPI.UploadAvatar)
+			routes.Handle(me, http.MethodDelete, "/avatar", middleware.Authenticated(), avatarsAPI.DeleteAvatar)
+		}
+
+		// アバター画像（img 要素から読み込めるよう認証不要）
+		v1.GET("/users/:id/avatar", avatarsAPI.GetAvatar)
+
+		// 署名付きURLのファイル配信（URLの署名と有効期限で認可するため認証不要）
+		v1.GET("/files/*key", c.files)
+		v1.HEAD("/files/*key", c.files)
+
 		// ユーザー関連（認証必要）
 		users := v1.Group("/users")
-		users.Use(middleware.JWTAuth())
+		users.Use(middleware.Authenticate(accounts, tokens, apiKeys, sessions), middleware.Tenant(tenants, tenantOptions))
+		{
+			routes.Handle(users, http.MethodGet, "", middleware.Require(auth.PermUsersRead), usersAPI.GetUsers)
+			routes.Handle(users, http.MethodGet, "/:id", middleware.Require(auth.PermUsersRead), usersAPI.GetUserByID)
+			// 本人以外のユーザーの更新・削除には管理者権限が必要
+			routes.Handle(users, http.MethodPut, "/:id", middleware.Require(auth.PermUsersWrite).OthersRequire("id", auth.PermUsersAdmin), usersAPI.UpdateUser)
+			routes.Handle(users, http.MethodDelete, "/:id", middleware.Require(auth.PermUsersWrite).OthersRequire("id", auth.PermUsersAdmin), usersAPI.DeleteUser)
+			routes.Handle(users, http.MethodPut, "/:id/role", middleware.Require(auth.PermUsersAdmin), usersAPI.ChangeUserRole)
+			routes.Handle(users, http.MethodPost, "/:id/suspend", middleware.Require(auth.PermUsersAdmin), usersAPI.SuspendUser)
+			routes.Handle(users, http.MethodPost, "/:id/disable", middleware.Require(auth.PermUsersAdmin), usersAPI.DisableUser)
+			routes.Handle(users, http.MethodPost, "/:id/reinstate", middleware.Require(auth.PermUsersAdmin), usersAPI.ReinstateUser)
+		}
+
+		// 組織（認証必要。組織内の操作の可否は組織でのロールで判定する）
+		orgs := v1.Group("/organizations")
+		orgs.Use(middleware.Authenticate(accounts, tokens, apiKeys, sessions))
 		{
-			users.GET("", userController.GetUsers)
-			users.GET("/:id", userController.GetUser)
-			users.PUT("/:id", userController.UpdateUser)
-			users.DELETE("/:id", userController.DeleteUser)
+			routes.Handle(orgs, http.MethodGet, "", middleware.Require(auth.PermUsersRead), orgsAPI.GetOrganizations)
+			routes.Handle(orgs, http.MethodPost, "", middleware.Require(auth.PermUsersWrite), orgsAPI.CreateOrganization)
+			routes.Handle(orgs, http.MethodGet, "/:id", middleware.Require(auth.PermUsersRead), orgsAPI.GetOrganization)
+			routes.Handle(orgs, http.MethodPost, "/:id/token", middleware.Require(auth.PermUsersRead), orgsAPI.IssueOrganizationToken)
+			routes.Handle(orgs, http.MethodGet, "/:id/members", middleware.Require(auth.PermUsersRead), orgsAPI.GetOrganizationMembers)
+			routes.Handle(orgs, http.MethodPost, "/:id/members", middleware.Require(auth.PermUsersWrite), orgsAPI.AddOrganizationMember)
+			routes.Handle(orgs, http.MethodPut, "/:id/members/:user_id", middleware.Require(auth.PermUsersWrite), orgsAPI.UpdateOrganizationMember)
+			routes.Handle(orgs, http.MethodDelete, "/:id/members/:user_id", middleware.Require(auth.PermUsersWrite), orgsAPI.RemoveOrganizationMember)
+		}
+
+		// 招待（承諾・辞退は招待リンクのトークンで行うため認証不要）
+		invitations := v1.Group("/invitations")
+		{
+			invitations.GET("/preview", invitationsAPI.PreviewInvitation)
+			invitations.POST("/accept", middleware.OptionalAuthenticate(accounts, tokens, sessions), invitationsAPI.AcceptInvitation)
+			invitations.POST("/decline", invitationsAPI.DeclineInvitation)
+
+			// 作成・一覧・取り消し（テナント指定時は組織の owner / admin、それ以外は管理者のみ。ユースケースで判定する）
+			managed := invitations.Group("")
+			managed.Use(middleware.Authenticate(accounts, tokens, apiKeys, sessions), middleware.Tenant(tenants, tenantOptions))
+			routes.Handle(managed, http.MethodGet, "", middleware.Require(auth.PermUsersWrite), invitationsAPI.GetInvitations)
+			routes.Handle(managed, http.MethodPost, "", middleware.Require(auth.PermUsersWrite), invitationsAPI.CreateInvitation)
+			routes.Handle(managed, http.MethodDelete, "/:id", middleware.Require(auth.PermUsersWrite), invitationsAPI.RevokeInvitation)
+		}
+
+		// 管理者向け（認証・管理者権限必要）
+		admin := v1.Group("/admin")
+		admin.Use(middleware.Authenticate(accounts, tokens, apiKeys, sessions))
+		{
+			routes.Handle(admin, http.MethodGet, "/routes", middleware.Require(auth.PermUsersAdmin), routes.Handler(r))
+			routes.Handle(admin, http.MethodGet, "/audit-logs", middleware.Require(auth.PermUsersAdmin), auditAPI.GetAuditLogs)
+			routes.Handle(admin, http.MethodPost, "/users/import", middleware.Require(auth.PermUsersAdmin), userBulkAPI.ImportUsers)
+			routes.Handle(admin, http.MethodGet, "/users/export", middleware.Require(auth.PermUsersAdmin), userBulkAPI.ExportUsers)
+			routes.Handle(admin, http.MethodGet, "/jobs/dead", middleware.Require(auth.PermUsersAdmin), jobsAPI.GetDeadJobs)
+			routes.Handle(admin, http.MethodPost, "/jobs/dead/:id/requeue", middleware.Require(auth.PermUsersAdmin), jobsAPI.RequeueDeadJob)
+			routes.Handle(admin, http.MethodGet, "/webhooks", middleware.Require(auth.PermUsersAdmin), webhooksAPI.GetWebhooks)
+			routes.Handle(admin, http.MethodPost, "/webhooks", middleware.Require(auth.PermUsersAdmin), webhooksAPI.CreateWebhook)
+			routes.Handle(admin, http.MethodGet, "/webhooks/:id", middleware.Require(auth.PermUsersAdmin), webhooksAPI.GetWebhook)
+			routes.Handle(admin, http.MethodPut, "/webhooks/:id", middleware.Require(auth.PermUsersAdmin), webhooksAPI.UpdateWebhook)
+			routes.Handle(admin, http.MethodDelete, "/webhooks/:id", middleware.Require(auth.PermUsersAdmin), webhooksAPI.DeleteWebhook)
+			routes.Handle(admin, http.MethodGet, "/webhooks/:id/deliveries", middleware.Require(auth.PermUsersAdmin), webhooksAPI.GetWebhookDeliveries)
+			routes.Handle(admin, http.MethodPost, "/webhooks/:id/deliveries/:delivery_id/redeliver", middleware.Require(auth.PermUsersAdmin), webhooksAPI.RedeliverWebhookDelivery)
 		}
 	}
 
 	return r
-} 
\ No newline at end of file
+} 
+
+// validateResponses レスポンスをOpenAPI仕様と照合するか（未設定なら開発・テスト環境のみ）
+func validateResponses() bool {
+	if value := os.Getenv("OPENAPI_VALIDATE_RESPONSES"); value != "" {
+		return value == "true" || value == "1"
+	}
+	env := os.Getenv("APP_ENV")
+	return env == "development" || env == "test"
+}
+
+// corsConfig CORSの設定（既定のポリシーは CORS_* の環境変数から読み込む）
+// 認証不要で配信するアバター画像・署名付きURLのファイルは、資格情報なしで全オリジンからの取得を許可する
+func corsConfig() cors.Config {
+	policy, err := cors.LoadPolicy()
+	if err != nil {
+		log.Fatalf("Invalid CORS configuration: %v", err)
+	}
+
+	public := cors.Policy{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{http.MethodGet, http.MethodHead},
+		AllowedHeaders: []string{"Range", "X-Request-ID"},
+		ExposedHeaders: []string{"Accept-Ranges", "Content-Range", "X-Request-ID"},
+		MaxAge:         policy.MaxAge,
+	}
+	return cors.Config{
+		Default: policy,
+		Routes: []cors.Route{
+			{Path: "/api/v1/users/*/avatar", Policy: public},
+			{Path: "/api/v1/files/**", Policy: public},
+		},
+	}
+}
+
+// docsEnabled /docs でAPIドキュメントを配信するか（未設定なら本番環境以外で有効）
+func docsEnabled() bool {
+	if value := os.Getenv("DOCS_ENABLED"); value != "" {
+		return value == "true" || value == "1"
+	}
+	return os.Getenv("APP_ENV") != "production"
+}
+
+// graphqlLimits GraphQLクエリの上限（GRAPHQL_MAX_DEPTH / GRAPHQL_MAX_COMPLEXITY、0で制限なし）
+func graphqlLimits() graphqlserver.Limits {
+	limits := graphqlserver.DefaultLimits
+	if value := os.Getenv("GRAPHQL_MAX_DEPTH"); value != "" {
+		depth, err := strconv.Atoi(value)
+		if err != nil {
+			log.Fatalf("Invalid GRAPHQL_MAX_DEPTH: %v", err)
+		}
+		limits.MaxDepth = depth
+	}
+	if value := os.Getenv("GRAPHQL_MAX_COMPLEXITY"); value != "" {
+		complexity, err := strconv.Atoi(value)
+		if err != nil {
+			log.Fatalf("Invalid GRAPHQL_MAX_COMPLEXITY: %v", err)
+		}
+		limits.MaxComplexity = complexity
+	}
+	return limits
+}
+
+// jobQueueOptions ジョブキューの設定（JOB_WORKERS でワーカー数を指定）
+func jobQueueOptions() jobqueue.Options {
+	opts := jobqueue.DefaultOptions
+	if value := os.Getenv("JOB_WORKERS"); value != "" {
+		workers, err := strconv.Atoi(value)
+		if err != nil || workers <= 0 {
+			log.Fatalf("Invalid JOB_WORKERS: %q", value)
+		}
+		opts.Workers = workers
+	}
+	return opts
+}
+
+// outboxSinks ドメインイベントの送信先（OUTBOX_SINKS にカンマ区切りで log / webhook / redis / nats を指定、既定は webhook）
+// 戻り値の関数で送信先の接続を閉じる
+func outboxSinks(ctx context.Context, webhooks usecase.WebhookUseCase) ([]outbox.Sink, func(), error) {
+	names := os.Getenv("OUTBOX_SINKS")
+	if names == "" {
+		names = "webhook"
+	}
+
+	var sinks []outbox.Sink
+	var closers []func()
+	closeAll := func() {
+		for _, closer := range closers {
+			closer()
+		}
+	}
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "log":
+			sinks = append(sinks, outbox.NewLogSink(nil))
+		case "webhook":
+			sinks = append(sinks, outbox.SinkFunc("webhook", webhooks.Dispatch))
+		case "redis":
+			client, err := kvstore.ConnectRedis(ctx)
+			if err != nil {
+				closeAll()
+				return nil, nil, err
+			}
+			if client == nil {
+				closeAll()
+				return nil, nil, fmt.Errorf("redis sink requires REDIS_HOST")
+			}
+			closers = append(closers, func() { client.Close() })
+			stream := os.Getenv("OUTBOX_REDIS_STREAM")
+			if stream == "" {
+				stream = "events"
+			}
+			sinks = append(sinks, outbox.NewRedisStreamSink(client, stream, 100000))
+		case "nats":
+			url := os.Getenv("NATS_URL")
+			if url == "" {
+				url = nats.DefaultURL
+			}
+			conn, err := nats.Connect(url, nats.Name("app-template-outbox"))
+			if err != nil {
+				closeAll()
+				return nil, nil, fmt.Errorf("failed to connect to nats: %w", err)
+			}
+			closers = append(closers, func() { conn.Drain() })
+			prefix := os.Getenv("OUTBOX_NATS_SUBJECT_PREFIX")
+			if prefix == "" {
+				prefix = "events"
+			}
+			sinks = append(sinks, outbox.NewNATSSink(conn, prefix))
+		default:
+			closeAll()
+			return nil, nil, fmt.Errorf("unknown outbox sink %q", name)
+		}
+	}
+	return sinks, closeAll, nil
+}
+
+// invitationOptions 招待の設定（INVITATION_TTL、INVITATION_ACCEPT_URL）
+func invitationOptions() usecase.InvitationOptions {
+	opts := usecase.InvitationOptions{
+		TTL:       usecase.DefaultInvitationTTL,
+		AcceptURL: os.Getenv("INVITATION_ACCEPT_URL"),
+	}
+	if value := os.Getenv("INVITATION_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("Invalid INVITATION_TTL: %v", err)
+		}
+		opts.TTL = ttl
+	}
+	return opts
+}
+
+// sessionConfig セッションCookieの設定
+// AUTH_MODE=cookie でログイン時にセッションCookieを発行する（既定の token はJWTを返す）。
+// SESSION_TTL（既定7日）、SESSION_COOKIE_NAME / SESSION_COOKIE_DOMAIN、SESSION_COOKIE_SAMESITE（lax / strict / none、既定 lax）、
+// SESSION_COOKIE_SECURE=false で Secure 属性を外す（HTTPSでない開発環境用）
+func sessionConfig() session.Config {
+	cfg := session.Config{
+		CookieName: os.Getenv("SESSION_COOKIE_NAME"),
+		TTL:        session.DefaultTTL,
+		Domain:     os.Getenv("SESSION_COOKIE_DOMAIN"),
+		Secure:     os.Getenv("SESSION_COOKIE_SECURE") != "false",
+		SameSite:   http.SameSiteLaxMode,
+	}
+
+	switch mode := os.Getenv("AUTH_MODE"); mode {
+	case "", "token":
+	case "cookie":
+		cfg.CookieMode = true
+	default:
+		log.Fatalf("Invalid AUTH_MODE %q (expected token or cookie)", mode)
+	}
+
+	if value := os.Getenv("SESSION_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl <= 0 {
+			log.Fatalf("Invalid SESSION_TTL: %q", value)
+		}
+		cfg.TTL = ttl
+	}
+
+	switch sameSite := os.Getenv("SESSION_COOKIE_SAMESITE"); sameSite {
+	case "", "lax":
+	case "strict":
+		cfg.SameSite = http.SameSiteStrictMode
+	case "none":
+		// SameSite=None は Secure でなければブラウザが受け付けない
+		if !cfg.Secure {
+			log.Fatalf("SESSION_COOKIE_SAMESITE=none requires SESSION_COOKIE_SECURE")
+		}
+		cfg.SameSite = http.SameSiteNoneMode
+	default:
+		log.Fatalf("Invalid SESSION_COOKIE_SAMESITE %q (expected lax, strict or none)", sameSite)
+	}
+
+	return cfg
+}
+
+// shutdownTimeout 終了時に処理中のリクエスト・ジョブを待つ時間（SHUTDOWN_TIMEOUT、既定30秒）
+func shutdownTimeout() time.Duration {
+	if value := os.Getenv("SHUTDOWN_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("Invalid SHUTDOWN_TIMEOUT: %v", err)
+		}
+		return timeout
+	}
+	return 30 * time.Second
+}
+
+// totpIssuer 認証アプリに表示する発行者名
+func totpIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "App Template"
+}
+
+// webAuthnConfig 環境変数からWebAuthnのRelying Party設定を作成
+func webAuthnConfig() *webauthn.Config {
+	rpID := os.Getenv("WEBAUTHN_RP_ID")
+	if rpID == "" {
+		rpID = "localhost"
+	}
+
+	rpName := os.Getenv("WEBAUTHN_RP_NAME")
+	if rpName == "" {
+		rpName = "App Template"
+	}
+
+	origins := []string{"http://localhost:3000"}
+	if value := os.Getenv("WEBAUTHN_RP_ORIGINS"); value != "" {
+		origins = nil
+		for _, origin := range strings.Split(value, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				origins = append(origins, origin)
+			}
+		}
+	}
+
+	return &webauthn.Config{
+		RPID:          rpID,
+		RPDisplayName: rpName,
+		RPOrigins:     origins,
+	}
+}
\ No newline at end of file
@@ -0,0 +1,486 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/format"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// problemPackage 生成コードがエラーレスポンスの出力に使うパッケージ
+const problemPackage = "app-template/pkg/problem"
+
+// initialisms Goの命名規則で大文字にする略語
+var initialisms = map[string]bool{
+	"API": true, "CSRF": true, "HTTP": true, "ID": true, "JSON": true, "JWT": true,
+	"MFA": true, "TOTP": true, "URL": true, "UUID": true,
+}
+
+// spellings 大文字・小文字の位置が決まっている語（oauth → OAuth）
+var spellings = map[string]string{
+	"OAUTH": "OAuth",
+}
+
+// methodOrder 生成する操作の並び順
+var methodOrder = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// operation 生成対象の操作
+type operation struct {
+	Name        string
+	Method      string
+	Path        string
+	Summary     string
+	PathParams  []parameter
+	QueryParams []parameter
+}
+
+// parameter パスパラメータ・クエリパラメータ
+type parameter struct {
+	Name        string
+	GoName      string
+	GoType      string
+	Description string
+}
+
+// generator 仕様からGoのコードを生成する
+type generator struct {
+	doc *openapi3.T
+	buf bytes.Buffer
+}
+
+// generate 仕様ファイルを読み込んでモデルとサーバーインターフェースを生成
+func generate(specPath, pkg string) ([]byte, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromFile(specPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load spec: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid spec: %w", err)
+	}
+
+	g := &generator{doc: doc}
+	operations, err := g.operations()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.models(); err != nil {
+		return nil, err
+	}
+	g.params(operations)
+	g.servers(operations)
+	g.helpers()
+
+	// 日時を使うモデルがある場合のみ time を import する
+	imports := []string{`"net/http"`, `"strconv"`}
+	if bytes.Contains(g.buf.Bytes(), []byte("time.Time")) {
+		imports = append(imports, `"time"`)
+	}
+
+	var file bytes.Buffer
+	fmt.Fprintf(&file, "// Code generated by cmd/openapi gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&file, "// Package %s OpenAPI仕様（api/）から生成したモデルとサーバーインターフェース\n", pkg)
+	fmt.Fprintf(&file, "package %s\n\n", pkg)
+	fmt.Fprintf(&file, "import (\n%s\n\n\"github.com/gin-gonic/gin\"\n\n\"%s\"\n)\n\n", strings.Join(imports, "\n"), problemPackage)
+	file.Write(g.buf.Bytes())
+
+	source, err := format.Source(file.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w\n%s", err, file.String())
+	}
+	return source, nil
+}
+
+// operations 仕様の操作をタグごとに並べて返す
+func (g *generator) operations() (map[string][]operation, error) {
+	byTag := map[string][]operation{}
+
+	paths := g.doc.Paths.Map()
+	keys := make([]string, 0, len(paths))
+	for path := range paths {
+		keys = append(keys, path)
+	}
+	sort.Strings(keys)
+
+	for _, path := range keys {
+		item := paths[path]
+		for _, method := range methodOrder {
+			op := item.GetOperation(method)
+			if op == nil {
+				continue
+			}
+			if op.OperationID == "" {
+				return nil, fmt.Errorf("%s %s: operationId is required", method, path)
+			}
+			// 手書きのハンドラーで処理する操作（ファイル配信など）はサーバーインターフェースを生成しない
+			if handwritten, _ := op.Extensions["x-handwritten"].(bool); handwritten {
+				continue
+			}
+
+			generated := operation{
+				Name:    goName(op.OperationID),
+				Method:  method,
+				Path:    path,
+				Summary: op.Summary,
+			}
+
+			for _, ref := range append(append(openapi3.Parameters{}, item.Parameters...), op.Parameters...) {
+				param := ref.Value
+				goType, err := g.goType(param.Schema, true)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: parameter %s: %w", method, path, param.Name, err)
+				}
+				p := parameter{
+					Name:        param.Name,
+					GoName:      goName(param.Name),
+					GoType:      goType,
+					Description: param.Description,
+				}
+				switch param.In {
+				case openapi3.ParameterInPath:
+					generated.PathParams = append(generated.PathParams, p)
+				case openapi3.ParameterInQuery:
+					generated.QueryParams = append(generated.QueryParams, p)
+				}
+			}
+
+			tag := "default"
+			if len(op.Tags) > 0 {
+				tag = op.Tags[0]
+			}
+			byTag[tag] = append(byTag[tag], generated)
+		}
+	}
+
+	return byTag, nil
+}
+
+// models components.schemas のモデルを生成
+func (g *generator) models() error {
+	schemas := g.doc.Components.Schemas
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		schema := schemas[name].Value
+		g.comment(goName(name), schema.Description)
+
+		if !schema.Type.Is(openapi3.TypeObject) {
+			goType, err := g.goType(schemas[name], true)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			g.printf("type %s %s\n\n", goName(name), goType)
+			continue
+		}
+
+		required := map[string]bool{}
+		for _, property := range schema.Required {
+			required[property] = true
+		}
+
+		properties := make([]string, 0, len(schema.Properties))
+		for property := range schema.Properties {
+			properties = append(properties, property)
+		}
+		sort.Strings(properties)
+
+		g.printf("type %s struct {\n", goName(name))
+		for _, property := range properties {
+			ref := schema.Properties[property]
+			goType, err := g.goType(ref, required[property])
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", name, property, err)
+			}
+
+			tag := property
+			if !required[property] {
+				tag += ",omitempty"
+			}
+			if description := propertyDescription(ref.Value); description != "" {
+				g.printf("// %s %s\n", goName(property), description)
+			}
+			g.printf("%s %s `json:%q`\n", goName(property), goType, tag)
+		}
+		g.printf("}\n\n")
+	}
+
+	return nil
+}
+
+// params クエリパラメータの構造体を生成
+func (g *generator) params(byTag map[string][]operation) {
+	for _, tag := range sortedTags(byTag) {
+		for _, op := range byTag[tag] {
+			if len(op.QueryParams) == 0 {
+				continue
+			}
+			g.printf("// %sParams %s のクエリパラメータ\n", op.Name, op.Name)
+			g.printf("type %sParams struct {\n", op.Name)
+			for _, p := range op.QueryParams {
+				if p.Description != "" {
+					g.printf("// %s %s\n", p.GoName, p.Description)
+				}
+				g.printf("%s *%s `form:%q`\n", p.GoName, p.GoType, p.Name)
+			}
+			g.printf("}\n\n")
+		}
+	}
+}
+
+// servers タグごとのサーバーインターフェースとgin.HandlerFuncへの変換を生成
+func (g *generator) servers(byTag map[string][]operation) {
+	for _, tag := range sortedTags(byTag) {
+		server := goName(tag) + "Server"
+		handlers := goName(tag) + "Handlers"
+
+		g.printf("// %s %s タグの操作を実装するサーバー\n", server, tag)
+		g.printf("// パス・クエリパラメータは仕様の型に変換して渡す。リクエストボディは実装側で読み込む\n")
+		g.printf("type %s interface {\n", server)
+		for _, op := range byTag[tag] {
+			g.printf("// %s %s\n// %s %s\n", op.Name, op.Summary, op.Method, op.Path)
+			g.printf("%s(%s)\n", op.Name, signature(op))
+		}
+		g.printf("}\n\n")
+
+		g.printf("// %s %s の各操作をパラメータを解析するgin.HandlerFuncとして提供する\n", handlers, server)
+		g.printf("type %s struct {\nServer %s\n}\n\n", handlers, server)
+
+		for _, op := range byTag[tag] {
+			g.printf("// %s %s %s\n", op.Name, op.Method, op.Path)
+			g.printf("func (h %s) %s(c *gin.Context) {\n", handlers, op.Name)
+
+			args := []string{"c"}
+			for _, p := range op.PathParams {
+				arg := lowerFirst(p.GoName)
+				g.parse(arg, p, "c.Param("+fmt.Sprintf("%q", p.Name)+")", false)
+				args = append(args, arg)
+			}
+			if len(op.QueryParams) > 0 {
+				g.printf("var params %sParams\n", op.Name)
+				for _, p := range op.QueryParams {
+					g.printf("if value, ok := c.GetQuery(%q); ok {\n", p.Name)
+					g.parse("params."+p.GoName, p, "value", true)
+					g.printf("}\n")
+				}
+				args = append(args, "params")
+			}
+
+			g.printf("h.Server.%s(%s)\n}\n\n", op.Name, strings.Join(args, ", "))
+		}
+	}
+}
+
+// parse パラメータの文字列を仕様の型に変換するコードを出力
+func (g *generator) parse(target string, p parameter, source string, pointer bool) {
+	var parse string
+	switch p.GoType {
+	case "int64":
+		parse = "strconv.ParseInt(" + source + ", 10, 64)"
+	case "int":
+		parse = "strconv.Atoi(" + source + ")"
+	case "float64":
+		parse = "strconv.ParseFloat(" + source + ", 64)"
+	case "bool":
+		parse = "strconv.ParseBool(" + source + ")"
+	case "time.Time":
+		parse = "time.Parse(time.RFC3339, " + source + ")"
+	default:
+		// 文字列はそのまま渡す
+		if pointer {
+			g.printf("v := %s\n%s = &v\n", source, target)
+		} else {
+			g.printf("%s := %s\n", target, source)
+		}
+		return
+	}
+
+	variable := target
+	if pointer {
+		variable = "v"
+	}
+	g.printf("%s, err := %s\n", variable, parse)
+	g.printf("if err != nil {\ninvalidParameter(c, %q, err)\nreturn\n}\n", p.Name)
+	if pointer {
+		g.printf("%s = &v\n", target)
+	}
+}
+
+// helpers 生成コードが使う補助関数を出力
+func (g *generator) helpers() {
+	g.printf(`// invalidParameter パラメータの型が仕様と一致しない場合のレスポンス
+func invalidParameter(c *gin.Context, name string, err error) {
+	problem.Write(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid parameter "+strconv.Quote(name)+": "+err.Error(),
+		problem.FieldError{Field: name, Message: err.Error()})
+}
+`)
+}
+
+// goType スキーマに対応するGoの型
+func (g *generator) goType(ref *openapi3.SchemaRef, required bool) (string, error) {
+	if ref == nil || ref.Value == nil {
+		return "", fmt.Errorf("schema is missing")
+	}
+
+	if strings.HasPrefix(ref.Ref, "#/components/schemas/") {
+		name := goName(strings.TrimPrefix(ref.Ref, "#/components/schemas/"))
+		if !required {
+			return "*" + name, nil
+		}
+		return name, nil
+	}
+
+	schema := ref.Value
+	var goType string
+	switch {
+	case schema.Type.Is(openapi3.TypeString):
+		goType = "string"
+		if schema.Format == "date-time" {
+			goType = "time.Time"
+		}
+	case schema.Type.Is(openapi3.TypeInteger):
+		goType = "int"
+		if schema.Format == "int64" {
+			goType = "int64"
+		}
+	case schema.Type.Is(openapi3.TypeNumber):
+		goType = "float64"
+	case schema.Type.Is(openapi3.TypeBoolean):
+		goType = "bool"
+	case schema.Type.Is(openapi3.TypeArray):
+		items, err := g.goType(schema.Items, true)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + items, nil
+	case schema.Type.Is(openapi3.TypeObject):
+		if values := schema.AdditionalProperties.Schema; values != nil {
+			valueType, err := g.goType(values, true)
+			if err != nil {
+				return "", err
+			}
+			return "map[string]" + valueType, nil
+		}
+		return "map[string]interface{}", nil
+	default:
+		// 型を指定しないスキーマは任意のJSONの値
+		return "interface{}", nil
+	}
+
+	if !required || schema.Nullable {
+		return "*" + goType, nil
+	}
+	return goType, nil
+}
+
+// comment 型のドキュメントコメントを出力
+func (g *generator) comment(name, description string) {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		g.printf("// %s\n", name)
+		return
+	}
+	g.printf("// %s %s\n", name, strings.ReplaceAll(description, "\n", "\n// "))
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// signature サーバーインターフェースのメソッドの引数
+func signature(op operation) string {
+	args := []string{"c *gin.Context"}
+	for _, p := range op.PathParams {
+		args = append(args, lowerFirst(p.GoName)+" "+p.GoType)
+	}
+	if len(op.QueryParams) > 0 {
+		args = append(args, "params "+op.Name+"Params")
+	}
+	return strings.Join(args, ", ")
+}
+
+// propertyDescription プロパティの説明（列挙値があれば併記）
+func propertyDescription(schema *openapi3.Schema) string {
+	description := strings.ReplaceAll(strings.TrimSpace(schema.Description), "\n", " ")
+	if len(schema.Enum) == 0 {
+		return description
+	}
+	values := make([]string, 0, len(schema.Enum))
+	for _, value := range schema.Enum {
+		values = append(values, fmt.Sprint(value))
+	}
+	return strings.TrimSpace(description + " (" + strings.Join(values, ", ") + ")")
+}
+
+// sortedTags タグ名の一覧を並べて返す
+func sortedTags(byTag map[string][]operation) []string {
+	tags := make([]string, 0, len(byTag))
+	for tag := range byTag {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// goName operationId やプロパティ名をGoの識別子に変換（created_at → CreatedAt, getUserById → GetUserByID）
+func goName(name string) string {
+	var words []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = nil
+		}
+	}
+
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ' || r == '.':
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+
+	var b strings.Builder
+	for _, word := range words {
+		upper := strings.ToUpper(word)
+		if spelling, ok := spellings[upper]; ok {
+			b.WriteString(spelling)
+			continue
+		}
+		if initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// lowerFirst 先頭を小文字にする（ID → id, UserID → userID）
+func lowerFirst(name string) string {
+	for word := range initialisms {
+		if strings.HasPrefix(name, word) {
+			return strings.ToLower(word) + name[len(word):]
+		}
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+const usage = `Usage: openapi <command> [flags]
+
+Commands:
+  merge  [-in ../api/index.yml] OUTPUT...
+  gen    [-in pkg/openapi/openapi.json] [-out api/api.gen.go] [-package api]
+
+merge は分割されたAPI仕様（api/index.yml）の $ref を解決して1つの仕様にまとめます。
+出力形式は拡張子で決まります（.yml/.yaml は YAML、.json は JSON）。
+gen はマージ済みの仕様からモデルとタグごとのサーバーインターフェースを生成します。
+`
+
+func main() {
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(args[0], args[1:]); err != nil {
+		log.Fatalf("%s: %v", args[0], err)
+	}
+}
+
+// run サブコマンドを実行
+func run(command string, args []string) error {
+	switch command {
+	case "merge":
+		return mergeCommand(args)
+	case "gen":
+		return genCommand(args)
+	default:
+		return fmt.Errorf("unknown command. Available commands: merge, gen")
+	}
+}
+
+// mergeCommand 仕様をマージして各出力先に書き出す
+func mergeCommand(args []string) error {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	in := fs.String("in", "../api/index.yml", "分割された仕様のエントリーファイル")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		return fmt.Errorf("at least one output file is required")
+	}
+
+	merged, err := merge(*in)
+	if err != nil {
+		return err
+	}
+
+	for _, out := range fs.Args() {
+		if err := write(out, merged); err != nil {
+			return err
+		}
+		log.Printf("wrote %s", out)
+	}
+
+	return nil
+}
+
+// genCommand 生成したコードをファイルに書き出す
+func genCommand(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	in := fs.String("in", "pkg/openapi/openapi.json", "マージ済みの仕様ファイル")
+	out := fs.String("out", "api/api.gen.go", "出力するGoファイル")
+	pkg := fs.String("package", "api", "生成するパッケージ名")
+	fs.Parse(args)
+
+	source, err := generate(*in, *pkg)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(*out, source, 0o644)
+}
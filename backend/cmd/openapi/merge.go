@@ -0,0 +1,432 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// generatedHeader マージ結果のYAMLに付けるコメント
+const generatedHeader = `統合されたOpenAPI仕様ファイル
+このファイルは make merge-api により自動生成されます
+手動で編集せず、api/ ディレクトリ内の分割されたファイルを編集してください`
+
+// resolver 分割された仕様ファイルの $ref を解決する
+// components に登録された定義への参照は #/components/... に置き換え、それ以外は展開する
+type resolver struct {
+	entry string
+	// files 読み込み済みファイル（絶対パス → ルートノード）
+	files map[string]*yaml.Node
+	// components 参照先（ファイル#ポインタ） → components 内の参照
+	components map[string]string
+	// resolving 展開中の参照（循環参照の検出用）
+	resolving map[string]bool
+}
+
+// merge エントリーファイルから参照を解決した1つの仕様を作成
+func merge(entry string) (*yaml.Node, error) {
+	abs, err := filepath.Abs(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &resolver{
+		entry:      abs,
+		files:      map[string]*yaml.Node{},
+		components: map[string]string{},
+		resolving:  map[string]bool{},
+	}
+
+	root, err := r.load(abs)
+	if err != nil {
+		return nil, err
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: root must be a mapping", entry)
+	}
+
+	if err := r.registerComponents(root); err != nil {
+		return nil, err
+	}
+
+	merged := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", HeadComment: generatedHeader}
+	for i := 0; i < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+
+		var resolved *yaml.Node
+		if key.Value == "components" {
+			resolved, err = r.resolveComponents(value)
+		} else {
+			resolved, err = r.resolve(value, abs)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key.Value, err)
+		}
+		merged.Content = append(merged.Content, copyScalar(key), resolved)
+	}
+
+	return merged, nil
+}
+
+// registerComponents components の各定義が参照するファイル上の位置を記録
+func (r *resolver) registerComponents(root *yaml.Node) error {
+	components := mappingValue(root, "components")
+	if components == nil {
+		return nil
+	}
+
+	for i := 0; i < len(components.Content); i += 2 {
+		kind := components.Content[i].Value
+		definitions, err := r.definitions(components.Content[i+1])
+		if err != nil {
+			return fmt.Errorf("components.%s: %w", kind, err)
+		}
+
+		for j := 0; j < len(definitions.Content); j += 2 {
+			name := definitions.Content[j].Value
+			ref := refValue(definitions.Content[j+1])
+			if ref == "" || strings.HasPrefix(ref, "#") {
+				continue
+			}
+			target, _ := r.target(ref, r.entry)
+			r.components[target] = "#/components/" + kind + "/" + escapePointer(name)
+		}
+	}
+
+	return nil
+}
+
+// resolveComponents components を展開（各定義の本体はそのまま展開し、内部の参照は components に向ける）
+func (r *resolver) resolveComponents(components *yaml.Node) (*yaml.Node, error) {
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for i := 0; i < len(components.Content); i += 2 {
+		kind := components.Content[i]
+		definitions, err := r.definitions(components.Content[i+1])
+		if err != nil {
+			return nil, err
+		}
+
+		resolved := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for j := 0; j < len(definitions.Content); j += 2 {
+			definition, err := r.inline(definitions.Content[j+1], r.entry)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", kind.Value, definitions.Content[j].Value, err)
+			}
+			resolved.Content = append(resolved.Content, copyScalar(definitions.Content[j]), definition)
+		}
+		out.Content = append(out.Content, copyScalar(kind), resolved)
+	}
+	return out, nil
+}
+
+// definitions components の種類ごとの定義一覧（種類全体を別ファイルで定義している場合は読み込む）
+func (r *resolver) definitions(node *yaml.Node) (*yaml.Node, error) {
+	ref := refValue(node)
+	if ref == "" {
+		return node, nil
+	}
+	return r.inline(node, r.entry)
+}
+
+// resolve ノード内の $ref を再帰的に解決
+func (r *resolver) resolve(node *yaml.Node, base string) (*yaml.Node, error) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return r.resolve(node.Alias, base)
+	case yaml.SequenceNode:
+		out := &yaml.Node{Kind: yaml.SequenceNode, Tag: node.Tag, Style: node.Style}
+		for _, item := range node.Content {
+			resolved, err := r.resolve(item, base)
+			if err != nil {
+				return nil, err
+			}
+			out.Content = append(out.Content, resolved)
+		}
+		return out, nil
+	case yaml.MappingNode:
+		ref := refValue(node)
+		if ref == "" {
+			return r.resolveMapping(node, base)
+		}
+		// エントリーファイル内の参照はそのまま残す
+		if strings.HasPrefix(ref, "#") && base == r.entry {
+			return refNode(ref), nil
+		}
+		target, _ := r.target(ref, base)
+		if component, ok := r.components[target]; ok {
+			return refNode(component), nil
+		}
+		return r.inline(node, base)
+	default:
+		return copyScalar(node), nil
+	}
+}
+
+// resolveMapping マッピングの各値を解決
+func (r *resolver) resolveMapping(node *yaml.Node, base string) (*yaml.Node, error) {
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: node.Tag, Style: node.Style}
+	for i := 0; i < len(node.Content); i += 2 {
+		resolved, err := r.resolve(node.Content[i+1], base)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", node.Content[i].Value, err)
+		}
+		out.Content = append(out.Content, copyScalar(node.Content[i]), resolved)
+	}
+	return out, nil
+}
+
+// inline $ref の参照先を展開（$ref と並ぶキーは参照先の値を上書きする）
+func (r *resolver) inline(node *yaml.Node, base string) (*yaml.Node, error) {
+	ref := refValue(node)
+	if ref == "" {
+		return r.resolve(node, base)
+	}
+
+	target, file := r.target(ref, base)
+	if r.resolving[target] {
+		return nil, fmt.Errorf("circular $ref: %s", ref)
+	}
+	r.resolving[target] = true
+	defer delete(r.resolving, target)
+
+	referenced, err := r.lookup(target, file)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := r.inline(referenced, file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ref, err)
+	}
+
+	for i := 0; i < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		if key == "$ref" || resolved.Kind != yaml.MappingNode {
+			continue
+		}
+		value, err := r.resolve(node.Content[i+1], base)
+		if err != nil {
+			return nil, err
+		}
+		setMappingValue(resolved, copyScalar(node.Content[i]), value)
+	}
+
+	return resolved, nil
+}
+
+// target 参照先を「絶対パス#ポインタ」の形式で返す
+func (r *resolver) target(ref, base string) (string, string) {
+	file, pointer, _ := strings.Cut(ref, "#")
+	if file == "" {
+		file = base
+	} else {
+		file = filepath.Join(filepath.Dir(base), file)
+	}
+	return file + "#" + strings.TrimSuffix(pointer, "/"), file
+}
+
+// lookup 参照先のノードを取得
+func (r *resolver) lookup(target, file string) (*yaml.Node, error) {
+	root, err := r.load(file)
+	if err != nil {
+		return nil, err
+	}
+
+	_, pointer, _ := strings.Cut(target, "#")
+	node := root
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		switch node.Kind {
+		case yaml.MappingNode:
+			node = mappingValue(node, token)
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node.Content) {
+				node = nil
+			} else {
+				node = node.Content[index]
+			}
+		default:
+			node = nil
+		}
+		if node == nil {
+			return nil, fmt.Errorf("$ref target not found: %s", r.relative(target))
+		}
+	}
+
+	return node, nil
+}
+
+// load YAMLファイルを読み込む（読み込み済みならキャッシュを返す）
+func (r *resolver) load(file string) (*yaml.Node, error) {
+	if root, ok := r.files[file]; ok {
+		return root, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", r.relative(file), err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, fmt.Errorf("%s: empty document", r.relative(file))
+	}
+
+	r.files[file] = doc.Content[0]
+	return doc.Content[0], nil
+}
+
+// relative エラーメッセージ用にエントリーファイルからの相対パスにする
+func (r *resolver) relative(path string) string {
+	if rel, err := filepath.Rel(filepath.Dir(r.entry), path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// write マージ結果を拡張子に応じた形式で書き出す
+func write(path string, node *yaml.Node) error {
+	var buf bytes.Buffer
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		var compact bytes.Buffer
+		if err := encodeJSON(&compact, node); err != nil {
+			return err
+		}
+		if err := json.Indent(&buf, compact.Bytes(), "", "  "); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+	case ".yml", ".yaml":
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(node); err != nil {
+			return err
+		}
+		encoder.Close()
+	default:
+		return fmt.Errorf("%s: unsupported output format (use .json, .yml or .yaml)", path)
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// encodeJSON キーの順序を保ったままJSONに変換
+func encodeJSON(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return encodeJSON(buf, node.Alias)
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(node.Content[i].Value)
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := encodeJSON(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		buf.Write(data)
+	default:
+		return fmt.Errorf("line %d: unsupported yaml node", node.Line)
+	}
+	return nil
+}
+
+// refValue マッピングの $ref の値（なければ空文字列）
+func refValue(node *yaml.Node) string {
+	if node.Kind != yaml.MappingNode {
+		return ""
+	}
+	if value := mappingValue(node, "$ref"); value != nil {
+		return value.Value
+	}
+	return ""
+}
+
+// mappingValue マッピングからキーに対応する値を取得
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue マッピングの値を設定（既存のキーは置き換える）
+func setMappingValue(node, key, value *yaml.Node) {
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value == key.Value {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, key, value)
+}
+
+// refNode $ref だけを持つマッピングを作成
+func refNode(ref string) *yaml.Node {
+	return &yaml.Node{
+		Kind: yaml.MappingNode,
+		Tag:  "!!map",
+		Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: "$ref"},
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: ref, Style: yaml.DoubleQuotedStyle},
+		},
+	}
+}
+
+// copyScalar スカラーノードを複製（コメントは引き継がない）
+func copyScalar(node *yaml.Node) *yaml.Node {
+	return &yaml.Node{
+		Kind:  node.Kind,
+		Tag:   node.Tag,
+		Value: node.Value,
+		Style: node.Style,
+	}
+}
+
+// escapePointer JSONポインタのトークンをエスケープ
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
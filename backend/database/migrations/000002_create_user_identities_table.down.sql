@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS user_identities;
+
+UPDATE users SET password = '' WHERE password IS NULL;
+ALTER TABLE users MODIFY password VARCHAR(255) NOT NULL;
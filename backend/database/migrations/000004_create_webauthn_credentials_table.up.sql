@@ -0,0 +1,13 @@
+CREATE TABLE webauthn_credentials (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    credential_id VARBINARY(1023) NOT NULL,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    -- 公開鍵・署名カウンタ・フラグ等をJSONで保持
+    data JSON NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at DATETIME NULL,
+    UNIQUE KEY uq_webauthn_credentials_credential_id (credential_id(255)),
+    KEY idx_webauthn_credentials_user_id (user_id),
+    CONSTRAINT fk_webauthn_credentials_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
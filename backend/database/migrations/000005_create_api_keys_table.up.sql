@@ -0,0 +1,18 @@
+CREATE TABLE api_keys (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    -- 一覧表示用のキー先頭部分（例: ak_Xy12AbCd）
+    prefix VARCHAR(16) NOT NULL,
+    -- キー全体のSHA-256（平文は保存しない）
+    key_hash CHAR(64) NOT NULL,
+    -- スペース区切りのスコープ
+    scopes VARCHAR(255) NOT NULL,
+    expires_at DATETIME NULL,
+    last_used_at DATETIME NULL,
+    revoked_at DATETIME NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_api_keys_key_hash (key_hash),
+    KEY idx_api_keys_user_id (user_id),
+    CONSTRAINT fk_api_keys_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,18 @@
+-- 監査ログは追記のみ。ユーザー削除後も残すため外部キーは張らない
+CREATE TABLE audit_logs (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    action VARCHAR(64) NOT NULL,
+    actor_id BIGINT NULL,
+    target_user_id BIGINT NULL,
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    changes JSON NULL,
+    metadata JSON NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    KEY idx_audit_logs_created_at (created_at),
+    KEY idx_audit_logs_action_created_at (action, created_at),
+    KEY idx_audit_logs_actor_id_created_at (actor_id, created_at),
+    KEY idx_audit_logs_target_user_id_created_at (target_user_id, created_at),
+    KEY idx_audit_logs_request_id (request_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
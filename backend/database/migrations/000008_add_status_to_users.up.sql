@@ -0,0 +1,5 @@
+ALTER TABLE users
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active' AFTER role,
+    ADD COLUMN status_reason VARCHAR(255) NULL AFTER status,
+    -- 一時停止の解除日時（NULLなら無期限）
+    ADD COLUMN suspended_until DATETIME NULL AFTER status_reason;
@@ -0,0 +1,18 @@
+-- バックグラウンドジョブ（JOB_QUEUE_BACKEND=mysql の場合に使用）
+-- 完了したジョブは削除し、再試行の上限に達したものは status = 'dead' として残す
+CREATE TABLE jobs (
+    id VARCHAR(32) PRIMARY KEY,
+    type VARCHAR(100) NOT NULL,
+    payload JSON NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    run_at DATETIME(3) NOT NULL,
+    locked_until DATETIME(3) NULL,
+    last_error TEXT NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    updated_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    KEY idx_jobs_status_run_at (status, run_at),
+    KEY idx_jobs_status_locked_until (status, locked_until),
+    KEY idx_jobs_status_updated_at (status, updated_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
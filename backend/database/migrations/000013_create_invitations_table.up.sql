@@ -0,0 +1,23 @@
+-- 招待（メールアドレス宛ての期限付き招待リンク）
+CREATE TABLE invitations (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    -- 承諾で作成するユーザーのロール
+    role VARCHAR(20) NOT NULL DEFAULT 'user',
+    -- 組織への招待（NULLなら組織なし）と組織でのロール
+    organization_id BIGINT NULL,
+    organization_role VARCHAR(20) NULL,
+    -- pending / accepted / declined / revoked
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    invited_by BIGINT NULL,
+    accepted_user_id BIGINT NULL,
+    expires_at DATETIME NOT NULL,
+    responded_at DATETIME NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    KEY idx_invitations_email_status (email, status),
+    KEY idx_invitations_organization_id (organization_id),
+    CONSTRAINT fk_invitations_organization FOREIGN KEY (organization_id) REFERENCES organizations (id) ON DELETE CASCADE,
+    CONSTRAINT fk_invitations_invited_by FOREIGN KEY (invited_by) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT fk_invitations_accepted_user FOREIGN KEY (accepted_user_id) REFERENCES users (id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
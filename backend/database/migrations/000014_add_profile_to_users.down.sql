@@ -0,0 +1,6 @@
+ALTER TABLE users
+    DROP COLUMN avatar_version,
+    DROP COLUMN bio,
+    DROP COLUMN timezone,
+    DROP COLUMN locale,
+    DROP COLUMN display_name;
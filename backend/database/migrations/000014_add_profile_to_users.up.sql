@@ -0,0 +1,9 @@
+ALTER TABLE users
+    ADD COLUMN display_name VARCHAR(100) NULL AFTER name,
+    -- BCP 47 の言語タグ（例: ja-JP）
+    ADD COLUMN locale VARCHAR(35) NULL AFTER display_name,
+    -- IANA タイムゾーン名（例: Asia/Tokyo）
+    ADD COLUMN timezone VARCHAR(64) NULL AFTER locale,
+    ADD COLUMN bio TEXT NULL AFTER timezone,
+    -- アバター画像の版（ストレージのキーに含める。NULLなら未設定）
+    ADD COLUMN avatar_version VARCHAR(32) NULL AFTER bio;
@@ -0,0 +1,13 @@
+-- ログインセッション（AUTH_MODE=cookie のセッションCookie用、SESSION_BACKEND=mysql の場合に使う）
+CREATE TABLE sessions (
+    -- セッショントークンのSHA-256（平文は保存しない）
+    id CHAR(64) PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    user_agent VARCHAR(255) NULL,
+    ip_address VARCHAR(45) NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY idx_sessions_user_id (user_id),
+    KEY idx_sessions_expires_at (expires_at),
+    CONSTRAINT fk_sessions_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/api"
+	"app-template/internal/entity"
+	"app-template/internal/usecase"
+	"app-template/pkg/middleware"
+	"app-template/pkg/problem"
+)
+
+// APIKeyController APIキーコントローラー
+type APIKeyController struct {
+	apiKeyUseCase usecase.APIKeyUseCase
+}
+
+var _ api.APIKeysServer = (*APIKeyController)(nil)
+
+// NewAPIKeyController APIキーコントローラーの新しいインスタンスを作成
+func NewAPIKeyController(apiKeyUseCase usecase.APIKeyUseCase) *APIKeyController {
+	return &APIKeyController{
+		apiKeyUseCase: apiKeyUseCase,
+	}
+}
+
+// GetAPIKeys APIキー一覧取得ハンドラー
+// @Summary APIキー一覧
+// @Description 失効していないAPIキーを取得します（キー本体は含みません）
+// @Tags api-keys
+// @Produce json
+// @Success 200 {object} entity.APIKeysResponse
+// @Security BearerAuth
+// @Router /me/api-keys [get]
+func (c *APIKeyController) GetAPIKeys(ctx *gin.Context) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	response, err := c.apiKeyUseCase.List(ctx.Request.Context(), userID)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// CreateAPIKey APIキー作成ハンドラー
+// @Summary APIキー作成
+// @Description スコープと有効期限を指定してAPIキーを発行します。キー本体はこのレスポンスでのみ返されます
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param request body entity.CreateAPIKeyRequest true "APIキー作成リクエスト"
+// @Success 201 {object} entity.CreatedAPIKey
+// @Failure 400 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /me/api-keys [post]
+func (c *APIKeyController) CreateAPIKey(ctx *gin.Context) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	var req entity.CreateAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return
+	}
+
+	response, err := c.apiKeyUseCase.Create(ctx.Request.Context(), userID, &req)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// RevokeAPIKey APIキー失効ハンドラー
+// @Summary APIキー失効
+// @Tags api-keys
+// @Param id path int true "APIキーID"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /me/api-keys/{id} [delete]
+func (c *APIKeyController) RevokeAPIKey(ctx *gin.Context, id int64) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	if err := c.apiKeyUseCase.Revoke(ctx.Request.Context(), userID, id); err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// respondError ユースケースのエラーをHTTPレスポンスに変換
+func (c *APIKeyController) respondError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrAPIKeyNotFound):
+		problem.Write(ctx, http.StatusNotFound, "API_KEY_NOT_FOUND", err.Error())
+	case errors.Is(err, usecase.ErrAPIKeyInvalidScope):
+		problem.Write(ctx, http.StatusBadRequest, "INVALID_API_KEY_SCOPE", err.Error())
+	case errors.Is(err, usecase.ErrAPIKeyInvalidExpiry):
+		problem.Write(ctx, http.StatusBadRequest, "INVALID_API_KEY_EXPIRY", err.Error())
+	case errors.Is(err, usecase.ErrAPIKeyLimitExceeded):
+		problem.Write(ctx, http.StatusConflict, "API_KEY_LIMIT_EXCEEDED", err.Error())
+	default:
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+}
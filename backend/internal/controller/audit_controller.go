@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/api"
+	"app-template/internal/entity"
+	"app-template/internal/usecase"
+	"app-template/pkg/problem"
+)
+
+// AuditController 監査ログコントローラー
+type AuditController struct {
+	auditUseCase usecase.AuditUseCase
+}
+
+var _ api.AuditLogsServer = (*AuditController)(nil)
+
+// NewAuditController 監査ログコントローラーの新しいインスタンスを作成
+func NewAuditController(auditUseCase usecase.AuditUseCase) *AuditController {
+	return &AuditController{
+		auditUseCase: auditUseCase,
+	}
+}
+
+// GetAuditLogs 監査ログ一覧取得ハンドラー
+// @Summary 監査ログ一覧
+// @Description 条件を指定して監査ログを新しい順に取得します（users:admin 権限が必要）
+// @Tags admin
+// @Produce json
+// @Param action query string false "アクション (例: user.delete)"
+// @Param actor_id query int false "操作者のユーザーID"
+// @Param target_user_id query int false "対象ユーザーID"
+// @Param request_id query string false "リクエストID"
+// @Param from query string false "開始日時 (RFC3339)"
+// @Param to query string false "終了日時 (RFC3339, この日時を含まない)"
+// @Param page query int false "ページ番号" default(1)
+// @Param limit query int false "1ページあたりの件数" default(50)
+// @Success 200 {object} entity.AuditLogsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/audit-logs [get]
+func (c *AuditController) GetAuditLogs(ctx *gin.Context, query api.GetAuditLogsParams) {
+	// 未指定の値は条件に含めない（ページ・件数はユースケースで既定値になる）
+	filter := entity.AuditLogFilter{From: query.From, To: query.To}
+	if query.Action != nil {
+		filter.Action = *query.Action
+	}
+	if query.ActorID != nil {
+		filter.ActorID = *query.ActorID
+	}
+	if query.TargetUserID != nil {
+		filter.TargetUserID = *query.TargetUserID
+	}
+	if query.RequestID != nil {
+		filter.RequestID = *query.RequestID
+	}
+	if query.Page != nil {
+		filter.Page = *query.Page
+	}
+	if query.Limit != nil {
+		filter.Limit = *query.Limit
+	}
+
+	response, err := c.auditUseCase.List(ctx.Request.Context(), &filter)
+	if err != nil {
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/api"
+	"app-template/internal/usecase"
+	"app-template/pkg/middleware"
+	"app-template/pkg/problem"
+)
+
+// avatarFormField アップロードする画像のmultipartの項目名
+const avatarFormField = "avatar"
+
+// avatarFormOverhead multipartの境界やヘッダーの分としてリクエストボディの上限に加える大きさ
+const avatarFormOverhead = 64 << 10
+
+// AvatarController アバター画像コントローラー
+type AvatarController struct {
+	avatarUseCase usecase.AvatarUseCase
+}
+
+var _ api.AvatarsServer = (*AvatarController)(nil)
+
+// NewAvatarController アバター画像コントローラーの新しいインスタンスを作成
+func NewAvatarController(avatarUseCase usecase.AvatarUseCase) *AvatarController {
+	return &AvatarController{
+		avatarUseCase: avatarUseCase,
+	}
+}
+
+// UploadAvatar アバター画像アップロードハンドラー
+// @Summary アバター画像アップロード
+// @Description multipart/form-data の avatar 項目の画像（JPEG / PNG / GIF、5 MiB まで）をアバターにします。形式は内容から判定し、EXIF を取り除いて中央を正方形に切り抜き、64 / 128 / 256 px で保存します
+// @Tags me
+// @Accept multipart/form-data
+// @Produce json
+// @Param avatar formData file true "アバター画像"
+// @Success 200 {object} entity.User
+// @Failure 400 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 415 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /me/avatar [put]
+func (c *AvatarController) UploadAvatar(ctx *gin.Context) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, usecase.MaxAvatarBytes+avatarFormOverhead)
+	header, err := ctx.FormFile(avatarFormField)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.respondError(ctx, usecase.ErrAvatarTooLarge)
+			return
+		}
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "avatar file is required", problem.FieldError{Field: avatarFormField, Message: "is required"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, usecase.MaxAvatarBytes+1))
+	if err != nil {
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	user, err := c.avatarUseCase.Upload(ctx.Request.Context(), userID, data)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// DeleteAvatar アバター画像削除ハンドラー
+// @Summary アバター画像削除
+// @Description アバター画像を削除して未設定に戻します
+// @Tags me
+// @Produce json
+// @Success 200 {object} entity.User
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /me/avatar [delete]
+func (c *AvatarController) DeleteAvatar(ctx *gin.Context) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	user, err := c.avatarUseCase.Delete(ctx.Request.Context(), userID)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// GetAvatar アバター画像取得ハンドラー
+// @Summary アバター画像取得
+// @Description ユーザーのアバター画像を返します（img 要素から読み込めるよう認証不要）。size 以上で最も小さい大きさ（64 / 128 / 256）を返し、省略時は 256 px です。
+// @Description avatar_url のように版（v）を指定した場合は長期間キャッシュできます
+// @Tags users
+// @Produce image/jpeg
+// @Produce image/png
+// @Param id path int true "ユーザーID"
+// @Param size query int false "大きさ（px）"
+// @Param v query string false "画像の版（キャッシュ用）"
+// @Success 200 {file} binary
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{id}/avatar [get]
+func (c *AvatarController) GetAvatar(ctx *gin.Context, id int64, params api.GetAvatarParams) {
+	size := 0
+	if params.Size != nil {
+		if *params.Size < 1 {
+			problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid query parameters", problem.FieldError{Field: "size", Message: "must be a positive integer"})
+			return
+		}
+		size = *params.Size
+	}
+
+	body, info, err := c.avatarUseCase.Open(ctx.Request.Context(), id, size)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+	defer body.Close()
+
+	// アバター画像は小さいため読み込んでから返す（Range や If-Modified-Since は ServeContent が処理する）
+	data, err := io.ReadAll(body)
+	if err != nil {
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	if info.ContentType != "" {
+		ctx.Header("Content-Type", info.ContentType)
+	}
+	// 版を含むURLは画像を変更すると変わるため、長期間キャッシュしてよい
+	if params.V != nil && *params.V != "" {
+		ctx.Header("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		ctx.Header("Cache-Control", "public, max-age=300")
+	}
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	http.ServeContent(ctx.Writer, ctx.Request, "", info.ModTime, bytes.NewReader(data))
+}
+
+// respondError ユースケースのエラーをHTTPレスポンスに変換
+func (c *AvatarController) respondError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrAvatarUnsupportedType):
+		problem.Write(ctx, http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE", err.Error())
+	case errors.Is(err, usecase.ErrAvatarInvalidImage):
+		problem.Write(ctx, http.StatusBadRequest, "INVALID_IMAGE", err.Error())
+	case errors.Is(err, usecase.ErrAvatarTooLarge):
+		problem.Write(ctx, http.StatusRequestEntityTooLarge, "AVATAR_TOO_LARGE", "Avatar must be at most "+strconv.Itoa(usecase.MaxAvatarBytes>>20)+" MiB and 40 megapixels")
+	case errors.Is(err, usecase.ErrAvatarNotFound):
+		problem.Write(ctx, http.StatusNotFound, "AVATAR_NOT_FOUND", err.Error())
+	case err.Error() == "user not found":
+		problem.Write(ctx, http.StatusNotFound, "USER_NOT_FOUND", err.Error())
+	default:
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+}
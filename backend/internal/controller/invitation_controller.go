@@ -0,0 +1,235 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/api"
+	"app-template/internal/entity"
+	"app-template/internal/usecase"
+	"app-template/pkg/middleware"
+	"app-template/pkg/problem"
+	"app-template/pkg/session"
+)
+
+// InvitationController 招待コントローラー
+type InvitationController struct {
+	invitationUseCase usecase.InvitationUseCase
+	sessions          *session.Manager
+}
+
+var _ api.InvitationsServer = (*InvitationController)(nil)
+
+// NewInvitationController 招待コントローラーの新しいインスタンスを作成
+func NewInvitationController(invitationUseCase usecase.InvitationUseCase, sessions *session.Manager) *InvitationController {
+	return &InvitationController{
+		invitationUseCase: invitationUseCase,
+		sessions:          sessions,
+	}
+}
+
+// CreateInvitation 招待作成ハンドラー
+// @Summary 招待作成
+// @Description メールアドレス宛ての招待を作成し、招待リンクのトークンを返します（トークンはこのレスポンスでのみ返されます）。X-Organization などでテナントを指定すると組織への招待になり、組織の owner / admin が作成できます。テナントを指定しない招待と admin ロールの指定は users:admin 権限が必要です
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param request body entity.CreateInvitationRequest true "招待作成リクエスト"
+// @Success 201 {object} entity.CreatedInvitation
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /invitations [post]
+func (c *InvitationController) CreateInvitation(ctx *gin.Context) {
+	actorID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	var req entity.CreateInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return
+	}
+
+	response, err := c.invitationUseCase.Create(ctx.Request.Context(), actorID, &req)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// GetInvitations 招待一覧取得ハンドラー
+// @Summary 招待一覧
+// @Description 招待を新しい順に取得します。テナントを指定した場合はその組織への招待のみです
+// @Tags invitations
+// @Produce json
+// @Param status query string false "状態（pending / accepted / declined / revoked）"
+// @Param page query int false "ページ番号" default(1)
+// @Param limit query int false "1ページあたりの件数" default(20)
+// @Success 200 {object} entity.InvitationsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /invitations [get]
+func (c *InvitationController) GetInvitations(ctx *gin.Context, query api.GetInvitationsParams) {
+	params := entity.InvitationListParams{PaginationParams: paginationParams(query.Page, query.Limit)}
+	if query.Status != nil {
+		params.Status = *query.Status
+	}
+
+	response, err := c.invitationUseCase.List(ctx.Request.Context(), &params)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// RevokeInvitation 招待取り消しハンドラー
+// @Summary 招待取り消し
+// @Description 未回答の招待を取り消します。招待リンクは使えなくなります
+// @Tags invitations
+// @Param id path int true "招待ID"
+// @Success 204
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /invitations/{id} [delete]
+func (c *InvitationController) RevokeInvitation(ctx *gin.Context, id int64) {
+	if err := c.invitationUseCase.Revoke(ctx.Request.Context(), id); err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// PreviewInvitation 招待内容取得ハンドラー
+// @Summary 招待内容
+// @Description 招待リンクのトークンから招待先のメールアドレス・組織・有効期限と、既存のアカウントがあるかを取得します（認証不要）
+// @Tags invitations
+// @Produce json
+// @Param token query string true "招待トークン"
+// @Success 200 {object} entity.InvitationPreview
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Router /invitations/preview [get]
+func (c *InvitationController) PreviewInvitation(ctx *gin.Context, params api.PreviewInvitationParams) {
+	if params.Token == nil || *params.Token == "" {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "token is required")
+		return
+	}
+
+	response, err := c.invitationUseCase.Preview(ctx.Request.Context(), *params.Token)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// AcceptInvitation 招待承諾ハンドラー
+// @Summary 招待承諾
+// @Description 招待を承諾します。招待先のアカウントがなければ name と password でアカウントを作成してトークンを返します（招待制でも登録できます）。アカウントがあれば、そのアカウントでログインした状態で承諾すると組織のメンバーに追加されます
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param request body entity.AcceptInvitationRequest true "招待承諾リクエスト"
+// @Success 200 {object} entity.AuthResponse
+// @Success 201 {object} entity.AuthResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Router /invitations/accept [post]
+func (c *InvitationController) AcceptInvitation(ctx *gin.Context) {
+	var req entity.AcceptInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return
+	}
+
+	// 未認証なら0（新しいアカウントの作成のみ）
+	userID, _ := middleware.CurrentUserID(ctx)
+
+	response, err := c.invitationUseCase.Accept(ctx.Request.Context(), userID, &req)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	// アカウントを作成した場合はトークンを返す
+	status := http.StatusOK
+	if response.Token != "" {
+		status = http.StatusCreated
+	}
+	respondAuth(ctx, c.sessions, status, response)
+}
+
+// DeclineInvitation 招待辞退ハンドラー
+// @Summary 招待辞退
+// @Description 招待を辞退します（認証不要）
+// @Tags invitations
+// @Accept json
+// @Param request body entity.DeclineInvitationRequest true "招待辞退リクエスト"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Router /invitations/decline [post]
+func (c *InvitationController) DeclineInvitation(ctx *gin.Context) {
+	var req entity.DeclineInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return
+	}
+
+	if err := c.invitationUseCase.Decline(ctx.Request.Context(), req.Token); err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// respondError ユースケースのエラーをHTTPレスポンスに変換
+func (c *InvitationController) respondError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvitationNotFound):
+		problem.Write(ctx, http.StatusNotFound, "INVITATION_NOT_FOUND", err.Error())
+	case errors.Is(err, usecase.ErrInvitationInvalid):
+		problem.Write(ctx, http.StatusBadRequest, "INVALID_INVITATION", err.Error())
+	case errors.Is(err, usecase.ErrInvitationExpired):
+		problem.Write(ctx, http.StatusGone, "INVITATION_EXPIRED", err.Error())
+	case errors.Is(err, usecase.ErrInvitationNotPending):
+		problem.Write(ctx, http.StatusConflict, "INVITATION_NOT_PENDING", err.Error())
+	case errors.Is(err, usecase.ErrInvitationForbidden):
+		problem.Write(ctx, http.StatusForbidden, "FORBIDDEN", err.Error())
+	case errors.Is(err, usecase.ErrInvitationPending):
+		problem.Write(ctx, http.StatusConflict, "INVITATION_PENDING", err.Error())
+	case errors.Is(err, usecase.ErrInvitationUserExists), err.Error() == "email already exists":
+		problem.Write(ctx, http.StatusConflict, "EMAIL_ALREADY_EXISTS", err.Error())
+	case errors.Is(err, usecase.ErrOrganizationMemberExists):
+		problem.Write(ctx, http.StatusConflict, "ORGANIZATION_MEMBER_EXISTS", err.Error())
+	case errors.Is(err, usecase.ErrInvitationNoOrganization), errors.Is(err, usecase.ErrInvitationAccountRequired):
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, usecase.ErrInvitationLoginRequired):
+		problem.Write(ctx, http.StatusUnauthorized, "LOGIN_REQUIRED", err.Error())
+	case errors.Is(err, usecase.ErrInvitationEmailMismatch):
+		problem.Write(ctx, http.StatusForbidden, "INVITATION_EMAIL_MISMATCH", err.Error())
+	default:
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+}
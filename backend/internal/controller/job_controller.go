@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/api"
+	"app-template/pkg/jobqueue"
+	"app-template/pkg/problem"
+)
+
+// DeadLetterQueue デッドレターの確認・再実行ができるジョブキュー（*jobqueue.Queue が満たす）
+type DeadLetterQueue interface {
+	Dead(ctx context.Context, limit int) ([]*jobqueue.Job, error)
+	Requeue(ctx context.Context, id string) error
+}
+
+// DeadJobsResponse デッドレター一覧レスポンス
+type DeadJobsResponse struct {
+	Jobs []*jobqueue.Job `json:"jobs"`
+}
+
+// デッドレター一覧の取得件数
+const (
+	defaultDeadJobsLimit = 50
+	maxDeadJobsLimit     = 100
+)
+
+// JobController バックグラウンドジョブ管理コントローラー
+type JobController struct {
+	queue DeadLetterQueue
+}
+
+var _ api.JobsServer = (*JobController)(nil)
+
+// NewJobController バックグラウンドジョブ管理コントローラーの新しいインスタンスを作成
+func NewJobController(queue DeadLetterQueue) *JobController {
+	return &JobController{
+		queue: queue,
+	}
+}
+
+// GetDeadJobs デッドレター一覧取得ハンドラー
+// @Summary デッドレター一覧
+// @Description 再試行の上限に達した、または再試行できないエラーで失敗したジョブを新しい順に取得します（users:admin 権限が必要）
+// @Tags admin
+// @Produce json
+// @Param limit query int false "取得件数" default(50)
+// @Success 200 {object} DeadJobsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/jobs/dead [get]
+func (c *JobController) GetDeadJobs(ctx *gin.Context, params api.GetDeadJobsParams) {
+	limit := defaultDeadJobsLimit
+	if params.Limit != nil {
+		if *params.Limit < 1 || *params.Limit > maxDeadJobsLimit {
+			problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid query parameters", problem.FieldError{Field: "limit", Message: "must be between 1 and 100"})
+			return
+		}
+		limit = *params.Limit
+	}
+
+	jobs, err := c.queue.Dead(ctx.Request.Context(), limit)
+	if err != nil {
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, DeadJobsResponse{Jobs: jobs})
+}
+
+// RequeueDeadJob デッドレター再実行ハンドラー
+// @Summary デッドレター再実行
+// @Description デッドレターのジョブを試行回数を0に戻して再実行します（users:admin 権限が必要）
+// @Tags admin
+// @Param id path string true "ジョブID"
+// @Success 204
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/jobs/dead/{id}/requeue [post]
+func (c *JobController) RequeueDeadJob(ctx *gin.Context, id string) {
+	err := c.queue.Requeue(ctx.Request.Context(), id)
+	if errors.Is(err, jobqueue.ErrJobNotFound) {
+		problem.Write(ctx, http.StatusNotFound, "JOB_NOT_FOUND", "Job not found in dead letter queue")
+		return
+	}
+	if err != nil {
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
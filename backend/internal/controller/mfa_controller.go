@@ -0,0 +1,228 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/api"
+	"app-template/internal/entity"
+	"app-template/internal/usecase"
+	"app-template/pkg/auth"
+	"app-template/pkg/middleware"
+	"app-template/pkg/problem"
+	"app-template/pkg/session"
+)
+
+// MFAController 二要素認証コントローラー
+type MFAController struct {
+	mfaUseCase usecase.MFAUseCase
+	sessions   *session.Manager
+}
+
+var _ api.MFAServer = (*MFAController)(nil)
+
+// NewMFAController 二要素認証コントローラーの新しいインスタンスを作成
+func NewMFAController(mfaUseCase usecase.MFAUseCase, sessions *session.Manager) *MFAController {
+	return &MFAController{
+		mfaUseCase: mfaUseCase,
+		sessions:   sessions,
+	}
+}
+
+// VerifyMFA 二段階ログイン検証ハンドラー
+// @Summary 二段階ログイン検証
+// @Description ログイン時に返されたMFAチャレンジとコードを検証し、アクセストークンを発行します
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body entity.MFAVerifyRequest true "MFA検証リクエスト"
+// @Success 200 {object} entity.AuthResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /auth/mfa/verify [post]
+func (c *MFAController) VerifyMFA(ctx *gin.Context) {
+	var req entity.MFAVerifyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return
+	}
+
+	response, err := c.mfaUseCase.Verify(ctx.Request.Context(), &req)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	respondAuth(ctx, c.sessions, http.StatusOK, response)
+}
+
+// GetMFAStatus 二要素認証設定状況取得ハンドラー
+// @Summary 二要素認証設定状況
+// @Tags mfa
+// @Produce json
+// @Success 200 {object} entity.MFAStatusResponse
+// @Security BearerAuth
+// @Router /me/mfa [get]
+func (c *MFAController) GetMFAStatus(ctx *gin.Context) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	response, err := c.mfaUseCase.Status(ctx.Request.Context(), userID)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// EnrollTOTP TOTP登録開始ハンドラー
+// @Summary TOTP登録開始
+// @Description シークレットとQRコード用のプロビジョニングURIを発行します
+// @Tags mfa
+// @Produce json
+// @Success 200 {object} entity.TOTPEnrollment
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /me/mfa/totp [post]
+func (c *MFAController) EnrollTOTP(ctx *gin.Context) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	response, err := c.mfaUseCase.EnrollTOTP(ctx.Request.Context(), userID)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ConfirmTOTP TOTP登録確認ハンドラー
+// @Summary TOTP登録確認
+// @Description 認証アプリのコードで登録を確認し、リカバリーコードを発行します
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body entity.MFACodeRequest true "認証コード"
+// @Success 200 {object} entity.RecoveryCodesResponse
+// @Failure 400 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /me/mfa/totp/confirm [post]
+func (c *MFAController) ConfirmTOTP(ctx *gin.Context) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	var req entity.MFACodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return
+	}
+
+	response, err := c.mfaUseCase.ConfirmTOTP(ctx.Request.Context(), userID, req.Code)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// DisableTOTP 二要素認証無効化ハンドラー
+// @Summary 二要素認証無効化
+// @Tags mfa
+// @Accept json
+// @Param request body entity.MFACodeRequest true "TOTPコードまたはリカバリーコード"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /me/mfa/totp [delete]
+func (c *MFAController) DisableTOTP(ctx *gin.Context) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	var req entity.MFACodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return
+	}
+
+	if err := c.mfaUseCase.DisableTOTP(ctx.Request.Context(), userID, req.Code); err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes リカバリーコード再発行ハンドラー
+// @Summary リカバリーコード再発行
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body entity.MFACodeRequest true "TOTPコードまたはリカバリーコード"
+// @Success 200 {object} entity.RecoveryCodesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /me/mfa/recovery-codes [post]
+func (c *MFAController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	var req entity.MFACodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return
+	}
+
+	response, err := c.mfaUseCase.RegenerateRecoveryCodes(ctx.Request.Context(), userID, req.Code)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// respondError ユースケースのエラーをHTTPレスポンスに変換
+func (c *MFAController) respondError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrMFAInvalidCode):
+		problem.Write(ctx, http.StatusUnauthorized, "INVALID_MFA_CODE", err.Error())
+	case errors.Is(err, usecase.ErrMFAChallengeUsed):
+		problem.Write(ctx, http.StatusUnauthorized, "MFA_CHALLENGE_USED", err.Error())
+	case errors.Is(err, usecase.ErrMFATooManyAttempts):
+		problem.Write(ctx, http.StatusTooManyRequests, "TOO_MANY_MFA_ATTEMPTS", err.Error())
+	case errors.Is(err, usecase.ErrMFAAlreadyEnabled):
+		problem.Write(ctx, http.StatusConflict, "MFA_ALREADY_ENABLED", err.Error())
+	case errors.Is(err, usecase.ErrMFANotEnrolled):
+		problem.Write(ctx, http.StatusBadRequest, "MFA_NOT_ENROLLED", err.Error())
+	case errors.Is(err, usecase.ErrMFANotEnabled):
+		problem.Write(ctx, http.StatusBadRequest, "MFA_NOT_ENABLED", err.Error())
+	case auth.IsAccountBlocked(err):
+		problem.Write(ctx, http.StatusForbidden, auth.ErrorCode(err), err.Error())
+	case auth.IsTokenError(err):
+		problem.Write(ctx, http.StatusUnauthorized, auth.ErrorCode(err), err.Error())
+	default:
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+}
@@ -0,0 +1,215 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/api"
+	"app-template/internal/usecase"
+	"app-template/pkg/auth"
+	"app-template/pkg/middleware"
+	"app-template/pkg/oauth"
+	"app-template/pkg/problem"
+	"app-template/pkg/session"
+)
+
+// oauthStateCookie 認可リクエストの状態を保持するクッキー名
+const (
+	oauthStateCookie     = "oauth_state"
+	oauthStateCookiePath = "/api/v1/auth/oauth"
+)
+
+// OAuthController 外部プロバイダー認証コントローラー
+type OAuthController struct {
+	oauthUseCase usecase.OAuthUseCase
+	stateTTL     int
+	secureCookie bool
+	sessions     *session.Manager
+}
+
+var _ api.OAuthServer = (*OAuthController)(nil)
+
+// NewOAuthController 外部プロバイダー認証コントローラーの新しいインスタンスを作成
+func NewOAuthController(oauthUseCase usecase.OAuthUseCase, states *oauth.StateCodec, secureCookie bool, sessions *session.Manager) *OAuthController {
+	return &OAuthController{
+		oauthUseCase: oauthUseCase,
+		stateTTL:     int(states.TTL().Seconds()),
+		secureCookie: secureCookie,
+		sessions:     sessions,
+	}
+}
+
+// AuthorizeOAuth 外部プロバイダーでのログインを開始
+// @Summary 外部プロバイダーログイン開始
+// @Description 外部プロバイダーの認可エンドポイントへリダイレクトします（PKCE）
+// @Tags auth
+// @Param provider path string true "プロバイダー名 (google, github)"
+// @Success 302
+// @Failure 404 {object} ErrorResponse
+// @Router /auth/oauth/{provider} [get]
+func (c *OAuthController) AuthorizeOAuth(ctx *gin.Context, provider string) {
+	authorization, err := c.oauthUseCase.Begin(ctx.Request.Context(), provider, 0)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	c.setStateCookie(ctx, authorization.State, c.stateTTL)
+	ctx.Redirect(http.StatusFound, authorization.AuthorizationURL)
+}
+
+// CompleteOAuthLogin 外部プロバイダーからのコールバックを処理
+// @Summary 外部プロバイダーコールバック
+// @Description 認可コードを交換し、ログインまたはアカウント連携を完了します
+// @Tags auth
+// @Produce json
+// @Param provider path string true "プロバイダー名 (google, github)"
+// @Param code query string true "認可コード"
+// @Param state query string true "state"
+// @Success 200 {object} entity.OAuthCallbackResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/oauth/{provider}/callback [get]
+func (c *OAuthController) CompleteOAuthLogin(ctx *gin.Context, provider string, params api.CompleteOAuthLoginParams) {
+	encodedState, _ := ctx.Cookie(oauthStateCookie)
+	c.setStateCookie(ctx, "", -1)
+
+	if params.Error != nil && *params.Error != "" {
+		problem.Write(ctx, http.StatusUnauthorized, "OAUTH_PROVIDER_ERROR", "Provider returned error: "+*params.Error)
+		return
+	}
+
+	var code, state string
+	if params.Code != nil {
+		code = *params.Code
+	}
+	if params.State != nil {
+		state = *params.State
+	}
+	if code == "" || state == "" || encodedState == "" {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "code, state and state cookie are required")
+		return
+	}
+
+	result, err := c.oauthUseCase.Complete(ctx.Request.Context(), provider, code, state, encodedState)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+	if err := issueSession(ctx, c.sessions, result.Auth); err != nil {
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetIdentities 連携済みプロバイダー一覧取得ハンドラー
+// @Summary 連携済みプロバイダー一覧
+// @Description ログイン中のユーザーに連携されている外部プロバイダーを取得します
+// @Tags identities
+// @Produce json
+// @Success 200 {object} entity.UserIdentitiesResponse
+// @Security BearerAuth
+// @Router /me/identities [get]
+func (c *OAuthController) GetIdentities(ctx *gin.Context) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	response, err := c.oauthUseCase.ListIdentities(ctx.Request.Context(), userID)
+	if err != nil {
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// LinkIdentity アカウント連携開始ハンドラー
+// @Summary アカウント連携開始
+// @Description 外部プロバイダーとの連携を開始し、認可URLを返します
+// @Tags identities
+// @Produce json
+// @Param provider path string true "プロバイダー名 (google, github)"
+// @Success 200 {object} entity.OAuthAuthorization
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /me/identities/{provider} [post]
+func (c *OAuthController) LinkIdentity(ctx *gin.Context, provider string) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	authorization, err := c.oauthUseCase.Begin(ctx.Request.Context(), provider, userID)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	c.setStateCookie(ctx, authorization.State, c.stateTTL)
+	ctx.JSON(http.StatusOK, authorization)
+}
+
+// UnlinkIdentity アカウント連携解除ハンドラー
+// @Summary アカウント連携解除
+// @Description 外部プロバイダーとの連携を解除します
+// @Tags identities
+// @Param provider path string true "プロバイダー名 (google, github)"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /me/identities/{provider} [delete]
+func (c *OAuthController) UnlinkIdentity(ctx *gin.Context, provider string) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	if err := c.oauthUseCase.Unlink(ctx.Request.Context(), userID, provider); err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// setStateCookie 状態クッキーを設定（maxAgeが負なら削除）
+func (c *OAuthController) setStateCookie(ctx *gin.Context, value string, maxAge int) {
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oauthStateCookie, value, maxAge, oauthStateCookiePath, "", c.secureCookie, true)
+}
+
+// respondError ユースケースのエラーをHTTPレスポンスに変換
+func (c *OAuthController) respondError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrOAuthProviderNotFound):
+		problem.Write(ctx, http.StatusNotFound, "OAUTH_PROVIDER_NOT_FOUND", err.Error())
+	case errors.Is(err, oauth.ErrInvalidState), errors.Is(err, usecase.ErrOAuthStateMismatch):
+		problem.Write(ctx, http.StatusBadRequest, "OAUTH_STATE_INVALID", err.Error())
+	case errors.Is(err, usecase.ErrOAuthEmailRequired):
+		problem.Write(ctx, http.StatusBadRequest, "OAUTH_EMAIL_REQUIRED", err.Error())
+	case errors.Is(err, usecase.ErrOAuthEmailConflict):
+		problem.Write(ctx, http.StatusConflict, "EMAIL_ALREADY_EXISTS", err.Error())
+	case errors.Is(err, usecase.ErrRegistrationInviteOnly):
+		problem.Write(ctx, http.StatusForbidden, "REGISTRATION_INVITE_ONLY", err.Error())
+	case errors.Is(err, usecase.ErrIdentityAlreadyLinked):
+		problem.Write(ctx, http.StatusConflict, "IDENTITY_ALREADY_LINKED", err.Error())
+	case errors.Is(err, usecase.ErrIdentityNotFound):
+		problem.Write(ctx, http.StatusNotFound, "IDENTITY_NOT_FOUND", err.Error())
+	case errors.Is(err, usecase.ErrLastLoginMethod):
+		problem.Write(ctx, http.StatusConflict, "LAST_LOGIN_METHOD", err.Error())
+	case auth.IsAccountBlocked(err):
+		problem.Write(ctx, http.StatusForbidden, auth.ErrorCode(err), err.Error())
+	default:
+		problem.Write(ctx, http.StatusUnauthorized, "OAUTH_ERROR", err.Error())
+	}
+}
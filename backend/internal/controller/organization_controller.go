@@ -0,0 +1,297 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/api"
+	"app-template/internal/entity"
+	"app-template/internal/usecase"
+	"app-template/pkg/middleware"
+	"app-template/pkg/problem"
+)
+
+// OrganizationController 組織コントローラー
+type OrganizationController struct {
+	orgUseCase usecase.OrganizationUseCase
+}
+
+var _ api.OrganizationsServer = (*OrganizationController)(nil)
+
+// NewOrganizationController 組織コントローラーの新しいインスタンスを作成
+func NewOrganizationController(orgUseCase usecase.OrganizationUseCase) *OrganizationController {
+	return &OrganizationController{
+		orgUseCase: orgUseCase,
+	}
+}
+
+// GetOrganizations 所属組織一覧取得ハンドラー
+// @Summary 所属組織一覧
+// @Description 認証ユーザーが所属する組織とその組織でのロールを取得します
+// @Tags organizations
+// @Produce json
+// @Success 200 {object} entity.OrganizationsResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations [get]
+func (c *OrganizationController) GetOrganizations(ctx *gin.Context) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	response, err := c.orgUseCase.List(ctx.Request.Context(), userID)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// CreateOrganization 組織作成ハンドラー
+// @Summary 組織作成
+// @Description 組織を作成し、作成したユーザーをオーナーとして追加します。スラッグはサブドメイン・X-Organization ヘッダーでの指定に使います
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param request body entity.CreateOrganizationRequest true "組織作成リクエスト"
+// @Success 201 {object} entity.OrganizationMembership
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations [post]
+func (c *OrganizationController) CreateOrganization(ctx *gin.Context) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	var req entity.CreateOrganizationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return
+	}
+
+	response, err := c.orgUseCase.Create(ctx.Request.Context(), userID, &req)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// GetOrganization 組織詳細取得ハンドラー
+// @Summary 組織詳細
+// @Tags organizations
+// @Produce json
+// @Param id path int true "組織ID"
+// @Success 200 {object} entity.OrganizationMembership
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id} [get]
+func (c *OrganizationController) GetOrganization(ctx *gin.Context, orgID int64) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	response, err := c.orgUseCase.Get(ctx.Request.Context(), userID, orgID)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// IssueOrganizationToken 組織トークン発行ハンドラー
+// @Summary 組織トークン発行
+// @Description 組織をテナントとして指定したアクセストークン（org_id クレーム付き）を発行します。このトークンでのリクエストはヘッダー・サブドメインの指定がなくても組織内に絞り込まれます
+// @Tags organizations
+// @Produce json
+// @Param id path int true "組織ID"
+// @Success 200 {object} entity.OrganizationTokenResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/token [post]
+func (c *OrganizationController) IssueOrganizationToken(ctx *gin.Context, orgID int64) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	response, err := c.orgUseCase.IssueToken(ctx.Request.Context(), userID, orgID)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetOrganizationMembers メンバー一覧取得ハンドラー
+// @Summary 組織メンバー一覧
+// @Description 組織のメンバーを参加順に取得します（メンバーのみ）
+// @Tags organizations
+// @Produce json
+// @Param id path int true "組織ID"
+// @Param page query int false "ページ番号" default(1)
+// @Param limit query int false "1ページあたりの件数" default(20)
+// @Success 200 {object} entity.OrganizationMembersResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/members [get]
+func (c *OrganizationController) GetOrganizationMembers(ctx *gin.Context, orgID int64, query api.GetOrganizationMembersParams) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	params := paginationParams(query.Page, query.Limit)
+	response, err := c.orgUseCase.ListMembers(ctx.Request.Context(), userID, orgID, &params)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// AddOrganizationMember メンバー追加ハンドラー
+// @Summary 組織メンバー追加
+// @Description 登録済みのユーザーをメールアドレスで組織に追加します（owner / admin のみ。owner の追加は owner のみ）
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "組織ID"
+// @Param request body entity.AddOrganizationMemberRequest true "メンバー追加リクエスト"
+// @Success 201 {object} entity.OrganizationMember
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/members [post]
+func (c *OrganizationController) AddOrganizationMember(ctx *gin.Context, orgID int64) {
+	actorID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	var req entity.AddOrganizationMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return
+	}
+
+	response, err := c.orgUseCase.AddMember(ctx.Request.Context(), actorID, orgID, &req)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// UpdateOrganizationMember メンバーのロール変更ハンドラー
+// @Summary 組織メンバーのロール変更
+// @Description メンバーの組織でのロールを変更します（owner / admin のみ。owner への変更・owner からの変更は owner のみ。最後の owner は変更できません）
+// @Tags organizations
+// @Accept json
+// @Param id path int true "組織ID"
+// @Param user_id path int true "ユーザーID"
+// @Param request body entity.UpdateOrganizationMemberRequest true "ロール変更リクエスト"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/members/{user_id} [put]
+func (c *OrganizationController) UpdateOrganizationMember(ctx *gin.Context, orgID, userID int64) {
+	actorID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	var req entity.UpdateOrganizationMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return
+	}
+
+	if err := c.orgUseCase.UpdateMemberRole(ctx.Request.Context(), actorID, orgID, userID, req.Role); err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// RemoveOrganizationMember メンバー削除ハンドラー
+// @Summary 組織メンバー削除
+// @Description メンバーを組織から外します。自分自身は脱退できます（他のメンバーは owner / admin のみ。owner の削除は owner のみ。最後の owner は外せません）
+// @Tags organizations
+// @Param id path int true "組織ID"
+// @Param user_id path int true "ユーザーID"
+// @Success 204
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /organizations/{id}/members/{user_id} [delete]
+func (c *OrganizationController) RemoveOrganizationMember(ctx *gin.Context, orgID, userID int64) {
+	actorID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	if err := c.orgUseCase.RemoveMember(ctx.Request.Context(), actorID, orgID, userID); err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// respondError ユースケースのエラーをHTTPレスポンスに変換
+func (c *OrganizationController) respondError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrOrganizationNotFound):
+		problem.Write(ctx, http.StatusNotFound, "ORGANIZATION_NOT_FOUND", err.Error())
+	case errors.Is(err, usecase.ErrOrganizationMemberNotFound):
+		problem.Write(ctx, http.StatusNotFound, "ORGANIZATION_MEMBER_NOT_FOUND", err.Error())
+	case err.Error() == "user not found":
+		problem.Write(ctx, http.StatusNotFound, "USER_NOT_FOUND", err.Error())
+	case errors.Is(err, usecase.ErrNotOrganizationMember):
+		problem.Write(ctx, http.StatusForbidden, "NOT_ORGANIZATION_MEMBER", err.Error())
+	case errors.Is(err, usecase.ErrOrganizationForbidden):
+		problem.Write(ctx, http.StatusForbidden, "FORBIDDEN", err.Error())
+	case errors.Is(err, usecase.ErrOrganizationInvalidSlug), errors.Is(err, usecase.ErrInvalidRole):
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, usecase.ErrOrganizationSlugTaken):
+		problem.Write(ctx, http.StatusConflict, "ORGANIZATION_SLUG_TAKEN", err.Error())
+	case errors.Is(err, usecase.ErrOrganizationMemberExists):
+		problem.Write(ctx, http.StatusConflict, "ORGANIZATION_MEMBER_EXISTS", err.Error())
+	case errors.Is(err, usecase.ErrOrganizationLastOwner):
+		problem.Write(ctx, http.StatusConflict, "ORGANIZATION_LAST_OWNER", err.Error())
+	default:
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+}
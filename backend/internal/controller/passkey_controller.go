@@ -0,0 +1,251 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/api"
+	"app-template/internal/entity"
+	"app-template/internal/usecase"
+	"app-template/pkg/auth"
+	"app-template/pkg/middleware"
+	"app-template/pkg/problem"
+	"app-template/pkg/session"
+)
+
+// PasskeyController パスキー（WebAuthn）コントローラー
+type PasskeyController struct {
+	passkeyUseCase usecase.PasskeyUseCase
+	sessions       *session.Manager
+}
+
+var _ api.PasskeysServer = (*PasskeyController)(nil)
+
+// NewPasskeyController パスキーコントローラーの新しいインスタンスを作成
+func NewPasskeyController(passkeyUseCase usecase.PasskeyUseCase, sessions *session.Manager) *PasskeyController {
+	return &PasskeyController{
+		passkeyUseCase: passkeyUseCase,
+		sessions:       sessions,
+	}
+}
+
+// BeginPasskeyLogin パスキーログイン開始ハンドラー
+// @Summary パスキーログイン開始
+// @Description パスワードなしでログインするためのWebAuthnオプションを発行します
+// @Tags auth
+// @Produce json
+// @Success 200 {object} entity.PasskeyCeremony
+// @Router /auth/passkey/login/begin [post]
+func (c *PasskeyController) BeginPasskeyLogin(ctx *gin.Context) {
+	response, err := c.passkeyUseCase.BeginLogin(ctx.Request.Context())
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// FinishPasskeyLogin パスキーログイン完了ハンドラー
+// @Summary パスキーログイン完了
+// @Description 認証器の署名を検証し、アクセストークンを発行します
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body entity.PasskeyFinishRequest true "WebAuthnアサーション"
+// @Success 200 {object} entity.AuthResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/passkey/login/finish [post]
+func (c *PasskeyController) FinishPasskeyLogin(ctx *gin.Context) {
+	var req entity.PasskeyFinishRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return
+	}
+
+	response, err := c.passkeyUseCase.FinishLogin(ctx.Request.Context(), &req)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	respondAuth(ctx, c.sessions, http.StatusOK, response)
+}
+
+// BeginPasskeyMFA 二要素認証としてのパスキー認証開始ハンドラー
+// @Summary パスキーによる二段階ログイン開始
+// @Description ログイン時に返されたMFAチャレンジに対するWebAuthnオプションを発行します
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body entity.PasskeyBeginRequest true "MFAチャレンジ"
+// @Success 200 {object} entity.PasskeyCeremony
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/mfa/passkey/begin [post]
+func (c *PasskeyController) BeginPasskeyMFA(ctx *gin.Context) {
+	var req entity.PasskeyBeginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return
+	}
+
+	response, err := c.passkeyUseCase.BeginMFA(ctx.Request.Context(), &req)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// FinishPasskeyMFA 二要素認証としてのパスキー認証完了ハンドラー
+// @Summary パスキーによる二段階ログイン完了
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body entity.PasskeyFinishRequest true "WebAuthnアサーションとMFAチャレンジ"
+// @Success 200 {object} entity.AuthResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/mfa/passkey/finish [post]
+func (c *PasskeyController) FinishPasskeyMFA(ctx *gin.Context) {
+	var req entity.PasskeyFinishRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.ChallengeToken == "" {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return
+	}
+
+	response, err := c.passkeyUseCase.FinishMFA(ctx.Request.Context(), &req)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	respondAuth(ctx, c.sessions, http.StatusOK, response)
+}
+
+// GetPasskeys 登録済みパスキー一覧取得ハンドラー
+// @Summary 登録済みパスキー一覧
+// @Tags passkeys
+// @Produce json
+// @Success 200 {object} entity.PasskeysResponse
+// @Security BearerAuth
+// @Router /me/passkeys [get]
+func (c *PasskeyController) GetPasskeys(ctx *gin.Context) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	response, err := c.passkeyUseCase.List(ctx.Request.Context(), userID)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// BeginPasskeyRegistration パスキー登録開始ハンドラー
+// @Summary パスキー登録開始
+// @Description 新しいパスキーを作成するためのWebAuthnオプションを発行します
+// @Tags passkeys
+// @Produce json
+// @Success 200 {object} entity.PasskeyCeremony
+// @Security BearerAuth
+// @Router /me/passkeys/register/begin [post]
+func (c *PasskeyController) BeginPasskeyRegistration(ctx *gin.Context) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	response, err := c.passkeyUseCase.BeginRegistration(ctx.Request.Context(), userID)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// FinishPasskeyRegistration パスキー登録完了ハンドラー
+// @Summary パスキー登録完了
+// @Description 認証器のアテステーションを検証してパスキーを保存します
+// @Tags passkeys
+// @Accept json
+// @Produce json
+// @Param request body entity.PasskeyFinishRequest true "WebAuthnアテステーション"
+// @Success 201 {object} entity.WebAuthnCredential
+// @Failure 400 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /me/passkeys/register/finish [post]
+func (c *PasskeyController) FinishPasskeyRegistration(ctx *gin.Context) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	var req entity.PasskeyFinishRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return
+	}
+
+	credential, err := c.passkeyUseCase.FinishRegistration(ctx.Request.Context(), userID, &req)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, credential)
+}
+
+// DeletePasskey パスキー削除ハンドラー
+// @Summary パスキー削除
+// @Tags passkeys
+// @Param id path int true "パスキーID"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /me/passkeys/{id} [delete]
+func (c *PasskeyController) DeletePasskey(ctx *gin.Context, id int64) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	if err := c.passkeyUseCase.Delete(ctx.Request.Context(), userID, id); err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// respondError ユースケースのエラーをHTTPレスポンスに変換
+func (c *PasskeyController) respondError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrPasskeySessionInvalid):
+		problem.Write(ctx, http.StatusBadRequest, "PASSKEY_SESSION_INVALID", err.Error())
+	case errors.Is(err, usecase.ErrPasskeyNotRegistered):
+		problem.Write(ctx, http.StatusBadRequest, "PASSKEY_NOT_REGISTERED", err.Error())
+	case errors.Is(err, usecase.ErrPasskeyNotFound):
+		problem.Write(ctx, http.StatusNotFound, "PASSKEY_NOT_FOUND", err.Error())
+	case errors.Is(err, usecase.ErrPasskeyVerification):
+		problem.Write(ctx, http.StatusUnauthorized, "PASSKEY_VERIFICATION_FAILED", err.Error())
+	case errors.Is(err, usecase.ErrPasskeyCloned):
+		problem.Write(ctx, http.StatusUnauthorized, "PASSKEY_CLONED", err.Error())
+	case auth.IsAccountBlocked(err):
+		problem.Write(ctx, http.StatusForbidden, auth.ErrorCode(err), err.Error())
+	case auth.IsTokenError(err):
+		problem.Write(ctx, http.StatusUnauthorized, auth.ErrorCode(err), err.Error())
+	default:
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+}
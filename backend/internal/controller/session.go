@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/internal/entity"
+	"app-template/pkg/problem"
+	"app-template/pkg/session"
+)
+
+// respondAuth ログイン成功時の認証レスポンスを返す
+func respondAuth(ctx *gin.Context, sessions *session.Manager, status int, response *entity.AuthResponse) {
+	if err := issueSession(ctx, sessions, response); err != nil {
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+	ctx.JSON(status, toAuthResponse(response))
+}
+
+// issueSession Cookie認証モードならアクセストークンの代わりにセッションCookieを設定し、CSRFトークンを入れる
+// トークンを含まないレスポンス（二要素認証のチャレンジなど）は変更しない
+func issueSession(ctx *gin.Context, sessions *session.Manager, response *entity.AuthResponse) error {
+	if sessions == nil || !sessions.CookieMode() || response == nil || response.Token == "" || response.User == nil {
+		return nil
+	}
+
+	csrfToken, err := sessions.Login(ctx, response.User.ID)
+	if err != nil {
+		return err
+	}
+	response.Token = ""
+	response.CSRFToken = csrfToken
+	return nil
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"app-template/api"
+	"app-template/internal/entity"
+	"app-template/pkg/problem"
+)
+
+// bindRequest 本文を生成したリクエストの型で読み込み、ユースケースの型に変換して検証する（不正なら400を返す）
+// 読み込みと変換を分けることで、仕様のリクエストとユースケースの型の不一致をコンパイル時に検出する
+func bindRequest[B, R any](ctx *gin.Context, convert func(*B) R) (R, bool) {
+	var body B
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		var zero R
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return zero, false
+	}
+
+	req := convert(&body)
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return req, false
+	}
+	return req, true
+}
+
+// createUserRequest ユーザー登録リクエストをユースケースの型に変換
+func createUserRequest(body *api.CreateUserRequest) entity.CreateUserRequest {
+	return entity.CreateUserRequest{
+		Email:    body.Email,
+		Name:     body.Name,
+		Password: body.Password,
+	}
+}
+
+// loginRequest ログインリクエストをユースケースの型に変換
+func loginRequest(body *api.LoginRequest) entity.LoginRequest {
+	return entity.LoginRequest{
+		Email:    body.Email,
+		Password: body.Password,
+	}
+}
+
+// updateUserRequest ユーザー更新リクエストをユースケースの型に変換
+// プロフィールの項目は省略（変更しない）と空文字列（未設定に戻す）を区別する
+func updateUserRequest(body *api.UpdateUserRequest) entity.UpdateUserRequest {
+	req := entity.UpdateUserRequest{
+		DisplayName: body.DisplayName,
+		Locale:      body.Locale,
+		Timezone:    body.Timezone,
+		Bio:         body.Bio,
+	}
+	if body.Email != nil {
+		req.Email = *body.Email
+	}
+	if body.Name != nil {
+		req.Name = *body.Name
+	}
+	return req
+}
+
+// changeRoleRequest ロール変更リクエストをユースケースの型に変換
+func changeRoleRequest(body *api.ChangeRoleRequest) entity.ChangeRoleRequest {
+	return entity.ChangeRoleRequest{Role: body.Role}
+}
+
+// suspendUserRequest アカウント一時停止リクエストをユースケースの型に変換
+func suspendUserRequest(body *api.SuspendUserRequest) entity.SuspendUserRequest {
+	return entity.SuspendUserRequest{Reason: body.Reason, Until: body.Until}
+}
+
+// disableUserRequest アカウント無効化リクエストをユースケースの型に変換
+func disableUserRequest(body *api.DisableUserRequest) entity.DisableUserRequest {
+	return entity.DisableUserRequest{Reason: body.Reason}
+}
+
+// toUser ユーザーをレスポンスの型に変換
+func toUser(user *entity.User) api.User {
+	return api.User{
+		ID:             user.ID,
+		Email:          user.Email,
+		Name:           user.Name,
+		DisplayName:    optionalString(user.DisplayName),
+		Locale:         optionalString(user.Locale),
+		Timezone:       optionalString(user.Timezone),
+		Bio:            optionalString(user.Bio),
+		AvatarURL:      optionalString(user.AvatarURL),
+		Role:           user.Role,
+		Status:         user.Status,
+		StatusReason:   optionalString(user.StatusReason),
+		SuspendedUntil: user.SuspendedUntil,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+	}
+}
+
+// toUsersResponse ユーザー一覧をレスポンスの型に変換
+func toUsersResponse(response *entity.UsersResponse) api.UsersResponse {
+	users := make([]api.User, 0, len(response.Users))
+	for _, user := range response.Users {
+		users = append(users, toUser(user))
+	}
+
+	var pagination api.Pagination
+	if response.Pagination != nil {
+		pagination = api.Pagination{
+			Page:       response.Pagination.Page,
+			Limit:      response.Pagination.Limit,
+			Total:      response.Pagination.Total,
+			TotalPages: response.Pagination.TotalPages,
+		}
+	}
+	return api.UsersResponse{Users: users, Pagination: pagination}
+}
+
+// toAuthResponse 認証レスポンスをレスポンスの型に変換
+func toAuthResponse(response *entity.AuthResponse) api.AuthResponse {
+	converted := api.AuthResponse{
+		Token:          optionalString(response.Token),
+		CSRFToken:      optionalString(response.CSRFToken),
+		MFAMethods:     response.MFAMethods,
+		ChallengeToken: optionalString(response.ChallengeToken),
+	}
+	if response.User != nil {
+		user := toUser(response.User)
+		converted.User = &user
+	}
+	if response.MFARequired {
+		converted.MFARequired = &response.MFARequired
+	}
+	return converted
+}
+
+// optionalString 空文字列を省略する項目に変換
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/api"
+	"app-template/internal/entity"
+	"app-template/internal/usecase"
+	"app-template/pkg/problem"
+)
+
+// maxImportBytes 一括取り込みのリクエストボディの最大サイズ
+const maxImportBytes = 32 << 20
+
+// exportFlushInterval 出力をクライアントへ送る間隔（件数）
+const exportFlushInterval = 100
+
+// UserBulkController ユーザーの一括取り込み・出力コントローラー
+type UserBulkController struct {
+	bulkUseCase usecase.UserBulkUseCase
+}
+
+var _ api.UserBulkServer = (*UserBulkController)(nil)
+
+// NewUserBulkController ユーザーの一括取り込み・出力コントローラーの新しいインスタンスを作成
+func NewUserBulkController(bulkUseCase usecase.UserBulkUseCase) *UserBulkController {
+	return &UserBulkController{
+		bulkUseCase: bulkUseCase,
+	}
+}
+
+// ImportUsers ユーザー一括取り込みハンドラー
+// @Summary ユーザー一括取り込み
+// @Description CSV（ヘッダー行: email,name[,password][,role]）またはNDJSONのユーザーを作成し、行ごとの結果を返します（users:admin 権限が必要）
+// @Description 入力内・登録済みのメールアドレスと重複する行はスキップ、不正な行は失敗として残りの行を続けて取り込みます
+// @Tags admin
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param format query string false "入力形式（csv / ndjson、省略時はContent-Typeで判定）"
+// @Param dry_run query bool false "trueなら検証のみ行い、作成しない"
+// @Success 200 {object} entity.ImportUsersResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 415 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/users/import [post]
+func (c *UserBulkController) ImportUsers(ctx *gin.Context, params api.ImportUsersParams) {
+	var requested string
+	if params.Format != nil {
+		requested = *params.Format
+	}
+	format, ok := parseBulkFormat(requested, ctx.ContentType())
+	if !ok {
+		problem.Write(ctx, http.StatusUnsupportedMediaType, "UNSUPPORTED_FORMAT", "Import must be text/csv or application/x-ndjson")
+		return
+	}
+
+	dryRun := params.DryRun != nil && *params.DryRun
+
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportBytes)
+	rows, err := newUserRowReader(format, body)
+	if err == nil {
+		var response *entity.ImportUsersResponse
+		response, err = c.bulkUseCase.Import(ctx.Request.Context(), rows, dryRun)
+		if err == nil {
+			ctx.JSON(http.StatusOK, response)
+			return
+		}
+	}
+
+	var tooLarge *http.MaxBytesError
+	var invalid *invalidImportError
+	switch {
+	case errors.As(err, &tooLarge):
+		problem.Write(ctx, http.StatusRequestEntityTooLarge, "IMPORT_TOO_LARGE", "Import must be at most "+strconv.Itoa(maxImportBytes>>20)+" MiB")
+	case errors.As(err, &invalid):
+		problem.Write(ctx, http.StatusBadRequest, "INVALID_IMPORT", invalid.Error())
+	default:
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+}
+
+// ExportUsers ユーザー一括出力ハンドラー
+// @Summary ユーザー一括出力
+// @Description 全ユーザーをID順にCSVまたはNDJSONで出力します（users:admin 権限が必要）。件数によらず少しずつ読み込みながら送信します
+// @Tags admin
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "出力形式（csv / ndjson、省略時はAcceptで判定し、既定はcsv）"
+// @Success 200 {string} string
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/users/export [get]
+func (c *UserBulkController) ExportUsers(ctx *gin.Context, params api.ExportUsersParams) {
+	var format string
+	if params.Format != nil {
+		format = *params.Format
+	}
+	if format == "" {
+		format = acceptedBulkFormat(ctx.GetHeader("Accept"))
+	}
+	if _, ok := bulkContentTypes[format]; !ok {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid query parameters", problem.FieldError{Field: "format", Message: "must be one of: csv, ndjson"})
+		return
+	}
+
+	ctx.Header("Content-Type", bulkContentTypes[format])
+	ctx.Header("Content-Disposition", `attachment; filename="users.`+format+`"`)
+
+	writer, err := newUserWriter(format, ctx.Writer)
+	if err != nil {
+		log.Printf("failed to start user export: %v", err)
+		return
+	}
+
+	// 送信を始めた後はステータスを変えられないため、途中のエラーはログに残して打ち切る
+	count := 0
+	err = c.bulkUseCase.Export(ctx.Request.Context(), func(user *entity.User) error {
+		if err := writer.Write(user); err != nil {
+			return err
+		}
+		count++
+		if count%exportFlushInterval == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			ctx.Writer.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err != nil && !ctx.Writer.Written() {
+		ctx.Writer.Header().Del("Content-Disposition")
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("user export aborted after %d users: %v", count, err)
+		return
+	}
+	ctx.Writer.Flush()
+}
@@ -0,0 +1,270 @@
+package controller
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+
+	"app-template/internal/entity"
+	"app-template/internal/usecase"
+)
+
+// 一括取り込み・出力の形式
+const (
+	bulkFormatCSV    = "csv"
+	bulkFormatNDJSON = "ndjson"
+)
+
+// bulkContentTypes 形式ごとのContent-Type
+var bulkContentTypes = map[string]string{
+	bulkFormatCSV:    "text/csv; charset=utf-8",
+	bulkFormatNDJSON: "application/x-ndjson",
+}
+
+// bulkMediaTypes Content-Type・Acceptのメディアタイプと形式の対応
+var bulkMediaTypes = map[string]string{
+	"text/csv":             bulkFormatCSV,
+	"application/csv":      bulkFormatCSV,
+	"application/x-ndjson": bulkFormatNDJSON,
+	"application/ndjson":   bulkFormatNDJSON,
+	"application/jsonl":    bulkFormatNDJSON,
+}
+
+// importColumns 取り込みで受け付けるCSVの列（email・name は必須）
+var importColumns = map[string]bool{
+	"email":    true,
+	"name":     true,
+	"password": false,
+	"role":     false,
+}
+
+// exportColumns 出力するCSVの列
+var exportColumns = []string{"id", "email", "name", "role", "status", "status_reason", "suspended_until", "created_at", "updated_at"}
+
+// maxNDJSONLineBytes NDJSONの1行の最大バイト数
+const maxNDJSONLineBytes = 64 * 1024
+
+// invalidImportError 入力全体が読み取れない（ヘッダー不正・長すぎる行など）
+type invalidImportError struct {
+	err error
+}
+
+func (e *invalidImportError) Error() string {
+	return e.err.Error()
+}
+
+func (e *invalidImportError) Unwrap() error {
+	return e.err
+}
+
+// parseBulkFormat format パラメータまたはメディアタイプから形式を判定
+func parseBulkFormat(format, mediaType string) (string, bool) {
+	if format != "" {
+		_, ok := bulkContentTypes[format]
+		return format, ok
+	}
+
+	parsed, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return "", false
+	}
+	format, ok := bulkMediaTypes[parsed]
+	return format, ok
+}
+
+// acceptedBulkFormat Acceptヘッダーから出力形式を判定（該当がなければCSV）
+func acceptedBulkFormat(accept string) string {
+	for _, mediaType := range strings.Split(accept, ",") {
+		if format, ok := parseBulkFormat("", strings.TrimSpace(mediaType)); ok {
+			return format
+		}
+	}
+	return bulkFormatCSV
+}
+
+// newUserRowReader 形式に応じた取り込み行の読み取り器を作成
+func newUserRowReader(format string, r io.Reader) (usecase.UserRowReader, error) {
+	if format == bulkFormatNDJSON {
+		return newNDJSONUserReader(r), nil
+	}
+	return newCSVUserReader(r)
+}
+
+// csvUserReader ヘッダー行の列名で項目を対応付けるCSVの読み取り器
+type csvUserReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+// newCSVUserReader ヘッダー行を読み取ってCSVの読み取り器を作成
+func newCSVUserReader(r io.Reader) (*csvUserReader, error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, &invalidImportError{err: errors.New("header row is required")}
+	}
+	if err != nil {
+		return nil, &invalidImportError{err: err}
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			// Excelなどが付けるBOMを除く
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := importColumns[name]; !ok {
+			return nil, &invalidImportError{err: fmt.Errorf("unknown column %q", name)}
+		}
+		columns[name] = i
+	}
+	for name, required := range importColumns {
+		if _, ok := columns[name]; required && !ok {
+			return nil, &invalidImportError{err: fmt.Errorf("column %q is required", name)}
+		}
+	}
+
+	return &csvUserReader{reader: reader, columns: columns}, nil
+}
+
+// Next 次の行を読み取る（列数の不一致・引用符の誤りなど構文の誤りは行のエラー）
+func (r *csvUserReader) Next() (*entity.ImportUserRow, error) {
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return nil, &usecase.ImportRowError{Line: parseErr.StartLine, Err: parseErr.Err}
+	}
+	if err != nil {
+		return nil, &invalidImportError{err: err}
+	}
+
+	line, _ := r.reader.FieldPos(0)
+	return &entity.ImportUserRow{
+		Line:     line,
+		Email:    r.field(record, "email"),
+		Name:     r.field(record, "name"),
+		Password: r.field(record, "password"),
+		Role:     r.field(record, "role"),
+	}, nil
+}
+
+// field 列名の値（列がなければ空）
+func (r *csvUserReader) field(record []string, name string) string {
+	if i, ok := r.columns[name]; ok {
+		return record[i]
+	}
+	return ""
+}
+
+// ndjsonUserReader 1行に1つのJSONオブジェクトを読み取る読み取り器（空行は無視する）
+type ndjsonUserReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// newNDJSONUserReader NDJSONの読み取り器を作成
+func newNDJSONUserReader(r io.Reader) *ndjsonUserReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxNDJSONLineBytes)
+	return &ndjsonUserReader{scanner: scanner}
+}
+
+// Next 次の行を読み取る（JSONとして不正な行は行のエラー）
+func (r *ndjsonUserReader) Next() (*entity.ImportUserRow, error) {
+	for r.scanner.Scan() {
+		r.line++
+		data := bytes.TrimSpace(r.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		row := &entity.ImportUserRow{Line: r.line}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(row); err != nil {
+			return nil, &usecase.ImportRowError{Line: r.line, Err: fmt.Errorf("invalid JSON: %w", err)}
+		}
+		return row, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, &invalidImportError{err: err}
+	}
+	return nil, io.EOF
+}
+
+// userWriter 出力形式ごとの書き出し
+type userWriter interface {
+	Write(user *entity.User) error
+	Flush() error
+}
+
+// newUserWriter 形式に応じた書き出しを作成（CSVはヘッダー行を書き出す）
+func newUserWriter(format string, w io.Writer) (userWriter, error) {
+	if format == bulkFormatNDJSON {
+		return &ndjsonUserWriter{encoder: json.NewEncoder(w)}, nil
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportColumns); err != nil {
+		return nil, err
+	}
+	return &csvUserWriter{writer: writer, record: make([]string, len(exportColumns))}, nil
+}
+
+// csvUserWriter CSVの書き出し
+type csvUserWriter struct {
+	writer *csv.Writer
+	record []string
+}
+
+func (w *csvUserWriter) Write(user *entity.User) error {
+	suspendedUntil := ""
+	if user.SuspendedUntil != nil {
+		suspendedUntil = user.SuspendedUntil.Format(time.RFC3339)
+	}
+
+	w.record[0] = strconv.FormatInt(user.ID, 10)
+	w.record[1] = user.Email
+	w.record[2] = user.Name
+	w.record[3] = user.Role
+	w.record[4] = user.Status
+	w.record[5] = user.StatusReason
+	w.record[6] = suspendedUntil
+	w.record[7] = user.CreatedAt.Format(time.RFC3339)
+	w.record[8] = user.UpdatedAt.Format(time.RFC3339)
+	return w.writer.Write(w.record)
+}
+
+func (w *csvUserWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// ndjsonUserWriter NDJSONの書き出し（APIのユーザーと同じJSON）
+type ndjsonUserWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonUserWriter) Write(user *entity.User) error {
+	return w.encoder.Encode(user)
+}
+
+func (w *ndjsonUserWriter) Flush() error {
+	return nil
+}
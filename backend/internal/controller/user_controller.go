@@ -1,89 +1,123 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 
+	"app-template/api"
 	"app-template/internal/entity"
 	"app-template/internal/usecase"
+	"app-template/pkg/auth"
+	"app-template/pkg/middleware"
+	"app-template/pkg/problem"
+	"app-template/pkg/session"
 )
 
 // UserController ユーザーコントローラー
+// OpenAPI仕様から生成したインターフェースを実装し、リクエスト・レスポンスも生成した型で読み書きして
+// 仕様との不一致をコンパイル時に検出する
 type UserController struct {
 	userUseCase usecase.UserUseCase
+	sessions    *session.Manager
 }
 
+var (
+	_ api.AuthServer  = (*UserController)(nil)
+	_ api.UsersServer = (*UserController)(nil)
+)
+
 // NewUserController ユーザーコントローラーの新しいインスタンスを作成
-func NewUserController(userUseCase usecase.UserUseCase) *UserController {
+// sessions はCookie認証モードのログイン・ログアウトに使う
+func NewUserController(userUseCase usecase.UserUseCase, sessions *session.Manager) *UserController {
 	return &UserController{
 		userUseCase: userUseCase,
+		sessions:    sessions,
 	}
 }
 
-// Register ユーザー登録ハンドラー
+// RegisterUser ユーザー登録ハンドラー
 // @Summary ユーザー登録
 // @Description 新しいユーザーを登録します
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param request body entity.CreateUserRequest true "ユーザー登録リクエスト"
-// @Success 201 {object} entity.AuthResponse
+// @Param request body api.CreateUserRequest true "ユーザー登録リクエスト"
+// @Success 201 {object} api.AuthResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Router /auth/register [post]
-func (c *UserController) Register(ctx *gin.Context) {
-	var req entity.CreateUserRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, ErrorResponse{
-			Error: "Invalid request body",
-			Code:  "VALIDATION_ERROR",
-		})
+func (c *UserController) RegisterUser(ctx *gin.Context) {
+	req, ok := bindRequest(ctx, createUserRequest)
+	if !ok {
 		return
 	}
 
 	response, err := c.userUseCase.Register(ctx.Request.Context(), &req)
+	if errors.Is(err, usecase.ErrRegistrationInviteOnly) {
+		problem.Write(ctx, http.StatusForbidden, "REGISTRATION_INVITE_ONLY", err.Error())
+		return
+	}
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, ErrorResponse{
-			Error: err.Error(),
-			Code:  "REGISTRATION_ERROR",
-		})
+		problem.Write(ctx, http.StatusBadRequest, "REGISTRATION_ERROR", err.Error())
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, response)
+	respondAuth(ctx, c.sessions, http.StatusCreated, response)
 }
 
-// Login ログインハンドラー
+// LoginUser ログインハンドラー
 // @Summary ログイン
-// @Description ユーザーログインを行います
+// @Description ユーザーログインを行います（AUTH_MODE=cookie ではトークンの代わりにセッションCookieを設定し、CSRFトークンを返します）
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param request body entity.LoginRequest true "ログインリクエスト"
-// @Success 200 {object} entity.AuthResponse
+// @Param request body api.LoginRequest true "ログインリクエスト"
+// @Success 200 {object} api.AuthResponse
 // @Failure 401 {object} ErrorResponse
 // @Router /auth/login [post]
-func (c *UserController) Login(ctx *gin.Context) {
-	var req entity.LoginRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, ErrorResponse{
-			Error: "Invalid request body",
-			Code:  "VALIDATION_ERROR",
-		})
+func (c *UserController) LoginUser(ctx *gin.Context) {
+	req, ok := bindRequest(ctx, loginRequest)
+	if !ok {
 		return
 	}
 
 	response, err := c.userUseCase.Login(ctx.Request.Context(), &req)
+	if auth.IsAccountBlocked(err) {
+		problem.Write(ctx, http.StatusForbidden, auth.ErrorCode(err), err.Error())
+		return
+	}
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, ErrorResponse{
-			Error: err.Error(),
-			Code:  "LOGIN_ERROR",
-		})
+		problem.Write(ctx, http.StatusUnauthorized, "LOGIN_ERROR", err.Error())
 		return
 	}
 
-	ctx.JSON(http.StatusOK, response)
+	respondAuth(ctx, c.sessions, http.StatusOK, response)
+}
+
+// LogoutUser ログアウトハンドラー
+// @Summary ログアウト
+// @Description セッションCookieのセッションを削除してCookieを消去します。セッションCookieがある場合は X-CSRF-Token ヘッダーが必要です
+// @Tags auth
+// @Param X-CSRF-Token header string false "CSRFトークン"
+// @Success 204
+// @Failure 403 {object} ErrorResponse
+// @Router /auth/logout [post]
+func (c *UserController) LogoutUser(ctx *gin.Context) {
+	if c.sessions != nil {
+		err := c.sessions.Logout(ctx)
+		if errors.Is(err, auth.ErrCSRFTokenInvalid) {
+			problem.Write(ctx, http.StatusForbidden, auth.ErrorCode(err), err.Error())
+			return
+		}
+		if err != nil {
+			problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+			return
+		}
+	}
+
+	ctx.Status(http.StatusNoContent)
 }
 
 // GetUsers ユーザー一覧取得ハンドラー
@@ -94,63 +128,52 @@ func (c *UserController) Login(ctx *gin.Context) {
 // @Produce json
 // @Param page query int false "ページ番号" default(1)
 // @Param limit query int false "1ページあたりの件数" default(20)
-// @Success 200 {object} entity.UsersResponse
+// @Success 200 {object} api.UsersResponse
 // @Security BearerAuth
 // @Router /users [get]
-func (c *UserController) GetUsers(ctx *gin.Context) {
-	var params entity.PaginationParams
-	if err := ctx.ShouldBindQuery(&params); err != nil {
-		ctx.JSON(http.StatusBadRequest, ErrorResponse{
-			Error: "Invalid query parameters",
-			Code:  "VALIDATION_ERROR",
-		})
-		return
-	}
-
+func (c *UserController) GetUsers(ctx *gin.Context, query api.GetUsersParams) {
+	params := paginationParams(query.Page, query.Limit)
 	response, err := c.userUseCase.List(ctx.Request.Context(), &params)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error: err.Error(),
-			Code:  "INTERNAL_ERROR",
-		})
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
 		return
 	}
 
-	ctx.JSON(http.StatusOK, response)
+	ctx.JSON(http.StatusOK, toUsersResponse(response))
 }
 
-// GetUser ユーザー詳細取得ハンドラー
+// paginationParams 生成したクエリパラメータをページネーションの条件に変換
+// 未指定の値はユースケースで既定値になる
+func paginationParams(page, limit *int) entity.PaginationParams {
+	var params entity.PaginationParams
+	if page != nil {
+		params.Page = *page
+	}
+	if limit != nil {
+		params.Limit = *limit
+	}
+	return params
+}
+
+// GetUserByID ユーザー詳細取得ハンドラー
 // @Summary ユーザー詳細取得
 // @Description IDでユーザーの詳細を取得します
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param id path int true "ユーザーID"
-// @Success 200 {object} entity.User
+// @Success 200 {object} api.User
 // @Failure 404 {object} ErrorResponse
 // @Security BearerAuth
 // @Router /users/{id} [get]
-func (c *UserController) GetUser(ctx *gin.Context) {
-	idStr := ctx.Param("id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, ErrorResponse{
-			Error: "Invalid user ID",
-			Code:  "VALIDATION_ERROR",
-		})
-		return
-	}
-
+func (c *UserController) GetUserByID(ctx *gin.Context, id int64) {
 	user, err := c.userUseCase.GetByID(ctx.Request.Context(), id)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, ErrorResponse{
-			Error: err.Error(),
-			Code:  "USER_NOT_FOUND",
-		})
+		problem.Write(ctx, http.StatusNotFound, "USER_NOT_FOUND", err.Error())
 		return
 	}
 
-	ctx.JSON(http.StatusOK, user)
+	ctx.JSON(http.StatusOK, toUser(user))
 }
 
 // UpdateUser ユーザー更新ハンドラー
@@ -160,46 +183,158 @@ func (c *UserController) GetUser(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "ユーザーID"
-// @Param request body entity.UpdateUserRequest true "ユーザー更新リクエスト"
-// @Success 200 {object} entity.User
+// @Param request body api.UpdateUserRequest true "ユーザー更新リクエスト"
+// @Success 200 {object} api.User
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Security BearerAuth
 // @Router /users/{id} [put]
-func (c *UserController) UpdateUser(ctx *gin.Context) {
-	idStr := ctx.Param("id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
+func (c *UserController) UpdateUser(ctx *gin.Context, id int64) {
+	req, ok := bindRequest(ctx, updateUserRequest)
+	if !ok {
+		return
+	}
+
+	user, err := c.userUseCase.Update(ctx.Request.Context(), id, &req)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, ErrorResponse{
-			Error: "Invalid user ID",
-			Code:  "VALIDATION_ERROR",
-		})
+		statusCode := http.StatusBadRequest
+		if err.Error() == "user not found" {
+			statusCode = http.StatusNotFound
+		}
+		problem.Write(ctx, statusCode, "UPDATE_ERROR", err.Error())
 		return
 	}
 
-	var req entity.UpdateUserRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, ErrorResponse{
-			Error: "Invalid request body",
-			Code:  "VALIDATION_ERROR",
-		})
+	ctx.JSON(http.StatusOK, toUser(user))
+}
+
+// ChangeUserRole ロール変更ハンドラー
+// @Summary ロール変更
+// @Description ユーザーのロールを変更します（users:admin 権限が必要）
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "ユーザーID"
+// @Param request body api.ChangeRoleRequest true "ロール変更リクエスト"
+// @Success 200 {object} api.User
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /users/{id}/role [put]
+func (c *UserController) ChangeUserRole(ctx *gin.Context, id int64) {
+	actorID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
 		return
 	}
 
-	user, err := c.userUseCase.Update(ctx.Request.Context(), id, &req)
+	req, ok := bindRequest(ctx, changeRoleRequest)
+	if !ok {
+		return
+	}
+
+	user, err := c.userUseCase.ChangeRole(ctx.Request.Context(), actorID, id, req.Role)
 	if err != nil {
-		statusCode := http.StatusBadRequest
-		if err.Error() == "user not found" {
+		statusCode := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, usecase.ErrInvalidRole), errors.Is(err, usecase.ErrCannotChangeOwnRole):
+			statusCode = http.StatusBadRequest
+		case err.Error() == "user not found":
+			statusCode = http.StatusNotFound
+		}
+		problem.Write(ctx, statusCode, "ROLE_CHANGE_ERROR", err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toUser(user))
+}
+
+// SuspendUser アカウント一時停止ハンドラー
+// @Summary アカウント一時停止
+// @Description アカウントを一時停止します。untilを省略した場合は再開するまで無期限です（管理者のみ）
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "ユーザーID"
+// @Param request body api.SuspendUserRequest true "停止理由と解除日時"
+// @Success 200 {object} api.User
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /users/{id}/suspend [post]
+func (c *UserController) SuspendUser(ctx *gin.Context, id int64) {
+	req, ok := bindRequest(ctx, suspendUserRequest)
+	if !ok {
+		return
+	}
+	c.changeStatus(ctx, func(actorID int64) (*entity.User, error) {
+		return c.userUseCase.Suspend(ctx.Request.Context(), actorID, id, &req)
+	})
+}
+
+// DisableUser アカウント無効化ハンドラー
+// @Summary アカウント無効化
+// @Description アカウントを無効化します。再開されるまでログインとAPI利用を拒否します（管理者のみ）
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "ユーザーID"
+// @Param request body api.DisableUserRequest true "無効化の理由"
+// @Success 200 {object} api.User
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /users/{id}/disable [post]
+func (c *UserController) DisableUser(ctx *gin.Context, id int64) {
+	req, ok := bindRequest(ctx, disableUserRequest)
+	if !ok {
+		return
+	}
+	c.changeStatus(ctx, func(actorID int64) (*entity.User, error) {
+		return c.userUseCase.Disable(ctx.Request.Context(), actorID, id, &req)
+	})
+}
+
+// ReinstateUser アカウント再開ハンドラー
+// @Summary アカウント再開
+// @Description 一時停止・無効化したアカウントを再開します（管理者のみ）
+// @Tags users
+// @Produce json
+// @Param id path int true "ユーザーID"
+// @Success 200 {object} api.User
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /users/{id}/reinstate [post]
+func (c *UserController) ReinstateUser(ctx *gin.Context, id int64) {
+	c.changeStatus(ctx, func(actorID int64) (*entity.User, error) {
+		return c.userUseCase.Reinstate(ctx.Request.Context(), actorID, id)
+	})
+}
+
+// changeStatus アカウント状態変更ハンドラーの共通処理
+func (c *UserController) changeStatus(ctx *gin.Context, change func(actorID int64) (*entity.User, error)) {
+	actorID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		respondUnauthenticated(ctx)
+		return
+	}
+
+	user, err := change(actorID)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, usecase.ErrCannotChangeOwnStatus), errors.Is(err, usecase.ErrInvalidSuspendUntil):
+			statusCode = http.StatusBadRequest
+		case err.Error() == "user not found":
 			statusCode = http.StatusNotFound
 		}
-		ctx.JSON(statusCode, ErrorResponse{
-			Error: err.Error(),
-			Code:  "UPDATE_ERROR",
-		})
+		problem.Write(ctx, statusCode, "STATUS_CHANGE_ERROR", err.Error())
 		return
 	}
 
-	ctx.JSON(http.StatusOK, user)
+	ctx.JSON(http.StatusOK, toUser(user))
 }
 
 // DeleteUser ユーザー削除ハンドラー
@@ -213,35 +348,26 @@ func (c *UserController) UpdateUser(ctx *gin.Context) {
 // @Failure 404 {object} ErrorResponse
 // @Security BearerAuth
 // @Router /users/{id} [delete]
-func (c *UserController) DeleteUser(ctx *gin.Context) {
-	idStr := ctx.Param("id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, ErrorResponse{
-			Error: "Invalid user ID",
-			Code:  "VALIDATION_ERROR",
-		})
-		return
-	}
-
-	err = c.userUseCase.Delete(ctx.Request.Context(), id)
+func (c *UserController) DeleteUser(ctx *gin.Context, id int64) {
+	err := c.userUseCase.Delete(ctx.Request.Context(), id)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		if err.Error() == "user not found" {
 			statusCode = http.StatusNotFound
+		} else if errors.Is(err, usecase.ErrOrganizationLastOwner) {
+			statusCode = http.StatusConflict
 		}
-		ctx.JSON(statusCode, ErrorResponse{
-			Error: err.Error(),
-			Code:  "DELETE_ERROR",
-		})
+		problem.Write(ctx, statusCode, "DELETE_ERROR", err.Error())
 		return
 	}
 
 	ctx.Status(http.StatusNoContent)
 }
 
-// ErrorResponse エラーレスポンス
-type ErrorResponse struct {
-	Error string `json:"error"`
-	Code  string `json:"code"`
-} 
\ No newline at end of file
+// ErrorResponse エラーレスポンス（既定の形式。problem+json の場合は problem.Details）
+type ErrorResponse = problem.Response
+
+// respondUnauthenticated 認証情報がない場合のレスポンス
+func respondUnauthenticated(ctx *gin.Context) {
+	problem.Write(ctx, http.StatusUnauthorized, "UNAUTHORIZED", "Authentication required")
+}
@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/api"
+	"app-template/internal/entity"
+	"app-template/internal/usecase"
+	"app-template/pkg/problem"
+)
+
+// WebhookController Webhook管理コントローラー
+type WebhookController struct {
+	webhookUseCase usecase.WebhookUseCase
+}
+
+var _ api.WebhooksServer = (*WebhookController)(nil)
+
+// NewWebhookController Webhook管理コントローラーの新しいインスタンスを作成
+func NewWebhookController(webhookUseCase usecase.WebhookUseCase) *WebhookController {
+	return &WebhookController{
+		webhookUseCase: webhookUseCase,
+	}
+}
+
+// GetWebhooks Webhook一覧取得ハンドラー
+// @Summary Webhook一覧
+// @Description 登録されているWebhookの送信先を取得します（署名用の秘密鍵は含みません。users:admin 権限が必要）
+// @Tags admin
+// @Produce json
+// @Success 200 {object} entity.WebhooksResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/webhooks [get]
+func (c *WebhookController) GetWebhooks(ctx *gin.Context) {
+	response, err := c.webhookUseCase.List(ctx.Request.Context())
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// CreateWebhook Webhook作成ハンドラー
+// @Summary Webhook作成
+// @Description 送信先のURLと購読するイベント（user.created / user.updated / user.deleted）を登録します。署名用の秘密鍵はこのレスポンスでのみ返されます（users:admin 権限が必要）
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body entity.CreateWebhookRequest true "Webhook作成リクエスト"
+// @Success 201 {object} entity.CreatedWebhook
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/webhooks [post]
+func (c *WebhookController) CreateWebhook(ctx *gin.Context) {
+	var req entity.CreateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return
+	}
+
+	response, err := c.webhookUseCase.Create(ctx.Request.Context(), &req)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// GetWebhook Webhook詳細取得ハンドラー
+// @Summary Webhook詳細
+// @Tags admin
+// @Produce json
+// @Param id path int true "WebhookID"
+// @Success 200 {object} entity.Webhook
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/webhooks/{id} [get]
+func (c *WebhookController) GetWebhook(ctx *gin.Context, id int64) {
+	response, err := c.webhookUseCase.GetByID(ctx.Request.Context(), id)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// UpdateWebhook Webhook更新ハンドラー
+// @Summary Webhook更新
+// @Description 指定した項目のみ更新します。active を false にすると配信を止めます（users:admin 権限が必要）
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "WebhookID"
+// @Param request body entity.UpdateWebhookRequest true "Webhook更新リクエスト"
+// @Success 200 {object} entity.Webhook
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/webhooks/{id} [put]
+func (c *WebhookController) UpdateWebhook(ctx *gin.Context, id int64) {
+	var req entity.UpdateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		problem.Write(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request body", problem.FieldErrors(err)...)
+		return
+	}
+
+	response, err := c.webhookUseCase.Update(ctx.Request.Context(), id, &req)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// DeleteWebhook Webhook削除ハンドラー
+// @Summary Webhook削除
+// @Description 送信先と配信履歴を削除します（users:admin 権限が必要）
+// @Tags admin
+// @Param id path int true "WebhookID"
+// @Success 204
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/webhooks/{id} [delete]
+func (c *WebhookController) DeleteWebhook(ctx *gin.Context, id int64) {
+	if err := c.webhookUseCase.Delete(ctx.Request.Context(), id); err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetWebhookDeliveries 配信履歴取得ハンドラー
+// @Summary Webhook配信履歴
+// @Description 送信先への配信を新しい順に取得します。試行回数と直近の応答を含みます（users:admin 権限が必要）
+// @Tags admin
+// @Produce json
+// @Param id path int true "WebhookID"
+// @Param page query int false "ページ番号" default(1)
+// @Param limit query int false "1ページあたりの件数" default(20)
+// @Success 200 {object} entity.WebhookDeliveriesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/webhooks/{id}/deliveries [get]
+func (c *WebhookController) GetWebhookDeliveries(ctx *gin.Context, id int64, query api.GetWebhookDeliveriesParams) {
+	params := paginationParams(query.Page, query.Limit)
+	response, err := c.webhookUseCase.ListDeliveries(ctx.Request.Context(), id, &params)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// RedeliverWebhookDelivery Webhook再送ハンドラー
+// @Summary Webhook再送
+// @Description 過去の配信と同じ本文（同じイベントID）を新しい配信として送り直します（users:admin 権限が必要）
+// @Tags admin
+// @Produce json
+// @Param id path int true "WebhookID"
+// @Param delivery_id path int true "配信ID"
+// @Success 202 {object} entity.WebhookDelivery
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /admin/webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (c *WebhookController) RedeliverWebhookDelivery(ctx *gin.Context, id, deliveryID int64) {
+	response, err := c.webhookUseCase.Redeliver(ctx.Request.Context(), id, deliveryID)
+	if err != nil {
+		c.respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, response)
+}
+
+// respondError ユースケースのエラーをHTTPレスポンスに変換
+func (c *WebhookController) respondError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrWebhookNotFound):
+		problem.Write(ctx, http.StatusNotFound, "WEBHOOK_NOT_FOUND", err.Error())
+	case errors.Is(err, usecase.ErrWebhookDeliveryNotFound):
+		problem.Write(ctx, http.StatusNotFound, "WEBHOOK_DELIVERY_NOT_FOUND", err.Error())
+	case errors.Is(err, usecase.ErrWebhookInvalidURL):
+		problem.Write(ctx, http.StatusBadRequest, "INVALID_WEBHOOK_URL", err.Error())
+	case errors.Is(err, usecase.ErrWebhookInvalidEvent):
+		problem.Write(ctx, http.StatusBadRequest, "INVALID_WEBHOOK_EVENT", err.Error())
+	default:
+		problem.Write(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+}
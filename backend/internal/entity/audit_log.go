@@ -0,0 +1,121 @@
+package entity
+
+import "time"
+
+// 監査ログのアクション
+const (
+	AuditActionUserRegister     = "user.register"
+	AuditActionUserLoginSuccess = "user.login.success"
+	AuditActionUserLoginFailure = "user.login.failure"
+	AuditActionUserUpdate       = "user.update"
+	AuditActionUserDelete       = "user.delete"
+	AuditActionUserRoleChange   = "user.role_change"
+	AuditActionPasswordReset    = "user.password_reset"
+	AuditActionUserSuspend      = "user.suspend"
+	AuditActionUserDisable      = "user.disable"
+	AuditActionUserReinstate    = "user.reinstate"
+	AuditActionUserImport       = "user.import"
+	AuditActionWebhookCreate    = "webhook.create"
+	AuditActionWebhookUpdate    = "webhook.update"
+	AuditActionWebhookDelete    = "webhook.delete"
+	AuditActionWebhookRedeliver = "webhook.redeliver"
+
+	AuditActionOrganizationCreate           = "organization.create"
+	AuditActionOrganizationMemberAdd        = "organization.member_add"
+	AuditActionOrganizationMemberRoleChange = "organization.member_role_change"
+	AuditActionOrganizationMemberRemove     = "organization.member_remove"
+
+	AuditActionInvitationCreate  = "invitation.create"
+	AuditActionInvitationAccept  = "invitation.accept"
+	AuditActionInvitationDecline = "invitation.decline"
+	AuditActionInvitationRevoke  = "invitation.revoke"
+)
+
+// AuditLog 監査ログ（追記のみ）
+type AuditLog struct {
+	ID     int64  `json:"id"`
+	Action string `json:"action"`
+	// ActorID 操作したユーザー（未認証の操作はnil）
+	ActorID *int64 `json:"actor_id"`
+	// TargetUserID 操作対象のユーザー
+	TargetUserID *int64 `json:"target_user_id"`
+	IP           string `json:"ip"`
+	UserAgent    string `json:"user_agent"`
+	RequestID    string `json:"request_id"`
+	// Changes 更新されたフィールドの変更前後
+	Changes map[string]FieldChange `json:"changes,omitempty"`
+	// Metadata アクション固有の補足情報
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// FieldChange フィールドの変更前後の値
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditLogFilter 監査ログの検索条件
+type AuditLogFilter struct {
+	Action       string     `form:"action"`
+	ActorID      int64      `form:"actor_id"`
+	TargetUserID int64      `form:"target_user_id"`
+	RequestID    string     `form:"request_id"`
+	From         *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To           *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page         int        `form:"page"`
+	Limit        int        `form:"limit"`
+}
+
+// AuditLogsResponse 監査ログ一覧レスポンス
+type AuditLogsResponse struct {
+	AuditLogs  []*AuditLog         `json:"audit_logs"`
+	Pagination *PaginationResponse `json:"pagination"`
+}
+
+// DiffUser ユーザーの変更されたフィールドを抽出
+func DiffUser(before, after *User) map[string]FieldChange {
+	changes := map[string]FieldChange{}
+	if before.Email != after.Email {
+		changes["email"] = FieldChange{Before: before.Email, After: after.Email}
+	}
+	if before.Name != after.Name {
+		changes["name"] = FieldChange{Before: before.Name, After: after.Name}
+	}
+	if before.DisplayName != after.DisplayName {
+		changes["display_name"] = FieldChange{Before: before.DisplayName, After: after.DisplayName}
+	}
+	if before.Locale != after.Locale {
+		changes["locale"] = FieldChange{Before: before.Locale, After: after.Locale}
+	}
+	if before.Timezone != after.Timezone {
+		changes["timezone"] = FieldChange{Before: before.Timezone, After: after.Timezone}
+	}
+	if before.Bio != after.Bio {
+		changes["bio"] = FieldChange{Before: before.Bio, After: after.Bio}
+	}
+	if before.AvatarURL != after.AvatarURL {
+		changes["avatar_url"] = FieldChange{Before: before.AvatarURL, After: after.AvatarURL}
+	}
+	if before.Role != after.Role {
+		changes["role"] = FieldChange{Before: before.Role, After: after.Role}
+	}
+	if before.Status != after.Status {
+		changes["status"] = FieldChange{Before: before.Status, After: after.Status}
+	}
+	if before.StatusReason != after.StatusReason {
+		changes["status_reason"] = FieldChange{Before: before.StatusReason, After: after.StatusReason}
+	}
+	if !equalTime(before.SuspendedUntil, after.SuspendedUntil) {
+		changes["suspended_until"] = FieldChange{Before: before.SuspendedUntil, After: after.SuspendedUntil}
+	}
+	return changes
+}
+
+// equalTime nilを考慮して日時を比較
+func equalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,89 @@
+package entity
+
+import "time"
+
+// 招待の状態
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusDeclined = "declined"
+	InvitationStatusRevoked  = "revoked"
+)
+
+// Invitation メールアドレス宛ての招待
+type Invitation struct {
+	ID    int64  `json:"id"`
+	Email string `json:"email"`
+	// Role 承諾で作成するユーザーのロール
+	Role string `json:"role"`
+	// OrganizationID 組織への招待の場合の組織（承諾でメンバーに追加する）
+	OrganizationID   *int64 `json:"organization_id,omitempty"`
+	OrganizationRole string `json:"organization_role,omitempty"`
+	Status           string `json:"status"`
+	InvitedBy        *int64 `json:"invited_by"`
+	AcceptedUserID   *int64 `json:"accepted_user_id,omitempty"`
+	// ExpiresAt 招待リンクの有効期限
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// IsExpired 有効期限を過ぎているか
+func (i *Invitation) IsExpired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+// CreateInvitationRequest 招待作成リクエスト
+// X-Organization などでテナントを指定した場合はその組織への招待になる
+type CreateInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	// Role 承諾で作成するユーザーのロール（省略時は user。admin の指定は管理者のみ）
+	Role string `json:"role,omitempty" binding:"omitempty,oneof=user admin"`
+	// OrganizationRole 組織への招待の場合の組織でのロール（省略時は member）
+	OrganizationRole string `json:"organization_role,omitempty" binding:"omitempty,oneof=owner admin member"`
+	// ExpiresInHours 有効期間（時間、省略時は INVITATION_TTL）
+	ExpiresInHours int `json:"expires_in_hours,omitempty" binding:"omitempty,min=1,max=720"`
+}
+
+// CreatedInvitation 作成した招待（トークンはこのレスポンスでのみ返す）
+type CreatedInvitation struct {
+	*Invitation
+	Token string `json:"token"`
+	// URL 招待を承諾する画面のURL（INVITATION_ACCEPT_URL が設定されている場合）
+	URL string `json:"url,omitempty"`
+}
+
+// InvitationListParams 招待一覧の取得条件
+type InvitationListParams struct {
+	PaginationParams
+	Status string `form:"status" binding:"omitempty,oneof=pending accepted declined revoked"`
+}
+
+// InvitationsResponse 招待一覧レスポンス
+type InvitationsResponse struct {
+	Invitations []*Invitation       `json:"invitations"`
+	Pagination  *PaginationResponse `json:"pagination"`
+}
+
+// InvitationPreview 招待リンクを開いたときの表示内容
+type InvitationPreview struct {
+	Email            string    `json:"email"`
+	OrganizationName string    `json:"organization_name,omitempty"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	// AccountExists 既存のアカウントがあれば、そのアカウントでログインして承諾する
+	AccountExists bool `json:"account_exists"`
+}
+
+// AcceptInvitationRequest 招待承諾リクエスト
+// 既存のアカウントがない場合は name と password でアカウントを作成する
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Name     string `json:"name,omitempty" binding:"omitempty,max=255"`
+	Password string `json:"password,omitempty" binding:"omitempty,min=8"`
+}
+
+// DeclineInvitationRequest 招待辞退リクエスト
+type DeclineInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
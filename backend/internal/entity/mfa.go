@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"time"
+)
+
+// 二要素認証の方式
+const (
+	MFAMethodTOTP     = "totp"
+	MFAMethodWebAuthn = "webauthn"
+)
+
+// UserTOTP ユーザーのTOTP設定
+type UserTOTP struct {
+	UserID       int64
+	Secret       string
+	EnabledAt    *time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Enabled 登録確認済みで有効か
+func (t *UserTOTP) Enabled() bool {
+	return t.EnabledAt != nil
+}
+
+// TOTPEnrollment TOTP登録開始レスポンス
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFACodeRequest TOTPコードまたはリカバリーコードを伴うリクエスト
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFAVerifyRequest 二段階ログインの検証リクエスト
+type MFAVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// RecoveryCodesResponse リカバリーコード発行レスポンス（一度だけ表示する）
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAStatusResponse 二要素認証の設定状況
+type MFAStatusResponse struct {
+	TOTPEnabled            bool     `json:"totp_enabled"`
+	Methods                []string `json:"methods"`
+	RecoveryCodesRemaining int      `json:"recovery_codes_remaining"`
+}
@@ -0,0 +1,80 @@
+package entity
+
+import "time"
+
+// 組織でのロール
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+// IsOrgRole 定義済みの組織でのロールか判定
+func IsOrgRole(role string) bool {
+	return role == OrgRoleOwner || role == OrgRoleAdmin || role == OrgRoleMember
+}
+
+// CanManageMembers メンバーを管理できるロールか（owner / admin）
+func CanManageMembers(role string) bool {
+	return role == OrgRoleOwner || role == OrgRoleAdmin
+}
+
+// Organization 組織（テナント）
+type Organization struct {
+	ID int64 `json:"id"`
+	// Slug サブドメイン・X-Organization ヘッダーで指定する識別子
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OrganizationMembership ユーザーが所属する組織とそのロール
+type OrganizationMembership struct {
+	*Organization
+	Role string `json:"role"`
+}
+
+// OrganizationMember 組織のメンバー
+type OrganizationMember struct {
+	User     *User     `json:"user"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// CreateOrganizationRequest 組織作成リクエスト
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+	// Slug 英小文字・数字・ハイフン（3〜63文字）
+	Slug string `json:"slug" binding:"required,min=3,max=63"`
+}
+
+// AddOrganizationMemberRequest メンバー追加リクエスト（登録済みのユーザーをメールアドレスで追加する）
+type AddOrganizationMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	// Role 省略時は member
+	Role string `json:"role,omitempty" binding:"omitempty,oneof=owner admin member"`
+}
+
+// UpdateOrganizationMemberRequest メンバーのロール変更リクエスト
+type UpdateOrganizationMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner admin member"`
+}
+
+// OrganizationsResponse 所属する組織の一覧レスポンス
+type OrganizationsResponse struct {
+	Organizations []*OrganizationMembership `json:"organizations"`
+}
+
+// OrganizationMembersResponse メンバー一覧レスポンス
+type OrganizationMembersResponse struct {
+	Members    []*OrganizationMember `json:"members"`
+	Pagination *PaginationResponse   `json:"pagination"`
+}
+
+// OrganizationTokenResponse 組織を指定したアクセストークンのレスポンス
+type OrganizationTokenResponse struct {
+	Organization *Organization `json:"organization"`
+	Role         string        `json:"role"`
+	Token        string        `json:"token"`
+}
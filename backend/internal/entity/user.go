@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -9,9 +10,62 @@ type User struct {
 	ID        int64     `json:"id"`
 	Email     string    `json:"email"`
 	Name      string    `json:"name"`
+	// DisplayName 表示名（未設定ならnameを表示する）
+	DisplayName string `json:"display_name,omitempty"`
+	// Locale BCP 47 の言語タグ（例: ja-JP）
+	Locale string `json:"locale,omitempty"`
+	// Timezone IANA タイムゾーン名（例: Asia/Tokyo）
+	Timezone string `json:"timezone,omitempty"`
+	Bio      string `json:"bio,omitempty"`
+	// AvatarVersion アバター画像の版（空なら未設定）
+	AvatarVersion string `json:"-"`
+	// AvatarURL アバター画像のURL（size クエリで 64 / 128 / 256 を選べる）
+	AvatarURL string `json:"avatar_url,omitempty"`
 	Password  string    `json:"-"` // JSONには含めない
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Role      string    `json:"role"` // auth.RoleUser / auth.RoleAdmin
+	Status    string    `json:"status"`
+	// StatusReason 停止・無効化の理由
+	StatusReason string `json:"status_reason,omitempty"`
+	// SuspendedUntil 一時停止の解除日時（nilなら無期限）
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// アカウントの状態
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+	UserStatusDisabled  = "disabled"
+)
+
+// EffectiveStatus 現時点での状態（期限を過ぎた一時停止は有効として扱う）
+func (u *User) EffectiveStatus(now time.Time) string {
+	if u.Status == UserStatusSuspended && u.SuspendedUntil != nil && !now.Before(*u.SuspendedUntil) {
+		return UserStatusActive
+	}
+	if u.Status == "" {
+		return UserStatusActive
+	}
+	return u.Status
+}
+
+// IsActive 現時点でログイン・API利用が可能か
+func (u *User) IsActive(now time.Time) bool {
+	return u.EffectiveStatus(now) == UserStatusActive
+}
+
+// HasPassword パスワードが設定されているか（外部プロバイダーのみのユーザーはfalse）
+func (u *User) HasPassword() bool {
+	return u.Password != ""
+}
+
+// AvatarPath アバター画像の取得パス（版をクエリに含め、画像を変更するとURLも変わるようにする）
+func AvatarPath(userID int64, version string) string {
+	if version == "" {
+		return ""
+	}
+	return fmt.Sprintf("/api/v1/users/%d/avatar?v=%s", userID, version)
 }
 
 // CreateUserRequest ユーザー作成リクエスト
@@ -25,6 +79,28 @@ type CreateUserRequest struct {
 type UpdateUserRequest struct {
 	Email string `json:"email,omitempty" validate:"omitempty,email"`
 	Name  string `json:"name,omitempty" validate:"omitempty,min=1"`
+	// プロフィール（nilなら変更しない、空文字列なら未設定に戻す）
+	DisplayName *string `json:"display_name,omitempty" validate:"omitempty,max=100" binding:"omitempty,max=100"`
+	Locale      *string `json:"locale,omitempty" validate:"omitempty,max=35,eq=|bcp47_language_tag" binding:"omitempty,max=35,eq=|bcp47_language_tag"`
+	Timezone    *string `json:"timezone,omitempty" validate:"omitempty,max=64,eq=|timezone" binding:"omitempty,max=64,eq=|timezone"`
+	Bio         *string `json:"bio,omitempty" validate:"omitempty,max=1000" binding:"omitempty,max=1000"`
+}
+
+// ChangeRoleRequest ロール変更リクエスト
+type ChangeRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user admin"`
+}
+
+// SuspendUserRequest アカウント一時停止リクエスト
+type SuspendUserRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+	// Until 解除日時（省略時は再開されるまで無期限）
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// DisableUserRequest アカウント無効化リクエスト
+type DisableUserRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
 }
 
 // LoginRequest ログインリクエスト
@@ -34,9 +110,16 @@ type LoginRequest struct {
 }
 
 // AuthResponse 認証レスポンス
+// 二要素認証が有効な場合はTokenの代わりにChallengeTokenを返し、
+// POST /api/v1/auth/mfa/verify で本トークンに交換する
+// Cookie認証モード（AUTH_MODE=cookie）ではTokenの代わりにセッションCookieを設定し、CSRFTokenを返す
 type AuthResponse struct {
-	User  *User  `json:"user"`
-	Token string `json:"token"`
+	User           *User    `json:"user,omitempty"`
+	Token          string   `json:"token,omitempty"`
+	CSRFToken      string   `json:"csrf_token,omitempty"`
+	MFARequired    bool     `json:"mfa_required,omitempty"`
+	MFAMethods     []string `json:"mfa_methods,omitempty"`
+	ChallengeToken string   `json:"challenge_token,omitempty"`
 }
 
 // PaginationParams ページネーションパラメータ
@@ -53,6 +136,16 @@ type PaginationResponse struct {
 	TotalPages int `json:"total_pages"`
 }
 
+// UserFilter ユーザー検索条件
+type UserFilter struct {
+	// Query メールアドレスまたは名前の部分一致
+	Query  string
+	Role   string
+	Status string
+	Page   int
+	Limit  int
+}
+
 // UsersResponse ユーザー一覧レスポンス
 type UsersResponse struct {
 	Users      []*User             `json:"users"`
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"time"
+)
+
+// UserIdentity 外部認証プロバイダーのアカウントとユーザーの紐付け
+type UserIdentity struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OAuthAuthorization 外部プロバイダーの認可開始レスポンス
+type OAuthAuthorization struct {
+	AuthorizationURL string `json:"authorization_url"`
+	// State 認可リクエストの検証情報（署名済み。クッキーとして保存する）
+	State string `json:"-"`
+}
+
+// OAuthCallbackResult 外部プロバイダーのコールバック処理結果
+type OAuthCallbackResult struct {
+	// Auth ログイン時の認証レスポンス
+	Auth *AuthResponse `json:"auth,omitempty"`
+	// Identity アカウント連携時の連携情報
+	Identity *UserIdentity `json:"identity,omitempty"`
+}
+
+// UserIdentitiesResponse 連携済みプロバイダー一覧レスポンス
+type UserIdentitiesResponse struct {
+	Identities []*UserIdentity `json:"identities"`
+}
@@ -0,0 +1,39 @@
+package entity
+
+// 一括取り込みの行の結果
+const (
+	ImportStatusCreated = "created"
+	ImportStatusSkipped = "skipped"
+	ImportStatusFailed  = "failed"
+)
+
+// ImportUserRow 一括取り込みの1行（CSVのヘッダー名・NDJSONのキーはJSON名と同じ）
+type ImportUserRow struct {
+	// Line 入力での行番号（CSVはヘッダーを1行目として数える）
+	Line  int    `json:"-"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	// Password 省略時はパスワードなし（OAuth・パスキーでのみログインできる）
+	Password string `json:"password,omitempty"`
+	// Role 省略時は user
+	Role string `json:"role,omitempty"`
+}
+
+// ImportRowResult 一括取り込みの行ごとの結果
+type ImportRowResult struct {
+	Line   int    `json:"line"`
+	Email  string `json:"email,omitempty"`
+	Status string `json:"status"`
+	// Error スキップ・失敗の理由
+	Error string `json:"error,omitempty"`
+}
+
+// ImportUsersResponse 一括取り込みの結果
+// ドライランでは何も書き込まず、created は作成される予定の件数を表す
+type ImportUsersResponse struct {
+	DryRun  bool               `json:"dry_run"`
+	Created int                `json:"created"`
+	Skipped int                `json:"skipped"`
+	Failed  int                `json:"failed"`
+	Rows    []*ImportRowResult `json:"rows"`
+}
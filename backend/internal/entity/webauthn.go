@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebAuthnCredential 登録済みのパスキー
+type WebAuthnCredential struct {
+	ID           int64      `json:"id"`
+	UserID       int64      `json:"user_id"`
+	CredentialID []byte     `json:"-"`
+	Name         string     `json:"name"`
+	Data         []byte     `json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+}
+
+// PasskeyCeremony WebAuthnセレモニー開始レスポンス
+// Optionsはnavigator.credentials.create()/get()にそのまま渡す
+type PasskeyCeremony struct {
+	SessionID string      `json:"session_id"`
+	Options   interface{} `json:"options"`
+}
+
+// PasskeyBeginRequest 二要素認証としてのパスキー認証開始リクエスト
+type PasskeyBeginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+// PasskeyFinishRequest WebAuthnセレモニー完了リクエスト
+type PasskeyFinishRequest struct {
+	SessionID string `json:"session_id" binding:"required"`
+	// ChallengeToken 二要素認証として使う場合のMFAチャレンジ
+	ChallengeToken string `json:"challenge_token,omitempty"`
+	// Name 登録時のパスキー表示名
+	Name string `json:"name,omitempty"`
+	// Credential ブラウザが返したPublicKeyCredential
+	Credential json.RawMessage `json:"credential" binding:"required"`
+}
+
+// PasskeysResponse 登録済みパスキー一覧レスポンス
+type PasskeysResponse struct {
+	Passkeys []*WebAuthnCredential `json:"passkeys"`
+}
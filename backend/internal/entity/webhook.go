@@ -0,0 +1,131 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ドメインイベントの種類
+const (
+	EventUserCreated = "user.created"
+	EventUserUpdated = "user.updated"
+	EventUserDeleted = "user.deleted"
+)
+
+// WebhookEvents Webhookで購読できるイベント
+var WebhookEvents = []string{EventUserCreated, EventUserUpdated, EventUserDeleted}
+
+// IsWebhookEvent 購読できるイベントか判定
+func IsWebhookEvent(event string) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Event Webhookで送信するイベント（本文のJSON）
+type Event struct {
+	// ID イベントID（再送しても変わらないため受信側の重複排除に使える）
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// UserEventData ユーザーイベントのデータ（削除の場合は削除前のユーザー）
+type UserEventData struct {
+	User *User `json:"user"`
+	// Changes 更新されたフィールドの変更前後（user.updated のみ）
+	Changes map[string]FieldChange `json:"changes,omitempty"`
+}
+
+// Webhook 管理者が登録したWebhookの送信先
+type Webhook struct {
+	ID          int64    `json:"id"`
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	Events      []string `json:"events"`
+	// Secret 署名用の秘密鍵（作成時のレスポンスでのみ返す）
+	Secret    string    `json:"-"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Subscribes イベントを購読しているか
+func (w *Webhook) Subscribes(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateWebhookRequest Webhook作成リクエスト
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=2048"`
+	Description string   `json:"description" binding:"max=255"`
+	Events      []string `json:"events" binding:"required,min=1"`
+	// Active 省略時は有効
+	Active *bool `json:"active,omitempty"`
+}
+
+// UpdateWebhookRequest Webhook更新リクエスト（省略した項目は変更しない）
+type UpdateWebhookRequest struct {
+	URL         string   `json:"url,omitempty" binding:"omitempty,url,max=2048"`
+	Description *string  `json:"description,omitempty" binding:"omitempty,max=255"`
+	Events      []string `json:"events,omitempty" binding:"omitempty,min=1"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+// CreatedWebhook 作成直後のWebhook（署名用の秘密鍵はこのレスポンスでのみ返す）
+type CreatedWebhook struct {
+	*Webhook
+	Secret string `json:"secret"`
+}
+
+// WebhooksResponse Webhook一覧レスポンス
+type WebhooksResponse struct {
+	Webhooks []*Webhook `json:"webhooks"`
+}
+
+// 配信の状態
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookDelivery Webhookの配信履歴（イベントと送信先の組ごとに1件、再送は別の配信として記録）
+type WebhookDelivery struct {
+	ID        int64  `json:"id"`
+	WebhookID int64  `json:"webhook_id"`
+	EventID   string `json:"event_id"`
+	Event     string `json:"event"`
+	// Payload 送信する本文
+	Payload json.RawMessage `json:"payload"`
+	// Status pending（送信待ち・再試行中）/ succeeded / failed（再試行の上限に達した）
+	Status   string `json:"status"`
+	Attempts int    `json:"attempts"`
+	// ResponseStatus 直近の試行の応答ステータス（接続できなかった場合はnil）
+	ResponseStatus *int `json:"response_status"`
+	// ResponseBody 直近の試行の応答本文（先頭の一部のみ）
+	ResponseBody string `json:"response_body,omitempty"`
+	// Error 直近の試行の失敗理由
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+	// RedeliveryOf 再送元の配信ID
+	RedeliveryOf *int64     `json:"redelivery_of,omitempty"`
+	DeliveredAt  *time.Time `json:"delivered_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// WebhookDeliveriesResponse 配信履歴一覧レスポンス
+type WebhookDeliveriesResponse struct {
+	Deliveries []*WebhookDelivery  `json:"deliveries"`
+	Pagination *PaginationResponse `json:"pagination"`
+}
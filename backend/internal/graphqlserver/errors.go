@@ -0,0 +1,85 @@
+package graphqlserver
+
+import (
+	"errors"
+	"log"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+
+	"app-template/pkg/problem"
+)
+
+// Error エラーコード（RESTの code と同じ値）を extensions.code に含めるエラー
+type Error struct {
+	Code    string
+	Message string
+	Fields  []problem.FieldError
+}
+
+var _ gqlerrors.ExtendedError = (*Error)(nil)
+
+// Error エラーメッセージ
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Extensions レスポンスの extensions
+func (e *Error) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": e.Code}
+	if len(e.Fields) > 0 {
+		extensions["errors"] = e.Fields
+	}
+	return extensions
+}
+
+// newError エラーを作成
+func newError(code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// internalError 内部エラー（詳細はログにのみ出力）
+func internalError(message string, err error) *Error {
+	log.Printf("graphql: %s: %v", message, err)
+	return newError("INTERNAL_ERROR", message)
+}
+
+// validationError 入力検証エラーを項目ごとのエラーを含むエラーに変換
+func validationError(err error) *Error {
+	return &Error{
+		Code:    "VALIDATION_ERROR",
+		Message: "Invalid input",
+		Fields:  problem.FieldErrors(err),
+	}
+}
+
+// formatErrors レスポンスのエラーに extensions を設定
+// thunk（データローダー）が返したエラーはライブラリ内で包み直されて extensions が失われるため、
+// 元のエラーをたどって補う
+func formatErrors(errs []gqlerrors.FormattedError) []gqlerrors.FormattedError {
+	for i, formatted := range errs {
+		if formatted.Extensions != nil {
+			continue
+		}
+
+		var extended *Error
+		if errors.As(unwrapOriginal(formatted.OriginalError()), &extended) {
+			errs[i].Extensions = extended.Extensions()
+		}
+	}
+	return errs
+}
+
+// unwrapOriginal ライブラリのエラー型をたどって元のエラーを取り出す
+func unwrapOriginal(err error) error {
+	for err != nil {
+		switch e := err.(type) {
+		case *gqlerrors.Error:
+			err = e.OriginalError
+		case gqlerrors.FormattedError:
+			err = e.OriginalError()
+		default:
+			return err
+		}
+	}
+	return nil
+}
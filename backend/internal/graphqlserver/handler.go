@@ -0,0 +1,154 @@
+// Package graphqlserver ユースケースをGraphQLで公開するエンドポイント
+// RESTのコントローラーと同じユースケースを使い、認可もRESTと同じ規則で行う
+// ユーザーの読み込みはリクエストごとのデータローダーでまとめ、1回のクエリで取得する
+package graphqlserver
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	"app-template/internal/usecase"
+	"app-template/pkg/session"
+)
+
+// Handler GraphQLエンドポイント
+type Handler struct {
+	schema      graphql.Schema
+	userUseCase usecase.UserUseCase
+	limits      Limits
+}
+
+// request GraphQLリクエスト（GETではクエリパラメータ、POSTではJSONボディ）
+type request struct {
+	Query         string                 `json:"query" form:"query"`
+	OperationName string                 `json:"operationName" form:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// NewHandler GraphQLエンドポイントを作成
+// Cookie認証モードの sessions を渡すと、register・login はRESTと同じくトークンの代わりにセッションCookieを設定する
+func NewHandler(userUseCase usecase.UserUseCase, sessions *session.Manager, limits Limits) (*Handler, error) {
+	schema, err := newSchema(newResolver(userUseCase, sessions))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{
+		schema:      schema,
+		userUseCase: userUseCase,
+		limits:      limits,
+	}, nil
+}
+
+// Serve GraphQLリクエストを実行
+// 構文・検証・上限のエラーは実行前に 400 で返し、実行時のエラーは data と共に 200 で返す
+// GETではミューテーションを実行しない
+func (h *Handler) Serve(c *gin.Context) {
+	req, err := bindRequest(c)
+	if err != nil {
+		writeErrors(c, http.StatusBadRequest, gqlerrors.FormatError(err))
+		return
+	}
+	if req.Query == "" {
+		writeErrors(c, http.StatusBadRequest, gqlerrors.FormatError(newError("BAD_REQUEST", "query is required")))
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		writeErrors(c, http.StatusBadRequest, gqlerrors.FormatErrors(err)...)
+		return
+	}
+
+	if err := checkFragmentCycles(doc); err != nil {
+		writeErrors(c, http.StatusBadRequest, gqlerrors.FormatError(err))
+		return
+	}
+	if validation := graphql.ValidateDocument(&h.schema, doc, nil); !validation.IsValid {
+		writeErrors(c, http.StatusBadRequest, validation.Errors...)
+		return
+	}
+
+	operation := findOperation(doc, req.OperationName)
+	if operation == nil {
+		writeErrors(c, http.StatusBadRequest, gqlerrors.FormatError(newError("BAD_REQUEST", "operation not found")))
+		return
+	}
+	if c.Request.Method == http.MethodGet && operation.Operation != ast.OperationTypeQuery {
+		c.Header("Allow", http.MethodPost)
+		writeErrors(c, http.StatusMethodNotAllowed, gqlerrors.FormatError(newError("METHOD_NOT_ALLOWED", "only queries can be executed over GET")))
+		return
+	}
+
+	if err := checkLimits(doc, operation, req.Variables, h.limits); err != nil {
+		writeErrors(c, http.StatusBadRequest, gqlerrors.FormatError(err))
+		return
+	}
+
+	ctx := withLoaders(withGinContext(c.Request.Context(), c), newLoaders(h.userUseCase))
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+	result.Errors = formatErrors(result.Errors)
+
+	c.JSON(http.StatusOK, result)
+}
+
+// bindRequest リクエストを読み取る（GETの variables はJSON文字列）
+func bindRequest(c *gin.Context) (*request, error) {
+	req := &request{}
+	if c.Request.Method == http.MethodGet {
+		if err := c.ShouldBindQuery(req); err != nil {
+			return nil, newError("BAD_REQUEST", err.Error())
+		}
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return nil, newError("BAD_REQUEST", "variables must be a JSON object")
+			}
+		}
+		return req, nil
+	}
+
+	if err := c.ShouldBindJSON(req); err != nil {
+		return nil, newError("BAD_REQUEST", "Invalid request body")
+	}
+	return req, nil
+}
+
+// findOperation 実行する操作（名前の指定がなければ唯一の操作）
+func findOperation(doc *ast.Document, operationName string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationName == "" {
+			if found != nil {
+				return nil
+			}
+			found = operation
+		} else if operation.Name != nil && operation.Name.Value == operationName {
+			return operation
+		}
+	}
+	return found
+}
+
+// writeErrors 実行前のエラーを返す（data は含めない）
+func writeErrors(c *gin.Context, status int, errs ...gqlerrors.FormattedError) {
+	c.JSON(status, gin.H{"errors": formatErrors(errs)})
+}
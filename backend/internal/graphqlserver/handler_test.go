@@ -0,0 +1,277 @@
+package graphqlserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/internal/entity"
+	"app-template/internal/usecase"
+	"app-template/pkg/auth"
+	"app-template/pkg/kvstore"
+	"app-template/pkg/session"
+)
+
+// fakeUserUseCase 読み込みの呼び出しを記録するユースケース（使わないメソッドは未実装）
+type fakeUserUseCase struct {
+	usecase.UserUseCase
+
+	mu       sync.Mutex
+	users    map[int64]*entity.User
+	getByIDs [][]int64
+	lists    int
+}
+
+func newFakeUserUseCase(n int) *fakeUserUseCase {
+	f := &fakeUserUseCase{users: make(map[int64]*entity.User)}
+	for id := int64(1); id <= int64(n); id++ {
+		f.users[id] = &entity.User{ID: id, Email: fmt.Sprintf("user%d@example.com", id), Name: fmt.Sprintf("User %d", id), Role: auth.RoleUser}
+	}
+	return f
+}
+
+func (f *fakeUserUseCase) GetByIDs(_ context.Context, ids []int64) (map[int64]*entity.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.getByIDs = append(f.getByIDs, slices.Clone(ids))
+
+	found := make(map[int64]*entity.User, len(ids))
+	for _, id := range ids {
+		if user, ok := f.users[id]; ok {
+			found[id] = user
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeUserUseCase) Login(_ context.Context, req *entity.LoginRequest) (*entity.AuthResponse, error) {
+	for _, user := range f.users {
+		if user.Email == req.Email {
+			return &entity.AuthResponse{User: user, Token: "jwt-token"}, nil
+		}
+	}
+	return nil, errors.New("invalid email or password")
+}
+
+func (f *fakeUserUseCase) List(_ context.Context, params *entity.PaginationParams) (*entity.UsersResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lists++
+
+	users := []*entity.User{}
+	for id := int64((params.Page-1)*params.Limit + 1); id <= int64(params.Page*params.Limit); id++ {
+		if user, ok := f.users[id]; ok {
+			users = append(users, user)
+		}
+	}
+	return &entity.UsersResponse{
+		Users:      users,
+		Pagination: &entity.PaginationResponse{Page: params.Page, Limit: params.Limit, Total: len(f.users)},
+	}, nil
+}
+
+// response GraphQLのレスポンス
+type response struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+// errorCode 最初のエラーのコード
+func (r *response) errorCode() string {
+	if len(r.Errors) == 0 {
+		return ""
+	}
+	code, _ := r.Errors[0].Extensions["code"].(string)
+	return code
+}
+
+// serve ユーザーID 1 の主体でクエリを実行
+func serve(t *testing.T, uc usecase.UserUseCase, limits Limits, query string, variables map[string]interface{}) (int, *response) {
+	t.Helper()
+	rec, resp := serveWithSessions(t, uc, nil, limits, query, variables)
+	return rec.Code, resp
+}
+
+// serveWithSessions セッションのManagerを渡してクエリを実行（Cookieを確認できるようレコーダーを返す）
+func serveWithSessions(t *testing.T, uc usecase.UserUseCase, sessions *session.Manager, limits Limits, query string, variables map[string]interface{}) (*httptest.ResponseRecorder, *response) {
+	t.Helper()
+	handler, err := NewHandler(uc, sessions, limits)
+	if err != nil {
+		t.Fatalf("NewHandler: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/graphql", func(c *gin.Context) {
+		principal := &auth.Principal{UserID: 1, Method: "jwt", Role: auth.RoleUser}
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+	}, handler.Serve)
+
+	body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	resp := &response{}
+	if err := json.Unmarshal(rec.Body.Bytes(), resp); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+	return rec, resp
+}
+
+func TestServeBatchesUserLookups(t *testing.T) {
+	uc := newFakeUserUseCase(5)
+	query := `{
+		me { name }
+		a: user(id: "1") { name }
+		b: user(id: "2") { name }
+		c: user(id: "3") { name }
+		d: user(id: "4") { name }
+		missing: user(id: "99") { name }
+	}`
+
+	status, resp := serve(t, uc, DefaultLimits, query, nil)
+	if status != http.StatusOK || len(resp.Errors) > 0 {
+		t.Fatalf("status = %d, errors = %+v", status, resp.Errors)
+	}
+	if got := string(resp.Data["d"]); got != `{"name":"User 4"}` {
+		t.Errorf("d = %s", got)
+	}
+	if got := string(resp.Data["missing"]); got != "null" {
+		t.Errorf("missing = %s, want null", got)
+	}
+
+	// me と a は同じキーのため1件にまとまる
+	if len(uc.getByIDs) != 1 {
+		t.Fatalf("GetByIDs called %d times, want 1: %v", len(uc.getByIDs), uc.getByIDs)
+	}
+	keys := slices.Clone(uc.getByIDs[0])
+	slices.Sort(keys)
+	if want := []int64{1, 2, 3, 4, 99}; !slices.Equal(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+}
+
+func TestServeRejectsDeepQuery(t *testing.T) {
+	uc := newFakeUserUseCase(1)
+	query := `{ users { edges { node { id } } } }`
+
+	status, resp := serve(t, uc, Limits{MaxDepth: 3}, query, nil)
+	if status != http.StatusBadRequest || resp.errorCode() != "QUERY_TOO_DEEP" {
+		t.Fatalf("status = %d, code = %q", status, resp.errorCode())
+	}
+	if uc.lists != 0 {
+		t.Errorf("List called %d times before rejecting", uc.lists)
+	}
+
+	status, resp = serve(t, uc, Limits{MaxDepth: 4}, query, nil)
+	if status != http.StatusOK || len(resp.Errors) > 0 {
+		t.Errorf("depth 4: status = %d, errors = %+v", status, resp.Errors)
+	}
+}
+
+func TestServeRejectsComplexQueryWithVariable(t *testing.T) {
+	// users の複雑度は 1 + (nodes: 1 + id + name) * first
+	query := `query Users($first: Int) { users(first: $first) { nodes { id name } } }`
+	limits := Limits{MaxComplexity: 100}
+
+	tests := []struct {
+		name      string
+		variables map[string]interface{}
+		wantCode  string
+	}{
+		{name: "small first", variables: map[string]interface{}{"first": 5}},
+		{name: "default first", variables: nil},
+		{name: "large first", variables: map[string]interface{}{"first": 100}, wantCode: "QUERY_TOO_COMPLEX"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := newFakeUserUseCase(3)
+			status, resp := serve(t, uc, limits, query, tt.variables)
+			if tt.wantCode == "" {
+				if status != http.StatusOK || len(resp.Errors) > 0 {
+					t.Fatalf("status = %d, errors = %+v", status, resp.Errors)
+				}
+				return
+			}
+			if status != http.StatusBadRequest || resp.errorCode() != tt.wantCode {
+				t.Fatalf("status = %d, code = %q, want %q", status, resp.errorCode(), tt.wantCode)
+			}
+			if uc.lists != 0 {
+				t.Errorf("List called %d times before rejecting", uc.lists)
+			}
+		})
+	}
+}
+
+func TestServeRejectsFragmentCycle(t *testing.T) {
+	query := `
+		{ me { ...A } }
+		fragment A on User { id ...B }
+		fragment B on User { name ...A }
+	`
+	status, resp := serve(t, newFakeUserUseCase(1), DefaultLimits, query, nil)
+	if status != http.StatusBadRequest || resp.errorCode() != "BAD_REQUEST" {
+		t.Fatalf("status = %d, errors = %+v", status, resp.Errors)
+	}
+}
+
+func TestServeLoginInCookieMode(t *testing.T) {
+	query := `mutation { login(input: {email: "user1@example.com", password: "password123"}) { token csrfToken user { id } } }`
+
+	tests := []struct {
+		name       string
+		cookieMode bool
+	}{
+		{name: "token mode"},
+		{name: "cookie mode", cookieMode: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := session.NewManager(session.NewKV(kvstore.NewMemory()), "secret", session.Config{CookieMode: tt.cookieMode})
+			rec, resp := serveWithSessions(t, newFakeUserUseCase(1), sessions, DefaultLimits, query, nil)
+			if rec.Code != http.StatusOK || len(resp.Errors) > 0 {
+				t.Fatalf("status = %d, errors = %+v", rec.Code, resp.Errors)
+			}
+
+			var payload struct {
+				Token     *string `json:"token"`
+				CSRFToken *string `json:"csrfToken"`
+			}
+			if err := json.Unmarshal(resp.Data["login"], &payload); err != nil {
+				t.Fatal(err)
+			}
+			cookies := map[string]string{}
+			for _, cookie := range rec.Result().Cookies() {
+				cookies[cookie.Name] = cookie.Value
+			}
+
+			if !tt.cookieMode {
+				if payload.Token == nil || *payload.Token != "jwt-token" || payload.CSRFToken != nil || len(cookies) != 0 {
+					t.Errorf("token mode: payload = %s, cookies = %v", resp.Data["login"], cookies)
+				}
+				return
+			}
+			// Cookie認証モードではトークンを返さず、RESTと同じCookieを設定する
+			if payload.Token != nil {
+				t.Errorf("token leaked in cookie mode: %s", *payload.Token)
+			}
+			if payload.CSRFToken == nil || cookies[session.CSRFCookieName] != *payload.CSRFToken || cookies[session.DefaultCookieName] == "" {
+				t.Errorf("cookie mode: payload = %s, cookies = %v", resp.Data["login"], cookies)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package graphqlserver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Limits クエリの深さ・複雑度の上限（0以下なら制限なし）
+type Limits struct {
+	// MaxDepth フィールドの入れ子の深さ
+	MaxDepth int
+	// MaxComplexity フィールド数の合計（一覧は取得件数を掛けて数える）
+	MaxComplexity int
+}
+
+// DefaultLimits 既定の上限
+var DefaultLimits = Limits{MaxDepth: 10, MaxComplexity: 1000}
+
+// listFields 一覧フィールドと既定の取得件数（子フィールドの複雑度に件数を掛ける）
+var listFields = map[string]int{
+	"users": defaultPageSize,
+}
+
+// queryAnalyzer 実行前にクエリの深さと複雑度を数える
+type queryAnalyzer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// checkLimits 実行する操作が上限を超えていないか確認
+// イントロスペクション（__schema など）は数えない
+func checkLimits(doc *ast.Document, operation *ast.OperationDefinition, variables map[string]interface{}, limits Limits) error {
+	analyzer := &queryAnalyzer{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+	}
+	for _, definition := range doc.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			analyzer.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	depth, complexity := analyzer.selectionSet(operation.SelectionSet, map[string]bool{})
+	if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+		return newError("QUERY_TOO_DEEP", fmt.Sprintf("query depth %d exceeds the maximum of %d", depth, limits.MaxDepth))
+	}
+	if limits.MaxComplexity > 0 && complexity > limits.MaxComplexity {
+		return newError("QUERY_TOO_COMPLEX", fmt.Sprintf("query complexity %d exceeds the maximum of %d", complexity, limits.MaxComplexity))
+	}
+	return nil
+}
+
+// checkFragmentCycles フラグメントが自身を展開していないか確認
+// ライブラリの検証（OverlappingFieldsCanBeMerged）は循環するフラグメントで再帰が止まらず
+// スタックオーバーフローでプロセスごと落ちるため、検証より前に拒否する
+func checkFragmentCycles(doc *ast.Document) error {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, definition := range doc.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	// 0: 未訪問、1: 展開中、2: 確認済み
+	state := make(map[string]int, len(fragments))
+	var visit func(set *ast.SelectionSet) string
+	visit = func(set *ast.SelectionSet) string {
+		if set == nil {
+			return ""
+		}
+		for _, selection := range set.Selections {
+			var cycle string
+			switch selection := selection.(type) {
+			case *ast.Field:
+				cycle = visit(selection.SelectionSet)
+			case *ast.InlineFragment:
+				cycle = visit(selection.SelectionSet)
+			case *ast.FragmentSpread:
+				name := selection.Name.Value
+				fragment, ok := fragments[name]
+				if !ok || state[name] == 2 {
+					continue
+				}
+				if state[name] == 1 {
+					return name
+				}
+				state[name] = 1
+				cycle = visit(fragment.SelectionSet)
+				state[name] = 2
+			}
+			if cycle != "" {
+				return cycle
+			}
+		}
+		return ""
+	}
+
+	for name, fragment := range fragments {
+		if state[name] != 0 {
+			continue
+		}
+		state[name] = 1
+		cycle := visit(fragment.SelectionSet)
+		state[name] = 2
+		if cycle != "" {
+			return newError("BAD_REQUEST", fmt.Sprintf("cannot spread fragment %q within itself", cycle))
+		}
+	}
+	return nil
+}
+
+// selectionSet 選択セットの深さと複雑度（visitingは展開中のフラグメント）
+func (a *queryAnalyzer) selectionSet(set *ast.SelectionSet, visiting map[string]bool) (depth, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	for _, selection := range set.Selections {
+		var d, c int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			d, c = a.field(selection, visiting)
+		case *ast.InlineFragment:
+			d, c = a.selectionSet(selection.SelectionSet, visiting)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := a.fragments[name]
+			if !ok || visiting[name] {
+				continue
+			}
+			visiting[name] = true
+			d, c = a.selectionSet(fragment.SelectionSet, visiting)
+			delete(visiting, name)
+		}
+
+		depth = max(depth, d)
+		complexity += c
+	}
+	return depth, complexity
+}
+
+// field フィールド1つ分の深さと複雑度
+func (a *queryAnalyzer) field(field *ast.Field, visiting map[string]bool) (depth, complexity int) {
+	if strings.HasPrefix(field.Name.Value, "__") {
+		return 0, 0
+	}
+
+	childDepth, childComplexity := a.selectionSet(field.SelectionSet, visiting)
+	return childDepth + 1, 1 + childComplexity*a.listSize(field)
+}
+
+// listSize 一覧フィールドの取得件数（first の値、未指定なら既定の件数）
+func (a *queryAnalyzer) listSize(field *ast.Field) int {
+	size, isList := listFields[field.Name.Value]
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "first" {
+			continue
+		}
+		if n, ok := a.intValue(argument.Value); ok {
+			size, isList = n, true
+		}
+	}
+	if !isList || size < 1 {
+		return 1
+	}
+	return size
+}
+
+// intValue 整数リテラルまたは変数の値
+func (a *queryAnalyzer) intValue(value ast.Value) (int, bool) {
+	switch value := value.(type) {
+	case *ast.IntValue:
+		n, err := strconv.Atoi(value.Value)
+		return n, err == nil
+	case *ast.Variable:
+		switch n := a.variables[value.Name.Value].(type) {
+		case float64:
+			return int(n), true
+		case int:
+			return n, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,155 @@
+package graphqlserver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// parseOperation クエリを構文解析して最初の操作を返す（スキーマの検証はしない）
+func parseOperation(t *testing.T, query string) (*ast.Document, *ast.OperationDefinition) {
+	t.Helper()
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	return doc, findOperation(doc, "")
+}
+
+// limitCode 上限エラーのコード（上限内なら空文字列）
+func limitCode(t *testing.T, query string, variables map[string]interface{}, limits Limits) string {
+	t.Helper()
+	doc, operation := parseOperation(t, query)
+	err := checkLimits(doc, operation, variables, limits)
+	if err == nil {
+		return ""
+	}
+	var limitErr *Error
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("unexpected error type %T: %v", err, err)
+	}
+	return limitErr.Code
+}
+
+func TestCheckLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		limits    Limits
+		want      string
+	}{
+		{
+			name:   "depth within limit",
+			query:  `{ users { edges { node { id } } } }`,
+			limits: Limits{MaxDepth: 4},
+		},
+		{
+			name:   "too deep",
+			query:  `{ users { edges { node { id } } } }`,
+			limits: Limits{MaxDepth: 3},
+			want:   "QUERY_TOO_DEEP",
+		},
+		{
+			name:   "depth through fragments",
+			query:  `{ users { ...Conn } } fragment Conn on UserConnection { edges { ... on UserEdge { node { id } } } }`,
+			limits: Limits{MaxDepth: 3},
+			want:   "QUERY_TOO_DEEP",
+		},
+		{
+			name:   "introspection is not counted",
+			query:  `{ __schema { types { fields { type { name } } } } }`,
+			limits: Limits{MaxDepth: 1, MaxComplexity: 1},
+		},
+		{
+			// 1 + (nodes: 1 + id + name) * 20
+			name:   "default page size",
+			query:  `{ users { nodes { id name } } }`,
+			limits: Limits{MaxComplexity: 61},
+		},
+		{
+			name:   "default page size too complex",
+			query:  `{ users { nodes { id name } } }`,
+			limits: Limits{MaxComplexity: 60},
+			want:   "QUERY_TOO_COMPLEX",
+		},
+		{
+			name:   "literal first",
+			query:  `{ users(first: 100) { nodes { id name } } }`,
+			limits: Limits{MaxComplexity: 300},
+			want:   "QUERY_TOO_COMPLEX",
+		},
+		{
+			name:      "first from JSON variable",
+			query:     `query($n: Int) { users(first: $n) { nodes { id name } } }`,
+			variables: map[string]interface{}{"n": float64(100)},
+			limits:    Limits{MaxComplexity: 300},
+			want:      "QUERY_TOO_COMPLEX",
+		},
+		{
+			name:      "first from int variable",
+			query:     `query($n: Int) { users(first: $n) { nodes { id name } } }`,
+			variables: map[string]interface{}{"n": 2},
+			limits:    Limits{MaxComplexity: 7},
+		},
+		{
+			name:   "aliases add up",
+			query:  `{ a: users { nodes { id } } b: users { nodes { id } } }`,
+			limits: Limits{MaxComplexity: 81},
+			want:   "QUERY_TOO_COMPLEX",
+		},
+		{
+			name:   "no limits",
+			query:  `{ users(first: 100) { edges { node { id name email } } } }`,
+			limits: Limits{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := limitCode(t, tt.query, tt.variables, tt.limits); got != tt.want {
+				t.Errorf("code = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckLimitsFragmentCycle(t *testing.T) {
+	// スキーマの検証を通さずに直接数えても無限に展開しない
+	query := `
+		{ users { nodes { ...A } } }
+		fragment A on User { id ...B }
+		fragment B on User { name ...A }
+	`
+	if got := limitCode(t, query, nil, Limits{MaxDepth: 3, MaxComplexity: 100}); got != "" {
+		t.Errorf("code = %q, want none", got)
+	}
+	if got := limitCode(t, query, nil, Limits{MaxComplexity: 10}); got != "QUERY_TOO_COMPLEX" {
+		t.Errorf("code = %q, want QUERY_TOO_COMPLEX", got)
+	}
+}
+
+func TestCheckFragmentCycles(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{name: "no fragments", query: `{ me { id } }`},
+		{name: "shared fragment", query: `{ me { ...A ...B } } fragment A on User { ...C } fragment B on User { ...C } fragment C on User { id }`},
+		{name: "self spread", query: `{ me { ...A } } fragment A on User { ...A }`, wantErr: true},
+		{name: "mutual spread", query: `{ me { id } } fragment A on User { ...B } fragment B on User { ...A }`, wantErr: true},
+		{name: "through inline fragment", query: `{ me { ...A } } fragment A on User { ... on User { ...B } } fragment B on User { id ...A }`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, _ := parseOperation(t, tt.query)
+			if err := checkFragmentCycles(doc); (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package graphqlserver
+
+import (
+	"context"
+
+	"app-template/internal/entity"
+	"app-template/internal/usecase"
+	"app-template/pkg/dataloader"
+)
+
+// maxBatchSize 1回のバッチで読み込む最大件数（IN句の長さの上限）
+const maxBatchSize = 100
+
+// loaders リクエストごとのデータローダー
+type loaders struct {
+	// users GetByID をまとめて1回の WHERE id IN (...) で読み込む
+	users *dataloader.Loader[int64, *entity.User]
+}
+
+type loadersContextKey struct{}
+
+// newLoaders リクエスト用のデータローダーを作成
+func newLoaders(userUseCase usecase.UserUseCase) *loaders {
+	return &loaders{
+		users: dataloader.New(userUseCase.GetByIDs, maxBatchSize),
+	}
+}
+
+// withLoaders コンテキストにデータローダーを設定
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersContextKey{}, l)
+}
+
+// loadersFromContext コンテキストからデータローダーを取得
+func loadersFromContext(ctx context.Context) *loaders {
+	return ctx.Value(loadersContextKey{}).(*loaders)
+}
@@ -0,0 +1,365 @@
+package graphqlserver
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/graphql-go/graphql"
+
+	"app-template/internal/entity"
+	"app-template/internal/usecase"
+	"app-template/pkg/auth"
+	"app-template/pkg/dataloader"
+	"app-template/pkg/session"
+)
+
+const (
+	// defaultPageSize users の既定の取得件数
+	defaultPageSize = 20
+	// maxPageSize users の最大取得件数（ユースケースの上限と同じ）
+	maxPageSize = 100
+	// cursorPrefix カーソルに埋め込む位置の接頭辞
+	cursorPrefix = "offset:"
+)
+
+// resolver スキーマのフィールドを解決する（usecase.UserUseCase のアダプター）
+type resolver struct {
+	userUseCase usecase.UserUseCase
+	sessions    *session.Manager
+	validate    *validator.Validate
+}
+
+// ginContextKey セッションCookieを設定するリクエストのコンテキストキー
+type ginContextKey struct{}
+
+// withGinContext セッションCookieを設定するためにリクエストのgin.Contextをコンテキストに設定
+func withGinContext(ctx context.Context, c *gin.Context) context.Context {
+	return context.WithValue(ctx, ginContextKey{}, c)
+}
+
+// newResolver リゾルバーを作成
+func newResolver(userUseCase usecase.UserUseCase, sessions *session.Manager) *resolver {
+	// エンティティの validate タグで検証し、項目名はJSON（= 入力型のフィールド名）で返す
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	})
+
+	return &resolver{
+		userUseCase: userUseCase,
+		sessions:    sessions,
+		validate:    validate,
+	}
+}
+
+// me ログイン中のユーザー
+func (r *resolver) me(p graphql.ResolveParams) (interface{}, error) {
+	principal, ok := auth.PrincipalFromContext(p.Context)
+	if !ok {
+		return nil, newError("UNAUTHORIZED", "Authentication required")
+	}
+	return loadUser(p.Context, principal.UserID), nil
+}
+
+// user ユーザー詳細
+func (r *resolver) user(p graphql.ResolveParams) (interface{}, error) {
+	if err := authorize(p.Context, auth.PermUsersRead); err != nil {
+		return nil, err
+	}
+
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	return loadUser(p.Context, id), nil
+}
+
+// userConnection ユーザー一覧のコネクション
+type userConnection struct {
+	Edges      []*userEdge    `json:"edges"`
+	Nodes      []*entity.User `json:"nodes"`
+	PageInfo   *pageInfo      `json:"pageInfo"`
+	TotalCount int            `json:"totalCount"`
+}
+
+// userEdge コネクションの要素
+type userEdge struct {
+	Cursor string       `json:"cursor"`
+	Node   *entity.User `json:"node"`
+}
+
+// pageInfo コネクションのページ情報
+type pageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor"`
+	EndCursor       *string `json:"endCursor"`
+}
+
+// users ユーザー一覧（カーソルは一覧での位置）
+func (r *resolver) users(p graphql.ResolveParams) (interface{}, error) {
+	if err := authorize(p.Context, auth.PermUsersRead); err != nil {
+		return nil, err
+	}
+
+	first, _ := p.Args["first"].(int)
+	if first < 1 || first > maxPageSize {
+		return nil, newError("VALIDATION_ERROR", fmt.Sprintf("first must be between 1 and %d", maxPageSize))
+	}
+
+	offset := 0
+	if after, ok := p.Args["after"].(string); ok {
+		position, err := decodeCursor(after)
+		if err != nil {
+			return nil, err
+		}
+		offset = position + 1
+	}
+
+	users, total, err := r.listFrom(p.Context, offset, first)
+	if err != nil {
+		return nil, internalError("failed to list users", err)
+	}
+
+	connection := &userConnection{
+		Edges:      make([]*userEdge, 0, len(users)),
+		Nodes:      users,
+		TotalCount: total,
+		PageInfo: &pageInfo{
+			HasNextPage:     offset+len(users) < total,
+			HasPreviousPage: offset > 0,
+		},
+	}
+	for i, user := range users {
+		connection.Edges = append(connection.Edges, &userEdge{
+			Cursor: encodeCursor(offset + i),
+			Node:   user,
+		})
+	}
+	if len(connection.Edges) > 0 {
+		connection.PageInfo.StartCursor = &connection.Edges[0].Cursor
+		connection.PageInfo.EndCursor = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+
+	return connection, nil
+}
+
+// listFrom 位置offsetからlimit件を取得
+// ユースケースの一覧はページ単位のため、位置がページ境界にない場合は続くページも読んで切り出す
+func (r *resolver) listFrom(ctx context.Context, offset, limit int) ([]*entity.User, int, error) {
+	page := offset/limit + 1
+	skip := offset % limit
+
+	response, err := r.userUseCase.List(ctx, &entity.PaginationParams{Page: page, Limit: limit})
+	if err != nil {
+		return nil, 0, err
+	}
+	users := response.Users
+	total := response.Pagination.Total
+
+	if skip > 0 && len(users) == limit {
+		next, err := r.userUseCase.List(ctx, &entity.PaginationParams{Page: page + 1, Limit: limit})
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, next.Users...)
+	}
+
+	if skip >= len(users) {
+		return []*entity.User{}, total, nil
+	}
+	users = users[skip:]
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, total, nil
+}
+
+// register ユーザー登録
+func (r *resolver) register(p graphql.ResolveParams) (interface{}, error) {
+	input, _ := p.Args["input"].(map[string]interface{})
+	createReq := &entity.CreateUserRequest{
+		Email:    stringArg(input, "email"),
+		Name:     stringArg(input, "name"),
+		Password: stringArg(input, "password"),
+	}
+	if err := r.validate.Struct(createReq); err != nil {
+		return nil, validationError(err)
+	}
+
+	response, err := r.userUseCase.Register(p.Context, createReq)
+	if errors.Is(err, usecase.ErrRegistrationInviteOnly) {
+		return nil, newError("REGISTRATION_INVITE_ONLY", err.Error())
+	}
+	if err != nil {
+		return nil, newError("REGISTRATION_ERROR", err.Error())
+	}
+
+	return r.issueSession(p.Context, response)
+}
+
+// login ログイン
+func (r *resolver) login(p graphql.ResolveParams) (interface{}, error) {
+	input, _ := p.Args["input"].(map[string]interface{})
+	loginReq := &entity.LoginRequest{
+		Email:    stringArg(input, "email"),
+		Password: stringArg(input, "password"),
+	}
+	if err := r.validate.Struct(loginReq); err != nil {
+		return nil, validationError(err)
+	}
+
+	response, err := r.userUseCase.Login(p.Context, loginReq)
+	if auth.IsAccountBlocked(err) {
+		return nil, newError(auth.ErrorCode(err), err.Error())
+	}
+	if err != nil {
+		return nil, newError("LOGIN_ERROR", err.Error())
+	}
+
+	return r.issueSession(p.Context, response)
+}
+
+// issueSession Cookie認証モードならRESTと同じくアクセストークンの代わりにセッションCookieを設定し、CSRFトークンを入れる
+// トークンを含まないレスポンス（二要素認証のチャレンジなど）は変更しない
+func (r *resolver) issueSession(ctx context.Context, response *entity.AuthResponse) (*entity.AuthResponse, error) {
+	if r.sessions == nil || !r.sessions.CookieMode() || response.Token == "" || response.User == nil {
+		return response, nil
+	}
+	c, ok := ctx.Value(ginContextKey{}).(*gin.Context)
+	if !ok {
+		return nil, internalError("Failed to create session", errors.New("request context is not set"))
+	}
+
+	csrfToken, err := r.sessions.Login(c, response.User.ID)
+	if err != nil {
+		return nil, internalError("Failed to create session", err)
+	}
+	response.Token = ""
+	response.CSRFToken = csrfToken
+	return response, nil
+}
+
+// updateUser ユーザー更新
+func (r *resolver) updateUser(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeSelfOr(p.Context, id, auth.PermUsersWrite); err != nil {
+		return nil, err
+	}
+
+	input, _ := p.Args["input"].(map[string]interface{})
+	updateReq := &entity.UpdateUserRequest{
+		Email:       stringArg(input, "email"),
+		Name:        stringArg(input, "name"),
+		DisplayName: optionalStringArg(input, "displayName"),
+		Locale:      optionalStringArg(input, "locale"),
+		Timezone:    optionalStringArg(input, "timezone"),
+		Bio:         optionalStringArg(input, "bio"),
+	}
+	if err := r.validate.Struct(updateReq); err != nil {
+		return nil, validationError(err)
+	}
+
+	user, err := r.userUseCase.Update(p.Context, id, updateReq)
+	if err != nil {
+		switch err.Error() {
+		case "user not found", "email already exists":
+			return nil, newError("UPDATE_ERROR", err.Error())
+		default:
+			return nil, internalError("failed to update user", err)
+		}
+	}
+
+	return user, nil
+}
+
+// loadUser リクエスト内のローダーでユーザーを読み込むthunk（存在しなければnull）
+func loadUser(ctx context.Context, id int64) func() (interface{}, error) {
+	thunk := loadersFromContext(ctx).users.Load(ctx, id)
+	return func() (interface{}, error) {
+		user, err := thunk()
+		if errors.Is(err, dataloader.ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, internalError("failed to get user", err)
+		}
+		return user, nil
+	}
+}
+
+// authorize 認証済み主体が権限をすべて持つか確認（middleware.Require に相当）
+func authorize(ctx context.Context, permissions ...auth.Permission) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return newError("UNAUTHORIZED", "Authentication required")
+	}
+
+	for _, permission := range permissions {
+		if !principal.Has(permission) {
+			return newError("FORBIDDEN", "Missing permission: "+string(permission))
+		}
+	}
+	return nil
+}
+
+// authorizeSelfOr 本人以外が対象の場合は users:admin も必要とする（OthersRequire に相当）
+func authorizeSelfOr(ctx context.Context, id int64, permission auth.Permission) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if ok && principal.UserID != id {
+		return authorize(ctx, permission, auth.PermUsersAdmin)
+	}
+	return authorize(ctx, permission)
+}
+
+// parseID ID引数を数値に変換
+func parseID(value interface{}) (int64, error) {
+	s, _ := value.(string)
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, newError("INVALID_ID", "Invalid user ID")
+	}
+	return id, nil
+}
+
+// stringArg 入力オブジェクトの文字列項目（省略時は空）
+func stringArg(input map[string]interface{}, name string) string {
+	s, _ := input[name].(string)
+	return s
+}
+
+// optionalStringArg 入力の文字列（省略時はnil）
+func optionalStringArg(input map[string]interface{}, name string) *string {
+	s, ok := input[name].(string)
+	if !ok {
+		return nil
+	}
+	return &s
+}
+
+// encodeCursor 一覧での位置をカーソルに変換
+func encodeCursor(position int) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(position)))
+}
+
+// decodeCursor カーソルを一覧での位置に変換
+func decodeCursor(cursor string) (int, error) {
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err == nil && strings.HasPrefix(string(decoded), cursorPrefix) {
+		position, err := strconv.Atoi(strings.TrimPrefix(string(decoded), cursorPrefix))
+		if err == nil && position >= 0 {
+			return position, nil
+		}
+	}
+	return 0, newError("INVALID_CURSOR", "Invalid cursor")
+}
@@ -0,0 +1,247 @@
+package graphqlserver
+
+import (
+	"github.com/graphql-go/graphql"
+
+	"app-template/internal/entity"
+)
+
+// newSchema スキーマを作成（フィールド名はcamelCase、値はエンティティから解決する）
+func newSchema(r *resolver) (graphql.Schema, error) {
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id":    &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"email": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"name":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"displayName": userField(graphql.String, func(user *entity.User) interface{} {
+				return optionalString(user.DisplayName)
+			}),
+			"locale": userField(graphql.String, func(user *entity.User) interface{} {
+				return optionalString(user.Locale)
+			}),
+			"timezone": userField(graphql.String, func(user *entity.User) interface{} {
+				return optionalString(user.Timezone)
+			}),
+			"bio": userField(graphql.String, func(user *entity.User) interface{} {
+				return optionalString(user.Bio)
+			}),
+			"avatarUrl": userField(graphql.String, func(user *entity.User) interface{} {
+				return optionalString(user.AvatarURL)
+			}),
+			"role": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"status": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "active / suspended / disabled",
+			},
+			"statusReason": userField(graphql.String, func(user *entity.User) interface{} {
+				if user.StatusReason == "" {
+					return nil
+				}
+				return user.StatusReason
+			}),
+			"suspendedUntil": userField(graphql.DateTime, func(user *entity.User) interface{} {
+				return user.SuspendedUntil
+			}),
+			"createdAt": userField(graphql.NewNonNull(graphql.DateTime), func(user *entity.User) interface{} {
+				return user.CreatedAt
+			}),
+			"updatedAt": userField(graphql.NewNonNull(graphql.DateTime), func(user *entity.User) interface{} {
+				return user.UpdatedAt
+			}),
+		},
+	})
+
+	authPayloadType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "AuthPayload",
+		Description: "MFAが必要な場合は token の代わりに challengeToken を返す。Cookie認証モードでは token の代わりにセッションCookieを設定して csrfToken を返す",
+		Fields: graphql.Fields{
+			"user": &graphql.Field{Type: userType},
+			"token": authField(graphql.String, func(response *entity.AuthResponse) interface{} {
+				if response.Token == "" {
+					return nil
+				}
+				return response.Token
+			}),
+			"csrfToken": authField(graphql.String, func(response *entity.AuthResponse) interface{} {
+				if response.CSRFToken == "" {
+					return nil
+				}
+				return response.CSRFToken
+			}),
+			"mfaRequired": authField(graphql.NewNonNull(graphql.Boolean), func(response *entity.AuthResponse) interface{} {
+				return response.MFARequired
+			}),
+			"mfaMethods": authField(graphql.NewList(graphql.NewNonNull(graphql.String)), func(response *entity.AuthResponse) interface{} {
+				return response.MFAMethods
+			}),
+			"challengeToken": authField(graphql.String, func(response *entity.AuthResponse) interface{} {
+				if response.ChallengeToken == "" {
+					return nil
+				}
+				return response.ChallengeToken
+			}),
+		},
+	})
+
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage":     &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"hasPreviousPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"startCursor":     &graphql.Field{Type: graphql.String},
+			"endCursor":       &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	userEdgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "UserEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"node":   &graphql.Field{Type: graphql.NewNonNull(userType)},
+		},
+	})
+
+	userConnectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "UserConnection",
+		Fields: graphql.Fields{
+			"edges":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(userEdgeType)))},
+			"nodes":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(userType)))},
+			"pageInfo":   &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+			"totalCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	registerInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "RegisterInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"email":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"name":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"password": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	loginInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "LoginInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"email":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"password": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	updateUserInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "UpdateUserInput",
+		Description: "省略した項目は変更しない（プロフィールの項目は空文字列で未設定に戻す）",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"email":       &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"name":        &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"displayName": &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"locale":      &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "BCP 47 の言語タグ（例: ja-JP）"},
+			"timezone":    &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "IANA タイムゾーン名（例: Asia/Tokyo）"},
+			"bio":         &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"me": &graphql.Field{
+				Type:        userType,
+				Description: "ログイン中のユーザー",
+				Resolve:     r.me,
+			},
+			"user": &graphql.Field{
+				Type:        userType,
+				Description: "ユーザー詳細（users:read が必要、存在しなければnull）",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: r.user,
+			},
+			"users": &graphql.Field{
+				Type:        graphql.NewNonNull(userConnectionType),
+				Description: "ユーザー一覧（users:read が必要）",
+				Args: graphql.FieldConfigArgument{
+					"first": &graphql.ArgumentConfig{
+						Type:         graphql.Int,
+						DefaultValue: defaultPageSize,
+						Description:  "取得件数（1〜100）",
+					},
+					"after": &graphql.ArgumentConfig{
+						Type:        graphql.String,
+						Description: "このカーソルより後を取得",
+					},
+				},
+				Resolve: r.users,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"register": &graphql.Field{
+				Type: graphql.NewNonNull(authPayloadType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(registerInput)},
+				},
+				Resolve: r.register,
+			},
+			"login": &graphql.Field{
+				Type: graphql.NewNonNull(authPayloadType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(loginInput)},
+				},
+				Resolve: r.login,
+			},
+			"updateUser": &graphql.Field{
+				Type:        graphql.NewNonNull(userType),
+				Description: "ユーザー更新（本人以外は users:admin も必要）",
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(updateUserInput)},
+				},
+				Resolve: r.updateUser,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
+
+// optionalString 空文字列をnullとして返す
+func optionalString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// userField ユーザーの値から解決するフィールド
+func userField(typ graphql.Output, value func(*entity.User) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: typ,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			if user, ok := p.Source.(*entity.User); ok {
+				return value(user), nil
+			}
+			return nil, nil
+		},
+	}
+}
+
+// authField 認証レスポンスの値から解決するフィールド
+func authField(typ graphql.Output, value func(*entity.AuthResponse) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: typ,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			if response, ok := p.Source.(*entity.AuthResponse); ok {
+				return value(response), nil
+			}
+			return nil, nil
+		},
+	}
+}
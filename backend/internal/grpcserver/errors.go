@@ -0,0 +1,85 @@
+package grpcserver
+
+import (
+	"errors"
+	"log"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"app-template/pkg/auth"
+	"app-template/pkg/tenant"
+)
+
+// errorDomain ErrorInfo のドメイン
+const errorDomain = "app-template"
+
+// errorStatus エラーコード（RESTの code と同じ値）を ErrorInfo に含めたステータス
+func errorStatus(code codes.Code, reason, message string) error {
+	st := status.New(code, message)
+	if detailed, err := st.WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: errorDomain}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// authErrorStatus 認証エラーをステータスに変換（abortAuthError に相当）
+func authErrorStatus(err error) error {
+	switch {
+	case auth.IsAccountBlocked(err):
+		return errorStatus(codes.PermissionDenied, auth.ErrorCode(err), err.Error())
+	case auth.IsTokenError(err):
+		return errorStatus(codes.Unauthenticated, auth.ErrorCode(err), err.Error())
+	default:
+		return internalError("failed to authenticate request", err)
+	}
+}
+
+// tenantErrorStatus テナント解決のエラーをステータスに変換（abortTenantError に相当）
+func tenantErrorStatus(err error) error {
+	switch {
+	case errors.Is(err, tenant.ErrNotFound):
+		return errorStatus(codes.NotFound, "ORGANIZATION_NOT_FOUND", err.Error())
+	case errors.Is(err, tenant.ErrNotMember):
+		return errorStatus(codes.PermissionDenied, "NOT_ORGANIZATION_MEMBER", err.Error())
+	case errors.Is(err, tenant.ErrMismatch):
+		return errorStatus(codes.PermissionDenied, "TENANT_MISMATCH", err.Error())
+	case errors.Is(err, tenant.ErrRequired):
+		return errorStatus(codes.InvalidArgument, "TENANT_REQUIRED", err.Error())
+	default:
+		return internalError("failed to resolve organization", err)
+	}
+}
+
+// internalError 内部エラー（詳細はログにのみ出力）
+func internalError(message string, err error) error {
+	log.Printf("grpc: %s: %v", message, err)
+	return errorStatus(codes.Internal, "INTERNAL_ERROR", message)
+}
+
+// validationError 入力検証エラーを項目ごとの違反を含むステータスに変換
+func validationError(err error) error {
+	st := status.New(codes.InvalidArgument, "invalid request")
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(validationErrors))
+		for _, fe := range validationErrors {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       fe.Field(),
+				Description: "failed on '" + fe.Tag() + "' validation",
+			})
+		}
+		if detailed, err := st.WithDetails(
+			&errdetails.ErrorInfo{Reason: "VALIDATION_ERROR", Domain: errorDomain},
+			&errdetails.BadRequest{FieldViolations: violations},
+		); err == nil {
+			st = detailed
+		}
+		return st.Err()
+	}
+
+	return errorStatus(codes.InvalidArgument, "VALIDATION_ERROR", err.Error())
+}
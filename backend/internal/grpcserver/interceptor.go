@@ -0,0 +1,172 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"app-template/pkg/auth"
+	"app-template/pkg/requestinfo"
+	"app-template/pkg/tenant"
+	userv1 "app-template/proto/user/v1"
+)
+
+// requestIDMetadata リクエストIDのメタデータキー（HTTPの X-Request-ID に相当）
+const requestIDMetadata = "x-request-id"
+
+// tenantMetadata テナントを指定するメタデータキー（HTTPの X-Organization に相当）
+const tenantMetadata = "x-organization"
+
+// publicMethods 認証不要のRPC
+var publicMethods = map[string]bool{
+	userv1.UserService_Register_FullMethodName: true,
+	userv1.UserService_Login_FullMethodName:    true,
+}
+
+// publicServices 認証不要のサービス（ヘルスチェック・リフレクション）
+var publicServices = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.",
+}
+
+// isPublic 認証不要のRPCか
+func isPublic(fullMethod string) bool {
+	if publicMethods[fullMethod] {
+		return true
+	}
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// withRequestInfo リクエストID・IP・User-Agentをコンテキストに設定（middleware.RequestInfo に相当）
+func withRequestInfo(ctx context.Context) (context.Context, string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	requestID := firstValue(md, requestIDMetadata)
+	if !requestinfo.ValidID(requestID) {
+		requestID = requestinfo.NewID()
+	}
+
+	info := requestinfo.Info{
+		RequestID: requestID,
+		UserAgent: firstValue(md, "user-agent"),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		info.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(info.IP); err == nil {
+			info.IP = host
+		}
+	}
+
+	return requestinfo.WithInfo(ctx, info), requestID
+}
+
+func unaryRequestInfo(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, requestID := withRequestInfo(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, requestID))
+	return handler(ctx, req)
+}
+
+func streamRequestInfo(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, requestID := withRequestInfo(ss.Context())
+	ss.SetHeader(metadata.Pairs(requestIDMetadata, requestID))
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+// authInterceptor authorization メタデータのBearer資格情報を検証するインターセプター
+type authInterceptor struct {
+	accounts      auth.AccountChecker
+	authenticator auth.Authenticator
+	tenants       tenant.Resolver
+	tenantOptions tenant.Options
+}
+
+func (a *authInterceptor) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if isPublic(info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	ctx, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authInterceptor) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if isPublic(info.FullMethod) {
+		return handler(srv, ss)
+	}
+
+	ctx, err := a.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticate 資格情報を検証し、認証済み主体をコンテキストに設定
+// 認証後はアカウントの現在の状態を確認し、停止中なら拒否、ロールは最新の値に置き換える。
+// テナント（org_id クレーム・x-organization メタデータ・:authority のサブドメイン）も middleware.Tenant と同じ規則で解決する
+func (a *authInterceptor) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	authorization := firstValue(md, "authorization")
+	if authorization == "" {
+		return nil, errorStatus(codes.Unauthenticated, "MISSING_AUTH_HEADER", "authorization metadata required")
+	}
+
+	credential := strings.TrimPrefix(authorization, "Bearer ")
+	if credential == authorization {
+		return nil, errorStatus(codes.Unauthenticated, "INVALID_AUTH_FORMAT", "Bearer token required")
+	}
+
+	principal, err := a.authenticator.Authenticate(ctx, credential)
+	if err == nil {
+		var state *auth.AccountState
+		state, err = a.accounts.CheckAccount(ctx, principal.UserID)
+		if err == nil {
+			principal.Role = state.Role
+		}
+	}
+	if err != nil {
+		return nil, authErrorStatus(err)
+	}
+
+	t, err := tenant.Resolve(ctx, a.tenants, a.tenantOptions, principal, firstValue(md, tenantMetadata), firstValue(md, ":authority"))
+	if err != nil {
+		return nil, tenantErrorStatus(err)
+	}
+
+	ctx = auth.WithPrincipal(ctx, principal)
+	if t != nil {
+		ctx = tenant.WithTenant(ctx, t)
+	}
+	return ctx, nil
+}
+
+// contextStream コンテキストを差し替えたServerStream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// firstValue メタデータの最初の値
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+// Package grpcserver ユースケースをgRPCで公開するサーバー
+// RESTのコントローラーと同じユースケースを使い、認証・認可もRESTと同じ規則で行う
+package grpcserver
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	"app-template/internal/usecase"
+	"app-template/pkg/auth"
+	"app-template/pkg/session"
+	"app-template/pkg/tenant"
+	userv1 "app-template/proto/user/v1"
+)
+
+// Server gRPCサーバー
+type Server struct {
+	*grpc.Server
+	health *health.Server
+}
+
+// NewServer ユーザーサービス・ヘルスチェック・リフレクションを登録したgRPCサーバーを作成
+// 認証は middleware.Authenticate と同じく、認証器チェーンで検証した後にアカウントの状態を確認し、
+// middleware.Tenant と同じくテナントを解決する
+// gRPCはCookieを扱わないため、Cookie認証モードの sessions を渡すと Register・Login でトークンを発行しない
+func NewServer(users usecase.UserUseCase, sessions *session.Manager, accounts auth.AccountChecker, tenants tenant.Resolver, tenantOptions tenant.Options, authenticators ...auth.Authenticator) *Server {
+	authInterceptor := &authInterceptor{
+		accounts:      accounts,
+		authenticator: auth.Chain(authenticators),
+		tenants:       tenants,
+		tenantOptions: tenantOptions,
+	}
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryRequestInfo, authInterceptor.unary),
+		grpc.ChainStreamInterceptor(streamRequestInfo, authInterceptor.stream),
+	)
+
+	userv1.RegisterUserServiceServer(server, NewUserServer(users, sessions))
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	healthServer.SetServingStatus(userv1.UserService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+
+	reflection.Register(server)
+
+	return &Server{Server: server, health: healthServer}
+}
+
+// GracefulStop ヘルスチェックを停止中にしてから処理中のRPCの完了を待って停止
+func (s *Server) GracefulStop() {
+	s.health.Shutdown()
+	s.Server.GracefulStop()
+}
@@ -0,0 +1,333 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"app-template/internal/entity"
+	"app-template/internal/usecase"
+	"app-template/pkg/auth"
+	"app-template/pkg/tenant"
+	userv1 "app-template/proto/user/v1"
+)
+
+// テストで使うユーザー
+const (
+	aliceID     int64 = 1 // 組織 acme だけに所属
+	adminID     int64 = 2 // システム管理者
+	carolID     int64 = 3 // 組織 acme と globex に所属
+	suspendedID int64 = 4 // 停止中
+	demotedID   int64 = 5 // 管理者のトークンを持つが、現在は一般ユーザー
+)
+
+func (f *fakeUserUseCase) GetByID(ctx context.Context, id int64) (*entity.User, error) {
+	t, _ := tenant.FromContext(ctx)
+	f.tenants = append(f.tenants, t)
+	if user, ok := f.users[id]; ok {
+		return user, nil
+	}
+	return nil, errors.New("user not found")
+}
+
+func (f *fakeUserUseCase) Update(_ context.Context, id int64, req *entity.UpdateUserRequest) (*entity.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+	updated := *user
+	updated.Name = req.Name
+	return &updated, nil
+}
+
+func (f *fakeUserUseCase) Delete(_ context.Context, id int64) error {
+	switch id {
+	case aliceID:
+		return usecase.ErrOrganizationLastOwner
+	case carolID:
+		return errors.New("database is gone")
+	}
+	if _, ok := f.users[id]; !ok {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// fakeAccounts ユーザーごとの現在のロールと停止を返す
+type fakeAccounts struct{}
+
+func (fakeAccounts) CheckAccount(_ context.Context, userID int64) (*auth.AccountState, error) {
+	switch userID {
+	case suspendedID:
+		return nil, auth.ErrAccountSuspended
+	case adminID:
+		return &auth.AccountState{Role: auth.RoleAdmin, Status: entity.UserStatusActive}, nil
+	default:
+		return &auth.AccountState{Role: auth.RoleUser, Status: entity.UserStatusActive}, nil
+	}
+}
+
+// fakeTenants acme（ID 1）と globex（ID 2）の2つの組織
+type fakeTenants struct{}
+
+var organizations = map[string]*tenant.Tenant{
+	"acme":   {ID: 1, Slug: "acme", Role: entity.OrgRoleMember},
+	"globex": {ID: 2, Slug: "globex", Role: entity.OrgRoleMember},
+}
+
+// memberships ユーザーが所属する組織
+var memberships = map[int64][]string{
+	aliceID:   {"acme"},
+	carolID:   {"acme", "globex"},
+	demotedID: {"acme"},
+}
+
+func (fakeTenants) Resolve(_ context.Context, principal *auth.Principal, ref string) (*tenant.Tenant, error) {
+	t, ok := organizations[ref]
+	if !ok {
+		return nil, tenant.ErrNotFound
+	}
+	for _, slug := range memberships[principal.UserID] {
+		if slug == ref {
+			return t, nil
+		}
+	}
+	return nil, tenant.ErrNotMember
+}
+
+func (fakeTenants) Default(_ context.Context, principal *auth.Principal) (*tenant.Tenant, error) {
+	switch slugs := memberships[principal.UserID]; len(slugs) {
+	case 0:
+		return tenant.Personal(principal.UserID), nil
+	case 1:
+		return organizations[slugs[0]], nil
+	default:
+		return nil, nil
+	}
+}
+
+// testServer bufconn で起動したgRPCサーバーとクライアント
+type testServer struct {
+	users  *fakeUserUseCase
+	tokens *auth.JWTManager
+	client userv1.UserServiceClient
+	health healthpb.HealthClient
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	tokens := auth.NewJWTManager(auth.JWTConfig{Secret: "secret", Issuer: "test", Audience: "test", TTL: time.Hour})
+	users := newFakeUserUseCase()
+	users.users[carolID] = &entity.User{ID: carolID, Email: "carol@example.com", Name: "Carol", Role: auth.RoleUser}
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer(users, nil, fakeAccounts{}, fakeTenants{}, tenant.Options{}, tokens)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return &testServer{
+		users:  users,
+		tokens: tokens,
+		client: userv1.NewUserServiceClient(conn),
+		health: healthpb.NewHealthClient(conn),
+	}
+}
+
+// token ユーザーのアクセストークン
+func (s *testServer) token(t *testing.T, userID int64, role string) string {
+	t.Helper()
+	token, _, err := s.tokens.Generate(userID, role)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// outgoing メタデータ（キーと値の組）を付けたコンテキスト
+func outgoing(pairs ...string) context.Context {
+	return metadata.NewOutgoingContext(context.Background(), metadata.Pairs(pairs...))
+}
+
+// checkStatus gRPCのステータスコードと ErrorInfo の reason を確認
+func checkStatus(t *testing.T, err error, code codes.Code, reason string) {
+	t.Helper()
+	if got := status.Code(err); got != code {
+		t.Fatalf("code = %v, want %v (%v)", got, code, err)
+	}
+	if got := errorReason(err); got != reason {
+		t.Errorf("reason = %q, want %q", got, reason)
+	}
+}
+
+func TestServerAuthentication(t *testing.T) {
+	s := newTestServer(t)
+	alice := "Bearer " + s.token(t, aliceID, auth.RoleUser)
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		wantCode   codes.Code
+		wantReason string
+	}{
+		{name: "no credentials", ctx: context.Background(), wantCode: codes.Unauthenticated, wantReason: "MISSING_AUTH_HEADER"},
+		{name: "not a bearer credential", ctx: outgoing("authorization", "Basic YWxpY2U6c2VjcmV0"), wantCode: codes.Unauthenticated, wantReason: "INVALID_AUTH_FORMAT"},
+		{name: "malformed token", ctx: outgoing("authorization", "Bearer not-a-jwt"), wantCode: codes.Unauthenticated, wantReason: "MALFORMED_TOKEN"},
+		{name: "suspended account", ctx: outgoing("authorization", "Bearer "+s.token(t, suspendedID, auth.RoleUser)), wantCode: codes.PermissionDenied, wantReason: "ACCOUNT_SUSPENDED"},
+		{name: "valid token", ctx: outgoing("authorization", alice), wantCode: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.client.GetUser(tt.ctx, &userv1.GetUserRequest{Id: aliceID})
+			if tt.wantCode == codes.OK {
+				if err != nil {
+					t.Fatalf("GetUser: %v", err)
+				}
+				return
+			}
+			checkStatus(t, err, tt.wantCode, tt.wantReason)
+		})
+	}
+
+	// 登録・ログインとヘルスチェックは認証不要
+	if _, err := s.client.Login(context.Background(), &userv1.LoginRequest{Email: "alice@example.com", Password: "password123"}); err != nil {
+		t.Errorf("Login without credentials: %v", err)
+	}
+	health, err := s.health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: userv1.UserService_ServiceDesc.ServiceName})
+	if err != nil || health.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("health = %v, %v", health, err)
+	}
+}
+
+func TestServerPermissions(t *testing.T) {
+	s := newTestServer(t)
+	alice := outgoing("authorization", "Bearer "+s.token(t, aliceID, auth.RoleUser))
+	admin := outgoing("authorization", "Bearer "+s.token(t, adminID, auth.RoleAdmin))
+	// トークンのロールではなくアカウントの現在のロールで認可する
+	demoted := outgoing("authorization", "Bearer "+s.token(t, demotedID, auth.RoleAdmin))
+
+	if _, err := s.client.UpdateUser(alice, &userv1.UpdateUserRequest{Id: aliceID, Email: "alice@example.com", Name: "Alice A."}); err != nil {
+		t.Errorf("update self: %v", err)
+	}
+	_, err := s.client.UpdateUser(alice, &userv1.UpdateUserRequest{Id: adminID, Email: "admin@example.com", Name: "Admin"})
+	checkStatus(t, err, codes.PermissionDenied, "FORBIDDEN")
+	if _, err := s.client.UpdateUser(admin, &userv1.UpdateUserRequest{Id: aliceID, Email: "alice@example.com", Name: "Alice"}); err != nil {
+		t.Errorf("admin updating another user: %v", err)
+	}
+	_, err = s.client.DeleteUser(demoted, &userv1.DeleteUserRequest{Id: adminID})
+	checkStatus(t, err, codes.PermissionDenied, "FORBIDDEN")
+}
+
+func TestServerTenantResolution(t *testing.T) {
+	s := newTestServer(t)
+	alice := "Bearer " + s.token(t, aliceID, auth.RoleUser)
+	carol := "Bearer " + s.token(t, carolID, auth.RoleUser)
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		wantCode   codes.Code
+		wantReason string
+		wantTenant string
+	}{
+		{name: "single organization by default", ctx: outgoing("authorization", alice), wantTenant: "acme"},
+		{name: "several organizations need a choice", ctx: outgoing("authorization", carol), wantCode: codes.InvalidArgument, wantReason: "TENANT_REQUIRED"},
+		{name: "chosen by metadata", ctx: outgoing("authorization", carol, "x-organization", "globex"), wantTenant: "globex"},
+		{name: "not a member", ctx: outgoing("authorization", alice, "x-organization", "globex"), wantCode: codes.PermissionDenied, wantReason: "NOT_ORGANIZATION_MEMBER"},
+		{name: "unknown organization", ctx: outgoing("authorization", alice, "x-organization", "initech"), wantCode: codes.NotFound, wantReason: "ORGANIZATION_NOT_FOUND"},
+		{name: "system admin spans organizations", ctx: outgoing("authorization", "Bearer "+s.token(t, adminID, auth.RoleAdmin))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.users.tenants = nil
+			_, err := s.client.GetUser(tt.ctx, &userv1.GetUserRequest{Id: aliceID})
+			if tt.wantCode != codes.OK {
+				checkStatus(t, err, tt.wantCode, tt.wantReason)
+				if len(s.users.tenants) != 0 {
+					t.Error("use case was called without a tenant")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetUser: %v", err)
+			}
+			if len(s.users.tenants) != 1 {
+				t.Fatalf("use case called %d times", len(s.users.tenants))
+			}
+			got := ""
+			if tn := s.users.tenants[0]; tn != nil {
+				got = tn.Slug
+			}
+			if got != tt.wantTenant {
+				t.Errorf("tenant = %q, want %q", got, tt.wantTenant)
+			}
+		})
+	}
+}
+
+func TestServerErrorTranslation(t *testing.T) {
+	s := newTestServer(t)
+	admin := outgoing("authorization", "Bearer "+s.token(t, adminID, auth.RoleAdmin))
+
+	_, err := s.client.GetUser(admin, &userv1.GetUserRequest{Id: 99})
+	checkStatus(t, err, codes.NotFound, "USER_NOT_FOUND")
+
+	_, err = s.client.DeleteUser(admin, &userv1.DeleteUserRequest{Id: 99})
+	checkStatus(t, err, codes.NotFound, "DELETE_ERROR")
+	_, err = s.client.DeleteUser(admin, &userv1.DeleteUserRequest{Id: aliceID})
+	checkStatus(t, err, codes.FailedPrecondition, "DELETE_ERROR")
+	// 内部エラーの詳細はクライアントに返さない
+	_, err = s.client.DeleteUser(admin, &userv1.DeleteUserRequest{Id: carolID})
+	checkStatus(t, err, codes.Internal, "INTERNAL_ERROR")
+	if msg := status.Convert(err).Message(); msg != "failed to delete user" {
+		t.Errorf("internal error message = %q", msg)
+	}
+
+	_, err = s.client.UpdateUser(admin, &userv1.UpdateUserRequest{Id: aliceID, Email: "not-an-email", Name: "Alice"})
+	checkStatus(t, err, codes.InvalidArgument, "VALIDATION_ERROR")
+	var fields []string
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				fields = append(fields, violation.GetField())
+			}
+		}
+	}
+	if len(fields) != 1 || fields[0] != "email" {
+		t.Errorf("field violations = %v, want [email]", fields)
+	}
+}
+
+func TestServerReturnsRequestID(t *testing.T) {
+	s := newTestServer(t)
+	ctx := outgoing("authorization", "Bearer "+s.token(t, aliceID, auth.RoleUser), "x-request-id", "req-123")
+
+	var header metadata.MD
+	if _, err := s.client.GetUser(ctx, &userv1.GetUserRequest{Id: aliceID}, grpc.Header(&header)); err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if got := header.Get("x-request-id"); len(got) != 1 || got[0] != "req-123" {
+		t.Errorf("x-request-id = %v", got)
+	}
+}
@@ -0,0 +1,257 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"app-template/internal/entity"
+	"app-template/internal/usecase"
+	"app-template/pkg/auth"
+	"app-template/pkg/session"
+	userv1 "app-template/proto/user/v1"
+)
+
+// UserServer ユーザーサービス（usecase.UserUseCase のアダプター）
+type UserServer struct {
+	userv1.UnimplementedUserServiceServer
+	userUseCase usecase.UserUseCase
+	sessions    *session.Manager
+	validate    *validator.Validate
+}
+
+var _ userv1.UserServiceServer = (*UserServer)(nil)
+
+// NewUserServer ユーザーサービスの新しいインスタンスを作成
+func NewUserServer(userUseCase usecase.UserUseCase, sessions *session.Manager) *UserServer {
+	// エンティティの validate タグで検証し、項目名はJSON（= protoのフィールド名）で返す
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	})
+
+	return &UserServer{
+		userUseCase: userUseCase,
+		sessions:    sessions,
+		validate:    validate,
+	}
+}
+
+// Register ユーザー登録
+func (s *UserServer) Register(ctx context.Context, req *userv1.RegisterRequest) (*userv1.AuthResponse, error) {
+	if err := s.checkTokenLogin(); err != nil {
+		return nil, err
+	}
+
+	createReq := &entity.CreateUserRequest{
+		Email:    req.GetEmail(),
+		Name:     req.GetName(),
+		Password: req.GetPassword(),
+	}
+	if err := s.validate.Struct(createReq); err != nil {
+		return nil, validationError(err)
+	}
+
+	response, err := s.userUseCase.Register(ctx, createReq)
+	if err != nil {
+		if err.Error() == "email already exists" {
+			return nil, errorStatus(codes.AlreadyExists, "REGISTRATION_ERROR", err.Error())
+		}
+		if errors.Is(err, usecase.ErrRegistrationInviteOnly) {
+			return nil, errorStatus(codes.PermissionDenied, "REGISTRATION_INVITE_ONLY", err.Error())
+		}
+		return nil, errorStatus(codes.InvalidArgument, "REGISTRATION_ERROR", err.Error())
+	}
+
+	return toAuthResponse(response), nil
+}
+
+// Login ログイン
+func (s *UserServer) Login(ctx context.Context, req *userv1.LoginRequest) (*userv1.AuthResponse, error) {
+	if err := s.checkTokenLogin(); err != nil {
+		return nil, err
+	}
+
+	loginReq := &entity.LoginRequest{
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	}
+	if err := s.validate.Struct(loginReq); err != nil {
+		return nil, validationError(err)
+	}
+
+	response, err := s.userUseCase.Login(ctx, loginReq)
+	if auth.IsAccountBlocked(err) {
+		return nil, errorStatus(codes.PermissionDenied, auth.ErrorCode(err), err.Error())
+	}
+	if err != nil {
+		return nil, errorStatus(codes.Unauthenticated, "LOGIN_ERROR", err.Error())
+	}
+
+	return toAuthResponse(response), nil
+}
+
+// checkTokenLogin Cookie認証モードではアクセストークンを返さないため、トークンを発行するRPCを拒否する
+// （Cookieを扱えないgRPCのクライアントはAPIキーで認証する）
+func (s *UserServer) checkTokenLogin() error {
+	if s.sessions != nil && s.sessions.CookieMode() {
+		return errorStatus(codes.FailedPrecondition, "TOKEN_LOGIN_DISABLED", "token login is disabled in cookie auth mode; use an API key")
+	}
+	return nil
+}
+
+// GetUser ユーザー詳細取得
+func (s *UserServer) GetUser(ctx context.Context, req *userv1.GetUserRequest) (*userv1.User, error) {
+	if err := authorize(ctx, auth.PermUsersRead); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userUseCase.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, errorStatus(codes.NotFound, "USER_NOT_FOUND", err.Error())
+	}
+
+	return toUser(user), nil
+}
+
+// UpdateUser ユーザー更新
+func (s *UserServer) UpdateUser(ctx context.Context, req *userv1.UpdateUserRequest) (*userv1.User, error) {
+	if err := authorizeSelfOr(ctx, req.GetId(), auth.PermUsersWrite); err != nil {
+		return nil, err
+	}
+
+	updateReq := &entity.UpdateUserRequest{
+		Email: req.GetEmail(),
+		Name:  req.GetName(),
+	}
+	if err := s.validate.Struct(updateReq); err != nil {
+		return nil, validationError(err)
+	}
+
+	user, err := s.userUseCase.Update(ctx, req.GetId(), updateReq)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			return nil, errorStatus(codes.NotFound, "UPDATE_ERROR", err.Error())
+		case "email already exists":
+			return nil, errorStatus(codes.AlreadyExists, "UPDATE_ERROR", err.Error())
+		default:
+			return nil, internalError("failed to update user", err)
+		}
+	}
+
+	return toUser(user), nil
+}
+
+// DeleteUser ユーザー削除
+func (s *UserServer) DeleteUser(ctx context.Context, req *userv1.DeleteUserRequest) (*emptypb.Empty, error) {
+	if err := authorizeSelfOr(ctx, req.GetId(), auth.PermUsersWrite); err != nil {
+		return nil, err
+	}
+
+	if err := s.userUseCase.Delete(ctx, req.GetId()); err != nil {
+		if err.Error() == "user not found" {
+			return nil, errorStatus(codes.NotFound, "DELETE_ERROR", err.Error())
+		}
+		if errors.Is(err, usecase.ErrOrganizationLastOwner) {
+			return nil, errorStatus(codes.FailedPrecondition, "DELETE_ERROR", err.Error())
+		}
+		return nil, internalError("failed to delete user", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// ListUsers ユーザー一覧取得
+func (s *UserServer) ListUsers(ctx context.Context, req *userv1.ListUsersRequest) (*userv1.ListUsersResponse, error) {
+	if err := authorize(ctx, auth.PermUsersRead); err != nil {
+		return nil, err
+	}
+
+	// 0 はユースケースで既定値になる
+	response, err := s.userUseCase.List(ctx, &entity.PaginationParams{
+		Page:  int(req.GetPage()),
+		Limit: int(req.GetLimit()),
+	})
+	if err != nil {
+		return nil, internalError("failed to list users", err)
+	}
+
+	users := make([]*userv1.User, 0, len(response.Users))
+	for _, user := range response.Users {
+		users = append(users, toUser(user))
+	}
+
+	return &userv1.ListUsersResponse{
+		Users: users,
+		Pagination: &userv1.Pagination{
+			Page:       int32(response.Pagination.Page),
+			Limit:      int32(response.Pagination.Limit),
+			Total:      int32(response.Pagination.Total),
+			TotalPages: int32(response.Pagination.TotalPages),
+		},
+	}, nil
+}
+
+// authorize 認証済み主体が権限をすべて持つか確認（middleware.Require に相当）
+func authorize(ctx context.Context, permissions ...auth.Permission) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return errorStatus(codes.Unauthenticated, "UNAUTHORIZED", "Authentication required")
+	}
+
+	for _, permission := range permissions {
+		if !principal.Has(permission) {
+			return errorStatus(codes.PermissionDenied, "FORBIDDEN", "Missing permission: "+string(permission))
+		}
+	}
+	return nil
+}
+
+// authorizeSelfOr 本人以外が対象の場合は users:admin も必要とする（OthersRequire に相当）
+func authorizeSelfOr(ctx context.Context, id int64, permission auth.Permission) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if ok && principal.UserID != id {
+		return authorize(ctx, permission, auth.PermUsersAdmin)
+	}
+	return authorize(ctx, permission)
+}
+
+// toUser エンティティをメッセージに変換
+func toUser(user *entity.User) *userv1.User {
+	if user == nil {
+		return nil
+	}
+
+	message := &userv1.User{
+		Id:           user.ID,
+		Email:        user.Email,
+		Name:         user.Name,
+		Role:         user.Role,
+		Status:       user.Status,
+		StatusReason: user.StatusReason,
+		CreatedAt:    timestamppb.New(user.CreatedAt),
+		UpdatedAt:    timestamppb.New(user.UpdatedAt),
+	}
+	if user.SuspendedUntil != nil {
+		message.SuspendedUntil = timestamppb.New(*user.SuspendedUntil)
+	}
+	return message
+}
+
+// toAuthResponse 認証レスポンスをメッセージに変換
+func toAuthResponse(response *entity.AuthResponse) *userv1.AuthResponse {
+	return &userv1.AuthResponse{
+		User:           toUser(response.User),
+		Token:          response.Token,
+		MfaRequired:    response.MFARequired,
+		MfaMethods:     response.MFAMethods,
+		ChallengeToken: response.ChallengeToken,
+	}
+}
@@ -0,0 +1,89 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"app-template/internal/entity"
+	"app-template/internal/usecase"
+	"app-template/pkg/auth"
+	"app-template/pkg/kvstore"
+	"app-template/pkg/session"
+	"app-template/pkg/tenant"
+	userv1 "app-template/proto/user/v1"
+)
+
+// fakeUserUseCase メモリ上のユーザーで動くユースケース（使わないメソッドは未実装）
+type fakeUserUseCase struct {
+	usecase.UserUseCase
+
+	users  map[int64]*entity.User
+	logins int
+	// tenants GetByID が受け取ったテナント
+	tenants []*tenant.Tenant
+}
+
+func newFakeUserUseCase() *fakeUserUseCase {
+	return &fakeUserUseCase{users: map[int64]*entity.User{
+		1: {ID: 1, Email: "alice@example.com", Name: "Alice", Role: auth.RoleUser},
+		2: {ID: 2, Email: "admin@example.com", Name: "Admin", Role: auth.RoleAdmin},
+	}}
+}
+
+func (f *fakeUserUseCase) Register(_ context.Context, req *entity.CreateUserRequest) (*entity.AuthResponse, error) {
+	user := &entity.User{ID: int64(len(f.users) + 1), Email: req.Email, Name: req.Name, Role: auth.RoleUser}
+	f.users[user.ID] = user
+	return &entity.AuthResponse{User: user, Token: "jwt-token"}, nil
+}
+
+func (f *fakeUserUseCase) Login(_ context.Context, req *entity.LoginRequest) (*entity.AuthResponse, error) {
+	f.logins++
+	for _, user := range f.users {
+		if user.Email == req.Email {
+			return &entity.AuthResponse{User: user, Token: "jwt-token"}, nil
+		}
+	}
+	return nil, errors.New("invalid email or password")
+}
+
+// errorReason ステータスの ErrorInfo の reason
+func errorReason(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.GetReason()
+		}
+	}
+	return ""
+}
+
+func TestUserServerTokenLoginInCookieMode(t *testing.T) {
+	ctx := context.Background()
+	login := &userv1.LoginRequest{Email: "alice@example.com", Password: "password123"}
+	register := &userv1.RegisterRequest{Email: "bob@example.com", Name: "Bob", Password: "password123"}
+
+	// トークン認証モードではトークンを返す
+	uc := newFakeUserUseCase()
+	server := NewUserServer(uc, session.NewManager(session.NewKV(kvstore.NewMemory()), "secret", session.Config{}))
+	resp, err := server.Login(ctx, login)
+	if err != nil || resp.GetToken() != "jwt-token" {
+		t.Fatalf("token mode Login = %v, %v", resp, err)
+	}
+
+	// Cookie認証モードではトークンを発行する前に拒否する
+	uc = newFakeUserUseCase()
+	server = NewUserServer(uc, session.NewManager(session.NewKV(kvstore.NewMemory()), "secret", session.Config{CookieMode: true}))
+	if _, err := server.Login(ctx, login); status.Code(err) != codes.FailedPrecondition || errorReason(err) != "TOKEN_LOGIN_DISABLED" {
+		t.Errorf("cookie mode Login error = %v (reason %q)", err, errorReason(err))
+	}
+	if _, err := server.Register(ctx, register); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("cookie mode Register error = %v", err)
+	}
+	if uc.logins != 0 || len(uc.users) != 2 {
+		t.Errorf("use case was called in cookie mode: logins = %d, users = %d", uc.logins, len(uc.users))
+	}
+}
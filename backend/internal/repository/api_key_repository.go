@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"app-template/internal/entity"
+)
+
+// ErrAPIKeyNotFound 失効対象のAPIキーが存在しない（他のユーザーのキー・失効済みを含む）
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKeyRepository APIキーリポジトリのインターフェース
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *entity.APIKey) (*entity.APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (*entity.APIKey, error)
+	ListByUserID(ctx context.Context, userID int64) ([]*entity.APIKey, error)
+	Revoke(ctx context.Context, userID, id int64) error
+	TouchLastUsed(ctx context.Context, id int64) error
+}
+
+// apiKeyRepository APIキーリポジトリの実装
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository APIキーリポジトリの新しいインスタンスを作成
+func NewAPIKeyRepository(db *sql.DB) APIKeyRepository {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+// Create 新しいAPIキーを保存
+func (r *apiKeyRepository) Create(ctx context.Context, key *entity.APIKey) (*entity.APIKey, error) {
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, NOW())
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		key.UserID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		strings.Join(key.Scopes, " "),
+		key.ExpiresAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return r.GetByHash(ctx, key.KeyHash)
+}
+
+// GetByHash キーハッシュでAPIキーを取得
+func (r *apiKeyRepository) GetByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	query := `
+		SELECT id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at
+		FROM api_keys
+		WHERE key_hash = ?
+	`
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, keyHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// ListByUserID ユーザーの有効なAPIキー一覧を取得（失効済みは除く）
+func (r *apiKeyRepository) ListByUserID(ctx context.Context, userID int64) ([]*entity.APIKey, error) {
+	query := `
+		SELECT id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at
+		FROM api_keys
+		WHERE user_id = ? AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*entity.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Revoke ユーザーのAPIキーを失効
+func (r *apiKeyRepository) Revoke(ctx context.Context, userID, id int64) error {
+	query := `UPDATE api_keys SET revoked_at = NOW() WHERE id = ? AND user_id = ? AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// TouchLastUsed 最終使用日時を更新（書き込みを抑えるため1分以内の再更新は行わない）
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id int64) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = NOW()
+		WHERE id = ? AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL 1 MINUTE)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to update api key last used: %w", err)
+	}
+
+	return nil
+}
+
+// scanAPIKey 1行分のAPIキーを読み込む
+func scanAPIKey(row rowScanner) (*entity.APIKey, error) {
+	key := &entity.APIKey{}
+	var scopes string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&scopes,
+		&expiresAt,
+		&lastUsedAt,
+		&revokedAt,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = strings.Fields(scopes)
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+
+	return key, nil
+}
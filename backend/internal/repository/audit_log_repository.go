@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"app-template/internal/entity"
+	"app-template/pkg/requestinfo"
+)
+
+// audit_logs の列の長さ（文字数）。超える値で挿入が失敗して記録が欠けないよう切り詰める
+const (
+	auditIPLength        = 45
+	auditUserAgentLength = 512
+)
+
+// AuditLogRepository 監査ログリポジトリのインターフェース
+// 監査ログは追記のみのため、更新・削除の操作は提供しない
+type AuditLogRepository interface {
+	Create(ctx context.Context, log *entity.AuditLog) error
+	List(ctx context.Context, filter *entity.AuditLogFilter) ([]*entity.AuditLog, *entity.PaginationResponse, error)
+}
+
+// auditLogRepository 監査ログリポジトリの実装
+type auditLogRepository struct {
+	db *sql.DB
+}
+
+// NewAuditLogRepository 監査ログリポジトリの新しいインスタンスを作成
+func NewAuditLogRepository(db *sql.DB) AuditLogRepository {
+	return &auditLogRepository{
+		db: db,
+	}
+}
+
+// Create 監査ログを追記
+func (r *auditLogRepository) Create(ctx context.Context, log *entity.AuditLog) error {
+	query := `
+		INSERT INTO audit_logs (action, actor_id, target_user_id, ip, user_agent, request_id, changes, metadata, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW(3))
+	`
+
+	changes, err := nullJSON(log.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit changes: %w", err)
+	}
+	metadata, err := nullJSON(log.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit metadata: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, query,
+		log.Action,
+		log.ActorID,
+		log.TargetUserID,
+		requestinfo.Truncate(log.IP, auditIPLength),
+		requestinfo.Truncate(log.UserAgent, auditUserAgentLength),
+		log.RequestID,
+		changes,
+		metadata,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	return nil
+}
+
+// List 条件に一致する監査ログを新しい順に取得
+func (r *auditLogRepository) List(ctx context.Context, filter *entity.AuditLogFilter) ([]*entity.AuditLog, *entity.PaginationResponse, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if filter.ActorID > 0 {
+		conditions = append(conditions, "actor_id = ?")
+		args = append(args, filter.ActorID)
+	}
+	if filter.TargetUserID > 0 {
+		conditions = append(conditions, "target_user_id = ?")
+		args = append(args, filter.TargetUserID)
+	}
+	if filter.RequestID != "" {
+		conditions = append(conditions, "request_id = ?")
+		args = append(args, filter.RequestID)
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, *filter.To)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// 総件数を取得
+	var total int
+	countQuery := `SELECT COUNT(*) FROM audit_logs ` + where
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	// ページネーション計算
+	offset := (filter.Page - 1) * filter.Limit
+	totalPages := int(math.Ceil(float64(total) / float64(filter.Limit)))
+
+	query := `
+		SELECT id, action, actor_id, target_user_id, ip, user_agent, request_id, changes, metadata, created_at
+		FROM audit_logs
+		` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, filter.Limit, offset)...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []*entity.AuditLog{}
+	for rows.Next() {
+		log, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		logs = append(logs, log)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+
+	pagination := &entity.PaginationResponse{
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	return logs, pagination, nil
+}
+
+// scanAuditLog 1行分の監査ログを読み込む
+func scanAuditLog(row rowScanner) (*entity.AuditLog, error) {
+	log := &entity.AuditLog{}
+	var actorID, targetUserID sql.NullInt64
+	var changes, metadata []byte
+	err := row.Scan(
+		&log.ID,
+		&log.Action,
+		&actorID,
+		&targetUserID,
+		&log.IP,
+		&log.UserAgent,
+		&log.RequestID,
+		&changes,
+		&metadata,
+		&log.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if actorID.Valid {
+		log.ActorID = &actorID.Int64
+	}
+	if targetUserID.Valid {
+		log.TargetUserID = &targetUserID.Int64
+	}
+	if len(changes) > 0 {
+		if err := json.Unmarshal(changes, &log.Changes); err != nil {
+			return nil, err
+		}
+	}
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &log.Metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	return log, nil
+}
+
+// nullJSON 空の値をNULLとしてJSON文字列に変換
+// JSONカラムにはバイナリ文字セットの値を保存できないため文字列で渡す
+func nullJSON[T any](value map[string]T) (interface{}, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+
+	"app-template/internal/entity"
+	"app-template/pkg/tenant"
+)
+
+// InvitationRepository 招待リポジトリのインターフェース
+// コンテキストにテナントがあれば、取得・一覧はその組織への招待に絞り込む
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *entity.Invitation) (*entity.Invitation, error)
+	GetByID(ctx context.Context, id int64) (*entity.Invitation, error)
+	GetPending(ctx context.Context, email string, organizationID *int64) (*entity.Invitation, error)
+	List(ctx context.Context, params *entity.InvitationListParams) ([]*entity.Invitation, *entity.PaginationResponse, error)
+	Respond(ctx context.Context, id int64, status string, acceptedUserID *int64) error
+}
+
+// invitationRepository 招待リポジトリの実装
+type invitationRepository struct {
+	db *sql.DB
+}
+
+// NewInvitationRepository 招待リポジトリの新しいインスタンスを作成
+func NewInvitationRepository(db *sql.DB) InvitationRepository {
+	return &invitationRepository{
+		db: db,
+	}
+}
+
+// invitationColumns 招待の取得列
+const invitationColumns = "id, email, role, organization_id, organization_role, status, invited_by, accepted_user_id, expires_at, responded_at, created_at, updated_at"
+
+// Create 招待を作成
+func (r *invitationRepository) Create(ctx context.Context, invitation *entity.Invitation) (*entity.Invitation, error) {
+	query := `
+		INSERT INTO invitations (email, role, organization_id, organization_role, status, invited_by, expires_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		invitation.Email,
+		invitation.Role,
+		invitation.OrganizationID,
+		nullString(invitation.OrganizationRole),
+		entity.InvitationStatusPending,
+		invitation.InvitedBy,
+		invitation.ExpiresAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// GetByID IDで招待を取得（存在しなければnil）
+func (r *invitationRepository) GetByID(ctx context.Context, id int64) (*entity.Invitation, error) {
+	where, args := invitationTenantWhere(ctx, []string{"id = ?"}, []interface{}{id})
+	query := `SELECT ` + invitationColumns + ` FROM invitations ` + where
+
+	invitation, err := scanInvitation(r.db.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	return invitation, nil
+}
+
+// GetPending メールアドレス・組織への有効期限内の未回答の招待を取得（存在しなければnil）
+func (r *invitationRepository) GetPending(ctx context.Context, email string, organizationID *int64) (*entity.Invitation, error) {
+	conditions := []string{"email = ?", "status = ?", "expires_at > NOW()"}
+	args := []interface{}{email, entity.InvitationStatusPending}
+	if organizationID != nil {
+		conditions = append(conditions, "organization_id = ?")
+		args = append(args, *organizationID)
+	} else {
+		conditions = append(conditions, "organization_id IS NULL")
+	}
+
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM invitations
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY id DESC
+		LIMIT 1
+	`
+
+	invitation, err := scanInvitation(r.db.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	return invitation, nil
+}
+
+// List 招待を新しい順に取得
+func (r *invitationRepository) List(ctx context.Context, params *entity.InvitationListParams) ([]*entity.Invitation, *entity.PaginationResponse, error) {
+	var conditions []string
+	var args []interface{}
+	if params.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, params.Status)
+	}
+	where, args := invitationTenantWhere(ctx, conditions, args)
+
+	// 総件数を取得
+	var total int
+	countQuery := `SELECT COUNT(*) FROM invitations ` + where
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("failed to count invitations: %w", err)
+	}
+
+	// ページネーション計算
+	offset := (params.Page - 1) * params.Limit
+	totalPages := int(math.Ceil(float64(total) / float64(params.Limit)))
+
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM invitations
+		` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, params.Limit, offset)...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []*entity.Invitation{}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan invitation: %w", err)
+		}
+		invitations = append(invitations, invitation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+
+	pagination := &entity.PaginationResponse{
+		Page:       params.Page,
+		Limit:      params.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	return invitations, pagination, nil
+}
+
+// Respond 未回答の招待を承諾・辞退・取り消しの状態にする
+// 既に回答済みなら "invitation not found" を返すため、同じ招待を二重に承諾できない
+func (r *invitationRepository) Respond(ctx context.Context, id int64, status string, acceptedUserID *int64) error {
+	query := `
+		UPDATE invitations
+		SET status = ?, accepted_user_id = ?, responded_at = NOW(), updated_at = NOW()
+		WHERE id = ? AND status = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query, status, acceptedUserID, id, entity.InvitationStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to update invitation: %w", err)
+	}
+
+	return requireAffected(result, "invitation not found")
+}
+
+// invitationTenantWhere コンテキストのテナントがあれば組織の条件を加えてWHERE句を組み立てる
+// 個人のテナントにはどの組織の招待も見せない
+func invitationTenantWhere(ctx context.Context, conditions []string, args []interface{}) (string, []interface{}) {
+	if t, ok := tenant.FromContext(ctx); ok {
+		if t.IsPersonal() {
+			conditions = append(conditions, "1 = 0")
+		} else {
+			conditions = append(conditions, "organization_id = ?")
+			args = append(args, t.ID)
+		}
+	}
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// scanInvitation 1行分の招待を読み込む
+func scanInvitation(row rowScanner) (*entity.Invitation, error) {
+	invitation := &entity.Invitation{}
+	var organizationID, invitedBy, acceptedUserID sql.NullInt64
+	var organizationRole sql.NullString
+	var respondedAt sql.NullTime
+	err := row.Scan(
+		&invitation.ID,
+		&invitation.Email,
+		&invitation.Role,
+		&organizationID,
+		&organizationRole,
+		&invitation.Status,
+		&invitedBy,
+		&acceptedUserID,
+		&invitation.ExpiresAt,
+		&respondedAt,
+		&invitation.CreatedAt,
+		&invitation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if organizationID.Valid {
+		invitation.OrganizationID = &organizationID.Int64
+	}
+	invitation.OrganizationRole = organizationRole.String
+	if invitedBy.Valid {
+		invitation.InvitedBy = &invitedBy.Int64
+	}
+	if acceptedUserID.Valid {
+		invitation.AcceptedUserID = &acceptedUserID.Int64
+	}
+	if respondedAt.Valid {
+		invitation.RespondedAt = &respondedAt.Time
+	}
+
+	return invitation, nil
+}
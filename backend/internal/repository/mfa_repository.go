@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"app-template/internal/entity"
+)
+
+// MFARepository 二要素認証リポジトリのインターフェース
+type MFARepository interface {
+	GetTOTP(ctx context.Context, userID int64) (*entity.UserTOTP, error)
+	SaveTOTPSecret(ctx context.Context, userID int64, secret string) error
+	EnableTOTP(ctx context.Context, userID int64, step int64) error
+	ConsumeTOTPStep(ctx context.Context, userID int64, step int64) (bool, error)
+	DeleteTOTP(ctx context.Context, userID int64) error
+	IsTOTPEnabled(ctx context.Context, userID int64) (bool, error)
+	Methods(ctx context.Context, userID int64) ([]string, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error
+	ConsumeRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID int64) (int, error)
+}
+
+// mfaRepository 二要素認証リポジトリの実装
+type mfaRepository struct {
+	db *sql.DB
+}
+
+// NewMFARepository 二要素認証リポジトリの新しいインスタンスを作成
+func NewMFARepository(db *sql.DB) MFARepository {
+	return &mfaRepository{
+		db: db,
+	}
+}
+
+// GetTOTP ユーザーのTOTP設定を取得
+func (r *mfaRepository) GetTOTP(ctx context.Context, userID int64) (*entity.UserTOTP, error) {
+	query := `
+		SELECT user_id, secret, enabled_at, last_used_step, created_at, updated_at
+		FROM user_totp
+		WHERE user_id = ?
+	`
+
+	totp := &entity.UserTOTP{}
+	var enabledAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&totp.UserID,
+		&totp.Secret,
+		&enabledAt,
+		&totp.LastUsedStep,
+		&totp.CreatedAt,
+		&totp.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get totp: %w", err)
+	}
+
+	if enabledAt.Valid {
+		totp.EnabledAt = &enabledAt.Time
+	}
+
+	return totp, nil
+}
+
+// SaveTOTPSecret 未確認のTOTPシークレットを保存（既存の未確認シークレットは置き換え）
+func (r *mfaRepository) SaveTOTPSecret(ctx context.Context, userID int64, secret string) error {
+	query := `
+		INSERT INTO user_totp (user_id, secret, enabled_at, last_used_step, created_at, updated_at)
+		VALUES (?, ?, NULL, 0, NOW(), NOW())
+		ON DUPLICATE KEY UPDATE secret = VALUES(secret), enabled_at = NULL, last_used_step = 0, updated_at = NOW()
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, secret); err != nil {
+		return fmt.Errorf("failed to save totp secret: %w", err)
+	}
+
+	return nil
+}
+
+// EnableTOTP TOTPを有効化（確認に使ったステップを使用済みとして記録）
+func (r *mfaRepository) EnableTOTP(ctx context.Context, userID int64, step int64) error {
+	query := `
+		UPDATE user_totp
+		SET enabled_at = NOW(), last_used_step = ?, updated_at = NOW()
+		WHERE user_id = ?
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, step, userID); err != nil {
+		return fmt.Errorf("failed to enable totp: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeTOTPStep 使用済みステップを更新（同じコードの再利用を防ぐ）
+func (r *mfaRepository) ConsumeTOTPStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	query := `
+		UPDATE user_totp
+		SET last_used_step = ?, updated_at = NOW()
+		WHERE user_id = ? AND last_used_step < ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query, step, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to consume totp step: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// DeleteTOTP TOTP設定とリカバリーコードを削除
+func (r *mfaRepository) DeleteTOTP(ctx context.Context, userID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete totp: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// IsTOTPEnabled TOTPが有効か
+func (r *mfaRepository) IsTOTPEnabled(ctx context.Context, userID int64) (bool, error) {
+	query := `SELECT COUNT(*) FROM user_totp WHERE user_id = ? AND enabled_at IS NOT NULL`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check totp: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// Methods ユーザーが利用可能な二要素認証の方式を取得
+func (r *mfaRepository) Methods(ctx context.Context, userID int64) ([]string, error) {
+	query := `
+		SELECT
+			EXISTS(SELECT 1 FROM user_totp WHERE user_id = ? AND enabled_at IS NOT NULL),
+			EXISTS(SELECT 1 FROM webauthn_credentials WHERE user_id = ?)
+	`
+
+	var totpEnabled, webauthnEnabled bool
+	if err := r.db.QueryRowContext(ctx, query, userID, userID).Scan(&totpEnabled, &webauthnEnabled); err != nil {
+		return nil, fmt.Errorf("failed to get mfa methods: %w", err)
+	}
+
+	methods := []string{}
+	if totpEnabled {
+		methods = append(methods, entity.MFAMethodTOTP)
+	}
+	if webauthnEnabled {
+		methods = append(methods, entity.MFAMethodWebAuthn)
+	}
+
+	return methods, nil
+}
+
+// ReplaceRecoveryCodes リカバリーコードを新しいセットに置き換え
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	query := `INSERT INTO user_recovery_codes (user_id, code_hash, created_at) VALUES (?, ?, NOW())`
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, query, userID, hash); err != nil {
+			return fmt.Errorf("failed to insert recovery code: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ConsumeRecoveryCode 未使用のリカバリーコードを使用済みにする
+func (r *mfaRepository) ConsumeRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	query := `
+		UPDATE user_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = ? AND code_hash = ? AND used_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to consume recovery code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// CountRecoveryCodes 未使用のリカバリーコード数を取得
+func (r *mfaRepository) CountRecoveryCodes(ctx context.Context, userID int64) (int, error) {
+	query := `SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = ? AND used_at IS NULL`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+
+	"app-template/internal/entity"
+)
+
+// OrganizationRepository 組織リポジトリのインターフェース
+type OrganizationRepository interface {
+	Create(ctx context.Context, org *entity.Organization, ownerID int64) (*entity.Organization, error)
+	GetByID(ctx context.Context, id int64) (*entity.Organization, error)
+	GetBySlug(ctx context.Context, slug string) (*entity.Organization, error)
+	ListByUserID(ctx context.Context, userID int64) ([]*entity.OrganizationMembership, error)
+	GetMemberRole(ctx context.Context, orgID, userID int64) (string, error)
+	CountOwners(ctx context.Context, orgID int64) (int, error)
+	AddMember(ctx context.Context, orgID, userID int64, role string) error
+	UpdateMemberRole(ctx context.Context, orgID, userID int64, role string) error
+	RemoveMember(ctx context.Context, orgID, userID int64) error
+	ListMembers(ctx context.Context, orgID int64, params *entity.PaginationParams) ([]*entity.OrganizationMember, *entity.PaginationResponse, error)
+}
+
+// organizationRepository 組織リポジトリの実装
+type organizationRepository struct {
+	db *sql.DB
+}
+
+// NewOrganizationRepository 組織リポジトリの新しいインスタンスを作成
+func NewOrganizationRepository(db *sql.DB) OrganizationRepository {
+	return &organizationRepository{
+		db: db,
+	}
+}
+
+// organizationColumns 組織の取得列
+const organizationColumns = "o.id, o.slug, o.name, o.created_at, o.updated_at"
+
+// Create 組織を作成し、作成者をオーナーとして追加する
+func (r *organizationRepository) Create(ctx context.Context, org *entity.Organization, ownerID int64) (*entity.Organization, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO organizations (slug, name, created_at, updated_at) VALUES (?, ?, NOW(), NOW())`
+	result, err := tx.ExecContext(ctx, query, org.Slug, org.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	if err := addMember(ctx, tx, id, ownerID, entity.OrgRoleOwner); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// GetByID IDで組織を取得（存在しなければnil）
+func (r *organizationRepository) GetByID(ctx context.Context, id int64) (*entity.Organization, error) {
+	return r.get(ctx, `o.id = ?`, id)
+}
+
+// GetBySlug スラッグで組織を取得（存在しなければnil）
+func (r *organizationRepository) GetBySlug(ctx context.Context, slug string) (*entity.Organization, error) {
+	return r.get(ctx, `o.slug = ?`, slug)
+}
+
+func (r *organizationRepository) get(ctx context.Context, condition string, arg interface{}) (*entity.Organization, error) {
+	query := `SELECT ` + organizationColumns + ` FROM organizations o WHERE ` + condition
+
+	org, err := scanOrganization(r.db.QueryRowContext(ctx, query, arg))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	return org, nil
+}
+
+// ListByUserID ユーザーが所属する組織とロールを取得
+func (r *organizationRepository) ListByUserID(ctx context.Context, userID int64) ([]*entity.OrganizationMembership, error) {
+	query := `
+		SELECT ` + organizationColumns + `, m.role
+		FROM organizations o
+		JOIN organization_members m ON m.organization_id = o.id
+		WHERE m.user_id = ?
+		ORDER BY o.name, o.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	defer rows.Close()
+
+	memberships := []*entity.OrganizationMembership{}
+	for rows.Next() {
+		membership := &entity.OrganizationMembership{Organization: &entity.Organization{}}
+		err := rows.Scan(
+			&membership.ID,
+			&membership.Slug,
+			&membership.Name,
+			&membership.CreatedAt,
+			&membership.UpdatedAt,
+			&membership.Role,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		memberships = append(memberships, membership)
+	}
+
+	return memberships, rows.Err()
+}
+
+// GetMemberRole 組織でのユーザーのロールを取得（メンバーでなければ空文字列）
+func (r *organizationRepository) GetMemberRole(ctx context.Context, orgID, userID int64) (string, error) {
+	query := `SELECT role FROM organization_members WHERE organization_id = ? AND user_id = ?`
+
+	var role string
+	err := r.db.QueryRowContext(ctx, query, orgID, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get organization member: %w", err)
+	}
+
+	return role, nil
+}
+
+// CountOwners 組織のオーナーの数を取得
+func (r *organizationRepository) CountOwners(ctx context.Context, orgID int64) (int, error) {
+	query := `SELECT COUNT(*) FROM organization_members WHERE organization_id = ? AND role = ?`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, orgID, entity.OrgRoleOwner).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count organization owners: %w", err)
+	}
+
+	return count, nil
+}
+
+// AddMember ユーザーを組織のメンバーに追加
+func (r *organizationRepository) AddMember(ctx context.Context, orgID, userID int64, role string) error {
+	return addMember(ctx, r.db, orgID, userID, role)
+}
+
+// execer *sql.DB と *sql.Tx の共通インターフェース
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// addMember メンバーを追加（トランザクション内からも使う）
+func addMember(ctx context.Context, exec execer, orgID, userID int64, role string) error {
+	query := `
+		INSERT INTO organization_members (organization_id, user_id, role, created_at, updated_at)
+		VALUES (?, ?, ?, NOW(), NOW())
+	`
+	if _, err := exec.ExecContext(ctx, query, orgID, userID, role); err != nil {
+		return fmt.Errorf("failed to add organization member: %w", err)
+	}
+	return nil
+}
+
+// UpdateMemberRole メンバーのロールを変更
+func (r *organizationRepository) UpdateMemberRole(ctx context.Context, orgID, userID int64, role string) error {
+	query := `UPDATE organization_members SET role = ?, updated_at = NOW() WHERE organization_id = ? AND user_id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, role, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update organization member: %w", err)
+	}
+
+	return requireAffected(result, "organization member not found")
+}
+
+// RemoveMember メンバーを組織から外す
+func (r *organizationRepository) RemoveMember(ctx context.Context, orgID, userID int64) error {
+	query := `DELETE FROM organization_members WHERE organization_id = ? AND user_id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove organization member: %w", err)
+	}
+
+	return requireAffected(result, "organization member not found")
+}
+
+// ListMembers 組織のメンバーを参加順に取得
+func (r *organizationRepository) ListMembers(ctx context.Context, orgID int64, params *entity.PaginationParams) ([]*entity.OrganizationMember, *entity.PaginationResponse, error) {
+	var total int
+	countQuery := `SELECT COUNT(*) FROM organization_members WHERE organization_id = ?`
+	if err := r.db.QueryRowContext(ctx, countQuery, orgID).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("failed to count organization members: %w", err)
+	}
+
+	// ページネーション計算
+	offset := (params.Page - 1) * params.Limit
+	totalPages := int(math.Ceil(float64(total) / float64(params.Limit)))
+
+	query := `
+		SELECT m.role, m.created_at, ` + prefixColumns("u", userColumns) + `
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.organization_id = ?
+		ORDER BY m.created_at, m.user_id
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID, params.Limit, offset)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list organization members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []*entity.OrganizationMember{}
+	for rows.Next() {
+		member := &entity.OrganizationMember{}
+		user, err := scanUser(prefixedScanner{row: rows, prefix: []interface{}{&member.Role, &member.JoinedAt}})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan organization member: %w", err)
+		}
+		member.User = user
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list organization members: %w", err)
+	}
+
+	pagination := &entity.PaginationResponse{
+		Page:       params.Page,
+		Limit:      params.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	return members, pagination, nil
+}
+
+// scanOrganization 1行分の組織を読み込む
+func scanOrganization(row rowScanner) (*entity.Organization, error) {
+	org := &entity.Organization{}
+	err := row.Scan(
+		&org.ID,
+		&org.Slug,
+		&org.Name,
+		&org.CreatedAt,
+		&org.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// prefixedScanner 先頭の列を別の変数に読み込んでから残りの列を渡す
+type prefixedScanner struct {
+	row    rowScanner
+	prefix []interface{}
+}
+
+func (s prefixedScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(append([]interface{}{}, s.prefix...), dest...)...)
+}
+
+// prefixColumns カンマ区切りの列名にテーブルの別名を付ける
+func prefixColumns(alias, columns string) string {
+	parts := strings.Split(columns, ", ")
+	for i, column := range parts {
+		parts[i] = alias + "." + column
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"app-template/internal/entity"
+)
+
+// UserIdentityRepository 外部プロバイダー連携リポジトリのインターフェース
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *entity.UserIdentity) (*entity.UserIdentity, error)
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error)
+	ListByUserID(ctx context.Context, userID int64) ([]*entity.UserIdentity, error)
+	Delete(ctx context.Context, userID int64, provider string) error
+}
+
+// userIdentityRepository 外部プロバイダー連携リポジトリの実装
+type userIdentityRepository struct {
+	db *sql.DB
+}
+
+// NewUserIdentityRepository 外部プロバイダー連携リポジトリの新しいインスタンスを作成
+func NewUserIdentityRepository(db *sql.DB) UserIdentityRepository {
+	return &userIdentityRepository{
+		db: db,
+	}
+}
+
+// Create 新しい連携を作成
+func (r *userIdentityRepository) Create(ctx context.Context, identity *entity.UserIdentity) (*entity.UserIdentity, error) {
+	query := `
+		INSERT INTO user_identities (user_id, provider, subject, email, created_at, updated_at)
+		VALUES (?, ?, ?, ?, NOW(), NOW())
+	`
+
+	_, err := r.db.ExecContext(ctx, query, identity.UserID, identity.Provider, identity.Subject, identity.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user identity: %w", err)
+	}
+
+	return r.GetByProviderSubject(ctx, identity.Provider, identity.Subject)
+}
+
+// GetByProviderSubject プロバイダーとサブジェクトで連携を取得
+func (r *userIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error) {
+	query := `
+		SELECT id, user_id, provider, subject, email, created_at, updated_at
+		FROM user_identities
+		WHERE provider = ? AND subject = ?
+	`
+
+	identity, err := scanUserIdentity(r.db.QueryRowContext(ctx, query, provider, subject))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user identity: %w", err)
+	}
+
+	return identity, nil
+}
+
+// ListByUserID ユーザーの連携一覧を取得
+func (r *userIdentityRepository) ListByUserID(ctx context.Context, userID int64) ([]*entity.UserIdentity, error) {
+	query := `
+		SELECT id, user_id, provider, subject, email, created_at, updated_at
+		FROM user_identities
+		WHERE user_id = ?
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user identities: %w", err)
+	}
+	defer rows.Close()
+
+	identities := []*entity.UserIdentity{}
+	for rows.Next() {
+		identity, err := scanUserIdentity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user identity: %w", err)
+		}
+		identities = append(identities, identity)
+	}
+
+	return identities, rows.Err()
+}
+
+// Delete ユーザーとプロバイダーの連携を削除
+func (r *userIdentityRepository) Delete(ctx context.Context, userID int64, provider string) error {
+	query := `DELETE FROM user_identities WHERE user_id = ? AND provider = ?`
+
+	result, err := r.db.ExecContext(ctx, query, userID, provider)
+	if err != nil {
+		return fmt.Errorf("failed to delete user identity: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("identity not found")
+	}
+
+	return nil
+}
+
+// scanUserIdentity 1行分の連携を読み込む
+func scanUserIdentity(row rowScanner) (*entity.UserIdentity, error) {
+	identity := &entity.UserIdentity{}
+	err := row.Scan(
+		&identity.ID,
+		&identity.UserID,
+		&identity.Provider,
+		&identity.Subject,
+		&identity.Email,
+		&identity.CreatedAt,
+		&identity.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
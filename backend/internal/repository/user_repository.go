@@ -5,18 +5,37 @@ import (
 	"database/sql"
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
+	"time"
 
 	"app-template/internal/entity"
+	"app-template/pkg/outbox"
+	"app-template/pkg/tenant"
 )
 
+// userColumns scanUserで読み込むユーザーのカラム
+const userColumns = "id, email, name, display_name, locale, timezone, bio, avatar_version, password, role, status, status_reason, suspended_until, created_at, updated_at"
+
 // UserRepository ユーザーリポジトリのインターフェース
+// コンテキストにテナントがある場合、取得・更新・削除はその組織のメンバーに限られ、作成したユーザーはメンバーに追加される
+// （メールアドレスはテナントをまたいで一意なため、GetByEmail・ExistingEmailsは絞り込まない）
 type UserRepository interface {
 	Create(ctx context.Context, user *entity.User) (*entity.User, error)
+	CreateBatch(ctx context.Context, users []*entity.User) error
 	GetByID(ctx context.Context, id int64) (*entity.User, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]*entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
+	ExistingEmails(ctx context.Context, emails []string) (map[string]bool, error)
 	Update(ctx context.Context, id int64, user *entity.User) (*entity.User, error)
+	UpdateRole(ctx context.Context, id int64, role string) error
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context, params *entity.PaginationParams) ([]*entity.User, *entity.PaginationResponse, error)
+	ListAfterID(ctx context.Context, afterID int64, limit int) ([]*entity.User, error)
+	Search(ctx context.Context, filter *entity.UserFilter) ([]*entity.User, *entity.PaginationResponse, error)
+	UpdatePassword(ctx context.Context, id int64, passwordHash string) error
+	UpdateStatus(ctx context.Context, id int64, status, reason string, until *time.Time) error
+	UpdateAvatar(ctx context.Context, id int64, version string) (*entity.User, error)
 }
 
 // userRepository ユーザーリポジトリの実装
@@ -31,14 +50,20 @@ func NewUserRepository(db *sql.DB) UserRepository {
 	}
 }
 
-// Create 新しいユーザーを作成
+// Create 新しいユーザーを作成（user.created イベントを同じトランザクションでアウトボックスに書き込む）
 func (r *userRepository) Create(ctx context.Context, user *entity.User) (*entity.User, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO users (email, name, password, created_at, updated_at)
-		VALUES (?, ?, ?, NOW(), NOW())
+		INSERT INTO users (email, name, password, role, created_at, updated_at)
+		VALUES (?, ?, ?, ?, NOW(), NOW())
 	`
-	
-	result, err := r.db.ExecContext(ctx, query, user.Email, user.Name, user.Password)
+
+	result, err := tx.ExecContext(ctx, query, user.Email, user.Name, nullString(user.Password), user.Role)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
@@ -48,27 +73,104 @@ func (r *userRepository) Create(ctx context.Context, user *entity.User) (*entity
 		return nil, fmt.Errorf("failed to get last insert id: %w", err)
 	}
 
-	return r.GetByID(ctx, id)
+	if err := addTenantMember(ctx, tx, id); err != nil {
+		return nil, err
+	}
+	created, err := getUserInTx(ctx, tx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeUserEvent(ctx, tx, entity.EventUserCreated, &entity.UserEventData{User: created}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return created, nil
 }
 
-// GetByID IDでユーザーを取得
-func (r *userRepository) GetByID(ctx context.Context, id int64) (*entity.User, error) {
+// CreateBatch 複数のユーザーを1回のINSERTで作成（1文で実行するため、失敗時は1件も作成されない）
+func (r *userRepository) CreateBatch(ctx context.Context, users []*entity.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(users))
+	args := make([]interface{}, 0, len(users)*4)
+	for i, user := range users {
+		placeholders[i] = "(?, ?, ?, ?, NOW(), NOW())"
+		args = append(args, user.Email, user.Name, nullString(user.Password), user.Role)
+	}
+
 	query := `
-		SELECT id, email, name, password, created_at, updated_at
+		INSERT INTO users (email, name, password, role, created_at, updated_at)
+		VALUES ` + strings.Join(placeholders, ", ")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to create users: %w", err)
+	}
+
+	// 1文のINSERTでもIDが連続する保証はないため、メールアドレスで作成したユーザーを読み直す
+	emails := make([]interface{}, len(users))
+	for i, user := range users {
+		emails[i] = user.Email
+	}
+	selectQuery := `
+		SELECT ` + userColumns + `
 		FROM users
-		WHERE id = ?
+		WHERE email IN (` + strings.TrimSuffix(strings.Repeat("?,", len(users)), ",") + `)
+		ORDER BY id ASC
 	`
+	rows, err := tx.QueryContext(ctx, selectQuery, emails...)
+	if err != nil {
+		return fmt.Errorf("failed to get created users: %w", err)
+	}
+	defer rows.Close()
 
-	user := &entity.User{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Name,
-		&user.Password,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	var created []*entity.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan user: %w", err)
+		}
+		created = append(created, user)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate users: %w", err)
+	}
+	rows.Close()
 
+	for _, user := range created {
+		if err := addTenantMember(ctx, tx, user.ID); err != nil {
+			return err
+		}
+		if err := writeUserEvent(ctx, tx, entity.EventUserCreated, &entity.UserEventData{User: user}); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetByID IDでユーザーを取得
+func (r *userRepository) GetByID(ctx context.Context, id int64) (*entity.User, error) {
+	where, args := tenantWhere(ctx, []string{"id = ?"}, []interface{}{id})
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		` + where
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, args...))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -79,24 +181,54 @@ func (r *userRepository) GetByID(ctx context.Context, id int64) (*entity.User, e
 	return user, nil
 }
 
+// GetByIDs 複数のIDのユーザーを1回のクエリで取得（存在しないIDは結果に含まれない、順序は不定）
+func (r *userRepository) GetByIDs(ctx context.Context, ids []int64) ([]*entity.User, error) {
+	users := []*entity.User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	where, args := tenantWhere(ctx, []string{"id IN (" + strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",") + ")"}, args)
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		` + where
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users by ids: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate users: %w", err)
+	}
+
+	return users, nil
+}
+
 // GetByEmail Emailでユーザーを取得
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
 	query := `
-		SELECT id, email, name, password, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE email = ?
 	`
 
-	user := &entity.User{}
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Name,
-		&user.Password,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
-
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, email))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -107,29 +239,110 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.
 	return user, nil
 }
 
-// Update ユーザーを更新
+// ExistingEmails 登録済みのメールアドレスを1回のクエリで取得（キーは小文字）
+func (r *userRepository) ExistingEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(emails) == 0 {
+		return existing, nil
+	}
+
+	args := make([]interface{}, len(emails))
+	for i, email := range emails {
+		args[i] = email
+	}
+
+	query := `
+		SELECT email
+		FROM users
+		WHERE email IN (` + strings.TrimSuffix(strings.Repeat("?,", len(emails)), ",") + `)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get existing emails: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, fmt.Errorf("failed to scan email: %w", err)
+		}
+		existing[strings.ToLower(email)] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate emails: %w", err)
+	}
+
+	return existing, nil
+}
+
+// Update ユーザーを更新（変更があれば user.updated イベントを同じトランザクションでアウトボックスに書き込む）
 func (r *userRepository) Update(ctx context.Context, id int64, user *entity.User) (*entity.User, error) {
 	query := `
 		UPDATE users 
-		SET email = ?, name = ?, updated_at = NOW()
+		SET email = ?, name = ?, display_name = ?, locale = ?, timezone = ?, bio = ?, updated_at = NOW()
 		WHERE id = ?
 	`
 
-	_, err := r.db.ExecContext(ctx, query, user.Email, user.Name, id)
+	updated, err := r.updateUser(ctx, id, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, query,
+			user.Email,
+			user.Name,
+			nullString(user.DisplayName),
+			nullString(user.Locale),
+			nullString(user.Timezone),
+			nullString(user.Bio),
+			id,
+		); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to update user: %w", err)
+		if err.Error() == "user not found" {
+			return nil, nil
+		}
+		return nil, err
 	}
 
-	return r.GetByID(ctx, id)
+	return updated, nil
 }
 
-// Delete ユーザーを削除
-func (r *userRepository) Delete(ctx context.Context, id int64) error {
-	query := `DELETE FROM users WHERE id = ?`
+// UpdateRole ユーザーのロールを更新
+func (r *userRepository) UpdateRole(ctx context.Context, id int64, role string) error {
+	query := `UPDATE users SET role = ?, updated_at = NOW() WHERE id = ?`
+
+	_, err := r.updateUser(ctx, id, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, query, role, id); err != nil {
+			return fmt.Errorf("failed to update user role: %w", err)
+		}
+		return nil
+	})
+	return err
+}
 
-	result, err := r.db.ExecContext(ctx, query, id)
+// UpdateAvatar アバター画像の版を更新（空文字列なら未設定に戻す）
+func (r *userRepository) UpdateAvatar(ctx context.Context, id int64, version string) (*entity.User, error) {
+	query := `UPDATE users SET avatar_version = ?, updated_at = NOW() WHERE id = ?`
+
+	return r.updateUser(ctx, id, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, query, nullString(version), id); err != nil {
+			return fmt.Errorf("failed to update user avatar: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdatePassword パスワードハッシュを更新
+func (r *userRepository) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	where, args := tenantWhere(ctx, []string{"id = ?"}, []interface{}{passwordHash, id})
+	query := `UPDATE users SET password = ?, updated_at = NOW() ` + where
+
+	result, err := r.db.ExecContext(ctx, query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to delete user: %w", err)
+		return fmt.Errorf("failed to update password: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -144,12 +357,178 @@ func (r *userRepository) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+// UpdateStatus アカウントの状態を更新
+func (r *userRepository) UpdateStatus(ctx context.Context, id int64, status, reason string, until *time.Time) error {
+	query := `UPDATE users SET status = ?, status_reason = ?, suspended_until = ?, updated_at = NOW() WHERE id = ?`
+
+	_, err := r.updateUser(ctx, id, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, query, status, nullString(reason), until, id); err != nil {
+			return fmt.Errorf("failed to update user status: %w", err)
+		}
+		return nil
+	})
+	return err
+}
+
+// Delete ユーザーを削除（削除前のユーザーを user.deleted イベントとして同じトランザクションでアウトボックスに書き込む）
+// 組織のテナント内では他の組織に影響しないよう、その組織のメンバーから外すだけにする
+func (r *userRepository) Delete(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	user, err := getUserInTx(ctx, tx, id, true)
+	if err != nil {
+		return err
+	}
+
+	if t, ok := tenant.FromContext(ctx); ok && !t.IsPersonal() {
+		if err := removeTenantMember(ctx, tx, t.ID, id); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if err := writeUserEvent(ctx, tx, entity.EventUserDeleted, &entity.UserEventData{User: user}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// updateUser 行をロックしてupdateを実行し、変更があれば user.updated イベントを同じトランザクションでアウトボックスに書き込む
+// 行ロックで同じユーザーの更新を直列化するため、イベントは更新した順にアウトボックスへ並ぶ
+func (r *userRepository) updateUser(ctx context.Context, id int64, update func(tx *sql.Tx) error) (*entity.User, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := getUserInTx(ctx, tx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := update(tx); err != nil {
+		return nil, err
+	}
+	after, err := getUserInTx(ctx, tx, id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if changes := entity.DiffUser(before, after); len(changes) > 0 {
+		if err := writeUserEvent(ctx, tx, entity.EventUserUpdated, &entity.UserEventData{User: after, Changes: changes}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return after, nil
+}
+
+// getUserInTx トランザクション内でユーザーを取得（forUpdateなら行をロックする）
+func getUserInTx(ctx context.Context, tx *sql.Tx, id int64, forUpdate bool) (*entity.User, error) {
+	where, args := tenantWhere(ctx, []string{"id = ?"}, []interface{}{id})
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		` + where
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	user, err := scanUser(tx.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by id: %w", err)
+	}
+
+	return user, nil
+}
+
+// tenantWhere 条件にコンテキストのテナントのメンバーへの絞り込みを加えたWHERE句を作る（条件がなければ空文字列）
+// 個人のテナントでは主体自身だけに絞り込む
+func tenantWhere(ctx context.Context, conditions []string, args []interface{}) (string, []interface{}) {
+	if t, ok := tenant.FromContext(ctx); ok {
+		if t.IsPersonal() {
+			conditions = append(conditions, "users.id = ?")
+			args = append(args, t.UserID)
+		} else {
+			conditions = append(conditions, "EXISTS (SELECT 1 FROM organization_members tm WHERE tm.user_id = users.id AND tm.organization_id = ?)")
+			args = append(args, t.ID)
+		}
+	}
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// addTenantMember コンテキストに組織のテナントがあれば作成したユーザーをメンバーとして追加
+func addTenantMember(ctx context.Context, tx *sql.Tx, userID int64) error {
+	t, ok := tenant.FromContext(ctx)
+	if !ok || t.IsPersonal() {
+		return nil
+	}
+	return addMember(ctx, tx, t.ID, userID, entity.OrgRoleMember)
+}
+
+// removeTenantMember ユーザーを組織のメンバーから外す（最後のオーナーは外さない）
+func removeTenantMember(ctx context.Context, tx *sql.Tx, orgID, userID int64) error {
+	var role string
+	err := tx.QueryRowContext(ctx, `SELECT role FROM organization_members WHERE organization_id = ? AND user_id = ? FOR UPDATE`, orgID, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get organization member: %w", err)
+	}
+
+	if role == entity.OrgRoleOwner {
+		var owners int
+		query := `SELECT COUNT(*) FROM organization_members WHERE organization_id = ? AND role = ? FOR UPDATE`
+		if err := tx.QueryRowContext(ctx, query, orgID, entity.OrgRoleOwner).Scan(&owners); err != nil {
+			return fmt.Errorf("failed to count organization owners: %w", err)
+		}
+		if owners <= 1 {
+			return fmt.Errorf("organization must keep at least one owner")
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM organization_members WHERE organization_id = ? AND user_id = ?`, orgID, userID); err != nil {
+		return fmt.Errorf("failed to remove organization member: %w", err)
+	}
+	return nil
+}
+
+// writeUserEvent ユーザーのイベントをアウトボックスに書き込む
+func writeUserEvent(ctx context.Context, tx *sql.Tx, eventType string, data *entity.UserEventData) error {
+	return outbox.Write(ctx, tx, "user", strconv.FormatInt(data.User.ID, 10), eventType, data)
+}
+
 // List ユーザー一覧を取得
 func (r *userRepository) List(ctx context.Context, params *entity.PaginationParams) ([]*entity.User, *entity.PaginationResponse, error) {
 	// 総件数を取得
+	where, args := tenantWhere(ctx, nil, nil)
 	var total int
-	countQuery := `SELECT COUNT(*) FROM users`
-	err := r.db.QueryRowContext(ctx, countQuery).Scan(&total)
+	countQuery := `SELECT COUNT(*) FROM users ` + where
+	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to count users: %w", err)
 	}
@@ -160,29 +539,23 @@ func (r *userRepository) List(ctx context.Context, params *entity.PaginationPara
 
 	// ユーザーリストを取得
 	query := `
-		SELECT id, email, name, password, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
+		` + where + `
 		ORDER BY created_at DESC
 		LIMIT ? OFFSET ?
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, params.Limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, append(args, params.Limit, offset)...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to list users: %w", err)
 	}
 	defer rows.Close()
 
-	var users []*entity.User
+	// 0件でも空配列として返す（JSONでnullにしない）
+	users := []*entity.User{}
 	for rows.Next() {
-		user := &entity.User{}
-		err := rows.Scan(
-			&user.ID,
-			&user.Email,
-			&user.Name,
-			&user.Password,
-			&user.CreatedAt,
-			&user.UpdatedAt,
-		)
+		user, err := scanUser(rows)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to scan user: %w", err)
 		}
@@ -197,4 +570,159 @@ func (r *userRepository) List(ctx context.Context, params *entity.PaginationPara
 	}
 
 	return users, pagination, nil
-} 
\ No newline at end of file
+} 
+
+// ListAfterID IDがafterIDより大きいユーザーをID順にlimit件取得（キーセットページネーション）
+func (r *userRepository) ListAfterID(ctx context.Context, afterID int64, limit int) ([]*entity.User, error) {
+	where, args := tenantWhere(ctx, []string{"id > ?"}, []interface{}{afterID})
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		` + where + `
+		ORDER BY id ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*entity.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate users: %w", err)
+	}
+
+	return users, nil
+}
+
+// rowScanner *sql.Row と *sql.Rows の共通インターフェース
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser 1行分のユーザーを読み込む
+func scanUser(row rowScanner) (*entity.User, error) {
+	user := &entity.User{}
+	var displayName, locale, timezone, bio, avatarVersion sql.NullString
+	var password, statusReason sql.NullString
+	var suspendedUntil sql.NullTime
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.Name,
+		&displayName,
+		&locale,
+		&timezone,
+		&bio,
+		&avatarVersion,
+		&password,
+		&user.Role,
+		&user.Status,
+		&statusReason,
+		&suspendedUntil,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	user.DisplayName = displayName.String
+	user.Locale = locale.String
+	user.Timezone = timezone.String
+	user.Bio = bio.String
+	user.AvatarVersion = avatarVersion.String
+	user.AvatarURL = entity.AvatarPath(user.ID, user.AvatarVersion)
+	user.Password = password.String
+	user.StatusReason = statusReason.String
+	if suspendedUntil.Valid {
+		user.SuspendedUntil = &suspendedUntil.Time
+	}
+	return user, nil
+}
+
+// nullString 空文字列をNULLとして扱う
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// Search 条件に一致するユーザーを取得
+func (r *userRepository) Search(ctx context.Context, filter *entity.UserFilter) ([]*entity.User, *entity.PaginationResponse, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(filter.Query) + "%"
+		conditions = append(conditions, "(email LIKE ? OR name LIKE ?)")
+		args = append(args, pattern, pattern)
+	}
+	if filter.Role != "" {
+		conditions = append(conditions, "role = ?")
+		args = append(args, filter.Role)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+
+	where, args := tenantWhere(ctx, conditions, args)
+
+	// 総件数を取得
+	var total int
+	countQuery := `SELECT COUNT(*) FROM users ` + where
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	// ページネーション計算
+	offset := (filter.Page - 1) * filter.Limit
+	totalPages := int(math.Ceil(float64(total) / float64(filter.Limit)))
+
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		` + where + `
+		ORDER BY id ASC
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, filter.Limit, offset)...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to search users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*entity.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to search users: %w", err)
+	}
+
+	pagination := &entity.PaginationResponse{
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	return users, pagination, nil
+}
+
+// escapeLike LIKE句のワイルドカードをエスケープ
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
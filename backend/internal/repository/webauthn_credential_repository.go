@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"app-template/internal/entity"
+)
+
+// WebAuthnCredentialRepository パスキーリポジトリのインターフェース
+type WebAuthnCredentialRepository interface {
+	Create(ctx context.Context, credential *entity.WebAuthnCredential) (*entity.WebAuthnCredential, error)
+	GetByCredentialID(ctx context.Context, credentialID []byte) (*entity.WebAuthnCredential, error)
+	ListByUserID(ctx context.Context, userID int64) ([]*entity.WebAuthnCredential, error)
+	UpdateAfterLogin(ctx context.Context, id int64, data []byte) error
+	Delete(ctx context.Context, userID, id int64) error
+}
+
+// webAuthnCredentialRepository パスキーリポジトリの実装
+type webAuthnCredentialRepository struct {
+	db *sql.DB
+}
+
+// NewWebAuthnCredentialRepository パスキーリポジトリの新しいインスタンスを作成
+func NewWebAuthnCredentialRepository(db *sql.DB) WebAuthnCredentialRepository {
+	return &webAuthnCredentialRepository{
+		db: db,
+	}
+}
+
+// Create 新しいパスキーを登録
+func (r *webAuthnCredentialRepository) Create(ctx context.Context, credential *entity.WebAuthnCredential) (*entity.WebAuthnCredential, error) {
+	query := `
+		INSERT INTO webauthn_credentials (user_id, credential_id, name, data, created_at)
+		VALUES (?, ?, ?, ?, NOW())
+	`
+
+	// JSONカラムにはバイナリ文字セットの値を保存できないため文字列で渡す
+	_, err := r.db.ExecContext(ctx, query, credential.UserID, credential.CredentialID, credential.Name, string(credential.Data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webauthn credential: %w", err)
+	}
+
+	return r.GetByCredentialID(ctx, credential.CredentialID)
+}
+
+// GetByCredentialID クレデンシャルIDでパスキーを取得
+func (r *webAuthnCredentialRepository) GetByCredentialID(ctx context.Context, credentialID []byte) (*entity.WebAuthnCredential, error) {
+	query := `
+		SELECT id, user_id, credential_id, name, data, created_at, last_used_at
+		FROM webauthn_credentials
+		WHERE credential_id = ?
+	`
+
+	credential, err := scanWebAuthnCredential(r.db.QueryRowContext(ctx, query, credentialID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webauthn credential: %w", err)
+	}
+
+	return credential, nil
+}
+
+// ListByUserID ユーザーのパスキー一覧を取得
+func (r *webAuthnCredentialRepository) ListByUserID(ctx context.Context, userID int64) ([]*entity.WebAuthnCredential, error) {
+	query := `
+		SELECT id, user_id, credential_id, name, data, created_at, last_used_at
+		FROM webauthn_credentials
+		WHERE user_id = ?
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webauthn credentials: %w", err)
+	}
+	defer rows.Close()
+
+	credentials := []*entity.WebAuthnCredential{}
+	for rows.Next() {
+		credential, err := scanWebAuthnCredential(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webauthn credential: %w", err)
+		}
+		credentials = append(credentials, credential)
+	}
+
+	return credentials, rows.Err()
+}
+
+// UpdateAfterLogin 認証後の署名カウンタ等と最終使用日時を更新
+func (r *webAuthnCredentialRepository) UpdateAfterLogin(ctx context.Context, id int64, data []byte) error {
+	query := `UPDATE webauthn_credentials SET data = ?, last_used_at = NOW() WHERE id = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, string(data), id); err != nil {
+		return fmt.Errorf("failed to update webauthn credential: %w", err)
+	}
+
+	return nil
+}
+
+// Delete ユーザーのパスキーを削除
+func (r *webAuthnCredentialRepository) Delete(ctx context.Context, userID, id int64) error {
+	query := `DELETE FROM webauthn_credentials WHERE id = ? AND user_id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webauthn credential: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("passkey not found")
+	}
+
+	return nil
+}
+
+// scanWebAuthnCredential 1行分のパスキーを読み込む
+func scanWebAuthnCredential(row rowScanner) (*entity.WebAuthnCredential, error) {
+	credential := &entity.WebAuthnCredential{}
+	var lastUsedAt sql.NullTime
+	err := row.Scan(
+		&credential.ID,
+		&credential.UserID,
+		&credential.CredentialID,
+		&credential.Name,
+		&credential.Data,
+		&credential.CreatedAt,
+		&lastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		credential.LastUsedAt = &lastUsedAt.Time
+	}
+	return credential, nil
+}
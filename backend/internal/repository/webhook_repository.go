@@ -0,0 +1,388 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"app-template/internal/entity"
+)
+
+// WebhookRepository Webhookの送信先と配信履歴のリポジトリのインターフェース
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error)
+	GetByID(ctx context.Context, id int64) (*entity.Webhook, error)
+	List(ctx context.Context) ([]*entity.Webhook, error)
+	ListActiveByEvent(ctx context.Context, event string) ([]*entity.Webhook, error)
+	Update(ctx context.Context, webhook *entity.Webhook) error
+	Delete(ctx context.Context, id int64) error
+	CreateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) (*entity.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, id int64) (*entity.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, webhookID int64, params *entity.PaginationParams) ([]*entity.WebhookDelivery, *entity.PaginationResponse, error)
+	DeliveredWebhookIDs(ctx context.Context, eventID string) (map[int64]bool, error)
+}
+
+// webhookRepository Webhookリポジトリの実装
+type webhookRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookRepository Webhookリポジトリの新しいインスタンスを作成
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+// webhookColumns 送信先の取得列
+const webhookColumns = "id, url, description, events, secret, active, created_at, updated_at"
+
+// deliveryColumns 配信履歴の取得列
+const deliveryColumns = "id, webhook_id, event_id, event, payload, status, attempts, response_status, response_body, error, duration_ms, redelivery_of, delivered_at, created_at, updated_at"
+
+// Create 送信先を保存
+func (r *webhookRepository) Create(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error) {
+	query := `
+		INSERT INTO webhooks (url, description, events, secret, active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, NOW(3), NOW(3))
+	`
+
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook events: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, query,
+		webhook.URL,
+		webhook.Description,
+		string(events),
+		webhook.Secret,
+		webhook.Active,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// GetByID IDで送信先を取得（存在しなければnil）
+func (r *webhookRepository) GetByID(ctx context.Context, id int64) (*entity.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = ?`
+
+	webhook, err := scanWebhook(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// List 送信先の一覧を取得
+func (r *webhookRepository) List(ctx context.Context) ([]*entity.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks ORDER BY id`
+	return r.list(ctx, query)
+}
+
+// ListActiveByEvent イベントを購読している有効な送信先を取得
+func (r *webhookRepository) ListActiveByEvent(ctx context.Context, event string) ([]*entity.Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		WHERE active = TRUE AND JSON_CONTAINS(events, JSON_QUOTE(?))
+		ORDER BY id
+	`
+	return r.list(ctx, query, event)
+}
+
+func (r *webhookRepository) list(ctx context.Context, query string, args ...interface{}) ([]*entity.Webhook, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []*entity.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// Update 送信先を更新
+func (r *webhookRepository) Update(ctx context.Context, webhook *entity.Webhook) error {
+	query := `
+		UPDATE webhooks
+		SET url = ?, description = ?, events = ?, active = ?, updated_at = NOW(3)
+		WHERE id = ?
+	`
+
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook events: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, query,
+		webhook.URL,
+		webhook.Description,
+		string(events),
+		webhook.Active,
+		webhook.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	return requireAffected(result, "webhook not found")
+}
+
+// Delete 送信先を削除（配信履歴も削除される）
+func (r *webhookRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM webhooks WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	return requireAffected(result, "webhook not found")
+}
+
+// CreateDelivery 配信を記録
+func (r *webhookRepository) CreateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) (*entity.WebhookDelivery, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event, payload, status, redelivery_of, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, NOW(3), NOW(3))
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		delivery.WebhookID,
+		delivery.EventID,
+		delivery.Event,
+		string(delivery.Payload),
+		delivery.Status,
+		delivery.RedeliveryOf,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	return r.GetDelivery(ctx, id)
+}
+
+// GetDelivery IDで配信を取得（存在しなければnil）
+func (r *webhookRepository) GetDelivery(ctx context.Context, id int64) (*entity.WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE id = ?`
+
+	delivery, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// UpdateDelivery 試行の結果を記録
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, response_status = ?, response_body = ?, error = ?, duration_ms = ?, delivered_at = ?, updated_at = NOW(3)
+		WHERE id = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseStatus,
+		nullString(delivery.ResponseBody),
+		nullString(delivery.Error),
+		delivery.DurationMS,
+		delivery.DeliveredAt,
+		delivery.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	return requireAffected(result, "webhook delivery not found")
+}
+
+// ListDeliveries 送信先の配信履歴を新しい順に取得
+func (r *webhookRepository) ListDeliveries(ctx context.Context, webhookID int64, params *entity.PaginationParams) ([]*entity.WebhookDelivery, *entity.PaginationResponse, error) {
+	var total int
+	countQuery := `SELECT COUNT(*) FROM webhook_deliveries WHERE webhook_id = ?`
+	if err := r.db.QueryRowContext(ctx, countQuery, webhookID).Scan(&total); err != nil {
+		return nil, nil, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	// ページネーション計算
+	offset := (params.Page - 1) * params.Limit
+	totalPages := int(math.Ceil(float64(total) / float64(params.Limit)))
+
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE webhook_id = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, webhookID, params.Limit, offset)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []*entity.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	pagination := &entity.PaginationResponse{
+		Page:       params.Page,
+		Limit:      params.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	return deliveries, pagination, nil
+}
+
+// DeliveredWebhookIDs イベントの配信を記録済みの送信先のIDを取得（ジョブを積めずに失敗した配信は含めない）
+func (r *webhookRepository) DeliveredWebhookIDs(ctx context.Context, eventID string) (map[int64]bool, error) {
+	query := `
+		SELECT DISTINCT webhook_id
+		FROM webhook_deliveries
+		WHERE event_id = ? AND (status <> ? OR attempts > 0)
+	`
+	rows, err := r.db.QueryContext(ctx, query, eventID, entity.WebhookDeliveryFailed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries for event: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook id: %w", err)
+		}
+		ids[id] = true
+	}
+
+	return ids, rows.Err()
+}
+
+// scanWebhook 1行分の送信先を読み込む
+func scanWebhook(row rowScanner) (*entity.Webhook, error) {
+	webhook := &entity.Webhook{}
+	var events []byte
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Description,
+		&events,
+		&webhook.Secret,
+		&webhook.Active,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(events, &webhook.Events); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// scanWebhookDelivery 1行分の配信履歴を読み込む
+func scanWebhookDelivery(row rowScanner) (*entity.WebhookDelivery, error) {
+	delivery := &entity.WebhookDelivery{}
+	var payload []byte
+	var responseStatus sql.NullInt32
+	var responseBody, deliveryError sql.NullString
+	var redeliveryOf sql.NullInt64
+	var deliveredAt sql.NullTime
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.EventID,
+		&delivery.Event,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&responseStatus,
+		&responseBody,
+		&deliveryError,
+		&delivery.DurationMS,
+		&redeliveryOf,
+		&deliveredAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Payload = payload
+	if responseStatus.Valid {
+		status := int(responseStatus.Int32)
+		delivery.ResponseStatus = &status
+	}
+	delivery.ResponseBody = responseBody.String
+	delivery.Error = deliveryError.String
+	if redeliveryOf.Valid {
+		delivery.RedeliveryOf = &redeliveryOf.Int64
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+
+	return delivery, nil
+}
+
+// requireAffected 更新対象の行がなければnotFoundのエラーを返す
+func requireAffected(result sql.Result, notFound string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s", notFound)
+	}
+
+	return nil
+}
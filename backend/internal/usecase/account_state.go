@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"app-template/internal/entity"
+	"app-template/internal/repository"
+	"app-template/pkg/auth"
+	"app-template/pkg/kvstore"
+)
+
+// accountStateTTL アカウント状態のキャッシュ期間
+// 状態変更時はキャッシュを削除するため、この期間は削除に失敗した場合の上限になる
+const accountStateTTL = time.Minute
+
+// AccountStateCache アカウント状態のキャッシュ
+// 認証ミドルウェアが毎リクエスト参照し、状態・ロールの変更時に破棄する
+type AccountStateCache interface {
+	auth.AccountChecker
+	Invalidate(ctx context.Context, userID int64)
+}
+
+// cachedAccountState キャッシュに保存するアカウント状態
+type cachedAccountState struct {
+	Role           string     `json:"role"`
+	Status         string     `json:"status"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+}
+
+// accountStateCache アカウント状態キャッシュの実装
+type accountStateCache struct {
+	userRepo repository.UserRepository
+	store    kvstore.Store
+}
+
+// NewAccountStateCache アカウント状態キャッシュの新しいインスタンスを作成
+func NewAccountStateCache(userRepo repository.UserRepository, store kvstore.Store) AccountStateCache {
+	return &accountStateCache{
+		userRepo: userRepo,
+		store:    store,
+	}
+}
+
+// CheckAccount キャッシュまたはデータベースからアカウント状態を取得して利用可否を判定
+func (c *accountStateCache) CheckAccount(ctx context.Context, userID int64) (*auth.AccountState, error) {
+	state, err := c.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 一時停止の期限はキャッシュ時ではなく判定時点で評価する
+	user := &entity.User{Status: state.Status, SuspendedUntil: state.SuspendedUntil}
+	status := user.EffectiveStatus(time.Now())
+	if err := accountStatusError(status); err != nil {
+		return nil, err
+	}
+
+	return &auth.AccountState{
+		Role:   state.Role,
+		Status: status,
+	}, nil
+}
+
+// Invalidate キャッシュを破棄（失敗してもTTLで失効するためログのみ）
+func (c *accountStateCache) Invalidate(ctx context.Context, userID int64) {
+	if err := c.store.Delete(context.WithoutCancel(ctx), accountStateKey(userID)); err != nil {
+		log.Printf("account state %d: failed to invalidate cache: %v", userID, err)
+	}
+}
+
+// load キャッシュになければデータベースから読み込んでキャッシュする
+func (c *accountStateCache) load(ctx context.Context, userID int64) (*cachedAccountState, error) {
+	key := accountStateKey(userID)
+
+	data, err := c.store.Get(ctx, key)
+	if err == nil {
+		var state cachedAccountState
+		if err := json.Unmarshal(data, &state); err == nil {
+			return &state, nil
+		}
+	} else if !errors.Is(err, kvstore.ErrNotFound) {
+		// キャッシュ障害時はデータベースで判定を続ける
+		log.Printf("account state %d: failed to read cache: %v", userID, err)
+	}
+
+	user, err := c.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, auth.ErrAccountNotFound
+	}
+
+	state := &cachedAccountState{
+		Role:           user.Role,
+		Status:         user.Status,
+		SuspendedUntil: user.SuspendedUntil,
+	}
+	if data, err := json.Marshal(state); err == nil {
+		if err := c.store.Set(ctx, key, data, accountStateTTL); err != nil {
+			log.Printf("account state %d: failed to write cache: %v", userID, err)
+		}
+	}
+
+	return state, nil
+}
+
+// checkAccountStatus ログイン時にアカウントが利用可能か確認
+func checkAccountStatus(user *entity.User) error {
+	return accountStatusError(user.EffectiveStatus(time.Now()))
+}
+
+// accountStatusError 状態に対応するエラーを返す（利用可能ならnil）
+func accountStatusError(status string) error {
+	switch status {
+	case entity.UserStatusSuspended:
+		return auth.ErrAccountSuspended
+	case entity.UserStatusDisabled:
+		return auth.ErrAccountDisabled
+	default:
+		return nil
+	}
+}
+
+// accountStateKey アカウント状態のキャッシュキー
+func accountStateKey(userID int64) string {
+	return "account_state:" + strconv.FormatInt(userID, 10)
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"app-template/internal/entity"
+	"app-template/internal/repository"
+	"app-template/pkg/auth"
+)
+
+// maxAPIKeysPerUser ユーザーあたりの有効なAPIキーの上限
+const maxAPIKeysPerUser = 20
+
+// APIキーのエラー
+var (
+	ErrAPIKeyNotFound      = errors.New("api key not found")
+	ErrAPIKeyInvalidScope  = errors.New("api key scope is unknown or not granted to your role")
+	ErrAPIKeyInvalidExpiry = errors.New("api key expiry must be in the future")
+	ErrAPIKeyLimitExceeded = errors.New("too many api keys; revoke unused keys first")
+)
+
+// APIKeyUseCase APIキーユースケースのインターフェース
+// Authenticateにより認証器チェーンに組み込める
+type APIKeyUseCase interface {
+	auth.Authenticator
+	Create(ctx context.Context, userID int64, req *entity.CreateAPIKeyRequest) (*entity.CreatedAPIKey, error)
+	List(ctx context.Context, userID int64) (*entity.APIKeysResponse, error)
+	Revoke(ctx context.Context, userID, id int64) error
+}
+
+// apiKeyUseCase APIキーユースケースの実装
+type apiKeyUseCase struct {
+	apiKeyRepo repository.APIKeyRepository
+	userRepo   repository.UserRepository
+}
+
+// NewAPIKeyUseCase APIキーユースケースの新しいインスタンスを作成
+func NewAPIKeyUseCase(apiKeyRepo repository.APIKeyRepository, userRepo repository.UserRepository) APIKeyUseCase {
+	return &apiKeyUseCase{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// Create APIキーを発行（平文のキーは戻り値でのみ返す）
+func (u *apiKeyUseCase) Create(ctx context.Context, userID int64, req *entity.CreateAPIKeyRequest) (*entity.CreatedAPIKey, error) {
+	user, err := u.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes, err := normalizeScopes(user.Role, req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrAPIKeyInvalidExpiry
+	}
+
+	existing, err := u.apiKeyRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	if len(existing) >= maxAPIKeysPerUser {
+		return nil, ErrAPIKeyLimitExceeded
+	}
+
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := u.apiKeyRepo.Create(ctx, &entity.APIKey{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Scopes:    scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return &entity.CreatedAPIKey{
+		APIKey: created,
+		Key:    key,
+	}, nil
+}
+
+// List ユーザーのAPIキー一覧を取得
+func (u *apiKeyUseCase) List(ctx context.Context, userID int64) (*entity.APIKeysResponse, error) {
+	keys, err := u.apiKeyRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	return &entity.APIKeysResponse{
+		APIKeys: keys,
+	}, nil
+}
+
+// Revoke APIキーを失効
+func (u *apiKeyUseCase) Revoke(ctx context.Context, userID, id int64) error {
+	if err := u.apiKeyRepo.Revoke(ctx, userID, id); err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	return nil
+}
+
+// Authenticate APIキーを検証して最終使用日時を記録
+func (u *apiKeyUseCase) Authenticate(ctx context.Context, credential string) (*auth.Principal, error) {
+	if !auth.IsAPIKey(credential) {
+		return nil, auth.ErrUnsupportedCredential
+	}
+
+	key, err := u.apiKeyRepo.GetByHash(ctx, auth.HashAPIKey(credential))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	if key == nil {
+		return nil, auth.ErrAPIKeyInvalid
+	}
+	if key.RevokedAt != nil {
+		return nil, auth.ErrAPIKeyRevoked
+	}
+	if !key.IsActive(time.Now()) {
+		return nil, auth.ErrAPIKeyExpired
+	}
+
+	// 最終使用日時の記録失敗でリクエストは拒否しない
+	if err := u.apiKeyRepo.TouchLastUsed(ctx, key.ID); err != nil {
+		log.Printf("api key %d: %v", key.ID, err)
+	}
+
+	// ロール（権限の上限）とアカウントの状態は認証ミドルウェアが現在の値で確認する
+	return &auth.Principal{
+		UserID:   key.UserID,
+		Method:   auth.MethodAPIKey,
+		Scopes:   key.Scopes,
+		APIKeyID: key.ID,
+	}, nil
+}
+
+// getUser ユーザーを取得
+func (u *apiKeyUseCase) getUser(ctx context.Context, userID int64) (*entity.User, error) {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	return user, nil
+}
+
+// normalizeScopes スコープを検証して重複を除く（ロールにない権限は付与できない）
+func normalizeScopes(role string, scopes []string) ([]string, error) {
+	seen := make(map[string]bool, len(scopes))
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !auth.IsPermission(scope) || !auth.RoleGrants(role, auth.Permission(scope)) {
+			return nil, fmt.Errorf("%w: %s", ErrAPIKeyInvalidScope, scope)
+		}
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		normalized = append(normalized, scope)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"app-template/internal/entity"
+	"app-template/internal/repository"
+	"app-template/pkg/auth"
+	"app-template/pkg/requestinfo"
+)
+
+// AuditUseCase 監査ログユースケースのインターフェース
+type AuditUseCase interface {
+	Record(ctx context.Context, entry *entity.AuditLog)
+	List(ctx context.Context, filter *entity.AuditLogFilter) (*entity.AuditLogsResponse, error)
+}
+
+// auditUseCase 監査ログユースケースの実装
+type auditUseCase struct {
+	auditRepo repository.AuditLogRepository
+}
+
+// NewAuditUseCase 監査ログユースケースの新しいインスタンスを作成
+func NewAuditUseCase(auditRepo repository.AuditLogRepository) AuditUseCase {
+	return &auditUseCase{
+		auditRepo: auditRepo,
+	}
+}
+
+// Record 監査ログを記録
+// 操作者・IP・User-Agent・リクエストIDは未指定ならコンテキストから補完する。
+// 記録の失敗で本来の操作は失敗させない
+func (u *auditUseCase) Record(ctx context.Context, entry *entity.AuditLog) {
+	if entry.ActorID == nil {
+		if principal, ok := auth.PrincipalFromContext(ctx); ok {
+			entry.ActorID = &principal.UserID
+		}
+	}
+
+	info := requestinfo.FromContext(ctx)
+	if entry.IP == "" {
+		entry.IP = info.IP
+	}
+	if entry.UserAgent == "" {
+		entry.UserAgent = info.UserAgent
+	}
+	if entry.RequestID == "" {
+		entry.RequestID = info.RequestID
+	}
+
+	// リクエストがキャンセルされても記録は残す
+	if err := u.auditRepo.Create(context.WithoutCancel(ctx), entry); err != nil {
+		log.Printf("failed to record audit log %s (request %s): %v", entry.Action, entry.RequestID, err)
+	}
+}
+
+// List 監査ログを検索
+func (u *auditUseCase) List(ctx context.Context, filter *entity.AuditLogFilter) (*entity.AuditLogsResponse, error) {
+	// デフォルト値を設定
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+	if filter.Limit > 200 {
+		filter.Limit = 200
+	}
+
+	logs, pagination, err := u.auditRepo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+
+	return &entity.AuditLogsResponse{
+		AuditLogs:  logs,
+		Pagination: pagination,
+	}, nil
+}
+
+// auditUserID 監査ログ用にユーザーIDをポインタへ変換
+func auditUserID(id int64) *int64 {
+	return &id
+}
@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+
+	"app-template/internal/entity"
+	"app-template/internal/repository"
+	"app-template/pkg/imaging"
+	"app-template/pkg/storage"
+)
+
+// AvatarSizes 保存するアバター画像の大きさ（正方形の一辺、px。小さい順）
+var AvatarSizes = []int{64, 128, 256}
+
+const (
+	// MaxAvatarBytes アップロードできるアバター画像のファイルサイズの上限
+	MaxAvatarBytes = 5 << 20
+	// maxAvatarPixels 展開する画像の画素数の上限（小さなファイルで巨大な画像を展開させる攻撃を防ぐ）
+	maxAvatarPixels = 40_000_000
+	// avatarJPEGQuality JPEG で保存するときの品質
+	avatarJPEGQuality = 90
+)
+
+var (
+	ErrAvatarUnsupportedType = errors.New("avatar must be a JPEG, PNG or GIF image")
+	ErrAvatarInvalidImage    = errors.New("avatar image could not be decoded")
+	ErrAvatarTooLarge        = errors.New("avatar image is too large")
+	ErrAvatarNotFound        = errors.New("avatar not found")
+)
+
+// AvatarUseCase アバター画像ユースケースのインターフェース
+type AvatarUseCase interface {
+	// Upload 画像を正方形に切り抜いて AvatarSizes の大きさで保存し、ユーザーのアバターを置き換える
+	Upload(ctx context.Context, userID int64, data []byte) (*entity.User, error)
+	// Delete ユーザーのアバターを削除
+	Delete(ctx context.Context, userID int64) (*entity.User, error)
+	// Open size 以上で最も小さい大きさの画像を開く（size が0以下なら最大の大きさ。呼び出し側でCloseする）
+	Open(ctx context.Context, userID int64, size int) (io.ReadCloser, *storage.ObjectInfo, error)
+}
+
+// avatarUseCase アバター画像ユースケースの実装
+type avatarUseCase struct {
+	userRepo repository.UserRepository
+	store    storage.Storage
+	audit    AuditUseCase
+}
+
+// NewAvatarUseCase アバター画像ユースケースの新しいインスタンスを作成
+func NewAvatarUseCase(userRepo repository.UserRepository, store storage.Storage, audit AuditUseCase) AvatarUseCase {
+	return &avatarUseCase{
+		userRepo: userRepo,
+		store:    store,
+		audit:    audit,
+	}
+}
+
+// Upload 形式は内容から判定し、読み込んだ画素から再エンコードする（EXIFなどのメタデータは保存しない）
+// JPEG は JPEG、PNG・GIF は透過を保つため PNG で保存する
+func (u *avatarUseCase) Upload(ctx context.Context, userID int64, data []byte) (*entity.User, error) {
+	if len(data) > MaxAvatarBytes {
+		return nil, ErrAvatarTooLarge
+	}
+	contentType, err := imaging.Sniff(data)
+	if err != nil {
+		return nil, ErrAvatarUnsupportedType
+	}
+	img, err := imaging.Decode(data, maxAvatarPixels)
+	if errors.Is(err, imaging.ErrTooLarge) {
+		return nil, ErrAvatarTooLarge
+	}
+	if err != nil {
+		return nil, ErrAvatarInvalidImage
+	}
+
+	existingUser, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if existingUser == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	// 版ごとに別のキーに保存し、ユーザーの版を切り替えてから古い版を削除する
+	version, err := newAvatarVersion()
+	if err != nil {
+		return nil, err
+	}
+	square := imaging.CropSquare(img)
+	if contentType != imaging.TypeJPEG {
+		contentType = imaging.TypePNG
+	}
+	for _, size := range AvatarSizes {
+		encoded, err := encodeAvatar(imaging.Resize(square, size, size), contentType)
+		if err != nil {
+			return nil, err
+		}
+		key := avatarKey(userID, version, size)
+		if err := u.store.Put(ctx, key, bytes.NewReader(encoded), storage.PutOptions{ContentType: contentType}); err != nil {
+			u.deleteVersion(ctx, userID, version)
+			return nil, fmt.Errorf("failed to store avatar: %w", err)
+		}
+	}
+
+	return u.replace(ctx, existingUser, version)
+}
+
+// Delete ユーザーのアバターを未設定に戻す
+func (u *avatarUseCase) Delete(ctx context.Context, userID int64) (*entity.User, error) {
+	existingUser, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if existingUser == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	if existingUser.AvatarVersion == "" {
+		return nil, ErrAvatarNotFound
+	}
+
+	return u.replace(ctx, existingUser, "")
+}
+
+// Open 保存済みの大きさのうち、size 以上で最も小さいものを開く
+func (u *avatarUseCase) Open(ctx context.Context, userID int64, size int) (io.ReadCloser, *storage.ObjectInfo, error) {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || user.AvatarVersion == "" {
+		return nil, nil, ErrAvatarNotFound
+	}
+
+	selected := AvatarSizes[len(AvatarSizes)-1]
+	if size > 0 {
+		for _, candidate := range AvatarSizes {
+			if candidate >= size {
+				selected = candidate
+				break
+			}
+		}
+	}
+
+	body, info, err := u.store.Get(ctx, avatarKey(userID, user.AvatarVersion, selected))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrAvatarNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open avatar: %w", err)
+	}
+	return body, info, nil
+}
+
+// replace ユーザーのアバターの版を切り替えて監査ログを記録し、以前の版の画像を削除する
+func (u *avatarUseCase) replace(ctx context.Context, existingUser *entity.User, version string) (*entity.User, error) {
+	updatedUser, err := u.userRepo.UpdateAvatar(ctx, existingUser.ID, version)
+	if err != nil {
+		if version != "" {
+			u.deleteVersion(ctx, existingUser.ID, version)
+		}
+		if err.Error() == "user not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update avatar: %w", err)
+	}
+
+	if changes := entity.DiffUser(existingUser, updatedUser); len(changes) > 0 {
+		u.audit.Record(ctx, &entity.AuditLog{
+			Action:       entity.AuditActionUserUpdate,
+			TargetUserID: auditUserID(existingUser.ID),
+			Changes:      changes,
+		})
+	}
+
+	if existingUser.AvatarVersion != "" {
+		u.deleteVersion(ctx, existingUser.ID, existingUser.AvatarVersion)
+	}
+
+	return updatedUser, nil
+}
+
+// deleteVersion 版の画像をすべて削除（失敗しても処理は続け、ログに残す）
+func (u *avatarUseCase) deleteVersion(ctx context.Context, userID int64, version string) {
+	for _, size := range AvatarSizes {
+		key := avatarKey(userID, version, size)
+		if err := u.store.Delete(ctx, key); err != nil {
+			log.Printf("failed to delete avatar %s: %v", key, err)
+		}
+	}
+}
+
+// avatarKey アバター画像のストレージのキー
+func avatarKey(userID int64, version string, size int) string {
+	return fmt.Sprintf("avatars/%d/%s/%d", userID, version, size)
+}
+
+// newAvatarVersion ランダムな版を生成
+func newAvatarVersion() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate avatar version: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// encodeAvatar 画像を指定した形式でエンコード
+func encodeAvatar(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if contentType == imaging.TypeJPEG {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: avatarJPEGQuality})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode avatar: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,267 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+
+	"app-template/internal/entity"
+	"app-template/pkg/storage"
+)
+
+// exifMarker テスト画像の EXIF に埋め込む位置情報（保存した画像に残ってはならない）
+const exifMarker = "GPS 35.6812N 139.7671E"
+
+// avatarFixture メモリのストレージに保存するアバター画像ユースケース
+type avatarFixture struct {
+	uc    AvatarUseCase
+	users *memUserRepo
+	store storage.Storage
+	audit *memAudit
+	user  *entity.User
+}
+
+func newAvatarFixture(t *testing.T) *avatarFixture {
+	t.Helper()
+	users := newMemUserRepo()
+	user, err := users.Create(context.Background(), &entity.User{Email: "alice@example.com", Name: "Alice"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	store := storage.NewMemory(storage.NewURLSigner("secret", storage.DefaultDownloadPath))
+	audit := &memAudit{}
+	return &avatarFixture{
+		uc:    NewAvatarUseCase(users, store, audit),
+		users: users,
+		store: store,
+		audit: audit,
+		user:  user,
+	}
+}
+
+// stored 保存されているオブジェクトのキーと内容
+func (f *avatarFixture) stored(t *testing.T) map[string][]byte {
+	t.Helper()
+	ctx := context.Background()
+	objects, err := f.store.List(ctx, "avatars/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	result := make(map[string][]byte, len(objects))
+	for _, object := range objects {
+		body, _, err := f.store.Get(ctx, object.Key)
+		if err != nil {
+			t.Fatalf("Get %s: %v", object.Key, err)
+		}
+		data, _ := io.ReadAll(body)
+		body.Close()
+		result[object.Key] = data
+	}
+	return result
+}
+
+// halfRed 左半分が赤、右半分が青の w×h の画像
+func halfRed(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+// jpegWithExif Orientation と位置情報を含む EXIF（APP1）付きの JPEG
+func jpegWithExif(t *testing.T, img image.Image, orientation uint16) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+	data := buf.Bytes()
+
+	// IFD0 に Orientation（SHORT）だけを持つビッグエンディアンの TIFF
+	tiff := []byte{'M', 'M', 0, 0x2A, 0, 0, 0, 8, 0, 1, 0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint16(tiff[18:], orientation)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	segment = append(segment, exifMarker...)
+
+	app1 := binary.BigEndian.AppendUint16([]byte{0xFF, 0xE1}, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+// isRed JPEG の誤差を許して赤とみなせるか
+func isRed(c color.Color) bool {
+	r, _, b, _ := c.RGBA()
+	return r>>8 > 200 && b>>8 < 60
+}
+
+func TestAvatarUploadStripsExifAndStoresEverySize(t *testing.T) {
+	f := newAvatarFixture(t)
+	// 横長の画像を「時計回りに90度回転して表示」と指定する
+	data := jpegWithExif(t, halfRed(300, 200), 6)
+	if !bytes.Contains(data, []byte(exifMarker)) {
+		t.Fatal("test image has no EXIF")
+	}
+
+	user, err := f.uc.Upload(context.Background(), f.user.ID, data)
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if user.AvatarVersion == "" {
+		t.Fatal("avatar version was not set")
+	}
+
+	stored := f.stored(t)
+	if len(stored) != len(AvatarSizes) {
+		t.Fatalf("stored %d objects, want %d", len(stored), len(AvatarSizes))
+	}
+	for _, size := range AvatarSizes {
+		key := avatarKey(f.user.ID, user.AvatarVersion, size)
+		encoded, ok := stored[key]
+		if !ok {
+			t.Fatalf("%s was not stored", key)
+		}
+		if bytes.Contains(encoded, []byte("Exif")) || bytes.Contains(encoded, []byte(exifMarker)) {
+			t.Errorf("%s still contains EXIF metadata", key)
+		}
+
+		info, err := f.store.Stat(context.Background(), key)
+		if err != nil || info.ContentType != "image/jpeg" {
+			t.Errorf("%s content type = %v, %v", key, info, err)
+		}
+		img, err := jpeg.Decode(bytes.NewReader(encoded))
+		if err != nil {
+			t.Fatalf("decode %s: %v", key, err)
+		}
+		if b := img.Bounds(); b.Dx() != size || b.Dy() != size {
+			t.Errorf("%s size = %dx%d, want %dx%d", key, b.Dx(), b.Dy(), size, size)
+		}
+		// 回転を反映してから切り抜くため、左半分の赤は上半分になる
+		if !isRed(img.At(size/2, size/8)) || isRed(img.At(size/2, size-1-size/8)) {
+			t.Errorf("%s was not rotated by the EXIF orientation", key)
+		}
+	}
+}
+
+func TestAvatarUploadConvertsToPNG(t *testing.T) {
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, halfRed(100, 40), nil); err != nil {
+		t.Fatalf("gif.Encode: %v", err)
+	}
+
+	f := newAvatarFixture(t)
+	user, err := f.uc.Upload(context.Background(), f.user.ID, gifData.Bytes())
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+
+	body, info, err := f.uc.Open(context.Background(), f.user.ID, 100)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer body.Close()
+	if want := avatarKey(f.user.ID, user.AvatarVersion, 128); info.Key != want || info.ContentType != "image/png" {
+		t.Errorf("opened %s (%s), want %s (image/png)", info.Key, info.ContentType, want)
+	}
+	img, err := png.Decode(body)
+	if err != nil {
+		t.Fatalf("png.Decode: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 128 || b.Dy() != 128 {
+		t.Errorf("size = %dx%d, want 128x128", b.Dx(), b.Dy())
+	}
+}
+
+func TestAvatarUploadRejects(t *testing.T) {
+	// 幅・高さだけを宣言する PNG（小さなファイルで巨大な画像を展開させる攻撃）
+	ihdr := []byte("IHDR\x00\x00\x27\x10\x00\x00\x27\x10\x08\x06\x00\x00\x00")
+	bomb := append([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0d"), ihdr...)
+	bomb = binary.BigEndian.AppendUint32(bomb, crc32.ChecksumIEEE(ihdr))
+
+	valid := jpegWithExif(t, halfRed(64, 64), 1)
+	oversized := append(bytes.Clone(valid), make([]byte, MaxAvatarBytes)...)
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"text", []byte("this is not an image"), ErrAvatarUnsupportedType},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`), ErrAvatarUnsupportedType},
+		{"empty", nil, ErrAvatarUnsupportedType},
+		{"oversized file", oversized, ErrAvatarTooLarge},
+		{"too many pixels", bomb, ErrAvatarTooLarge},
+		{"corrupt jpeg", valid[:len(valid)/2], ErrAvatarInvalidImage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAvatarFixture(t)
+			if _, err := f.uc.Upload(context.Background(), f.user.ID, tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("Upload err = %v, want %v", err, tt.want)
+			}
+			if stored := f.stored(t); len(stored) != 0 {
+				t.Errorf("stored %d objects for a rejected upload", len(stored))
+			}
+			if user, _ := f.users.GetByID(context.Background(), f.user.ID); user.AvatarVersion != "" {
+				t.Errorf("avatar version = %q, want unset", user.AvatarVersion)
+			}
+		})
+	}
+}
+
+func TestAvatarUploadReplacesPreviousVersion(t *testing.T) {
+	f := newAvatarFixture(t)
+	ctx := context.Background()
+	data := jpegWithExif(t, halfRed(64, 64), 1)
+
+	first, err := f.uc.Upload(ctx, f.user.ID, data)
+	if err != nil {
+		t.Fatalf("first Upload: %v", err)
+	}
+	second, err := f.uc.Upload(ctx, f.user.ID, data)
+	if err != nil {
+		t.Fatalf("second Upload: %v", err)
+	}
+	if first.AvatarVersion == second.AvatarVersion {
+		t.Fatal("version did not change")
+	}
+
+	// 以前の版の画像は削除する
+	stored := f.stored(t)
+	if len(stored) != len(AvatarSizes) {
+		t.Errorf("stored %d objects, want %d", len(stored), len(AvatarSizes))
+	}
+	for _, size := range AvatarSizes {
+		if _, ok := stored[avatarKey(f.user.ID, first.AvatarVersion, size)]; ok {
+			t.Errorf("previous version of size %d was not deleted", size)
+		}
+	}
+	if got := f.audit.actions(); len(got) != 2 {
+		t.Errorf("audit actions = %v, want 2 updates", got)
+	}
+
+	if _, err := f.uc.Delete(ctx, f.user.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if stored := f.stored(t); len(stored) != 0 {
+		t.Errorf("stored %d objects after Delete", len(stored))
+	}
+	if _, _, err := f.uc.Open(ctx, f.user.ID, 0); !errors.Is(err, ErrAvatarNotFound) {
+		t.Errorf("Open after Delete err = %v, want ErrAvatarNotFound", err)
+	}
+}
@@ -0,0 +1,454 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"app-template/internal/entity"
+	"app-template/internal/repository"
+	"app-template/pkg/tenant"
+)
+
+// テスト用のメモリ上のリポジトリ（テストで使うメソッドのみ実装し、それ以外は埋め込んだnilのインターフェースでpanicする）
+
+type memUserRepo struct {
+	repository.UserRepository
+
+	mu     sync.Mutex
+	nextID int64
+	users  map[int64]*entity.User
+}
+
+func newMemUserRepo() *memUserRepo {
+	return &memUserRepo{users: make(map[int64]*entity.User)}
+}
+
+func (r *memUserRepo) Create(ctx context.Context, user *entity.User) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if strings.EqualFold(u.Email, user.Email) {
+			return nil, errors.New("duplicate email")
+		}
+	}
+	r.nextID++
+	created := *user
+	created.ID = r.nextID
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	r.users[created.ID] = &created
+	copied := created
+	return &copied, nil
+}
+
+// GetByID 個人のテナントでは本人以外を見つからないものとして扱う（組織のテナントは絞り込まない）
+func (r *memUserRepo) GetByID(ctx context.Context, id int64) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := tenant.FromContext(ctx); ok && t.IsPersonal() && t.UserID != id {
+		return nil, nil
+	}
+	if u, ok := r.users[id]; ok {
+		copied := *u
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *memUserRepo) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if strings.EqualFold(u.Email, email) {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memUserRepo) UpdateAvatar(ctx context.Context, id int64, version string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+	u.AvatarVersion = version
+	u.AvatarURL = entity.AvatarPath(id, version)
+	u.UpdatedAt = time.Now()
+	copied := *u
+	return &copied, nil
+}
+
+type memIdentityRepo struct {
+	mu         sync.Mutex
+	nextID     int64
+	identities []*entity.UserIdentity
+}
+
+func (r *memIdentityRepo) Create(ctx context.Context, identity *entity.UserIdentity) (*entity.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, i := range r.identities {
+		if i.Provider == identity.Provider && (i.Subject == identity.Subject || i.UserID == identity.UserID) {
+			return nil, errors.New("duplicate identity")
+		}
+	}
+	r.nextID++
+	created := *identity
+	created.ID = r.nextID
+	r.identities = append(r.identities, &created)
+	copied := created
+	return &copied, nil
+}
+
+func (r *memIdentityRepo) GetByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, i := range r.identities {
+		if i.Provider == provider && i.Subject == subject {
+			copied := *i
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memIdentityRepo) ListByUserID(ctx context.Context, userID int64) ([]*entity.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []*entity.UserIdentity
+	for _, i := range r.identities {
+		if i.UserID == userID {
+			copied := *i
+			list = append(list, &copied)
+		}
+	}
+	return list, nil
+}
+
+func (r *memIdentityRepo) Delete(ctx context.Context, userID int64, provider string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for n, i := range r.identities {
+		if i.UserID == userID && i.Provider == provider {
+			r.identities = append(r.identities[:n], r.identities[n+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *memUserRepo) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return errors.New("user not found")
+	}
+	u.Password = passwordHash
+	return nil
+}
+
+func (r *memUserRepo) Delete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[id]; !ok {
+		return errors.New("user not found")
+	}
+	// 組織のテナント内ではメンバーから外すだけのためユーザーは残る
+	if t, ok := tenant.FromContext(ctx); ok && !t.IsPersonal() {
+		return nil
+	}
+	delete(r.users, id)
+	return nil
+}
+
+// memOrgRepo 所属する組織の一覧だけを返す組織リポジトリ
+type memOrgRepo struct {
+	repository.OrganizationRepository
+
+	memberships map[int64][]*entity.OrganizationMembership
+}
+
+func (r *memOrgRepo) ListByUserID(ctx context.Context, userID int64) ([]*entity.OrganizationMembership, error) {
+	return r.memberships[userID], nil
+}
+
+// memMFARepo 二要素認証を設定していないユーザーとして振る舞う
+type memMFARepo struct {
+	repository.MFARepository
+}
+
+func (memMFARepo) Methods(ctx context.Context, userID int64) ([]string, error) {
+	return nil, nil
+}
+
+// memTOTPRepo TOTPとリカバリーコードを保持する二要素認証リポジトリ
+type memTOTPRepo struct {
+	repository.MFARepository
+
+	mu       sync.Mutex
+	secrets  map[int64]*entity.UserTOTP
+	recovery map[int64]map[string]bool
+}
+
+func newMemTOTPRepo() *memTOTPRepo {
+	return &memTOTPRepo{secrets: make(map[int64]*entity.UserTOTP), recovery: make(map[int64]map[string]bool)}
+}
+
+// enable TOTPを有効にしてリカバリーコードを保存
+func (r *memTOTPRepo) enable(userID int64, secret string, recoveryCodes ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.secrets[userID] = &entity.UserTOTP{UserID: userID, Secret: secret, EnabledAt: &now}
+	r.recovery[userID] = make(map[string]bool)
+	for _, code := range recoveryCodes {
+		r.recovery[userID][hashRecoveryCode(code)] = true
+	}
+}
+
+func (r *memTOTPRepo) GetTOTP(ctx context.Context, userID int64) (*entity.UserTOTP, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := r.secrets[userID]; ok {
+		copied := *t
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *memTOTPRepo) ConsumeTOTPStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.secrets[userID]
+	if !ok || step <= t.LastUsedStep {
+		return false, nil
+	}
+	t.LastUsedStep = step
+	return true, nil
+}
+
+func (r *memTOTPRepo) ConsumeRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.recovery[userID][codeHash] {
+		return false, nil
+	}
+	delete(r.recovery[userID], codeHash)
+	return true, nil
+}
+
+func (r *memTOTPRepo) Methods(ctx context.Context, userID int64) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := r.secrets[userID]; ok && t.Enabled() {
+		return []string{entity.MFAMethodTOTP}, nil
+	}
+	return nil, nil
+}
+
+type memCredentialRepo struct {
+	mu          sync.Mutex
+	nextID      int64
+	credentials []*entity.WebAuthnCredential
+}
+
+func (r *memCredentialRepo) Create(ctx context.Context, credential *entity.WebAuthnCredential) (*entity.WebAuthnCredential, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	created := *credential
+	created.ID = r.nextID
+	created.CreatedAt = time.Now()
+	r.credentials = append(r.credentials, &created)
+	copied := created
+	return &copied, nil
+}
+
+func (r *memCredentialRepo) GetByCredentialID(ctx context.Context, credentialID []byte) (*entity.WebAuthnCredential, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.credentials {
+		if string(c.CredentialID) == string(credentialID) {
+			copied := *c
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memCredentialRepo) ListByUserID(ctx context.Context, userID int64) ([]*entity.WebAuthnCredential, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []*entity.WebAuthnCredential
+	for _, c := range r.credentials {
+		if c.UserID == userID {
+			copied := *c
+			list = append(list, &copied)
+		}
+	}
+	return list, nil
+}
+
+func (r *memCredentialRepo) UpdateAfterLogin(ctx context.Context, id int64, data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.credentials {
+		if c.ID == id {
+			now := time.Now()
+			c.Data = data
+			c.LastUsedAt = &now
+			return nil
+		}
+	}
+	return errors.New("passkey not found")
+}
+
+func (r *memCredentialRepo) Delete(ctx context.Context, userID, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for n, c := range r.credentials {
+		if c.ID == id && c.UserID == userID {
+			r.credentials = append(r.credentials[:n], r.credentials[n+1:]...)
+			return nil
+		}
+	}
+	return errors.New("passkey not found")
+}
+
+type memWebhookRepo struct {
+	repository.WebhookRepository
+
+	mu         sync.Mutex
+	nextID     int64
+	webhooks   map[int64]*entity.Webhook
+	deliveries map[int64]*entity.WebhookDelivery
+}
+
+func newMemWebhookRepo() *memWebhookRepo {
+	return &memWebhookRepo{
+		webhooks:   make(map[int64]*entity.Webhook),
+		deliveries: make(map[int64]*entity.WebhookDelivery),
+	}
+}
+
+func (r *memWebhookRepo) Create(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	created := *webhook
+	created.ID = r.nextID
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	r.webhooks[created.ID] = &created
+	copied := created
+	return &copied, nil
+}
+
+func (r *memWebhookRepo) GetByID(ctx context.Context, id int64) (*entity.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if w, ok := r.webhooks[id]; ok {
+		copied := *w
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *memWebhookRepo) ListActiveByEvent(ctx context.Context, event string) ([]*entity.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []*entity.Webhook
+	for _, w := range r.webhooks {
+		if w.Active && w.Subscribes(event) {
+			copied := *w
+			list = append(list, &copied)
+		}
+	}
+	return list, nil
+}
+
+func (r *memWebhookRepo) CreateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) (*entity.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	created := *delivery
+	created.ID = r.nextID
+	created.CreatedAt = time.Now()
+	r.deliveries[created.ID] = &created
+	copied := created
+	return &copied, nil
+}
+
+func (r *memWebhookRepo) GetDelivery(ctx context.Context, id int64) (*entity.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if d, ok := r.deliveries[id]; ok {
+		copied := *d
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *memWebhookRepo) UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.deliveries[delivery.ID]; !ok {
+		return errors.New("webhook delivery not found")
+	}
+	updated := *delivery
+	r.deliveries[delivery.ID] = &updated
+	return nil
+}
+
+func (r *memWebhookRepo) DeliveredWebhookIDs(ctx context.Context, eventID string) (map[int64]bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := map[int64]bool{}
+	for _, d := range r.deliveries {
+		if d.EventID == eventID {
+			ids[d.WebhookID] = true
+		}
+	}
+	return ids, nil
+}
+
+// memAudit 記録された監査ログを保持する
+type memAudit struct {
+	AuditUseCase
+
+	mu      sync.Mutex
+	entries []*entity.AuditLog
+}
+
+func (a *memAudit) Record(ctx context.Context, entry *entity.AuditLog) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, entry)
+}
+
+func (a *memAudit) actions() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	actions := make([]string, 0, len(a.entries))
+	for _, entry := range a.entries {
+		actions = append(actions, entry.Action)
+	}
+	return actions
+}
+
+// memSessions 失効させたユーザーを記録する
+type memSessions struct {
+	revoked []int64
+}
+
+func (s *memSessions) RevokeUser(ctx context.Context, userID int64) error {
+	s.revoked = append(s.revoked, userID)
+	return nil
+}
@@ -0,0 +1,443 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"app-template/internal/entity"
+	"app-template/internal/repository"
+	"app-template/pkg/auth"
+	"app-template/pkg/tenant"
+)
+
+// DefaultInvitationTTL 招待リンクの既定の有効期間
+const DefaultInvitationTTL = 7 * 24 * time.Hour
+
+// 招待のエラー
+var (
+	ErrInvitationNotFound        = errors.New("invitation not found")
+	ErrInvitationInvalid         = errors.New("invitation token is invalid")
+	ErrInvitationExpired         = errors.New("invitation has expired")
+	ErrInvitationNotPending      = errors.New("invitation has already been responded to")
+	ErrInvitationForbidden       = errors.New("not allowed to manage invitations")
+	ErrInvitationPending         = errors.New("a pending invitation already exists for this email")
+	ErrInvitationUserExists      = errors.New("a user with this email already exists")
+	ErrInvitationNoOrganization  = errors.New("organization_role requires an organization")
+	ErrInvitationLoginRequired   = errors.New("sign in as the invited user to accept this invitation")
+	ErrInvitationEmailMismatch   = errors.New("invitation was sent to a different email")
+	ErrInvitationAccountRequired = errors.New("name and password are required to create an account")
+)
+
+// InvitationOptions 招待の設定
+type InvitationOptions struct {
+	// TTL 有効期間を指定しない招待の有効期間
+	TTL time.Duration
+	// AcceptURL 招待を承諾する画面のURL（token クエリパラメーターを付けてレスポンスに含める）
+	AcceptURL string
+}
+
+// InvitationUseCase 招待ユースケースのインターフェース
+type InvitationUseCase interface {
+	Create(ctx context.Context, actorID int64, req *entity.CreateInvitationRequest) (*entity.CreatedInvitation, error)
+	List(ctx context.Context, params *entity.InvitationListParams) (*entity.InvitationsResponse, error)
+	Revoke(ctx context.Context, id int64) error
+	Preview(ctx context.Context, token string) (*entity.InvitationPreview, error)
+	Accept(ctx context.Context, userID int64, req *entity.AcceptInvitationRequest) (*entity.AuthResponse, error)
+	Decline(ctx context.Context, token string) error
+}
+
+// invitationUseCase 招待ユースケースの実装
+type invitationUseCase struct {
+	invitationRepo repository.InvitationRepository
+	orgRepo        repository.OrganizationRepository
+	userRepo       repository.UserRepository
+	users          UserUseCase
+	tokens         *auth.JWTManager
+	audit          AuditUseCase
+	opts           InvitationOptions
+}
+
+// NewInvitationUseCase 招待ユースケースの新しいインスタンスを作成
+func NewInvitationUseCase(
+	invitationRepo repository.InvitationRepository,
+	orgRepo repository.OrganizationRepository,
+	userRepo repository.UserRepository,
+	users UserUseCase,
+	tokens *auth.JWTManager,
+	audit AuditUseCase,
+	opts InvitationOptions,
+) InvitationUseCase {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultInvitationTTL
+	}
+	return &invitationUseCase{
+		invitationRepo: invitationRepo,
+		orgRepo:        orgRepo,
+		userRepo:       userRepo,
+		users:          users,
+		tokens:         tokens,
+		audit:          audit,
+		opts:           opts,
+	}
+}
+
+// Create 招待を作成し、招待リンクのトークンを発行
+// テナントを指定した場合はその組織への招待（owner / admin のみ）、指定しない場合は管理者のみ作成できる
+func (u *invitationUseCase) Create(ctx context.Context, actorID int64, req *entity.CreateInvitationRequest) (*entity.CreatedInvitation, error) {
+	principal, t, err := u.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	role := req.Role
+	if role == "" {
+		role = auth.RoleUser
+	}
+	if role != auth.RoleUser && !principal.Has(auth.PermUsersAdmin) {
+		return nil, ErrInvitationForbidden
+	}
+
+	email := strings.TrimSpace(req.Email)
+	existing, err := u.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+
+	invitation := &entity.Invitation{
+		Email:     email,
+		Role:      role,
+		InvitedBy: auditUserID(actorID),
+	}
+	if t != nil {
+		orgRole := req.OrganizationRole
+		if orgRole == "" {
+			orgRole = entity.OrgRoleMember
+		}
+		if orgRole == entity.OrgRoleOwner && t.Role != entity.OrgRoleOwner && !principal.Has(auth.PermUsersAdmin) {
+			return nil, ErrInvitationForbidden
+		}
+		if existing != nil {
+			member, err := u.orgRepo.GetMemberRole(ctx, t.ID, existing.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get organization member: %w", err)
+			}
+			if member != "" {
+				return nil, ErrOrganizationMemberExists
+			}
+		}
+		invitation.OrganizationID = &t.ID
+		invitation.OrganizationRole = orgRole
+	} else {
+		if req.OrganizationRole != "" {
+			return nil, ErrInvitationNoOrganization
+		}
+		// 組織への招待でなければ、既存のユーザーに対してできることはない
+		if existing != nil {
+			return nil, ErrInvitationUserExists
+		}
+	}
+
+	pending, err := u.invitationRepo.GetPending(ctx, email, invitation.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check pending invitations: %w", err)
+	}
+	if pending != nil {
+		return nil, ErrInvitationPending
+	}
+
+	ttl := u.opts.TTL
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+	invitation.ExpiresAt = time.Now().Add(ttl).Truncate(time.Second)
+
+	created, err := u.invitationRepo.Create(ctx, invitation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	token, _, err := u.tokens.GenerateInvitation(created.ID, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+
+	u.audit.Record(ctx, &entity.AuditLog{
+		Action:   entity.AuditActionInvitationCreate,
+		Metadata: invitationAuditMetadata(created),
+	})
+
+	return &entity.CreatedInvitation{
+		Invitation: created,
+		Token:      token,
+		URL:        u.acceptURL(token),
+	}, nil
+}
+
+// List 招待の一覧を取得（テナントを指定した場合はその組織への招待のみ）
+func (u *invitationUseCase) List(ctx context.Context, params *entity.InvitationListParams) (*entity.InvitationsResponse, error) {
+	if _, _, err := u.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	// デフォルト値を設定
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 20
+	}
+	if params.Limit > 100 {
+		params.Limit = 100
+	}
+
+	invitations, pagination, err := u.invitationRepo.List(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+
+	return &entity.InvitationsResponse{
+		Invitations: invitations,
+		Pagination:  pagination,
+	}, nil
+}
+
+// Revoke 未回答の招待を取り消す（招待リンクは使えなくなる）
+func (u *invitationUseCase) Revoke(ctx context.Context, id int64) error {
+	if _, _, err := u.authorize(ctx); err != nil {
+		return err
+	}
+
+	invitation, err := u.invitationRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get invitation: %w", err)
+	}
+	if invitation == nil {
+		return ErrInvitationNotFound
+	}
+	if invitation.Status != entity.InvitationStatusPending {
+		return ErrInvitationNotPending
+	}
+
+	if err := u.respond(ctx, invitation, entity.InvitationStatusRevoked, nil); err != nil {
+		return err
+	}
+
+	u.audit.Record(ctx, &entity.AuditLog{
+		Action:   entity.AuditActionInvitationRevoke,
+		Metadata: invitationAuditMetadata(invitation),
+	})
+
+	return nil
+}
+
+// Preview 招待リンクの内容を取得（承諾画面の表示用）
+func (u *invitationUseCase) Preview(ctx context.Context, token string) (*entity.InvitationPreview, error) {
+	invitation, err := u.pending(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &entity.InvitationPreview{
+		Email:     invitation.Email,
+		ExpiresAt: invitation.ExpiresAt,
+	}
+	if invitation.OrganizationID != nil {
+		org, err := u.orgRepo.GetByID(ctx, *invitation.OrganizationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get organization: %w", err)
+		}
+		if org != nil {
+			preview.OrganizationName = org.Name
+		}
+	}
+
+	existing, err := u.userRepo.GetByEmail(ctx, invitation.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+	preview.AccountExists = existing != nil
+
+	return preview, nil
+}
+
+// Accept 招待を承諾する
+// 招待先のメールアドレスのアカウントがなければ Register と同じくユーザーを作成してトークンを発行し、
+// あればそのユーザーとしてログインしている場合に限り組織のメンバーに追加する（userIDは未認証なら0）
+func (u *invitationUseCase) Accept(ctx context.Context, userID int64, req *entity.AcceptInvitationRequest) (*entity.AuthResponse, error) {
+	invitation, err := u.pending(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := u.userRepo.GetByEmail(ctx, invitation.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+
+	var response *entity.AuthResponse
+	if existing != nil {
+		if userID == 0 {
+			return nil, ErrInvitationLoginRequired
+		}
+		if userID != existing.ID {
+			return nil, ErrInvitationEmailMismatch
+		}
+		response = &entity.AuthResponse{User: existing}
+	} else {
+		if strings.TrimSpace(req.Name) == "" || req.Password == "" {
+			return nil, ErrInvitationAccountRequired
+		}
+		response, err = u.users.RegisterWithRole(ctx, &entity.CreateUserRequest{
+			Email:    invitation.Email,
+			Name:     strings.TrimSpace(req.Name),
+			Password: req.Password,
+		}, invitation.Role)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.joinOrganization(ctx, invitation, response.User.ID); err != nil {
+		return nil, err
+	}
+	if err := u.respond(ctx, invitation, entity.InvitationStatusAccepted, &response.User.ID); err != nil {
+		return nil, err
+	}
+
+	u.audit.Record(ctx, &entity.AuditLog{
+		Action:       entity.AuditActionInvitationAccept,
+		ActorID:      auditUserID(response.User.ID),
+		TargetUserID: auditUserID(response.User.ID),
+		Metadata:     invitationAuditMetadata(invitation),
+	})
+
+	return response, nil
+}
+
+// Decline 招待を辞退する（招待リンクを持っていれば認証は不要）
+func (u *invitationUseCase) Decline(ctx context.Context, token string) error {
+	invitation, err := u.pending(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	if err := u.respond(ctx, invitation, entity.InvitationStatusDeclined, nil); err != nil {
+		return err
+	}
+
+	u.audit.Record(ctx, &entity.AuditLog{
+		Action:   entity.AuditActionInvitationDecline,
+		Metadata: invitationAuditMetadata(invitation),
+	})
+
+	return nil
+}
+
+// authorize 招待を管理できるか確認し、主体と招待先のテナントを返す
+// テナントがあれば組織の owner / admin、なければ users:admin 権限が必要（users:admin はどの組織でも管理できる）
+func (u *invitationUseCase) authorize(ctx context.Context) (*auth.Principal, *tenant.Tenant, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, nil, ErrInvitationForbidden
+	}
+
+	t, _ := tenant.FromContext(ctx)
+	if principal.Has(auth.PermUsersAdmin) {
+		return principal, t, nil
+	}
+	if t != nil && entity.CanManageMembers(t.Role) {
+		return principal, t, nil
+	}
+	return nil, nil, ErrInvitationForbidden
+}
+
+// pending トークンを検証して未回答の招待を取得
+func (u *invitationUseCase) pending(ctx context.Context, token string) (*entity.Invitation, error) {
+	id, err := u.tokens.ParseInvitation(token)
+	if errors.Is(err, auth.ErrTokenExpired) {
+		return nil, ErrInvitationExpired
+	}
+	if err != nil {
+		return nil, ErrInvitationInvalid
+	}
+
+	invitation, err := u.invitationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	if invitation == nil {
+		return nil, ErrInvitationInvalid
+	}
+	if invitation.Status != entity.InvitationStatusPending {
+		return nil, ErrInvitationNotPending
+	}
+	if invitation.IsExpired(time.Now()) {
+		return nil, ErrInvitationExpired
+	}
+
+	return invitation, nil
+}
+
+// joinOrganization 組織への招待なら承諾したユーザーをメンバーに追加（既にメンバーなら何もしない）
+func (u *invitationUseCase) joinOrganization(ctx context.Context, invitation *entity.Invitation, userID int64) error {
+	if invitation.OrganizationID == nil {
+		return nil
+	}
+
+	role, err := u.orgRepo.GetMemberRole(ctx, *invitation.OrganizationID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get organization member: %w", err)
+	}
+	if role != "" {
+		return nil
+	}
+
+	if err := u.orgRepo.AddMember(ctx, *invitation.OrganizationID, userID, invitation.OrganizationRole); err != nil {
+		return fmt.Errorf("failed to add organization member: %w", err)
+	}
+	return nil
+}
+
+// respond 招待を回答済みにする（同時に回答された場合はErrInvitationNotPending）
+func (u *invitationUseCase) respond(ctx context.Context, invitation *entity.Invitation, status string, acceptedUserID *int64) error {
+	if err := u.invitationRepo.Respond(ctx, invitation.ID, status, acceptedUserID); err != nil {
+		if err.Error() == "invitation not found" {
+			return ErrInvitationNotPending
+		}
+		return fmt.Errorf("failed to update invitation: %w", err)
+	}
+	return nil
+}
+
+// acceptURL 承諾画面のURLにトークンを付ける（未設定なら空）
+func (u *invitationUseCase) acceptURL(token string) string {
+	if u.opts.AcceptURL == "" {
+		return ""
+	}
+	parsed, err := url.Parse(u.opts.AcceptURL)
+	if err != nil {
+		return ""
+	}
+	query := parsed.Query()
+	query.Set("token", token)
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+// invitationAuditMetadata 監査ログに記録する招待の情報
+func invitationAuditMetadata(invitation *entity.Invitation) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"invitation_id": invitation.ID,
+		"email":         invitation.Email,
+		"role":          invitation.Role,
+	}
+	if invitation.OrganizationID != nil {
+		metadata["organization_id"] = *invitation.OrganizationID
+		metadata["organization_role"] = invitation.OrganizationRole
+	}
+	return metadata
+}
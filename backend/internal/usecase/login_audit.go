@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"app-template/internal/entity"
+	"app-template/pkg/auth"
+)
+
+// recordLoginSuccess トークンを発行したログインを監査ログに記録（二要素認証のチャレンジを発行しただけでは記録しない）
+func recordLoginSuccess(ctx context.Context, audit AuditUseCase, userID int64, metadata map[string]interface{}) {
+	audit.Record(ctx, &entity.AuditLog{
+		Action:       entity.AuditActionUserLoginSuccess,
+		ActorID:      auditUserID(userID),
+		TargetUserID: auditUserID(userID),
+		Metadata:     metadata,
+	})
+}
+
+// recordLoginFailure ログイン失敗を監査ログに記録（ユーザーを特定できない場合は userID を0にして対象ユーザーなし）
+func recordLoginFailure(ctx context.Context, audit AuditUseCase, userID int64, reason string, metadata map[string]interface{}) {
+	entry := &entity.AuditLog{
+		Action:   entity.AuditActionUserLoginFailure,
+		Metadata: map[string]interface{}{"reason": reason},
+	}
+	for key, value := range metadata {
+		entry.Metadata[key] = value
+	}
+	if userID != 0 {
+		entry.TargetUserID = auditUserID(userID)
+	}
+	audit.Record(ctx, entry)
+}
+
+// recordLoginError 認証に失敗したエラーならログイン失敗として記録（内部エラーは記録しない）
+func recordLoginError(ctx context.Context, audit AuditUseCase, userID int64, err error, metadata map[string]interface{}) {
+	if reason := loginFailureReason(err); reason != "" {
+		recordLoginFailure(ctx, audit, userID, reason, metadata)
+	}
+}
+
+// loginFailureReason エラーに対応するログイン失敗の理由（認証の失敗でなければ空文字列）
+func loginFailureReason(err error) string {
+	switch {
+	case errors.Is(err, auth.ErrAccountSuspended):
+		return "account_suspended"
+	case errors.Is(err, auth.ErrAccountDisabled):
+		return "account_disabled"
+	case errors.Is(err, ErrMFAInvalidCode):
+		return "invalid_code"
+	case errors.Is(err, ErrMFANotEnabled):
+		return "mfa_not_enabled"
+	case errors.Is(err, ErrMFATooManyAttempts):
+		return "too_many_attempts"
+	case errors.Is(err, ErrMFAChallengeUsed):
+		return "challenge_used"
+	case errors.Is(err, ErrPasskeySessionInvalid):
+		return "invalid_session"
+	case errors.Is(err, ErrPasskeyVerification):
+		return "verification_failed"
+	case errors.Is(err, ErrPasskeyCloned):
+		return "cloned_authenticator"
+	case errors.Is(err, ErrOAuthEmailRequired):
+		return "email_required"
+	case errors.Is(err, ErrOAuthEmailConflict):
+		return "email_conflict"
+	case errors.Is(err, ErrRegistrationInviteOnly):
+		return "registration_invite_only"
+	case auth.IsTokenError(err):
+		return "invalid_token"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,417 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"app-template/internal/entity"
+	"app-template/internal/repository"
+	"app-template/pkg/auth"
+	"app-template/pkg/kvstore"
+	"app-template/pkg/totp"
+)
+
+const (
+	// recoveryCodeCount 発行するリカバリーコードの数
+	recoveryCodeCount = 10
+	// totpSkew 時計のずれとして許容する前後のステップ数
+	totpSkew = 1
+	// maxMFAChallengeAttempts 1つのチャレンジで試せるコードの回数
+	maxMFAChallengeAttempts = 5
+	// maxMFAUserAttempts mfaAttemptWindow の間にユーザーごとに試せるコードの回数（チャレンジを取り直しても増えない）
+	maxMFAUserAttempts = 10
+	// mfaAttemptWindow ユーザーごとの試行回数を数える期間
+	mfaAttemptWindow = 15 * time.Minute
+)
+
+// 二要素認証のエラー
+var (
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled    = errors.New("two-factor authentication enrollment not started")
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrMFAInvalidCode    = errors.New("invalid authentication code")
+	// ErrMFATooManyAttempts 6桁のコードの総当たりを防ぐため試行回数を制限する
+	ErrMFATooManyAttempts = errors.New("too many authentication attempts; try again later")
+	ErrMFAChallengeUsed   = errors.New("mfa challenge has already been used")
+)
+
+// MFAUseCase 二要素認証ユースケースのインターフェース
+type MFAUseCase interface {
+	Status(ctx context.Context, userID int64) (*entity.MFAStatusResponse, error)
+	EnrollTOTP(ctx context.Context, userID int64) (*entity.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID int64, code string) (*entity.RecoveryCodesResponse, error)
+	DisableTOTP(ctx context.Context, userID int64, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) (*entity.RecoveryCodesResponse, error)
+	Verify(ctx context.Context, req *entity.MFAVerifyRequest) (*entity.AuthResponse, error)
+}
+
+// mfaUseCase 二要素認証ユースケースの実装
+type mfaUseCase struct {
+	userRepo repository.UserRepository
+	mfaRepo  repository.MFARepository
+	// attempts 試行回数と使用済みのチャレンジ
+	attempts kvstore.Store
+	tokens   *auth.JWTManager
+	audit    AuditUseCase
+	issuer   string
+}
+
+// NewMFAUseCase 二要素認証ユースケースの新しいインスタンスを作成
+func NewMFAUseCase(userRepo repository.UserRepository, mfaRepo repository.MFARepository, attempts kvstore.Store, tokens *auth.JWTManager, audit AuditUseCase, issuer string) MFAUseCase {
+	return &mfaUseCase{
+		userRepo: userRepo,
+		mfaRepo:  mfaRepo,
+		attempts: attempts,
+		tokens:   tokens,
+		audit:    audit,
+		issuer:   issuer,
+	}
+}
+
+// Status 二要素認証の設定状況を取得
+func (u *mfaUseCase) Status(ctx context.Context, userID int64) (*entity.MFAStatusResponse, error) {
+	enabled, err := u.mfaRepo.IsTOTPEnabled(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mfa status: %w", err)
+	}
+
+	methods, err := u.mfaRepo.Methods(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mfa status: %w", err)
+	}
+
+	remaining, err := u.mfaRepo.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mfa status: %w", err)
+	}
+
+	return &entity.MFAStatusResponse{
+		TOTPEnabled:            enabled,
+		Methods:                methods,
+		RecoveryCodesRemaining: remaining,
+	}, nil
+}
+
+// EnrollTOTP TOTPの登録を開始（確認されるまで有効にならない）
+func (u *mfaUseCase) EnrollTOTP(ctx context.Context, userID int64) (*entity.TOTPEnrollment, error) {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	current, err := u.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get totp: %w", err)
+	}
+	if current != nil && current.Enabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.mfaRepo.SaveTOTPSecret(ctx, userID, secret); err != nil {
+		return nil, fmt.Errorf("failed to save totp secret: %w", err)
+	}
+
+	return &entity.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(u.issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP 認証アプリのコードで登録を確認し、リカバリーコードを発行
+func (u *mfaUseCase) ConfirmTOTP(ctx context.Context, userID int64, code string) (*entity.RecoveryCodesResponse, error) {
+	current, err := u.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get totp: %w", err)
+	}
+	if current == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if current.Enabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, ok := totp.Validate(current.Secret, code, time.Now(), totpSkew)
+	if !ok {
+		return nil, ErrMFAInvalidCode
+	}
+
+	if err := u.mfaRepo.EnableTOTP(ctx, userID, step); err != nil {
+		return nil, fmt.Errorf("failed to enable totp: %w", err)
+	}
+
+	return u.issueRecoveryCodes(ctx, userID)
+}
+
+// DisableTOTP 二要素認証を無効化（TOTPコードまたはリカバリーコードが必要）
+func (u *mfaUseCase) DisableTOTP(ctx context.Context, userID int64, code string) error {
+	if err := u.checkCode(ctx, userID, code); err != nil {
+		return err
+	}
+
+	if err := u.mfaRepo.DeleteTOTP(ctx, userID); err != nil {
+		return fmt.Errorf("failed to disable totp: %w", err)
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes リカバリーコードを再発行（既存のコードは無効になる）
+func (u *mfaUseCase) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) (*entity.RecoveryCodesResponse, error) {
+	if err := u.checkCode(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	return u.issueRecoveryCodes(ctx, userID)
+}
+
+// Verify MFAチャレンジとコードを検証して本トークンを発行（成否を監査ログに記録する）
+// チャレンジごと・ユーザーごとに試行回数を制限し、成功したチャレンジは再利用できない
+func (u *mfaUseCase) Verify(ctx context.Context, req *entity.MFAVerifyRequest) (*entity.AuthResponse, error) {
+	metadata := map[string]interface{}{"method": mfaCodeMethod(req.Code), "second_factor": true}
+
+	claims, err := u.tokens.ParseMFAChallenge(req.ChallengeToken)
+	if err != nil {
+		recordLoginError(ctx, u.audit, 0, err, metadata)
+		return nil, err
+	}
+	userID, err := claims.UserID()
+	if err != nil {
+		recordLoginError(ctx, u.audit, 0, err, metadata)
+		return nil, err
+	}
+
+	response, err := u.verify(ctx, claims, userID, req.Code)
+	if err != nil {
+		recordLoginError(ctx, u.audit, userID, err, metadata)
+		return nil, err
+	}
+
+	recordLoginSuccess(ctx, u.audit, userID, metadata)
+	return response, nil
+}
+
+// verify チャレンジのユーザーのコードを検証して本トークンを発行
+func (u *mfaUseCase) verify(ctx context.Context, claims *auth.Claims, userID int64, code string) (*entity.AuthResponse, error) {
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if _, err := u.attempts.Get(ctx, mfaChallengeUsedKey(claims.ID)); err == nil {
+		return nil, ErrMFAChallengeUsed
+	} else if !errors.Is(err, kvstore.ErrNotFound) {
+		return nil, fmt.Errorf("failed to check mfa challenge: %w", err)
+	}
+	if err := u.countAttempt(ctx, mfaChallengeAttemptsKey(claims.ID), maxMFAChallengeAttempts, ttl); err != nil {
+		return nil, err
+	}
+
+	if err := u.checkCode(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	// チャレンジ発行後に停止された場合に備えて再確認
+	if err := checkAccountStatus(user); err != nil {
+		return nil, err
+	}
+
+	// 同時に検証したリクエストのうち1つだけが本トークンを受け取る
+	if n, err := u.attempts.Incr(ctx, mfaChallengeUsedKey(claims.ID), ttl); err != nil {
+		return nil, fmt.Errorf("failed to consume mfa challenge: %w", err)
+	} else if n > 1 {
+		return nil, ErrMFAChallengeUsed
+	}
+
+	token, _, err := u.tokens.Generate(user.ID, user.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return &entity.AuthResponse{
+		User:  user,
+		Token: token,
+	}, nil
+}
+
+// mfaCodeMethod コードの種類（TOTPの桁数ならTOTP、それ以外はリカバリーコード）
+func mfaCodeMethod(code string) string {
+	if len(strings.TrimSpace(code)) == totp.Digits {
+		return entity.MFAMethodTOTP
+	}
+	return "recovery_code"
+}
+
+// checkCode 試行回数を制限して有効なTOTPコードまたは未使用のリカバリーコードか検証（成功すると試行回数を数え直す）
+func (u *mfaUseCase) checkCode(ctx context.Context, userID int64, code string) error {
+	key := mfaUserAttemptsKey(userID)
+	if err := u.countAttempt(ctx, key, maxMFAUserAttempts, mfaAttemptWindow); err != nil {
+		return err
+	}
+	if err := u.matchCode(ctx, userID, code); err != nil {
+		return err
+	}
+	if err := u.attempts.Delete(ctx, key); err != nil {
+		return fmt.Errorf("failed to reset mfa attempts: %w", err)
+	}
+	return nil
+}
+
+// countAttempt 試行回数を数え、上限を超えていればErrMFATooManyAttempts
+func (u *mfaUseCase) countAttempt(ctx context.Context, key string, limit int64, ttl time.Duration) error {
+	n, err := u.attempts.Incr(ctx, key, ttl)
+	if err != nil {
+		return fmt.Errorf("failed to count mfa attempts: %w", err)
+	}
+	if n > limit {
+		return ErrMFATooManyAttempts
+	}
+	return nil
+}
+
+// matchCode 有効なTOTPコードまたは未使用のリカバリーコードか検証
+func (u *mfaUseCase) matchCode(ctx context.Context, userID int64, code string) error {
+	current, err := u.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get totp: %w", err)
+	}
+	if current == nil || !current.Enabled() {
+		return ErrMFANotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if mfaCodeMethod(code) == entity.MFAMethodTOTP {
+		step, ok := totp.Validate(current.Secret, code, time.Now(), totpSkew)
+		if !ok {
+			return ErrMFAInvalidCode
+		}
+		consumed, err := u.mfaRepo.ConsumeTOTPStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if !consumed {
+			// 同じコードの再利用
+			return ErrMFAInvalidCode
+		}
+		return nil
+	}
+
+	consumed, err := u.mfaRepo.ConsumeRecoveryCode(ctx, userID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrMFAInvalidCode
+	}
+	return nil
+}
+
+// mfaUserAttemptsKey ユーザーごとの試行回数のキー
+func mfaUserAttemptsKey(userID int64) string {
+	return "mfa_attempts:user:" + strconv.FormatInt(userID, 10)
+}
+
+// mfaChallengeAttemptsKey チャレンジごとの試行回数のキー
+func mfaChallengeAttemptsKey(challengeID string) string {
+	return "mfa_attempts:challenge:" + challengeID
+}
+
+// mfaChallengeUsedKey 使用済みのチャレンジのキー
+func mfaChallengeUsedKey(challengeID string) string {
+	return "mfa_challenge_used:" + challengeID
+}
+
+// issueRecoveryCodes 新しいリカバリーコードを生成して保存
+func (u *mfaUseCase) issueRecoveryCodes(ctx context.Context, userID int64) (*entity.RecoveryCodesResponse, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = hashRecoveryCode(code)
+	}
+
+	if err := u.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to save recovery codes: %w", err)
+	}
+
+	return &entity.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// generateRecoveryCode xxxxx-xxxxx 形式のリカバリーコードを生成
+func generateRecoveryCode() (string, error) {
+	// 紛らわしい文字（l, o, 0, 1）を除いた32文字（剰余の偏りなし）
+	const alphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %w", err)
+	}
+	for i := range b {
+		b[i] = alphabet[int(b[i])%len(alphabet)]
+	}
+	return string(b[:5]) + "-" + string(b[5:]), nil
+}
+
+// hashRecoveryCode 入力揺れ（大文字・ハイフン・空白）を正規化してハッシュ化
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// newAuthResponse ログイン成功時のレスポンスを生成
+// 二要素認証（TOTPまたはパスキー）が有効なユーザーにはアクセストークンの代わりにMFAチャレンジを返す
+func newAuthResponse(ctx context.Context, mfaRepo repository.MFARepository, tokens *auth.JWTManager, user *entity.User) (*entity.AuthResponse, error) {
+	if err := checkAccountStatus(user); err != nil {
+		return nil, err
+	}
+
+	methods, err := mfaRepo.Methods(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check mfa: %w", err)
+	}
+
+	if len(methods) > 0 {
+		challenge, err := tokens.GenerateMFAChallenge(user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate mfa challenge: %w", err)
+		}
+		return &entity.AuthResponse{
+			MFARequired:    true,
+			MFAMethods:     methods,
+			ChallengeToken: challenge,
+		}, nil
+	}
+
+	token, _, err := tokens.Generate(user.ID, user.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return &entity.AuthResponse{
+		User:  user,
+		Token: token,
+	}, nil
+}
@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"app-template/internal/entity"
+	"app-template/pkg/auth"
+	"app-template/pkg/kvstore"
+	"app-template/pkg/totp"
+)
+
+// testTOTPSecret テスト用のTOTPシークレット（RFC 6238 のテストベクターと同じ鍵）
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// mfaFixture TOTPを有効にしたユーザーでパスワードログインと第二要素の検証を行う
+type mfaFixture struct {
+	users UserUseCase
+	mfa   MFAUseCase
+	repo  *memUserRepo
+	totp  *memTOTPRepo
+	audit *memAudit
+	user  *entity.User
+}
+
+func newMFAFixture(t *testing.T) *mfaFixture {
+	t.Helper()
+	repo := newMemUserRepo()
+	hashed, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := repo.Create(context.Background(), &entity.User{Email: "alice@example.com", Name: "Alice", Password: string(hashed), Role: auth.RoleUser})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	totpRepo := newMemTOTPRepo()
+	totpRepo.enable(user.ID, testTOTPSecret, "recovery-code-1")
+
+	tokens := auth.NewJWTManager(auth.JWTConfig{Secret: "secret", Issuer: "test", Audience: "test", TTL: time.Hour})
+	audit := &memAudit{}
+	return &mfaFixture{
+		users: NewUserUseCase(repo, totpRepo, tokens, audit, NewAccountStateCache(repo, kvstore.NewMemory()), &memSessions{}, RegistrationOpen),
+		mfa:   NewMFAUseCase(repo, totpRepo, kvstore.NewMemory(), tokens, audit, "test"),
+		repo:  repo,
+		totp:  totpRepo,
+		audit: audit,
+		user:  user,
+	}
+}
+
+// challenge パスワードでログインしてMFAチャレンジを受け取る
+func (f *mfaFixture) challenge(t *testing.T) string {
+	t.Helper()
+	resp, err := f.users.Login(context.Background(), &entity.LoginRequest{Email: "alice@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if !resp.MFARequired || resp.Token != "" || resp.ChallengeToken == "" {
+		t.Fatalf("login response = %+v, want an mfa challenge", resp)
+	}
+	return resp.ChallengeToken
+}
+
+// currentCode 現在のTOTPコード
+func currentCode(t *testing.T) string {
+	t.Helper()
+	code, err := totp.Code(testTOTPSecret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// loginEntries 記録されたログインの監査ログ
+func (a *memAudit) loginEntries() []*entity.AuditLog {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var entries []*entity.AuditLog
+	for _, entry := range a.entries {
+		if entry.Action == entity.AuditActionUserLoginSuccess || entry.Action == entity.AuditActionUserLoginFailure {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func TestMFAVerifyRecordsLoginAudit(t *testing.T) {
+	f := newMFAFixture(t)
+	ctx := context.Background()
+
+	challenge := f.challenge(t)
+	// チャレンジの発行はログイン成功ではない
+	if got := f.audit.loginEntries(); len(got) != 0 {
+		t.Fatalf("audit after the password step = %+v, want none", got)
+	}
+
+	if _, err := f.mfa.Verify(ctx, &entity.MFAVerifyRequest{ChallengeToken: challenge, Code: "000000"}); !errors.Is(err, ErrMFAInvalidCode) {
+		t.Fatalf("wrong code: error = %v, want ErrMFAInvalidCode", err)
+	}
+	if _, err := f.mfa.Verify(ctx, &entity.MFAVerifyRequest{ChallengeToken: "not-a-token", Code: "000000"}); err == nil {
+		t.Fatal("Verify accepted an invalid challenge")
+	}
+	resp, err := f.mfa.Verify(ctx, &entity.MFAVerifyRequest{ChallengeToken: challenge, Code: currentCode(t)})
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if resp.Token == "" {
+		t.Fatalf("verify response = %+v", resp)
+	}
+
+	entries := f.audit.loginEntries()
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Action+":"+stringValue(entry.Metadata["reason"]))
+	}
+	want := []string{
+		entity.AuditActionUserLoginFailure + ":invalid_code",
+		entity.AuditActionUserLoginFailure + ":invalid_token",
+		entity.AuditActionUserLoginSuccess + ":",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("login audit = %v, want %v", got, want)
+	}
+	if entries[0].TargetUserID == nil || *entries[0].TargetUserID != f.user.ID || entries[1].TargetUserID != nil {
+		t.Errorf("failure targets = %v, %v", entries[0].TargetUserID, entries[1].TargetUserID)
+	}
+	if success := entries[2]; success.Metadata["method"] != entity.MFAMethodTOTP || success.Metadata["second_factor"] != true {
+		t.Errorf("success metadata = %v", success.Metadata)
+	}
+}
+
+func TestMFAVerifyLimitsAttempts(t *testing.T) {
+	f := newMFAFixture(t)
+	ctx := context.Background()
+
+	// 同じチャレンジでは maxMFAChallengeAttempts 回まで。使い切ると正しいコードも受け付けない
+	challenge := f.challenge(t)
+	for i := 0; i < maxMFAChallengeAttempts; i++ {
+		if _, err := f.mfa.Verify(ctx, &entity.MFAVerifyRequest{ChallengeToken: challenge, Code: "000000"}); !errors.Is(err, ErrMFAInvalidCode) {
+			t.Fatalf("attempt %d: error = %v, want ErrMFAInvalidCode", i+1, err)
+		}
+	}
+	if _, err := f.mfa.Verify(ctx, &entity.MFAVerifyRequest{ChallengeToken: challenge, Code: currentCode(t)}); !errors.Is(err, ErrMFATooManyAttempts) {
+		t.Fatalf("challenge over the limit: error = %v, want ErrMFATooManyAttempts", err)
+	}
+
+	// チャレンジを取り直してもユーザーごとの上限は引き継がれる
+	for i := maxMFAChallengeAttempts; i < maxMFAUserAttempts; i++ {
+		if _, err := f.mfa.Verify(ctx, &entity.MFAVerifyRequest{ChallengeToken: f.challenge(t), Code: "000000"}); !errors.Is(err, ErrMFAInvalidCode) {
+			t.Fatalf("attempt %d: error = %v, want ErrMFAInvalidCode", i+1, err)
+		}
+	}
+	if _, err := f.mfa.Verify(ctx, &entity.MFAVerifyRequest{ChallengeToken: f.challenge(t), Code: currentCode(t)}); !errors.Is(err, ErrMFATooManyAttempts) {
+		t.Fatalf("user over the limit: error = %v, want ErrMFATooManyAttempts", err)
+	}
+
+	entries := f.audit.loginEntries()
+	if last := entries[len(entries)-1]; last.Metadata["reason"] != "too_many_attempts" {
+		t.Errorf("last login audit = %+v, want too_many_attempts", last)
+	}
+}
+
+func TestMFAVerifyChallengeIsSingleUse(t *testing.T) {
+	f := newMFAFixture(t)
+	ctx := context.Background()
+
+	challenge := f.challenge(t)
+	if _, err := f.mfa.Verify(ctx, &entity.MFAVerifyRequest{ChallengeToken: challenge, Code: "000000"}); !errors.Is(err, ErrMFAInvalidCode) {
+		t.Fatalf("wrong code: error = %v, want ErrMFAInvalidCode", err)
+	}
+	if _, err := f.mfa.Verify(ctx, &entity.MFAVerifyRequest{ChallengeToken: challenge, Code: currentCode(t)}); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	// 成功したチャレンジは再利用できない
+	if _, err := f.mfa.Verify(ctx, &entity.MFAVerifyRequest{ChallengeToken: challenge, Code: currentCode(t)}); !errors.Is(err, ErrMFAChallengeUsed) {
+		t.Fatalf("replayed challenge: error = %v, want ErrMFAChallengeUsed", err)
+	}
+	// 成功するとユーザーごとの失敗回数は数え直す
+	for i := 0; i < maxMFAUserAttempts; i++ {
+		if _, err := f.mfa.Verify(ctx, &entity.MFAVerifyRequest{ChallengeToken: f.challenge(t), Code: "000000"}); !errors.Is(err, ErrMFAInvalidCode) {
+			t.Fatalf("attempt %d after success: error = %v, want ErrMFAInvalidCode", i+1, err)
+		}
+	}
+}
+
+func TestPasswordLoginWithoutMFARecordsSuccess(t *testing.T) {
+	f := newMFAFixture(t)
+	delete(f.totp.secrets, f.user.ID)
+
+	if _, err := f.users.Login(context.Background(), &entity.LoginRequest{Email: "alice@example.com", Password: "wrong"}); err == nil {
+		t.Fatal("Login accepted a wrong password")
+	}
+	resp, err := f.users.Login(context.Background(), &entity.LoginRequest{Email: "alice@example.com", Password: "password123"})
+	if err != nil || resp.Token == "" {
+		t.Fatalf("Login = %+v, %v", resp, err)
+	}
+
+	want := []string{entity.AuditActionUserLoginFailure, entity.AuditActionUserLoginSuccess}
+	if got := f.audit.actions(); !slices.Equal(got, want) {
+		t.Errorf("audit actions = %v, want %v", got, want)
+	}
+}
+
+// stringValue 文字列でなければ空文字列
+func stringValue(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
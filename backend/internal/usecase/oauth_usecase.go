@@ -0,0 +1,312 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	"app-template/internal/entity"
+	"app-template/internal/repository"
+	"app-template/pkg/auth"
+	"app-template/pkg/oauth"
+)
+
+// 外部プロバイダー認証のエラー
+var (
+	ErrOAuthProviderNotFound = errors.New("oauth provider not found")
+	ErrOAuthStateMismatch    = errors.New("oauth state mismatch")
+	ErrOAuthEmailRequired    = errors.New("provider did not return a verified email")
+	ErrOAuthEmailConflict    = errors.New("email already registered; sign in and link the provider from your account")
+	ErrIdentityAlreadyLinked = errors.New("provider account is already linked to another user")
+	ErrIdentityNotFound      = errors.New("identity not found")
+	ErrLastLoginMethod       = errors.New("cannot unlink the only login method; set a password first")
+)
+
+// OAuthUseCase 外部プロバイダー認証ユースケースのインターフェース
+type OAuthUseCase interface {
+	Begin(ctx context.Context, provider string, linkUserID int64) (*entity.OAuthAuthorization, error)
+	Complete(ctx context.Context, provider, code, state, encodedState string) (*entity.OAuthCallbackResult, error)
+	ListIdentities(ctx context.Context, userID int64) (*entity.UserIdentitiesResponse, error)
+	Unlink(ctx context.Context, userID int64, provider string) error
+}
+
+// oauthUseCase 外部プロバイダー認証ユースケースの実装
+type oauthUseCase struct {
+	userRepo     repository.UserRepository
+	identityRepo repository.UserIdentityRepository
+	mfaRepo      repository.MFARepository
+	providers    *oauth.Registry
+	states       *oauth.StateCodec
+	tokens       *auth.JWTManager
+	audit        AuditUseCase
+	registration RegistrationMode
+}
+
+// NewOAuthUseCase 外部プロバイダー認証ユースケースの新しいインスタンスを作成
+func NewOAuthUseCase(
+	userRepo repository.UserRepository,
+	identityRepo repository.UserIdentityRepository,
+	mfaRepo repository.MFARepository,
+	providers *oauth.Registry,
+	states *oauth.StateCodec,
+	tokens *auth.JWTManager,
+	audit AuditUseCase,
+	registration RegistrationMode,
+) OAuthUseCase {
+	return &oauthUseCase{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		mfaRepo:      mfaRepo,
+		providers:    providers,
+		states:       states,
+		tokens:       tokens,
+		audit:        audit,
+		registration: registration,
+	}
+}
+
+// Begin 認可コードフローを開始（linkUserIDが0ならログイン、それ以外はアカウント連携）
+func (u *oauthUseCase) Begin(ctx context.Context, provider string, linkUserID int64) (*entity.OAuthAuthorization, error) {
+	p, ok := u.providers.Get(provider)
+	if !ok {
+		return nil, ErrOAuthProviderNotFound
+	}
+
+	st, err := u.states.New(provider, linkUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create oauth state: %w", err)
+	}
+
+	encoded, err := u.states.Encode(st)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode oauth state: %w", err)
+	}
+
+	return &entity.OAuthAuthorization{
+		AuthorizationURL: p.AuthCodeURL(st.State, st.Verifier, st.Nonce),
+		State:            encoded,
+	}, nil
+}
+
+// Complete コールバックを処理してログインまたはアカウント連携を行う
+func (u *oauthUseCase) Complete(ctx context.Context, provider, code, state, encodedState string) (*entity.OAuthCallbackResult, error) {
+	p, ok := u.providers.Get(provider)
+	if !ok {
+		return nil, ErrOAuthProviderNotFound
+	}
+
+	st, err := u.states.Decode(encodedState)
+	if err != nil {
+		return nil, err
+	}
+	// ログインの失敗は監査ログに記録する（アカウント連携は対象外）
+	metadata := map[string]interface{}{"method": "oauth", "provider": provider}
+	if st.Provider != provider || subtle.ConstantTimeCompare([]byte(st.State), []byte(state)) != 1 {
+		if st.LinkUserID == 0 {
+			recordLoginFailure(ctx, u.audit, 0, "state_mismatch", metadata)
+		}
+		return nil, ErrOAuthStateMismatch
+	}
+
+	identity, err := p.Exchange(ctx, code, st.Verifier, st.Nonce)
+	if err != nil {
+		if st.LinkUserID == 0 {
+			recordLoginFailure(ctx, u.audit, 0, "provider_error", metadata)
+		}
+		return nil, fmt.Errorf("failed to authenticate with provider: %w", err)
+	}
+
+	if st.LinkUserID > 0 {
+		linked, err := u.link(ctx, st.LinkUserID, identity)
+		if err != nil {
+			return nil, err
+		}
+		return &entity.OAuthCallbackResult{Identity: linked}, nil
+	}
+
+	authResponse, err := u.login(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.OAuthCallbackResult{Auth: authResponse}, nil
+}
+
+// ListIdentities ユーザーの連携済みプロバイダー一覧を取得
+func (u *oauthUseCase) ListIdentities(ctx context.Context, userID int64) (*entity.UserIdentitiesResponse, error) {
+	identities, err := u.identityRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+
+	return &entity.UserIdentitiesResponse{
+		Identities: identities,
+	}, nil
+}
+
+// Unlink プロバイダー連携を解除（唯一のログイン手段は解除不可）
+func (u *oauthUseCase) Unlink(ctx context.Context, userID int64, provider string) error {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return fmt.Errorf("user not found")
+	}
+
+	identities, err := u.identityRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list identities: %w", err)
+	}
+
+	found := false
+	for _, identity := range identities {
+		if identity.Provider == provider {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrIdentityNotFound
+	}
+	if !user.HasPassword() && len(identities) == 1 {
+		return ErrLastLoginMethod
+	}
+
+	if err := u.identityRepo.Delete(ctx, userID, provider); err != nil {
+		return fmt.Errorf("failed to unlink identity: %w", err)
+	}
+
+	return nil
+}
+
+// login 連携済みユーザーでログイン、未登録ならパスワードなしユーザーを作成（成否を監査ログに記録する）
+func (u *oauthUseCase) login(ctx context.Context, identity *oauth.Identity) (*entity.AuthResponse, error) {
+	metadata := map[string]interface{}{"method": "oauth", "provider": identity.Provider}
+
+	user, err := u.loginUser(ctx, identity)
+	if err != nil {
+		recordLoginError(ctx, u.audit, 0, err, metadata)
+		return nil, err
+	}
+
+	response, err := newAuthResponse(ctx, u.mfaRepo, u.tokens, user)
+	if err != nil {
+		recordLoginError(ctx, u.audit, user.ID, err, metadata)
+		return nil, err
+	}
+	// チャレンジの場合は第二要素の検証時に記録する
+	if !response.MFARequired {
+		recordLoginSuccess(ctx, u.audit, user.ID, metadata)
+	}
+
+	return response, nil
+}
+
+// loginUser 外部プロバイダーのアカウントに連携済みのユーザー、未登録なら新しく作成したユーザー
+func (u *oauthUseCase) loginUser(ctx context.Context, identity *oauth.Identity) (*entity.User, error) {
+	existing, err := u.identityRepo.GetByProviderSubject(ctx, identity.Provider, identity.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	var user *entity.User
+	if existing != nil {
+		user, err = u.userRepo.GetByID(ctx, existing.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if user == nil {
+			return nil, fmt.Errorf("user not found")
+		}
+	} else {
+		user, err = u.register(ctx, identity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+// register 外部プロバイダーの情報から新しいユーザーを作成
+func (u *oauthUseCase) register(ctx context.Context, identity *oauth.Identity) (*entity.User, error) {
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, ErrOAuthEmailRequired
+	}
+
+	// 既存アカウントへの自動連携は乗っ取りにつながるため行わない
+	existingUser, err := u.userRepo.GetByEmail(ctx, identity.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+	if existingUser != nil {
+		return nil, ErrOAuthEmailConflict
+	}
+	if u.registration == RegistrationInviteOnly {
+		return nil, ErrRegistrationInviteOnly
+	}
+
+	name := identity.Name
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	user, err := u.userRepo.Create(ctx, &entity.User{
+		Email: identity.Email,
+		Name:  name,
+		Role:  auth.RoleUser,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	_, err = u.identityRepo.Create(ctx, &entity.UserIdentity{
+		UserID:   user.ID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	return user, nil
+}
+
+// link 既存ユーザーにプロバイダーを連携
+func (u *oauthUseCase) link(ctx context.Context, userID int64, identity *oauth.Identity) (*entity.UserIdentity, error) {
+	existing, err := u.identityRepo.GetByProviderSubject(ctx, identity.Provider, identity.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+	if existing != nil {
+		if existing.UserID != userID {
+			return nil, ErrIdentityAlreadyLinked
+		}
+		return existing, nil
+	}
+
+	identities, err := u.identityRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+	for _, linked := range identities {
+		if linked.Provider == identity.Provider {
+			return nil, fmt.Errorf("another %s account is already linked; unlink it first", identity.Provider)
+		}
+	}
+
+	created, err := u.identityRepo.Create(ctx, &entity.UserIdentity{
+		UserID:   userID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	return created, nil
+}
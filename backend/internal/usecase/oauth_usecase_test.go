@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"app-template/internal/entity"
+	"app-template/pkg/auth"
+	"app-template/pkg/oauth"
+	"app-template/pkg/oauth/oauthtest"
+)
+
+type oauthFixture struct {
+	srv        *oauthtest.Server
+	users      *memUserRepo
+	identities *memIdentityRepo
+	tokens     *auth.JWTManager
+	audit      *memAudit
+	uc         OAuthUseCase
+}
+
+func newOAuthFixture(t *testing.T) *oauthFixture {
+	t.Helper()
+	srv := oauthtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	google, err := oauth.NewOIDCProvider(context.Background(), oauth.ProviderGoogle, srv.OIDCConfig())
+	if err != nil {
+		t.Fatalf("NewOIDCProvider: %v", err)
+	}
+
+	f := &oauthFixture{
+		srv:        srv,
+		users:      newMemUserRepo(),
+		identities: &memIdentityRepo{},
+		tokens:     auth.NewJWTManager(auth.JWTConfig{Secret: "secret", Issuer: "test", Audience: "test", TTL: time.Hour}),
+		audit:      &memAudit{},
+	}
+	f.uc = NewOAuthUseCase(
+		f.users,
+		f.identities,
+		memMFARepo{},
+		oauth.NewRegistry(google, oauth.NewGitHubProvider(srv.GitHubConfig())),
+		oauth.NewStateCodec("state-secret", time.Minute),
+		f.tokens,
+		f.audit,
+		RegistrationOpen,
+	)
+	return f
+}
+
+// signIn 認可の開始からコールバックまでを行う
+func (f *oauthFixture) signIn(t *testing.T, provider string, linkUserID int64, user oauthtest.User) (*entity.OAuthCallbackResult, error) {
+	t.Helper()
+	authz, err := f.uc.Begin(context.Background(), provider, linkUserID)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	code, state, err := f.srv.Authorize(authz.AuthorizationURL, user)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	return f.uc.Complete(context.Background(), provider, code, state, authz.State)
+}
+
+var (
+	aliceGoogle = oauthtest.User{Subject: "google-alice", Name: "Alice", Email: "alice@example.com", EmailVerified: true}
+	aliceGitHub = oauthtest.User{Subject: "1001", Login: "alice", Email: "alice@example.com", EmailVerified: true}
+)
+
+func TestOAuthLoginRegistersThenSignsIn(t *testing.T) {
+	f := newOAuthFixture(t)
+
+	first, err := f.signIn(t, oauth.ProviderGoogle, 0, aliceGoogle)
+	if err != nil {
+		t.Fatalf("first sign-in: %v", err)
+	}
+	if first.Auth == nil || first.Auth.User == nil || first.Auth.Token == "" {
+		t.Fatalf("first sign-in result = %+v", first)
+	}
+	if u := first.Auth.User; u.Email != aliceGoogle.Email || u.Name != "Alice" || u.Role != auth.RoleUser || u.HasPassword() {
+		t.Errorf("registered user = %+v", u)
+	}
+	claims, err := f.tokens.Parse(first.Auth.Token)
+	if err != nil {
+		t.Fatalf("Parse token: %v", err)
+	}
+	if id, _ := claims.UserID(); id != first.Auth.User.ID {
+		t.Errorf("token subject = %d, want %d", id, first.Auth.User.ID)
+	}
+
+	second, err := f.signIn(t, oauth.ProviderGoogle, 0, aliceGoogle)
+	if err != nil {
+		t.Fatalf("second sign-in: %v", err)
+	}
+	if second.Auth.User.ID != first.Auth.User.ID {
+		t.Errorf("second sign-in user = %d, want %d", second.Auth.User.ID, first.Auth.User.ID)
+	}
+	if len(f.users.users) != 1 {
+		t.Errorf("users = %d, want 1", len(f.users.users))
+	}
+	want := []string{entity.AuditActionUserLoginSuccess, entity.AuditActionUserLoginSuccess}
+	if got := f.audit.actions(); !slices.Equal(got, want) {
+		t.Errorf("audit actions = %v, want %v", got, want)
+	}
+}
+
+func TestOAuthCompleteRejectsStateMismatch(t *testing.T) {
+	f := newOAuthFixture(t)
+	ctx := context.Background()
+
+	authz, err := f.uc.Begin(ctx, oauth.ProviderGoogle, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state, err := f.srv.Authorize(authz.AuthorizationURL, aliceGoogle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.uc.Complete(ctx, oauth.ProviderGoogle, code, state+"x", authz.State); !errors.Is(err, ErrOAuthStateMismatch) {
+		t.Errorf("wrong state: error = %v, want ErrOAuthStateMismatch", err)
+	}
+	if _, err := f.uc.Complete(ctx, oauth.ProviderGitHub, code, state, authz.State); !errors.Is(err, ErrOAuthStateMismatch) {
+		t.Errorf("other provider: error = %v, want ErrOAuthStateMismatch", err)
+	}
+	if _, err := f.uc.Complete(ctx, oauth.ProviderGoogle, code, state, authz.State+"x"); !errors.Is(err, oauth.ErrInvalidState) {
+		t.Errorf("tampered state cookie: error = %v, want ErrInvalidState", err)
+	}
+	// 改ざんされた state はログインか連携か判別できないため記録しない
+	want := []string{entity.AuditActionUserLoginFailure, entity.AuditActionUserLoginFailure}
+	if got := f.audit.actions(); !slices.Equal(got, want) {
+		t.Errorf("audit actions = %v, want %v", got, want)
+	}
+
+	// 別の認可リクエストのstate cookieでは、同じプロバイダーでもPKCEのverifierが一致しない
+	other, err := f.uc.Begin(ctx, oauth.ProviderGoogle, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherState, err := oauth.NewStateCodec("state-secret", time.Minute).Decode(other.State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.uc.Complete(ctx, oauth.ProviderGoogle, code, otherState.State, other.State); err == nil {
+		t.Error("code redeemed with another request's verifier")
+	}
+	if len(f.users.users) != 0 {
+		t.Errorf("users = %d, want 0", len(f.users.users))
+	}
+}
+
+func TestOAuthCompleteRejectsNonceMismatch(t *testing.T) {
+	f := newOAuthFixture(t)
+	f.srv.NonceOverride = "nonce-from-another-request"
+
+	if _, err := f.signIn(t, oauth.ProviderGoogle, 0, aliceGoogle); err == nil {
+		t.Fatal("sign-in with a mismatched nonce succeeded")
+	}
+	if len(f.users.users) != 0 {
+		t.Errorf("users = %d, want 0", len(f.users.users))
+	}
+}
+
+func TestOAuthLoginDoesNotAutoLinkExistingEmail(t *testing.T) {
+	f := newOAuthFixture(t)
+	if _, err := f.users.Create(context.Background(), &entity.User{Email: aliceGoogle.Email, Name: "Alice", Password: "hash"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.signIn(t, oauth.ProviderGoogle, 0, aliceGoogle); !errors.Is(err, ErrOAuthEmailConflict) {
+		t.Fatalf("error = %v, want ErrOAuthEmailConflict", err)
+	}
+
+	unverified := aliceGoogle
+	unverified.Subject, unverified.Email, unverified.EmailVerified = "google-bob", "bob@example.com", false
+	if _, err := f.signIn(t, oauth.ProviderGoogle, 0, unverified); !errors.Is(err, ErrOAuthEmailRequired) {
+		t.Fatalf("unverified email: error = %v, want ErrOAuthEmailRequired", err)
+	}
+}
+
+func TestOAuthLinkToExistingAccount(t *testing.T) {
+	f := newOAuthFixture(t)
+	ctx := context.Background()
+	user, err := f.users.Create(ctx, &entity.User{Email: "alice@example.com", Name: "Alice", Password: "hash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := f.signIn(t, oauth.ProviderGitHub, user.ID, aliceGitHub)
+	if err != nil {
+		t.Fatalf("link: %v", err)
+	}
+	if result.Auth != nil || result.Identity == nil {
+		t.Fatalf("link result = %+v", result)
+	}
+	if got := result.Identity; got.UserID != user.ID || got.Provider != oauth.ProviderGitHub || got.Subject != "1001" {
+		t.Errorf("linked identity = %+v", got)
+	}
+
+	// 連携後はプロバイダーでログインでき、既存のアカウントになる
+	login, err := f.signIn(t, oauth.ProviderGitHub, 0, aliceGitHub)
+	if err != nil {
+		t.Fatalf("login after link: %v", err)
+	}
+	if login.Auth.User.ID != user.ID {
+		t.Errorf("login user = %d, want %d", login.Auth.User.ID, user.ID)
+	}
+
+	// 連携済みのプロバイダーアカウントは別のユーザーに連携できない
+	other, err := f.users.Create(ctx, &entity.User{Email: "mallory@example.com", Name: "Mallory", Password: "hash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.signIn(t, oauth.ProviderGitHub, other.ID, aliceGitHub); !errors.Is(err, ErrIdentityAlreadyLinked) {
+		t.Errorf("link to another user: error = %v, want ErrIdentityAlreadyLinked", err)
+	}
+
+	// 同じプロバイダーの別アカウントは解除するまで連携できない
+	second := aliceGitHub
+	second.Subject = "1002"
+	if _, err := f.signIn(t, oauth.ProviderGitHub, user.ID, second); err == nil {
+		t.Error("linked a second account of the same provider")
+	}
+}
+
+func TestOAuthUnlink(t *testing.T) {
+	f := newOAuthFixture(t)
+	ctx := context.Background()
+
+	// 外部プロバイダーのみで登録したユーザーは唯一のログイン手段を解除できない
+	registered, err := f.signIn(t, oauth.ProviderGoogle, 0, aliceGoogle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID := registered.Auth.User.ID
+	if err := f.uc.Unlink(ctx, userID, oauth.ProviderGoogle); !errors.Is(err, ErrLastLoginMethod) {
+		t.Fatalf("unlink only method: error = %v, want ErrLastLoginMethod", err)
+	}
+	if err := f.uc.Unlink(ctx, userID, oauth.ProviderGitHub); !errors.Is(err, ErrIdentityNotFound) {
+		t.Fatalf("unlink not linked: error = %v, want ErrIdentityNotFound", err)
+	}
+
+	// もう一つ連携すれば片方を解除できる
+	if _, err := f.signIn(t, oauth.ProviderGitHub, userID, aliceGitHub); err != nil {
+		t.Fatalf("link github: %v", err)
+	}
+	if err := f.uc.Unlink(ctx, userID, oauth.ProviderGoogle); err != nil {
+		t.Fatalf("unlink google: %v", err)
+	}
+	list, err := f.uc.ListIdentities(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Identities) != 1 || list.Identities[0].Provider != oauth.ProviderGitHub {
+		t.Errorf("identities after unlink = %+v", list.Identities)
+	}
+
+	// 解除したプロバイダーアカウントでのログインは新規登録扱い（メールアドレスが登録済みのため拒否）
+	if _, err := f.signIn(t, oauth.ProviderGoogle, 0, aliceGoogle); !errors.Is(err, ErrOAuthEmailConflict) {
+		t.Errorf("login with unlinked identity: error = %v, want ErrOAuthEmailConflict", err)
+	}
+
+	// パスワードがあれば最後の連携も解除できる
+	withPassword, err := f.users.Create(ctx, &entity.User{Email: "carol@example.com", Name: "Carol", Password: "hash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	carol := oauthtest.User{Subject: "google-carol", Email: "carol@example.com", EmailVerified: true}
+	if _, err := f.signIn(t, oauth.ProviderGoogle, withPassword.ID, carol); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.uc.Unlink(ctx, withPassword.ID, oauth.ProviderGoogle); err != nil {
+		t.Errorf("unlink with password: %v", err)
+	}
+}
+
+func TestOAuthInviteOnlyRejectsNewAccounts(t *testing.T) {
+	f := newOAuthFixture(t)
+	f.uc.(*oauthUseCase).registration = RegistrationInviteOnly
+
+	if _, err := f.signIn(t, oauth.ProviderGoogle, 0, aliceGoogle); !errors.Is(err, ErrRegistrationInviteOnly) {
+		t.Fatalf("error = %v, want ErrRegistrationInviteOnly", err)
+	}
+}
@@ -0,0 +1,444 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"app-template/internal/entity"
+	"app-template/internal/repository"
+	"app-template/pkg/auth"
+	"app-template/pkg/tenant"
+)
+
+// 組織のエラー
+var (
+	ErrOrganizationNotFound       = errors.New("organization not found")
+	ErrOrganizationInvalidSlug    = errors.New("organization slug must be 3-63 lowercase letters, digits or hyphens and not only digits")
+	ErrOrganizationSlugTaken      = errors.New("organization slug is already taken")
+	ErrNotOrganizationMember      = errors.New("not a member of the organization")
+	ErrOrganizationForbidden      = errors.New("organization role does not allow this operation")
+	ErrOrganizationMemberExists   = errors.New("user is already a member of the organization")
+	ErrOrganizationMemberNotFound = errors.New("organization member not found")
+	ErrOrganizationLastOwner      = errors.New("organization must keep at least one owner")
+)
+
+// organizationSlugPattern 組織のスラッグ（サブドメインとして使える形式）
+var organizationSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,61}[a-z0-9]$`)
+
+// OrganizationUseCase 組織ユースケースのインターフェース
+// Resolveによりテナント解決ミドルウェアに組み込める
+type OrganizationUseCase interface {
+	tenant.Resolver
+	Create(ctx context.Context, userID int64, req *entity.CreateOrganizationRequest) (*entity.OrganizationMembership, error)
+	List(ctx context.Context, userID int64) (*entity.OrganizationsResponse, error)
+	Get(ctx context.Context, userID, orgID int64) (*entity.OrganizationMembership, error)
+	IssueToken(ctx context.Context, userID, orgID int64) (*entity.OrganizationTokenResponse, error)
+	ListMembers(ctx context.Context, userID, orgID int64, params *entity.PaginationParams) (*entity.OrganizationMembersResponse, error)
+	AddMember(ctx context.Context, actorID, orgID int64, req *entity.AddOrganizationMemberRequest) (*entity.OrganizationMember, error)
+	UpdateMemberRole(ctx context.Context, actorID, orgID, userID int64, role string) error
+	RemoveMember(ctx context.Context, actorID, orgID, userID int64) error
+}
+
+// organizationUseCase 組織ユースケースの実装
+type organizationUseCase struct {
+	orgRepo  repository.OrganizationRepository
+	userRepo repository.UserRepository
+	tokens   *auth.JWTManager
+	audit    AuditUseCase
+}
+
+// NewOrganizationUseCase 組織ユースケースの新しいインスタンスを作成
+func NewOrganizationUseCase(orgRepo repository.OrganizationRepository, userRepo repository.UserRepository, tokens *auth.JWTManager, audit AuditUseCase) OrganizationUseCase {
+	return &organizationUseCase{
+		orgRepo:  orgRepo,
+		userRepo: userRepo,
+		tokens:   tokens,
+		audit:    audit,
+	}
+}
+
+// Resolve 組織IDまたはスラッグの組織を主体のテナントとして解決する
+// メンバーでないシステム管理者（auth.RoleAdmin）はロールなしのテナントとして解決する
+func (u *organizationUseCase) Resolve(ctx context.Context, principal *auth.Principal, ref string) (*tenant.Tenant, error) {
+	var org *entity.Organization
+	var err error
+	if id, parseErr := strconv.ParseInt(ref, 10, 64); parseErr == nil {
+		org, err = u.orgRepo.GetByID(ctx, id)
+	} else {
+		org, err = u.orgRepo.GetBySlug(ctx, strings.ToLower(ref))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, tenant.ErrNotFound
+	}
+
+	role, err := u.orgRepo.GetMemberRole(ctx, org.ID, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" && principal.Role != auth.RoleAdmin {
+		return nil, tenant.ErrNotMember
+	}
+
+	return &tenant.Tenant{
+		ID:   org.ID,
+		Slug: org.Slug,
+		Role: role,
+	}, nil
+}
+
+// Default 主体が所属する組織が1つだけならその組織をテナントとして返す（所属がなければ個人のテナント、複数ならnil）
+func (u *organizationUseCase) Default(ctx context.Context, principal *auth.Principal) (*tenant.Tenant, error) {
+	memberships, err := u.orgRepo.ListByUserID(ctx, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	switch len(memberships) {
+	case 0:
+		return tenant.Personal(principal.UserID), nil
+	case 1:
+	default:
+		return nil, nil
+	}
+
+	return &tenant.Tenant{
+		ID:   memberships[0].ID,
+		Slug: memberships[0].Slug,
+		Role: memberships[0].Role,
+	}, nil
+}
+
+// Create 組織を作成（作成者がオーナーになる）
+func (u *organizationUseCase) Create(ctx context.Context, userID int64, req *entity.CreateOrganizationRequest) (*entity.OrganizationMembership, error) {
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	if !isOrganizationSlug(slug) {
+		return nil, ErrOrganizationInvalidSlug
+	}
+
+	existing, err := u.orgRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check organization slug: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrOrganizationSlugTaken
+	}
+
+	org, err := u.orgRepo.Create(ctx, &entity.Organization{
+		Slug: slug,
+		Name: strings.TrimSpace(req.Name),
+	}, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	u.audit.Record(ctx, &entity.AuditLog{
+		Action:       entity.AuditActionOrganizationCreate,
+		TargetUserID: auditUserID(userID),
+		Metadata: map[string]interface{}{
+			"organization_id": org.ID,
+			"slug":            org.Slug,
+		},
+	})
+
+	return &entity.OrganizationMembership{
+		Organization: org,
+		Role:         entity.OrgRoleOwner,
+	}, nil
+}
+
+// List ユーザーが所属する組織の一覧を取得
+func (u *organizationUseCase) List(ctx context.Context, userID int64) (*entity.OrganizationsResponse, error) {
+	memberships, err := u.orgRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	return &entity.OrganizationsResponse{
+		Organizations: memberships,
+	}, nil
+}
+
+// Get 所属する組織を取得
+func (u *organizationUseCase) Get(ctx context.Context, userID, orgID int64) (*entity.OrganizationMembership, error) {
+	org, role, err := u.membership(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.OrganizationMembership{
+		Organization: org,
+		Role:         role,
+	}, nil
+}
+
+// IssueToken 組織をテナントとして指定したアクセストークンを発行
+func (u *organizationUseCase) IssueToken(ctx context.Context, userID, orgID int64) (*entity.OrganizationTokenResponse, error) {
+	org, role, err := u.membership(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	token, _, err := u.tokens.GenerateForOrganization(user.ID, user.Role, org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return &entity.OrganizationTokenResponse{
+		Organization: org,
+		Role:         role,
+		Token:        token,
+	}, nil
+}
+
+// ListMembers 組織のメンバー一覧を取得（メンバーのみ）
+func (u *organizationUseCase) ListMembers(ctx context.Context, userID, orgID int64, params *entity.PaginationParams) (*entity.OrganizationMembersResponse, error) {
+	if _, _, err := u.membership(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	// デフォルト値を設定
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 20
+	}
+	if params.Limit > 100 {
+		params.Limit = 100
+	}
+
+	members, pagination, err := u.orgRepo.ListMembers(ctx, orgID, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization members: %w", err)
+	}
+
+	return &entity.OrganizationMembersResponse{
+		Members:    members,
+		Pagination: pagination,
+	}, nil
+}
+
+// AddMember 登録済みのユーザーをメールアドレスで組織に追加（owner / admin のみ、owner の追加は owner のみ）
+func (u *organizationUseCase) AddMember(ctx context.Context, actorID, orgID int64, req *entity.AddOrganizationMemberRequest) (*entity.OrganizationMember, error) {
+	role := req.Role
+	if role == "" {
+		role = entity.OrgRoleMember
+	}
+
+	actorRole, err := u.managerRole(ctx, orgID, actorID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := u.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	existing, err := u.orgRepo.GetMemberRole(ctx, orgID, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization member: %w", err)
+	}
+	if existing != "" {
+		return nil, ErrOrganizationMemberExists
+	}
+
+	if err := u.orgRepo.AddMember(ctx, orgID, user.ID, role); err != nil {
+		return nil, fmt.Errorf("failed to add organization member: %w", err)
+	}
+
+	u.audit.Record(ctx, &entity.AuditLog{
+		Action:       entity.AuditActionOrganizationMemberAdd,
+		TargetUserID: auditUserID(user.ID),
+		Metadata: map[string]interface{}{
+			"organization_id": orgID,
+			"role":            role,
+			"actor_role":      actorRole,
+		},
+	})
+
+	return &entity.OrganizationMember{
+		User: user,
+		Role: role,
+	}, nil
+}
+
+// UpdateMemberRole メンバーのロールを変更（owner / admin のみ、owner への変更・owner からの変更は owner のみ）
+func (u *organizationUseCase) UpdateMemberRole(ctx context.Context, actorID, orgID, userID int64, role string) error {
+	if !entity.IsOrgRole(role) {
+		return ErrInvalidRole
+	}
+
+	current, err := u.orgRepo.GetMemberRole(ctx, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get organization member: %w", err)
+	}
+
+	// 変更前・変更後のどちらかが owner なら owner の権限が必要
+	required := role
+	if current == entity.OrgRoleOwner {
+		required = entity.OrgRoleOwner
+	}
+	if _, err := u.managerRole(ctx, orgID, actorID, required); err != nil {
+		return err
+	}
+	if current == "" {
+		return ErrOrganizationMemberNotFound
+	}
+	if current == role {
+		return nil
+	}
+
+	if current == entity.OrgRoleOwner {
+		if err := u.keepOwner(ctx, orgID); err != nil {
+			return err
+		}
+	}
+
+	if err := u.orgRepo.UpdateMemberRole(ctx, orgID, userID, role); err != nil {
+		if err.Error() == "organization member not found" {
+			return ErrOrganizationMemberNotFound
+		}
+		return fmt.Errorf("failed to update organization member: %w", err)
+	}
+
+	u.audit.Record(ctx, &entity.AuditLog{
+		Action:       entity.AuditActionOrganizationMemberRoleChange,
+		TargetUserID: auditUserID(userID),
+		Changes: map[string]entity.FieldChange{
+			"role": {Before: current, After: role},
+		},
+		Metadata: map[string]interface{}{
+			"organization_id": orgID,
+		},
+	})
+
+	return nil
+}
+
+// RemoveMember メンバーを組織から外す（本人の脱退、または owner / admin による削除。owner の削除は owner のみ）
+func (u *organizationUseCase) RemoveMember(ctx context.Context, actorID, orgID, userID int64) error {
+	current, err := u.orgRepo.GetMemberRole(ctx, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get organization member: %w", err)
+	}
+
+	if actorID == userID {
+		if _, _, err := u.membership(ctx, orgID, actorID); err != nil {
+			return err
+		}
+	} else if _, err := u.managerRole(ctx, orgID, actorID, current); err != nil {
+		return err
+	}
+	if current == "" {
+		return ErrOrganizationMemberNotFound
+	}
+
+	if current == entity.OrgRoleOwner {
+		if err := u.keepOwner(ctx, orgID); err != nil {
+			return err
+		}
+	}
+
+	if err := u.orgRepo.RemoveMember(ctx, orgID, userID); err != nil {
+		if err.Error() == "organization member not found" {
+			return ErrOrganizationMemberNotFound
+		}
+		return fmt.Errorf("failed to remove organization member: %w", err)
+	}
+
+	u.audit.Record(ctx, &entity.AuditLog{
+		Action:       entity.AuditActionOrganizationMemberRemove,
+		TargetUserID: auditUserID(userID),
+		Metadata: map[string]interface{}{
+			"organization_id": orgID,
+			"role":            current,
+		},
+	})
+
+	return nil
+}
+
+// membership 組織とユーザーのロールを取得（メンバーでなければErrNotOrganizationMember）
+// メンバーでないシステム管理者は owner として扱う
+func (u *organizationUseCase) membership(ctx context.Context, orgID, userID int64) (*entity.Organization, string, error) {
+	org, err := u.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get organization: %w", err)
+	}
+	if org == nil {
+		return nil, "", ErrOrganizationNotFound
+	}
+
+	role, err := u.orgRepo.GetMemberRole(ctx, orgID, userID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get organization member: %w", err)
+	}
+	if role == "" {
+		if !isSystemAdmin(ctx) {
+			return nil, "", ErrNotOrganizationMember
+		}
+		role = entity.OrgRoleOwner
+	}
+
+	return org, role, nil
+}
+
+// managerRole 操作者がメンバーを管理でき、対象のロールを扱えるか確認して操作者のロールを返す
+func (u *organizationUseCase) managerRole(ctx context.Context, orgID, actorID int64, targetRole string) (string, error) {
+	_, role, err := u.membership(ctx, orgID, actorID)
+	if err != nil {
+		return "", err
+	}
+	if !entity.CanManageMembers(role) {
+		return "", ErrOrganizationForbidden
+	}
+	if targetRole == entity.OrgRoleOwner && role != entity.OrgRoleOwner {
+		return "", ErrOrganizationForbidden
+	}
+	return role, nil
+}
+
+// keepOwner オーナーを1人も残さない変更を防ぐ
+func (u *organizationUseCase) keepOwner(ctx context.Context, orgID int64) error {
+	owners, err := u.orgRepo.CountOwners(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to count organization owners: %w", err)
+	}
+	if owners <= 1 {
+		return ErrOrganizationLastOwner
+	}
+	return nil
+}
+
+// isSystemAdmin 操作者がシステム管理者か
+func isSystemAdmin(ctx context.Context) bool {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	return ok && principal.Role == auth.RoleAdmin
+}
+
+// isOrganizationSlug スラッグとして使える文字列か（数字のみは組織IDと区別できないため不可）
+func isOrganizationSlug(slug string) bool {
+	if !organizationSlugPattern.MatchString(slug) {
+		return false
+	}
+	_, err := strconv.ParseInt(slug, 10, 64)
+	return err != nil
+}
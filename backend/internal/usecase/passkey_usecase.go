@@ -0,0 +1,487 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+
+	"app-template/internal/entity"
+	"app-template/internal/repository"
+	"app-template/pkg/auth"
+	"app-template/pkg/kvstore"
+)
+
+const (
+	// passkeySessionTTL WebAuthnセレモニーの有効期間
+	passkeySessionTTL = 5 * time.Minute
+
+	// セッションの用途（取り違え防止のためキーに含める）
+	passkeyPurposeRegister = "register"
+	passkeyPurposeLogin    = "login"
+	passkeyPurposeMFA      = "mfa"
+)
+
+// パスキーのエラー
+var (
+	ErrPasskeySessionInvalid = errors.New("passkey session is invalid or expired")
+	ErrPasskeyNotRegistered  = errors.New("no passkeys registered")
+	ErrPasskeyNotFound       = errors.New("passkey not found")
+	ErrPasskeyVerification   = errors.New("passkey verification failed")
+	ErrPasskeyCloned         = errors.New("passkey signature counter indicates a cloned authenticator")
+)
+
+// PasskeyUseCase パスキー（WebAuthn）ユースケースのインターフェース
+type PasskeyUseCase interface {
+	BeginRegistration(ctx context.Context, userID int64) (*entity.PasskeyCeremony, error)
+	FinishRegistration(ctx context.Context, userID int64, req *entity.PasskeyFinishRequest) (*entity.WebAuthnCredential, error)
+	List(ctx context.Context, userID int64) (*entity.PasskeysResponse, error)
+	Delete(ctx context.Context, userID, id int64) error
+	BeginLogin(ctx context.Context) (*entity.PasskeyCeremony, error)
+	FinishLogin(ctx context.Context, req *entity.PasskeyFinishRequest) (*entity.AuthResponse, error)
+	BeginMFA(ctx context.Context, req *entity.PasskeyBeginRequest) (*entity.PasskeyCeremony, error)
+	FinishMFA(ctx context.Context, req *entity.PasskeyFinishRequest) (*entity.AuthResponse, error)
+}
+
+// passkeyUseCase パスキーユースケースの実装
+type passkeyUseCase struct {
+	userRepo       repository.UserRepository
+	credentialRepo repository.WebAuthnCredentialRepository
+	sessions       kvstore.Store
+	webAuthn       *webauthn.WebAuthn
+	tokens         *auth.JWTManager
+	audit          AuditUseCase
+}
+
+// NewPasskeyUseCase パスキーユースケースの新しいインスタンスを作成
+func NewPasskeyUseCase(
+	userRepo repository.UserRepository,
+	credentialRepo repository.WebAuthnCredentialRepository,
+	sessions kvstore.Store,
+	webAuthn *webauthn.WebAuthn,
+	tokens *auth.JWTManager,
+	audit AuditUseCase,
+) PasskeyUseCase {
+	return &passkeyUseCase{
+		userRepo:       userRepo,
+		credentialRepo: credentialRepo,
+		sessions:       sessions,
+		webAuthn:       webAuthn,
+		tokens:         tokens,
+		audit:          audit,
+	}
+}
+
+// passkeySession セレモニー間で保持する情報
+type passkeySession struct {
+	UserID int64                `json:"user_id"`
+	Data   webauthn.SessionData `json:"data"`
+}
+
+// webAuthnUser entity.User を webauthn.User に適合させる
+type webAuthnUser struct {
+	user        *entity.User
+	credentials []webauthn.Credential
+	// records クレデンシャルIDからDBレコードへの対応
+	records map[string]*entity.WebAuthnCredential
+}
+
+// WebAuthnID ユーザーハンドル（ユーザーIDの10進表現）
+func (w *webAuthnUser) WebAuthnID() []byte {
+	return userHandle(w.user.ID)
+}
+
+// WebAuthnName ユーザー名
+func (w *webAuthnUser) WebAuthnName() string {
+	return w.user.Email
+}
+
+// WebAuthnDisplayName 表示名
+func (w *webAuthnUser) WebAuthnDisplayName() string {
+	return w.user.Name
+}
+
+// WebAuthnCredentials 登録済みクレデンシャル
+func (w *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return w.credentials
+}
+
+// BeginRegistration パスキー登録を開始
+func (u *passkeyUseCase) BeginRegistration(ctx context.Context, userID int64) (*entity.PasskeyCeremony, error) {
+	user, err := u.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 同じ認証器の二重登録を防ぐ
+	exclusions := webauthn.Credentials(user.credentials).CredentialDescriptors()
+	options, session, err := u.webAuthn.BeginRegistration(user,
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+		webauthn.WithExclusions(exclusions),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin registration: %w", err)
+	}
+
+	return u.saveSession(ctx, passkeyPurposeRegister, userID, session, options)
+}
+
+// FinishRegistration 認証器の応答を検証してパスキーを保存
+func (u *passkeyUseCase) FinishRegistration(ctx context.Context, userID int64, req *entity.PasskeyFinishRequest) (*entity.WebAuthnCredential, error) {
+	session, err := u.takeSession(ctx, passkeyPurposeRegister, req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != userID {
+		return nil, ErrPasskeySessionInvalid
+	}
+
+	user, err := u.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(req.Credential)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPasskeyVerification, err)
+	}
+
+	credential, err := u.webAuthn.CreateCredential(user, session.Data, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPasskeyVerification, err)
+	}
+
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode credential: %w", err)
+	}
+
+	name := req.Name
+	if name == "" {
+		name = "Passkey " + time.Now().Format("2006-01-02")
+	}
+
+	created, err := u.credentialRepo.Create(ctx, &entity.WebAuthnCredential{
+		UserID:       userID,
+		CredentialID: credential.ID,
+		Name:         name,
+		Data:         data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save passkey: %w", err)
+	}
+
+	return created, nil
+}
+
+// List 登録済みパスキー一覧を取得
+func (u *passkeyUseCase) List(ctx context.Context, userID int64) (*entity.PasskeysResponse, error) {
+	credentials, err := u.credentialRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list passkeys: %w", err)
+	}
+
+	return &entity.PasskeysResponse{
+		Passkeys: credentials,
+	}, nil
+}
+
+// Delete パスキーを削除
+func (u *passkeyUseCase) Delete(ctx context.Context, userID, id int64) error {
+	if err := u.credentialRepo.Delete(ctx, userID, id); err != nil {
+		if err.Error() == "passkey not found" {
+			return ErrPasskeyNotFound
+		}
+		return fmt.Errorf("failed to delete passkey: %w", err)
+	}
+	return nil
+}
+
+// BeginLogin パスワードレスログイン（第一要素）を開始
+func (u *passkeyUseCase) BeginLogin(ctx context.Context) (*entity.PasskeyCeremony, error) {
+	options, session, err := u.webAuthn.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationRequired),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin login: %w", err)
+	}
+
+	return u.saveSession(ctx, passkeyPurposeLogin, 0, session, options)
+}
+
+// FinishLogin パスキーの署名を検証してアクセストークンを発行（成否を監査ログに記録する）
+// ユーザー検証（生体認証・PIN）付きのパスキーはそれ自体が多要素のため追加のMFAは求めない
+func (u *passkeyUseCase) FinishLogin(ctx context.Context, req *entity.PasskeyFinishRequest) (*entity.AuthResponse, error) {
+	userID, response, err := u.finishLogin(ctx, req)
+	u.recordLogin(ctx, userID, false, err)
+	return response, err
+}
+
+// finishLogin パスキーの署名を検証してアクセストークンを発行（ユーザーを特定できればそのIDも返す）
+func (u *passkeyUseCase) finishLogin(ctx context.Context, req *entity.PasskeyFinishRequest) (int64, *entity.AuthResponse, error) {
+	session, err := u.takeSession(ctx, passkeyPurposeLogin, req.SessionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(req.Credential)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %v", ErrPasskeyVerification, err)
+	}
+
+	var resolved *webAuthnUser
+	handler := func(rawID, handle []byte) (webauthn.User, error) {
+		userID, err := strconv.ParseInt(string(handle), 10, 64)
+		if err != nil {
+			return nil, ErrPasskeyVerification
+		}
+		resolved, err = u.loadUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		return resolved, nil
+	}
+
+	credential, err := u.webAuthn.ValidateDiscoverableLogin(handler, session.Data, parsed)
+	if err != nil {
+		var userID int64
+		if resolved != nil {
+			userID = resolved.user.ID
+		}
+		return userID, nil, fmt.Errorf("%w: %v", ErrPasskeyVerification, err)
+	}
+
+	if err := u.recordUse(ctx, resolved, credential); err != nil {
+		return resolved.user.ID, nil, err
+	}
+
+	response, err := u.issueToken(resolved.user)
+	return resolved.user.ID, response, err
+}
+
+// BeginMFA パスワード認証後の第二要素としてパスキー認証を開始
+func (u *passkeyUseCase) BeginMFA(ctx context.Context, req *entity.PasskeyBeginRequest) (*entity.PasskeyCeremony, error) {
+	userID, err := u.challengeUserID(req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := u.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(user.credentials) == 0 {
+		return nil, ErrPasskeyNotRegistered
+	}
+
+	options, session, err := u.webAuthn.BeginLogin(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin login: %w", err)
+	}
+
+	return u.saveSession(ctx, passkeyPurposeMFA, userID, session, options)
+}
+
+// FinishMFA 第二要素のパスキー認証を検証してアクセストークンを発行（成否を監査ログに記録する）
+func (u *passkeyUseCase) FinishMFA(ctx context.Context, req *entity.PasskeyFinishRequest) (*entity.AuthResponse, error) {
+	userID, err := u.challengeUserID(req.ChallengeToken)
+	if err != nil {
+		u.recordLogin(ctx, 0, true, err)
+		return nil, err
+	}
+
+	response, err := u.finishMFA(ctx, userID, req)
+	u.recordLogin(ctx, userID, true, err)
+	return response, err
+}
+
+// finishMFA チャレンジのユーザーのパスキー認証を検証してアクセストークンを発行
+func (u *passkeyUseCase) finishMFA(ctx context.Context, userID int64, req *entity.PasskeyFinishRequest) (*entity.AuthResponse, error) {
+	session, err := u.takeSession(ctx, passkeyPurposeMFA, req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != userID {
+		return nil, ErrPasskeySessionInvalid
+	}
+
+	user, err := u.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(req.Credential)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPasskeyVerification, err)
+	}
+
+	credential, err := u.webAuthn.ValidateLogin(user, session.Data, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPasskeyVerification, err)
+	}
+
+	if err := u.recordUse(ctx, user, credential); err != nil {
+		return nil, err
+	}
+
+	return u.issueToken(user.user)
+}
+
+// recordLogin パスキーによるログインの成否を監査ログに記録
+func (u *passkeyUseCase) recordLogin(ctx context.Context, userID int64, secondFactor bool, err error) {
+	metadata := map[string]interface{}{"method": "passkey"}
+	if secondFactor {
+		metadata["second_factor"] = true
+	}
+	if err != nil {
+		recordLoginError(ctx, u.audit, userID, err, metadata)
+		return
+	}
+	recordLoginSuccess(ctx, u.audit, userID, metadata)
+}
+
+// loadUser ユーザーと登録済みクレデンシャルを読み込む
+func (u *passkeyUseCase) loadUser(ctx context.Context, userID int64) (*webAuthnUser, error) {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	records, err := u.credentialRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list passkeys: %w", err)
+	}
+
+	w := &webAuthnUser{
+		user:    user,
+		records: make(map[string]*entity.WebAuthnCredential, len(records)),
+	}
+	for _, record := range records {
+		var credential webauthn.Credential
+		if err := json.Unmarshal(record.Data, &credential); err != nil {
+			return nil, fmt.Errorf("failed to decode passkey %d: %w", record.ID, err)
+		}
+		w.credentials = append(w.credentials, credential)
+		w.records[string(credential.ID)] = record
+	}
+
+	return w, nil
+}
+
+// recordUse 署名カウンタを更新（クローン検知時は拒否）
+func (u *passkeyUseCase) recordUse(ctx context.Context, user *webAuthnUser, credential *webauthn.Credential) error {
+	if credential.Authenticator.CloneWarning {
+		return ErrPasskeyCloned
+	}
+
+	record, ok := user.records[string(credential.ID)]
+	if !ok {
+		return ErrPasskeyVerification
+	}
+
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return fmt.Errorf("failed to encode credential: %w", err)
+	}
+
+	if err := u.credentialRepo.UpdateAfterLogin(ctx, record.ID, data); err != nil {
+		return fmt.Errorf("failed to update passkey: %w", err)
+	}
+
+	return nil
+}
+
+// issueToken アクセストークンを発行
+func (u *passkeyUseCase) issueToken(user *entity.User) (*entity.AuthResponse, error) {
+	if err := checkAccountStatus(user); err != nil {
+		return nil, err
+	}
+
+	token, _, err := u.tokens.Generate(user.ID, user.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return &entity.AuthResponse{
+		User:  user,
+		Token: token,
+	}, nil
+}
+
+// challengeUserID MFAチャレンジトークンからユーザーIDを取得
+func (u *passkeyUseCase) challengeUserID(challengeToken string) (int64, error) {
+	claims, err := u.tokens.ParseMFAChallenge(challengeToken)
+	if err != nil {
+		return 0, err
+	}
+	return claims.UserID()
+}
+
+// saveSession セッションを保存してセレモニー開始レスポンスを作成
+func (u *passkeyUseCase) saveSession(ctx context.Context, purpose string, userID int64, data *webauthn.SessionData, options interface{}) (*entity.PasskeyCeremony, error) {
+	sessionID, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(&passkeySession{UserID: userID, Data: *data})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode passkey session: %w", err)
+	}
+
+	if err := u.sessions.Set(ctx, passkeySessionKey(purpose, sessionID), payload, passkeySessionTTL); err != nil {
+		return nil, fmt.Errorf("failed to store passkey session: %w", err)
+	}
+
+	return &entity.PasskeyCeremony{
+		SessionID: sessionID,
+		Options:   options,
+	}, nil
+}
+
+// takeSession セッションを取り出して削除（チャレンジは一度しか使えない）
+func (u *passkeyUseCase) takeSession(ctx context.Context, purpose, sessionID string) (*passkeySession, error) {
+	payload, err := u.sessions.GetDel(ctx, passkeySessionKey(purpose, sessionID))
+	if errors.Is(err, kvstore.ErrNotFound) {
+		return nil, ErrPasskeySessionInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load passkey session: %w", err)
+	}
+
+	var session passkeySession
+	if err := json.Unmarshal(payload, &session); err != nil {
+		return nil, ErrPasskeySessionInvalid
+	}
+
+	return &session, nil
+}
+
+// passkeySessionKey セッション保存用のキー
+func passkeySessionKey(purpose, sessionID string) string {
+	return "webauthn:" + purpose + ":" + sessionID
+}
+
+// userHandle ユーザーIDをWebAuthnのユーザーハンドルに変換
+func userHandle(userID int64) []byte {
+	return []byte(strconv.FormatInt(userID, 10))
+}
+
+// newSessionID ランダムなセッションIDを生成
+func newSessionID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
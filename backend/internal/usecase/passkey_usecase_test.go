@@ -0,0 +1,472 @@
+package usecase
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+
+	"app-template/internal/entity"
+	"app-template/pkg/auth"
+	"app-template/pkg/kvstore"
+)
+
+const (
+	testRPID   = "localhost"
+	testOrigin = "http://localhost:3000"
+)
+
+// softAuthenticator ES256の鍵をメモリに持つソフトウェア認証器（attestation none）
+type softAuthenticator struct {
+	origin string
+}
+
+type softCredential struct {
+	id         []byte
+	key        *ecdsa.PrivateKey
+	userHandle []byte
+}
+
+func newSoftAuthenticator() *softAuthenticator {
+	return &softAuthenticator{origin: testOrigin}
+}
+
+// create navigator.credentials.create() の応答を作る
+func (a *softAuthenticator) create(t *testing.T, options interface{}) ([]byte, *softCredential) {
+	t.Helper()
+	creation, ok := options.(*protocol.CredentialCreation)
+	if !ok {
+		t.Fatalf("options = %T, want *protocol.CredentialCreation", options)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	cred := &softCredential{id: id, key: key}
+	switch handle := creation.Response.User.ID.(type) {
+	case protocol.URLEncodedBase64:
+		cred.userHandle = handle
+	case []byte:
+		cred.userHandle = handle
+	case string:
+		cred.userHandle = []byte(handle)
+	}
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  int64(webauthncose.P256),
+		XCoord: key.X.FillBytes(make([]byte, 32)),
+		YCoord: key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authData := a.authData(0x01|0x04|0x40, 0)
+	authData = append(authData, make([]byte, 16)...) // AAGUID
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(id)))
+	authData = append(authData, id...)
+	authData = append(authData, publicKey...)
+
+	attestation, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": authData,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a.response(id, map[string]string{
+		"clientDataJSON":    a.clientData(t, "webauthn.create", creation.Response.Challenge),
+		"attestationObject": encode(attestation),
+	}), cred
+}
+
+// get navigator.credentials.get() の応答を作る（署名カウンタを counter にする）
+func (a *softAuthenticator) get(t *testing.T, options interface{}, cred *softCredential, counter uint32) []byte {
+	t.Helper()
+	assertion, ok := options.(*protocol.CredentialAssertion)
+	if !ok {
+		t.Fatalf("options = %T, want *protocol.CredentialAssertion", options)
+	}
+
+	authData := a.authData(0x01|0x04, counter)
+	clientData := a.clientData(t, "webauthn.get", assertion.Response.Challenge)
+	rawClientData, _ := base64.RawURLEncoding.DecodeString(clientData)
+	hash := sha256.Sum256(rawClientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), hash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, cred.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a.response(cred.id, map[string]string{
+		"clientDataJSON":    clientData,
+		"authenticatorData": encode(authData),
+		"signature":         encode(signature),
+		"userHandle":        encode(cred.userHandle),
+	})
+}
+
+func (a *softAuthenticator) authData(flags byte, counter uint32) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+	data := append(rpIDHash[:], flags)
+	return binary.BigEndian.AppendUint32(data, counter)
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, ceremony string, challenge protocol.URLEncodedBase64) string {
+	t.Helper()
+	data, err := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": challenge.String(),
+		"origin":    a.origin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encode(data)
+}
+
+func (a *softAuthenticator) response(id []byte, response map[string]string) []byte {
+	body, _ := json.Marshal(map[string]interface{}{
+		"id":       encode(id),
+		"rawId":    encode(id),
+		"type":     "public-key",
+		"response": response,
+	})
+	return body
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+type passkeyFixture struct {
+	users       *memUserRepo
+	credentials *memCredentialRepo
+	tokens      *auth.JWTManager
+	audit       *memAudit
+	uc          PasskeyUseCase
+	user        *entity.User
+}
+
+func newPasskeyFixture(t *testing.T) *passkeyFixture {
+	t.Helper()
+	w, err := webauthn.New(&webauthn.Config{RPID: testRPID, RPDisplayName: "Test", RPOrigins: []string{testOrigin}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &passkeyFixture{
+		users:       newMemUserRepo(),
+		credentials: &memCredentialRepo{},
+		tokens:      auth.NewJWTManager(auth.JWTConfig{Secret: "secret", Issuer: "test", Audience: "test", TTL: time.Hour}),
+		audit:       &memAudit{},
+	}
+	f.uc = NewPasskeyUseCase(f.users, f.credentials, kvstore.NewMemory(), w, f.tokens, f.audit)
+	f.user, err = f.users.Create(context.Background(), &entity.User{Email: "alice@example.com", Name: "Alice", Role: auth.RoleUser})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// register パスキーを登録する
+func (f *passkeyFixture) register(t *testing.T, a *softAuthenticator) *softCredential {
+	t.Helper()
+	ctx := context.Background()
+	ceremony, err := f.uc.BeginRegistration(ctx, f.user.ID)
+	if err != nil {
+		t.Fatalf("BeginRegistration: %v", err)
+	}
+	body, cred := a.create(t, ceremony.Options)
+	created, err := f.uc.FinishRegistration(ctx, f.user.ID, &entity.PasskeyFinishRequest{SessionID: ceremony.SessionID, Name: "laptop", Credential: body})
+	if err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+	if created.UserID != f.user.ID || created.Name != "laptop" || string(created.CredentialID) != string(cred.id) {
+		t.Fatalf("created passkey = %+v", created)
+	}
+	return cred
+}
+
+// login パスワードレスログインを行う
+func (f *passkeyFixture) login(t *testing.T, a *softAuthenticator, cred *softCredential, counter uint32) (*entity.AuthResponse, error) {
+	t.Helper()
+	ceremony, err := f.uc.BeginLogin(context.Background())
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	body := a.get(t, ceremony.Options, cred, counter)
+	return f.uc.FinishLogin(context.Background(), &entity.PasskeyFinishRequest{SessionID: ceremony.SessionID, Credential: body})
+}
+
+func TestPasskeyRegisterAndPasswordlessLogin(t *testing.T) {
+	f := newPasskeyFixture(t)
+	a := newSoftAuthenticator()
+	cred := f.register(t, a)
+
+	resp, err := f.login(t, a, cred, 1)
+	if err != nil {
+		t.Fatalf("FinishLogin: %v", err)
+	}
+	if resp.User == nil || resp.User.ID != f.user.ID || resp.Token == "" || resp.MFARequired {
+		t.Fatalf("login response = %+v", resp)
+	}
+	claims, err := f.tokens.Parse(resp.Token)
+	if err != nil {
+		t.Fatalf("Parse token: %v", err)
+	}
+	if id, _ := claims.UserID(); id != f.user.ID {
+		t.Errorf("token subject = %d, want %d", id, f.user.ID)
+	}
+
+	// 署名カウンタと最終利用日時を保存する
+	stored := f.credentials.credentials[0]
+	var credential webauthn.Credential
+	if err := json.Unmarshal(stored.Data, &credential); err != nil {
+		t.Fatal(err)
+	}
+	if credential.Authenticator.SignCount != 1 || stored.LastUsedAt == nil {
+		t.Errorf("stored sign count = %d, last used = %v", credential.Authenticator.SignCount, stored.LastUsedAt)
+	}
+
+	if _, err := f.login(t, a, cred, 2); err != nil {
+		t.Errorf("second login: %v", err)
+	}
+	want := []string{entity.AuditActionUserLoginSuccess, entity.AuditActionUserLoginSuccess}
+	if got := f.audit.actions(); !slices.Equal(got, want) {
+		t.Errorf("audit actions = %v, want %v", got, want)
+	}
+}
+
+func TestPasskeyRegistrationExcludesRegisteredCredentials(t *testing.T) {
+	f := newPasskeyFixture(t)
+	cred := f.register(t, newSoftAuthenticator())
+
+	ceremony, err := f.uc.BeginRegistration(context.Background(), f.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exclusions := ceremony.Options.(*protocol.CredentialCreation).Response.CredentialExcludeList
+	if len(exclusions) != 1 || string(exclusions[0].CredentialID) != string(cred.id) {
+		t.Errorf("excluded credentials = %+v", exclusions)
+	}
+}
+
+func TestPasskeyRegistrationSessionIsBoundToUserAndSingleUse(t *testing.T) {
+	f := newPasskeyFixture(t)
+	ctx := context.Background()
+	other, err := f.users.Create(ctx, &entity.User{Email: "bob@example.com", Name: "Bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ceremony, err := f.uc.BeginRegistration(ctx, f.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := newSoftAuthenticator().create(t, ceremony.Options)
+
+	req := &entity.PasskeyFinishRequest{SessionID: ceremony.SessionID, Credential: body}
+	if _, err := f.uc.FinishRegistration(ctx, other.ID, req); !errors.Is(err, ErrPasskeySessionInvalid) {
+		t.Fatalf("other user: error = %v, want ErrPasskeySessionInvalid", err)
+	}
+	// 失敗してもチャレンジは消費済み
+	if _, err := f.uc.FinishRegistration(ctx, f.user.ID, req); !errors.Is(err, ErrPasskeySessionInvalid) {
+		t.Fatalf("reused session: error = %v, want ErrPasskeySessionInvalid", err)
+	}
+	if len(f.credentials.credentials) != 0 {
+		t.Errorf("credentials = %d, want 0", len(f.credentials.credentials))
+	}
+}
+
+func TestPasskeyRegistrationRejectsWrongOrigin(t *testing.T) {
+	f := newPasskeyFixture(t)
+	ctx := context.Background()
+	a := newSoftAuthenticator()
+	a.origin = "https://evil.example"
+
+	ceremony, err := f.uc.BeginRegistration(ctx, f.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := a.create(t, ceremony.Options)
+	_, err = f.uc.FinishRegistration(ctx, f.user.ID, &entity.PasskeyFinishRequest{SessionID: ceremony.SessionID, Credential: body})
+	if !errors.Is(err, ErrPasskeyVerification) {
+		t.Fatalf("error = %v, want ErrPasskeyVerification", err)
+	}
+}
+
+func TestPasskeyLoginRejectsInvalidAssertions(t *testing.T) {
+	f := newPasskeyFixture(t)
+	a := newSoftAuthenticator()
+	cred := f.register(t, a)
+	ctx := context.Background()
+
+	// 登録していない鍵での署名
+	forged := *cred
+	forged.key, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, err := f.login(t, a, &forged, 1); !errors.Is(err, ErrPasskeyVerification) {
+		t.Errorf("forged signature: error = %v, want ErrPasskeyVerification", err)
+	}
+
+	// 別のセッションのチャレンジへの応答
+	first, err := f.uc.BeginLogin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := f.uc.BeginLogin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := a.get(t, first.Options, cred, 1)
+	if _, err := f.uc.FinishLogin(ctx, &entity.PasskeyFinishRequest{SessionID: second.SessionID, Credential: body}); !errors.Is(err, ErrPasskeyVerification) {
+		t.Errorf("challenge of another session: error = %v, want ErrPasskeyVerification", err)
+	}
+
+	// 用途の異なるセッションIDは使えない
+	registration, err := f.uc.BeginRegistration(ctx, f.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.uc.FinishLogin(ctx, &entity.PasskeyFinishRequest{SessionID: registration.SessionID, Credential: body}); !errors.Is(err, ErrPasskeySessionInvalid) {
+		t.Errorf("registration session: error = %v, want ErrPasskeySessionInvalid", err)
+	}
+
+	var reasons []string
+	for _, entry := range f.audit.loginEntries() {
+		if entry.Action != entity.AuditActionUserLoginFailure {
+			t.Fatalf("recorded %s for a rejected assertion", entry.Action)
+		}
+		reasons = append(reasons, stringValue(entry.Metadata["reason"]))
+	}
+	if want := []string{"verification_failed", "verification_failed", "invalid_session"}; !slices.Equal(reasons, want) {
+		t.Errorf("failure reasons = %v, want %v", reasons, want)
+	}
+}
+
+func TestPasskeyLoginDetectsClonedAuthenticator(t *testing.T) {
+	f := newPasskeyFixture(t)
+	a := newSoftAuthenticator()
+	cred := f.register(t, a)
+
+	if _, err := f.login(t, a, cred, 5); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if _, err := f.login(t, a, cred, 3); !errors.Is(err, ErrPasskeyCloned) {
+		t.Fatalf("counter went backwards: error = %v, want ErrPasskeyCloned", err)
+	}
+}
+
+func TestPasskeyLoginRejectsSuspendedUser(t *testing.T) {
+	f := newPasskeyFixture(t)
+	a := newSoftAuthenticator()
+	cred := f.register(t, a)
+	f.users.users[f.user.ID].Status = entity.UserStatusSuspended
+
+	if _, err := f.login(t, a, cred, 1); !errors.Is(err, auth.ErrAccountSuspended) {
+		t.Fatalf("error = %v, want ErrAccountSuspended", err)
+	}
+}
+
+func TestPasskeyAsSecondFactor(t *testing.T) {
+	f := newPasskeyFixture(t)
+	a := newSoftAuthenticator()
+	ctx := context.Background()
+
+	challenge, err := f.tokens.GenerateMFAChallenge(f.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.uc.BeginMFA(ctx, &entity.PasskeyBeginRequest{ChallengeToken: challenge}); !errors.Is(err, ErrPasskeyNotRegistered) {
+		t.Fatalf("no passkeys: error = %v, want ErrPasskeyNotRegistered", err)
+	}
+
+	cred := f.register(t, a)
+	ceremony, err := f.uc.BeginMFA(ctx, &entity.PasskeyBeginRequest{ChallengeToken: challenge})
+	if err != nil {
+		t.Fatalf("BeginMFA: %v", err)
+	}
+	allowed := ceremony.Options.(*protocol.CredentialAssertion).Response.AllowedCredentials
+	if len(allowed) != 1 || string(allowed[0].CredentialID) != string(cred.id) {
+		t.Errorf("allowed credentials = %+v", allowed)
+	}
+	body := a.get(t, ceremony.Options, cred, 1)
+
+	// チャレンジトークンはアクセストークンの代わりにならない
+	access, _, err := f.tokens.Generate(f.user.ID, f.user.Role)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.uc.FinishMFA(ctx, &entity.PasskeyFinishRequest{ChallengeToken: access, SessionID: ceremony.SessionID, Credential: body}); err == nil {
+		t.Fatal("FinishMFA accepted an access token as the challenge")
+	}
+
+	// 別のユーザーのチャレンジトークンではセッションが一致しない
+	other, err := f.tokens.GenerateMFAChallenge(f.user.ID + 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.uc.FinishMFA(ctx, &entity.PasskeyFinishRequest{ChallengeToken: other, SessionID: ceremony.SessionID, Credential: body}); !errors.Is(err, ErrPasskeySessionInvalid) {
+		t.Fatalf("other user's challenge: error = %v, want ErrPasskeySessionInvalid", err)
+	}
+
+	ceremony, err = f.uc.BeginMFA(ctx, &entity.PasskeyBeginRequest{ChallengeToken: challenge})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := f.uc.FinishMFA(ctx, &entity.PasskeyFinishRequest{ChallengeToken: challenge, SessionID: ceremony.SessionID, Credential: a.get(t, ceremony.Options, cred, 2)})
+	if err != nil {
+		t.Fatalf("FinishMFA: %v", err)
+	}
+	if resp.Token == "" || resp.User.ID != f.user.ID {
+		t.Errorf("mfa response = %+v", resp)
+	}
+
+	want := []string{entity.AuditActionUserLoginFailure, entity.AuditActionUserLoginFailure, entity.AuditActionUserLoginSuccess}
+	if got := f.audit.actions(); !slices.Equal(got, want) {
+		t.Errorf("audit actions = %v, want %v", got, want)
+	}
+}
+
+func TestPasskeyDelete(t *testing.T) {
+	f := newPasskeyFixture(t)
+	f.register(t, newSoftAuthenticator())
+	ctx := context.Background()
+	id := f.credentials.credentials[0].ID
+
+	if err := f.uc.Delete(ctx, f.user.ID+1, id); !errors.Is(err, ErrPasskeyNotFound) {
+		t.Fatalf("other user's passkey: error = %v, want ErrPasskeyNotFound", err)
+	}
+	if err := f.uc.Delete(ctx, f.user.ID, id); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	list, err := f.uc.List(ctx, f.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Passkeys) != 0 {
+		t.Errorf("passkeys = %d, want 0", len(list.Passkeys))
+	}
+}
@@ -0,0 +1,326 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/crypto/bcrypt"
+
+	"app-template/internal/entity"
+	"app-template/internal/repository"
+	"app-template/pkg/auth"
+)
+
+const (
+	// importBatchSize 一括取り込みで1回のINSERTにまとめる件数
+	importBatchSize = 500
+	// exportBatchSize 一括出力で1回に読み込む件数
+	exportBatchSize = 500
+	// maxPasswordBytes bcryptが扱えるパスワードの最大バイト数
+	maxPasswordBytes = 72
+)
+
+// 一括取り込みの行のエラー
+var (
+	ErrInvalidEmail      = errors.New("invalid email address")
+	ErrNameRequired      = errors.New("name is required")
+	ErrNameTooLong       = errors.New("name must be at most 255 characters")
+	ErrPasswordTooLong   = errors.New("password must be at most 72 bytes")
+	ErrDuplicateInImport = errors.New("duplicate email in import")
+	ErrEmailExists       = errors.New("email already exists")
+)
+
+// ImportRowError 入力の1行を読み取れなかった（その行を失敗として取り込みを続ける）
+type ImportRowError struct {
+	Line int
+	Err  error
+}
+
+func (e *ImportRowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ImportRowError) Unwrap() error {
+	return e.Err
+}
+
+// UserRowReader 取り込む行を順に返す（終端で io.EOF、読み取れない行は *ImportRowError）
+type UserRowReader interface {
+	Next() (*entity.ImportUserRow, error)
+}
+
+// UserBulkUseCase ユーザーの一括取り込み・出力のインターフェース
+type UserBulkUseCase interface {
+	Import(ctx context.Context, rows UserRowReader, dryRun bool) (*entity.ImportUsersResponse, error)
+	Export(ctx context.Context, write func(*entity.User) error) error
+}
+
+// userBulkUseCase ユーザーの一括取り込み・出力の実装
+type userBulkUseCase struct {
+	userRepo repository.UserRepository
+	audit    AuditUseCase
+	validate *validator.Validate
+}
+
+// NewUserBulkUseCase ユーザーの一括取り込み・出力の新しいインスタンスを作成
+func NewUserBulkUseCase(userRepo repository.UserRepository, audit AuditUseCase) UserBulkUseCase {
+	return &userBulkUseCase{
+		userRepo: userRepo,
+		audit:    audit,
+		validate: validator.New(),
+	}
+}
+
+// importRow 検証済みで作成待ちの行
+type importRow struct {
+	row    *entity.ImportUserRow
+	result *entity.ImportRowResult
+}
+
+// Import 行を順に読みながら検証し、importBatchSize 件ずつまとめて作成
+// 入力内・登録済みのメールアドレスと重複する行はスキップ、検証に失敗した行は失敗として続行する
+// ドライランでは検証と重複の確認のみ行い、何も書き込まない
+func (u *userBulkUseCase) Import(ctx context.Context, rows UserRowReader, dryRun bool) (*entity.ImportUsersResponse, error) {
+	response := &entity.ImportUsersResponse{
+		DryRun: dryRun,
+		Rows:   []*entity.ImportRowResult{},
+	}
+	seen := make(map[string]bool)
+	pending := make([]*importRow, 0, importBatchSize)
+
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+
+		var rowErr *ImportRowError
+		if errors.As(err, &rowErr) {
+			response.Rows = append(response.Rows, &entity.ImportRowResult{
+				Line:   rowErr.Line,
+				Status: entity.ImportStatusFailed,
+				Error:  rowErr.Err.Error(),
+			})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read import: %w", err)
+		}
+
+		normalizeImportRow(row)
+		result := &entity.ImportRowResult{Line: row.Line, Email: row.Email}
+		response.Rows = append(response.Rows, result)
+
+		if err := u.validateImportRow(row); err != nil {
+			result.Status = entity.ImportStatusFailed
+			result.Error = err.Error()
+			continue
+		}
+
+		key := strings.ToLower(row.Email)
+		if seen[key] {
+			result.Status = entity.ImportStatusSkipped
+			result.Error = ErrDuplicateInImport.Error()
+			continue
+		}
+		seen[key] = true
+
+		pending = append(pending, &importRow{row: row, result: result})
+		if len(pending) == importBatchSize {
+			if err := u.importBatch(ctx, pending, dryRun); err != nil {
+				return nil, err
+			}
+			pending = pending[:0]
+		}
+	}
+
+	if err := u.importBatch(ctx, pending, dryRun); err != nil {
+		return nil, err
+	}
+
+	for _, result := range response.Rows {
+		switch result.Status {
+		case entity.ImportStatusCreated:
+			response.Created++
+		case entity.ImportStatusSkipped:
+			response.Skipped++
+		default:
+			response.Failed++
+		}
+	}
+
+	if !dryRun {
+		u.audit.Record(ctx, &entity.AuditLog{
+			Action: entity.AuditActionUserImport,
+			Metadata: map[string]interface{}{
+				"created": response.Created,
+				"skipped": response.Skipped,
+				"failed":  response.Failed,
+			},
+		})
+	}
+
+	return response, nil
+}
+
+// importBatch 登録済みのメールアドレスを除いてまとめて作成
+// INSERTに失敗した場合はバッチ内の行をすべて失敗とし、続きの取り込みは続ける
+func (u *userBulkUseCase) importBatch(ctx context.Context, rows []*importRow, dryRun bool) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	emails := make([]string, len(rows))
+	for i, row := range rows {
+		emails[i] = row.row.Email
+	}
+	existing, err := u.userRepo.ExistingEmails(ctx, emails)
+	if err != nil {
+		return fmt.Errorf("failed to check emails: %w", err)
+	}
+
+	creating := make([]*importRow, 0, len(rows))
+	for _, row := range rows {
+		if existing[strings.ToLower(row.row.Email)] {
+			row.result.Status = entity.ImportStatusSkipped
+			row.result.Error = ErrEmailExists.Error()
+			continue
+		}
+		creating = append(creating, row)
+	}
+
+	if dryRun {
+		for _, row := range creating {
+			row.result.Status = entity.ImportStatusCreated
+		}
+		return nil
+	}
+
+	users := hashImportPasswords(creating)
+	if len(users) == 0 {
+		return nil
+	}
+
+	if err := u.userRepo.CreateBatch(ctx, users); err != nil {
+		log.Printf("failed to import users (lines %d-%d): %v", creating[0].row.Line, creating[len(creating)-1].row.Line, err)
+		for _, row := range creating {
+			if row.result.Status == "" {
+				row.result.Status = entity.ImportStatusFailed
+				row.result.Error = "failed to create user"
+			}
+		}
+		return nil
+	}
+
+	for _, row := range creating {
+		if row.result.Status == "" {
+			row.result.Status = entity.ImportStatusCreated
+		}
+	}
+	return nil
+}
+
+// hashImportPasswords パスワードを並列にハッシュ化して作成するユーザーを返す
+// ハッシュ化に失敗した行は失敗とし、作成対象から除く
+func hashImportPasswords(rows []*importRow) []*entity.User {
+	users := make([]*entity.User, len(rows))
+	sem := make(chan struct{}, runtime.NumCPU())
+	var wg sync.WaitGroup
+
+	for i, row := range rows {
+		users[i] = &entity.User{
+			Email: row.row.Email,
+			Name:  row.row.Name,
+			Role:  row.row.Role,
+		}
+		if row.row.Password == "" {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(user *entity.User, row *importRow) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			hashedPassword, err := bcrypt.GenerateFromPassword([]byte(row.row.Password), bcrypt.DefaultCost)
+			if err != nil {
+				row.result.Status = entity.ImportStatusFailed
+				row.result.Error = "failed to hash password"
+				return
+			}
+			user.Password = string(hashedPassword)
+		}(users[i], row)
+	}
+	wg.Wait()
+
+	hashed := users[:0]
+	for i, row := range rows {
+		if row.result.Status == "" {
+			hashed = append(hashed, users[i])
+		}
+	}
+	return hashed
+}
+
+// normalizeImportRow 前後の空白を除き、ロールの既定値を設定
+func normalizeImportRow(row *entity.ImportUserRow) {
+	row.Email = strings.TrimSpace(row.Email)
+	row.Name = strings.TrimSpace(row.Name)
+	row.Role = strings.TrimSpace(row.Role)
+	if row.Role == "" {
+		row.Role = auth.RoleUser
+	}
+}
+
+// validateImportRow 行を検証（登録APIと同じ規則）
+func (u *userBulkUseCase) validateImportRow(row *entity.ImportUserRow) error {
+	if err := u.validate.Var(row.Email, "required,email"); err != nil {
+		return ErrInvalidEmail
+	}
+	if row.Name == "" {
+		return ErrNameRequired
+	}
+	if len([]rune(row.Name)) > 255 {
+		return ErrNameTooLong
+	}
+	if row.Password != "" && len(row.Password) < minPasswordLength {
+		return ErrPasswordTooShort
+	}
+	if len(row.Password) > maxPasswordBytes {
+		return ErrPasswordTooLong
+	}
+	if !auth.IsRole(row.Role) {
+		return ErrInvalidRole
+	}
+	return nil
+}
+
+// Export ユーザーをID順に exportBatchSize 件ずつ読み込んで1件ずつ書き出す（全件をメモリに載せない）
+func (u *userBulkUseCase) Export(ctx context.Context, write func(*entity.User) error) error {
+	var afterID int64
+	for {
+		users, err := u.userRepo.ListAfterID(ctx, afterID, exportBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to export users: %w", err)
+		}
+
+		for _, user := range users {
+			if err := write(user); err != nil {
+				return err
+			}
+		}
+
+		if len(users) < exportBatchSize {
+			return nil
+		}
+		afterID = users[len(users)-1].ID
+	}
+}
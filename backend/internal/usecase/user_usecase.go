@@ -2,42 +2,137 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 
 	"app-template/internal/entity"
 	"app-template/internal/repository"
+	"app-template/pkg/auth"
+	"app-template/pkg/tenant"
 )
 
+// minPasswordLength パスワードの最小文字数
+const minPasswordLength = 8
+
+// ユーザー管理のエラー
+var (
+	ErrInvalidRole         = errors.New("invalid role")
+	ErrCannotChangeOwnRole = errors.New("cannot change your own role")
+	ErrPasswordTooShort    = errors.New("password must be at least 8 characters")
+	// ErrCannotChangeOwnStatus 管理者が自分自身を停止して締め出されるのを防ぐ
+	ErrCannotChangeOwnStatus = errors.New("cannot change your own account status")
+	ErrInvalidSuspendUntil   = errors.New("suspension end must be in the future")
+	// ErrRegistrationInviteOnly 招待制のため招待の承諾以外では登録できない
+	ErrRegistrationInviteOnly = errors.New("registration is by invitation only")
+)
+
+// RegistrationMode 新規登録の受け付け方
+type RegistrationMode string
+
+const (
+	// RegistrationOpen 誰でも登録できる
+	RegistrationOpen RegistrationMode = "open"
+	// RegistrationInviteOnly 招待の承諾でのみ登録できる（外部プロバイダーでの新規登録も拒否する）
+	RegistrationInviteOnly RegistrationMode = "invite_only"
+)
+
+// ParseRegistrationMode 登録の受け付け方を解析（空文字列は open）
+func ParseRegistrationMode(value string) (RegistrationMode, error) {
+	switch mode := RegistrationMode(value); mode {
+	case "":
+		return RegistrationOpen, nil
+	case RegistrationOpen, RegistrationInviteOnly:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown registration mode %q", value)
+	}
+}
+
 // UserUseCase ユーザーユースケースのインターフェース
 type UserUseCase interface {
 	Register(ctx context.Context, req *entity.CreateUserRequest) (*entity.AuthResponse, error)
+	RegisterWithRole(ctx context.Context, req *entity.CreateUserRequest, role string) (*entity.AuthResponse, error)
 	Login(ctx context.Context, req *entity.LoginRequest) (*entity.AuthResponse, error)
+	CreateUser(ctx context.Context, req *entity.CreateUserRequest, role string) (*entity.User, error)
+	ResetPassword(ctx context.Context, id int64, password string) error
 	GetByID(ctx context.Context, id int64) (*entity.User, error)
+	GetByIDs(ctx context.Context, ids []int64) (map[int64]*entity.User, error)
 	Update(ctx context.Context, id int64, req *entity.UpdateUserRequest) (*entity.User, error)
+	ChangeRole(ctx context.Context, actorID, id int64, role string) (*entity.User, error)
+	Suspend(ctx context.Context, actorID, id int64, req *entity.SuspendUserRequest) (*entity.User, error)
+	Disable(ctx context.Context, actorID, id int64, req *entity.DisableUserRequest) (*entity.User, error)
+	Reinstate(ctx context.Context, actorID, id int64) (*entity.User, error)
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context, params *entity.PaginationParams) (*entity.UsersResponse, error)
 }
 
+// SessionRevoker ユーザーのログインセッションを失効させる（session.Manager）
+type SessionRevoker interface {
+	RevokeUser(ctx context.Context, userID int64) error
+}
+
 // userUseCase ユーザーユースケースの実装
 type userUseCase struct {
-	userRepo  repository.UserRepository
-	jwtSecret string
+	userRepo repository.UserRepository
+	mfaRepo  repository.MFARepository
+	tokens   *auth.JWTManager
+	audit    AuditUseCase
+	accounts AccountStateCache
+	sessions SessionRevoker
+	// registration 新規登録の受け付け方
+	registration RegistrationMode
 }
 
 // NewUserUseCase ユーザーユースケースの新しいインスタンスを作成
-func NewUserUseCase(userRepo repository.UserRepository, jwtSecret string) UserUseCase {
+func NewUserUseCase(userRepo repository.UserRepository, mfaRepo repository.MFARepository, tokens *auth.JWTManager, audit AuditUseCase, accounts AccountStateCache, sessions SessionRevoker, registration RegistrationMode) UserUseCase {
 	return &userUseCase{
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
+		userRepo:     userRepo,
+		mfaRepo:      mfaRepo,
+		tokens:       tokens,
+		audit:        audit,
+		accounts:     accounts,
+		sessions:     sessions,
+		registration: registration,
 	}
 }
 
-// Register 新しいユーザーを登録
+// Register 新しいユーザーを登録（招待制ならErrRegistrationInviteOnly）
 func (u *userUseCase) Register(ctx context.Context, req *entity.CreateUserRequest) (*entity.AuthResponse, error) {
+	if u.registration == RegistrationInviteOnly {
+		return nil, ErrRegistrationInviteOnly
+	}
+	return u.RegisterWithRole(ctx, req, auth.RoleUser)
+}
+
+// RegisterWithRole ロールを指定して新しいユーザーを登録しトークンを発行
+// 登録の受け付け方は確認しないため、招待の承諾など登録を許可済みの経路から呼び出す
+func (u *userUseCase) RegisterWithRole(ctx context.Context, req *entity.CreateUserRequest, role string) (*entity.AuthResponse, error) {
+	createdUser, err := u.CreateUser(ctx, req, role)
+	if err != nil {
+		return nil, err
+	}
+
+	// JWTトークンを生成
+	token, err := u.generateJWT(createdUser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return &entity.AuthResponse{
+		User:  createdUser,
+		Token: token,
+	}, nil
+}
+
+// CreateUser ロールを指定してユーザーを作成（トークンは発行しない）
+func (u *userUseCase) CreateUser(ctx context.Context, req *entity.CreateUserRequest, role string) (*entity.User, error) {
+	if !auth.IsRole(role) {
+		return nil, ErrInvalidRole
+	}
+
 	// メールアドレスの重複チェック
 	existingUser, err := u.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
@@ -58,6 +153,7 @@ func (u *userUseCase) Register(ctx context.Context, req *entity.CreateUserReques
 		Email:    req.Email,
 		Name:     req.Name,
 		Password: string(hashedPassword),
+		Role:     role,
 	}
 
 	createdUser, err := u.userRepo.Create(ctx, user)
@@ -65,45 +161,90 @@ func (u *userUseCase) Register(ctx context.Context, req *entity.CreateUserReques
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	// JWTトークンを生成
-	token, err := u.generateJWT(createdUser.ID)
+	// 本人による登録なら操作者は作成されたユーザー自身
+	entry := &entity.AuditLog{
+		Action:       entity.AuditActionUserRegister,
+		TargetUserID: auditUserID(createdUser.ID),
+		Metadata: map[string]interface{}{
+			"email": createdUser.Email,
+			"role":  createdUser.Role,
+		},
+	}
+	if _, ok := auth.PrincipalFromContext(ctx); !ok {
+		entry.ActorID = auditUserID(createdUser.ID)
+	}
+	u.audit.Record(ctx, entry)
+
+	return createdUser, nil
+}
+
+// ResetPassword パスワードを再設定し、ユーザーのセッションを失効させる
+func (u *userUseCase) ResetPassword(ctx context.Context, id int64, password string) error {
+	if len(password) < minPasswordLength {
+		return ErrPasswordTooShort
+	}
+
+	if _, err := u.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	return &entity.AuthResponse{
-		User:  createdUser,
-		Token: token,
-	}, nil
+	if err := u.userRepo.UpdatePassword(ctx, id, string(hashedPassword)); err != nil {
+		if err.Error() == "user not found" {
+			return err
+		}
+		return fmt.Errorf("failed to reset password: %w", err)
+	}
+	if err := u.sessions.RevokeUser(ctx, id); err != nil {
+		return err
+	}
+
+	u.audit.Record(ctx, &entity.AuditLog{
+		Action:       entity.AuditActionPasswordReset,
+		TargetUserID: auditUserID(id),
+	})
+
+	return nil
 }
 
 // Login ユーザーのログイン
 func (u *userUseCase) Login(ctx context.Context, req *entity.LoginRequest) (*entity.AuthResponse, error) {
+	metadata := map[string]interface{}{"method": "password"}
+
 	// ユーザーをメールアドレスで検索
 	user, err := u.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 	if user == nil {
+		recordLoginFailure(ctx, u.audit, 0, "unknown_email", map[string]interface{}{"method": "password", "email": req.Email})
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
 	// パスワードを検証
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
+		recordLoginFailure(ctx, u.audit, user.ID, "invalid_password", metadata)
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
-	// JWTトークンを生成
-	token, err := u.generateJWT(user.ID)
+	// JWTトークンを生成（二要素認証が有効ならチャレンジトークン。停止中であることはパスワードが正しい場合にのみ伝える）
+	response, err := newAuthResponse(ctx, u.mfaRepo, u.tokens, user)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		recordLoginError(ctx, u.audit, user.ID, err, metadata)
+		return nil, err
 	}
 
-	return &entity.AuthResponse{
-		User:  user,
-		Token: token,
-	}, nil
+	// チャレンジの場合は第二要素の検証時に記録する
+	if !response.MFARequired {
+		recordLoginSuccess(ctx, u.audit, user.ID, metadata)
+	}
+
+	return response, nil
 }
 
 // GetByID IDでユーザーを取得
@@ -119,6 +260,21 @@ func (u *userUseCase) GetByID(ctx context.Context, id int64) (*entity.User, erro
 	return user, nil
 }
 
+// GetByIDs 複数のIDのユーザーをまとめて取得（存在しないIDはマップに含まれない）
+func (u *userUseCase) GetByIDs(ctx context.Context, ids []int64) (map[int64]*entity.User, error) {
+	users, err := u.userRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	byID := make(map[int64]*entity.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	return byID, nil
+}
+
 // Update ユーザーを更新
 func (u *userUseCase) Update(ctx context.Context, id int64, req *entity.UpdateUserRequest) (*entity.User, error) {
 	// 既存ユーザーの確認
@@ -143,8 +299,12 @@ func (u *userUseCase) Update(ctx context.Context, id int64, req *entity.UpdateUs
 
 	// 更新データを準備
 	updateUser := &entity.User{
-		Email: existingUser.Email,
-		Name:  existingUser.Name,
+		Email:       existingUser.Email,
+		Name:        existingUser.Name,
+		DisplayName: existingUser.DisplayName,
+		Locale:      existingUser.Locale,
+		Timezone:    existingUser.Timezone,
+		Bio:         existingUser.Bio,
 	}
 
 	if req.Email != "" {
@@ -153,17 +313,138 @@ func (u *userUseCase) Update(ctx context.Context, id int64, req *entity.UpdateUs
 	if req.Name != "" {
 		updateUser.Name = req.Name
 	}
+	if req.DisplayName != nil {
+		updateUser.DisplayName = *req.DisplayName
+	}
+	if req.Locale != nil {
+		updateUser.Locale = *req.Locale
+	}
+	if req.Timezone != nil {
+		updateUser.Timezone = *req.Timezone
+	}
+	if req.Bio != nil {
+		updateUser.Bio = *req.Bio
+	}
 
 	updatedUser, err := u.userRepo.Update(ctx, id, updateUser)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
+	if changes := entity.DiffUser(existingUser, updatedUser); len(changes) > 0 {
+		u.audit.Record(ctx, &entity.AuditLog{
+			Action:       entity.AuditActionUserUpdate,
+			TargetUserID: auditUserID(id),
+			Changes:      changes,
+		})
+	}
+
+	return updatedUser, nil
+}
+
+// ChangeRole ユーザーのロールを変更（管理者が自分自身を降格して締め出されるのを防ぐ）
+func (u *userUseCase) ChangeRole(ctx context.Context, actorID, id int64, role string) (*entity.User, error) {
+	if !auth.IsRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if actorID == id {
+		return nil, ErrCannotChangeOwnRole
+	}
+
+	existingUser, err := u.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepo.UpdateRole(ctx, id, role); err != nil {
+		if err.Error() == "user not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to change role: %w", err)
+	}
+	u.accounts.Invalidate(ctx, id)
+
+	updatedUser, err := u.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if changes := entity.DiffUser(existingUser, updatedUser); len(changes) > 0 {
+		u.audit.Record(ctx, &entity.AuditLog{
+			Action:       entity.AuditActionUserRoleChange,
+			TargetUserID: auditUserID(id),
+			Changes:      changes,
+		})
+	}
+
 	return updatedUser, nil
 }
 
-// Delete ユーザーを削除
+// Suspend アカウントを一時停止（Untilを省略した場合は再開するまで無期限）
+func (u *userUseCase) Suspend(ctx context.Context, actorID, id int64, req *entity.SuspendUserRequest) (*entity.User, error) {
+	if req.Until != nil && !req.Until.After(time.Now()) {
+		return nil, ErrInvalidSuspendUntil
+	}
+	metadata := map[string]interface{}{"reason": req.Reason}
+	if req.Until != nil {
+		metadata["until"] = req.Until
+	}
+	return u.changeStatus(ctx, actorID, id, entity.UserStatusSuspended, req.Reason, req.Until, entity.AuditActionUserSuspend, metadata)
+}
+
+// Disable アカウントを無効化（再開されるまでログイン・API利用を拒否）
+func (u *userUseCase) Disable(ctx context.Context, actorID, id int64, req *entity.DisableUserRequest) (*entity.User, error) {
+	metadata := map[string]interface{}{"reason": req.Reason}
+	return u.changeStatus(ctx, actorID, id, entity.UserStatusDisabled, req.Reason, nil, entity.AuditActionUserDisable, metadata)
+}
+
+// Reinstate 停止・無効化したアカウントを再開
+func (u *userUseCase) Reinstate(ctx context.Context, actorID, id int64) (*entity.User, error) {
+	return u.changeStatus(ctx, actorID, id, entity.UserStatusActive, "", nil, entity.AuditActionUserReinstate, nil)
+}
+
+// changeStatus アカウントの状態を変更して認証キャッシュを破棄
+func (u *userUseCase) changeStatus(ctx context.Context, actorID, id int64, status, reason string, until *time.Time, action string, metadata map[string]interface{}) (*entity.User, error) {
+	if actorID == id {
+		return nil, ErrCannotChangeOwnStatus
+	}
+
+	existingUser, err := u.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepo.UpdateStatus(ctx, id, status, reason, until); err != nil {
+		if err.Error() == "user not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to change account status: %w", err)
+	}
+	// 発行済みのトークン・APIキーにも次のリクエストから反映させる
+	u.accounts.Invalidate(ctx, id)
+
+	updatedUser, err := u.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	u.audit.Record(ctx, &entity.AuditLog{
+		Action:       action,
+		TargetUserID: auditUserID(id),
+		Changes:      entity.DiffUser(existingUser, updatedUser),
+		Metadata:     metadata,
+	})
+
+	return updatedUser, nil
+}
+
+// Delete ユーザーを削除（組織のテナント内ではその組織のメンバーから外すだけにする）
 func (u *userUseCase) Delete(ctx context.Context, id int64) error {
+	// 自分自身の削除はテナントによらずアカウントごと削除する
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && principal.UserID == id {
+		ctx = tenant.WithTenant(ctx, tenant.Personal(id))
+	}
+
 	// ユーザーの存在確認
 	user, err := u.userRepo.GetByID(ctx, id)
 	if err != nil {
@@ -175,9 +456,34 @@ func (u *userUseCase) Delete(ctx context.Context, id int64) error {
 
 	err = u.userRepo.Delete(ctx, id)
 	if err != nil {
+		if err.Error() == "organization must keep at least one owner" {
+			return ErrOrganizationLastOwner
+		}
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
+	if t, ok := tenant.FromContext(ctx); ok && !t.IsPersonal() {
+		u.audit.Record(ctx, &entity.AuditLog{
+			Action:       entity.AuditActionOrganizationMemberRemove,
+			TargetUserID: auditUserID(id),
+			Metadata: map[string]interface{}{
+				"organization_id": t.ID,
+			},
+		})
+		return nil
+	}
+	u.accounts.Invalidate(ctx, id)
+
+	// 削除後もユーザーを特定できるよう識別情報を残す
+	u.audit.Record(ctx, &entity.AuditLog{
+		Action:       entity.AuditActionUserDelete,
+		TargetUserID: auditUserID(id),
+		Metadata: map[string]interface{}{
+			"email": user.Email,
+			"name":  user.Name,
+		},
+	})
+
 	return nil
 }
 
@@ -206,13 +512,7 @@ func (u *userUseCase) List(ctx context.Context, params *entity.PaginationParams)
 }
 
 // generateJWT JWTトークンを生成
-func (u *userUseCase) generateJWT(userID int64) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(), // 24時間有効
-		"iat":     time.Now().Unix(),
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(u.jwtSecret))
-} 
\ No newline at end of file
+func (u *userUseCase) generateJWT(user *entity.User) (string, error) {
+	token, _, err := u.tokens.Generate(user.ID, user.Role)
+	return token, err
+}
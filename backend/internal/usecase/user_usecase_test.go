@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/internal/entity"
+	"app-template/pkg/auth"
+	"app-template/pkg/kvstore"
+	"app-template/pkg/middleware"
+	"app-template/pkg/tenant"
+)
+
+// userFixture メモリのリポジトリで動くユーザー・組織のユースケース
+type userFixture struct {
+	users    UserUseCase
+	orgs     OrganizationUseCase
+	repo     *memUserRepo
+	org      *memOrgRepo
+	tokens   *auth.JWTManager
+	audit    *memAudit
+	sessions *memSessions
+}
+
+func newUserFixture() *userFixture {
+	repo := newMemUserRepo()
+	org := &memOrgRepo{memberships: map[int64][]*entity.OrganizationMembership{}}
+	tokens := auth.NewJWTManager(auth.JWTConfig{Secret: "secret", Issuer: "test", Audience: "test", TTL: time.Hour})
+	audit := &memAudit{}
+	accounts := NewAccountStateCache(repo, kvstore.NewMemory())
+	sessions := &memSessions{}
+	return &userFixture{
+		users:    NewUserUseCase(repo, memMFARepo{}, tokens, audit, accounts, sessions, RegistrationOpen),
+		orgs:     NewOrganizationUseCase(org, repo, tokens, audit),
+		repo:     repo,
+		org:      org,
+		tokens:   tokens,
+		audit:    audit,
+		sessions: sessions,
+	}
+}
+
+// router /api/v1/users と同じ認証・テナントのミドルウェアを通してユーザーを返すルーター
+func (f *userFixture) router(opts tenant.Options) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	accounts := NewAccountStateCache(f.repo, kvstore.NewMemory())
+	users := r.Group("/api/v1/users", middleware.JWTAuth(f.tokens, accounts, nil), middleware.Tenant(f.orgs, opts))
+	users.GET("/:id", func(c *gin.Context) {
+		id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+		user, err := f.users.GetByID(c.Request.Context(), id)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, user)
+	})
+	return r
+}
+
+func TestRegisteredUserWithoutOrganizationReadsSelf(t *testing.T) {
+	f := newUserFixture()
+	ctx := context.Background()
+
+	registered, err := f.users.Register(ctx, &entity.CreateUserRequest{Email: "alice@example.com", Name: "Alice", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	other, err := f.users.Register(ctx, &entity.CreateUserRequest{Email: "bob@example.com", Name: "Bob", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	login, err := f.users.Login(ctx, &entity.LoginRequest{Email: "alice@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	get := func(r *gin.Engine, id int64) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/"+strconv.FormatInt(id, 10), nil)
+		req.Header.Set("Authorization", "Bearer "+login.Token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// 既定（テナント必須）でも組織に所属しないユーザーは個人のテナントで自分自身を読める
+	r := f.router(tenant.Options{})
+	w := get(r, registered.User.ID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET self status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var me entity.User
+	if err := json.Unmarshal(w.Body.Bytes(), &me); err != nil || me.Email != "alice@example.com" {
+		t.Errorf("self = %+v, %v", me, err)
+	}
+	// 個人のテナントには他のユーザーは含まれない
+	if w := get(r, other.User.ID); w.Code != http.StatusNotFound {
+		t.Errorf("GET other status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	// Optional では従来どおり全体が対象
+	if w := get(f.router(tenant.Options{Optional: true}), other.User.ID); w.Code != http.StatusOK {
+		t.Errorf("optional GET other status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	// 複数の組織に所属すると指定が必要
+	f.org.memberships[registered.User.ID] = []*entity.OrganizationMembership{
+		{Organization: &entity.Organization{ID: 1, Slug: "acme"}, Role: entity.OrgRoleMember},
+		{Organization: &entity.Organization{ID: 2, Slug: "globex"}, Role: entity.OrgRoleMember},
+	}
+	if w := get(r, registered.User.ID); w.Code != http.StatusBadRequest {
+		t.Errorf("GET with several organizations status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestDeleteInOrganizationRemovesMembership(t *testing.T) {
+	f := newUserFixture()
+	ctx := context.Background()
+	user, err := f.repo.Create(ctx, &entity.User{Email: "alice@example.com", Name: "Alice"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	admin := auth.WithPrincipal(ctx, &auth.Principal{UserID: 99, Role: auth.RoleUser})
+	if err := f.users.Delete(tenant.WithTenant(admin, &tenant.Tenant{ID: 1, Slug: "acme", Role: entity.OrgRoleAdmin}), user.ID); err != nil {
+		t.Fatalf("Delete in organization: %v", err)
+	}
+	if got, _ := f.repo.GetByID(ctx, user.ID); got == nil {
+		t.Fatal("user was deleted globally inside an organization")
+	}
+
+	// 本人による削除はテナントによらずアカウントごと削除する
+	self := auth.WithPrincipal(ctx, &auth.Principal{UserID: user.ID, Role: auth.RoleUser})
+	if err := f.users.Delete(tenant.WithTenant(self, &tenant.Tenant{ID: 1, Slug: "acme", Role: entity.OrgRoleMember}), user.ID); err != nil {
+		t.Fatalf("Delete self: %v", err)
+	}
+	if got, _ := f.repo.GetByID(ctx, user.ID); got != nil {
+		t.Error("user deleting their own account was kept")
+	}
+
+	want := []string{entity.AuditActionOrganizationMemberRemove, entity.AuditActionUserDelete}
+	if got := f.audit.actions(); !slices.Equal(got, want) {
+		t.Errorf("audit actions = %v, want %v", got, want)
+	}
+}
+
+func TestResetPasswordRevokesSessions(t *testing.T) {
+	f := newUserFixture()
+	ctx := context.Background()
+	registered, err := f.users.Register(ctx, &entity.CreateUserRequest{Email: "alice@example.com", Name: "Alice", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	id := registered.User.ID
+
+	if err := f.users.ResetPassword(ctx, id, "short"); err != ErrPasswordTooShort {
+		t.Fatalf("short password: error = %v, want ErrPasswordTooShort", err)
+	}
+	if len(f.sessions.revoked) != 0 {
+		t.Fatalf("sessions revoked for a rejected reset: %v", f.sessions.revoked)
+	}
+
+	if err := f.users.ResetPassword(ctx, id, "new-password123"); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	if !slices.Equal(f.sessions.revoked, []int64{id}) {
+		t.Errorf("revoked = %v, want [%d]", f.sessions.revoked, id)
+	}
+	if _, err := f.users.Login(ctx, &entity.LoginRequest{Email: "alice@example.com", Password: "new-password123"}); err != nil {
+		t.Errorf("Login with the new password: %v", err)
+	}
+}
@@ -0,0 +1,464 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+
+	"app-template/internal/entity"
+	"app-template/internal/repository"
+	"app-template/pkg/jobqueue"
+	"app-template/pkg/outbox"
+	"app-template/pkg/webhook"
+)
+
+// JobTypeWebhookDelivery Webhook配信ジョブの種類
+const JobTypeWebhookDelivery = "webhook.deliver"
+
+// Webhookのエラー
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrWebhookInvalidURL       = errors.New("webhook url must be an absolute http or https url")
+	ErrWebhookInvalidEvent     = errors.New("webhook event is unknown")
+)
+
+// JobEnqueuer ジョブの追加先（*jobqueue.Queue が満たす）
+type JobEnqueuer interface {
+	Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...jobqueue.EnqueueOption) (*jobqueue.Job, error)
+}
+
+// WebhookUseCase Webhookユースケースのインターフェース
+// Dispatchで購読中の送信先ごとに配信を記録してジョブキューに積み、Deliverジョブで署名付きで送信する
+type WebhookUseCase interface {
+	Dispatch(ctx context.Context, event *outbox.Event) error
+	Create(ctx context.Context, req *entity.CreateWebhookRequest) (*entity.CreatedWebhook, error)
+	List(ctx context.Context) (*entity.WebhooksResponse, error)
+	GetByID(ctx context.Context, id int64) (*entity.Webhook, error)
+	Update(ctx context.Context, id int64, req *entity.UpdateWebhookRequest) (*entity.Webhook, error)
+	Delete(ctx context.Context, id int64) error
+	ListDeliveries(ctx context.Context, webhookID int64, params *entity.PaginationParams) (*entity.WebhookDeliveriesResponse, error)
+	Redeliver(ctx context.Context, webhookID, deliveryID int64) (*entity.WebhookDelivery, error)
+	Deliver(ctx context.Context, job *jobqueue.Job) error
+}
+
+// webhookDeliveryJob 配信ジョブのペイロード
+type webhookDeliveryJob struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+// webhookUseCase Webhookユースケースの実装
+type webhookUseCase struct {
+	webhookRepo repository.WebhookRepository
+	jobs        JobEnqueuer
+	sender      *webhook.Sender
+	audit       AuditUseCase
+}
+
+// NewWebhookUseCase Webhookユースケースの新しいインスタンスを作成
+func NewWebhookUseCase(webhookRepo repository.WebhookRepository, jobs JobEnqueuer, sender *webhook.Sender, audit AuditUseCase) WebhookUseCase {
+	return &webhookUseCase{
+		webhookRepo: webhookRepo,
+		jobs:        jobs,
+		sender:      sender,
+		audit:       audit,
+	}
+}
+
+// Create 送信先を登録（署名用の秘密鍵は戻り値でのみ返す）
+func (u *webhookUseCase) Create(ctx context.Context, req *entity.CreateWebhookRequest) (*entity.CreatedWebhook, error) {
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	events, err := normalizeWebhookEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	created, err := u.webhookRepo.Create(ctx, &entity.Webhook{
+		URL:         req.URL,
+		Description: req.Description,
+		Events:      events,
+		Secret:      secret,
+		Active:      active,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	u.audit.Record(ctx, &entity.AuditLog{
+		Action: entity.AuditActionWebhookCreate,
+		Metadata: map[string]interface{}{
+			"webhook_id": created.ID,
+			"url":        created.URL,
+			"events":     created.Events,
+		},
+	})
+
+	return &entity.CreatedWebhook{
+		Webhook: created,
+		Secret:  secret,
+	}, nil
+}
+
+// List 送信先の一覧を取得
+func (u *webhookUseCase) List(ctx context.Context) (*entity.WebhooksResponse, error) {
+	webhooks, err := u.webhookRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	return &entity.WebhooksResponse{
+		Webhooks: webhooks,
+	}, nil
+}
+
+// GetByID IDで送信先を取得
+func (u *webhookUseCase) GetByID(ctx context.Context, id int64) (*entity.Webhook, error) {
+	hook, err := u.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	if hook == nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	return hook, nil
+}
+
+// Update 送信先を更新
+func (u *webhookUseCase) Update(ctx context.Context, id int64, req *entity.UpdateWebhookRequest) (*entity.Webhook, error) {
+	existing, err := u.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *existing
+	if req.URL != "" {
+		if err := validateWebhookURL(req.URL); err != nil {
+			return nil, err
+		}
+		updated.URL = req.URL
+	}
+	if req.Description != nil {
+		updated.Description = *req.Description
+	}
+	if req.Events != nil {
+		events, err := normalizeWebhookEvents(req.Events)
+		if err != nil {
+			return nil, err
+		}
+		updated.Events = events
+	}
+	if req.Active != nil {
+		updated.Active = *req.Active
+	}
+
+	if err := u.webhookRepo.Update(ctx, &updated); err != nil {
+		if err.Error() == "webhook not found" {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	result, err := u.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	u.audit.Record(ctx, &entity.AuditLog{
+		Action:   entity.AuditActionWebhookUpdate,
+		Changes:  diffWebhook(existing, result),
+		Metadata: map[string]interface{}{"webhook_id": id},
+	})
+
+	return result, nil
+}
+
+// Delete 送信先と配信履歴を削除
+func (u *webhookUseCase) Delete(ctx context.Context, id int64) error {
+	existing, err := u.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := u.webhookRepo.Delete(ctx, id); err != nil {
+		if err.Error() == "webhook not found" {
+			return ErrWebhookNotFound
+		}
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	u.audit.Record(ctx, &entity.AuditLog{
+		Action: entity.AuditActionWebhookDelete,
+		Metadata: map[string]interface{}{
+			"webhook_id": id,
+			"url":        existing.URL,
+		},
+	})
+
+	return nil
+}
+
+// ListDeliveries 送信先の配信履歴を新しい順に取得
+func (u *webhookUseCase) ListDeliveries(ctx context.Context, webhookID int64, params *entity.PaginationParams) (*entity.WebhookDeliveriesResponse, error) {
+	if _, err := u.GetByID(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	// デフォルト値を設定
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 20
+	}
+	if params.Limit > 100 {
+		params.Limit = 100
+	}
+
+	deliveries, pagination, err := u.webhookRepo.ListDeliveries(ctx, webhookID, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return &entity.WebhookDeliveriesResponse{
+		Deliveries: deliveries,
+		Pagination: pagination,
+	}, nil
+}
+
+// Redeliver 過去の配信と同じ本文を新しい配信として送り直す（イベントIDは変わらない）
+func (u *webhookUseCase) Redeliver(ctx context.Context, webhookID, deliveryID int64) (*entity.WebhookDelivery, error) {
+	original, err := u.webhookRepo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if original == nil || original.WebhookID != webhookID {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+
+	delivery, err := u.enqueueDelivery(ctx, &entity.WebhookDelivery{
+		WebhookID:    webhookID,
+		EventID:      original.EventID,
+		Event:        original.Event,
+		Payload:      original.Payload,
+		RedeliveryOf: &original.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u.audit.Record(ctx, &entity.AuditLog{
+		Action: entity.AuditActionWebhookRedeliver,
+		Metadata: map[string]interface{}{
+			"webhook_id":    webhookID,
+			"delivery_id":   delivery.ID,
+			"redelivery_of": original.ID,
+			"event_id":      original.EventID,
+		},
+	})
+
+	return delivery, nil
+}
+
+// Dispatch アウトボックスのイベントを購読中の送信先ごとに配信を記録してジョブキューに積む（アウトボックスの送信先）
+// 一部の送信先で失敗した場合はエラーを返して再配信させ、記録済みの送信先には重ねて積まない
+func (u *webhookUseCase) Dispatch(ctx context.Context, event *outbox.Event) error {
+	hooks, err := u.webhookRepo.ListActiveByEvent(ctx, event.Type)
+	if err != nil {
+		return fmt.Errorf("failed to find webhooks for %s: %w", event.Type, err)
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	envelope := &entity.Event{
+		ID:         strconv.FormatInt(event.ID, 10),
+		Type:       event.Type,
+		OccurredAt: event.CreatedAt.UTC(),
+		Data:       event.Payload,
+	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event.Type, err)
+	}
+
+	delivered := map[int64]bool{}
+	if event.Attempts > 0 {
+		if delivered, err = u.webhookRepo.DeliveredWebhookIDs(ctx, envelope.ID); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, hook := range hooks {
+		if delivered[hook.ID] {
+			continue
+		}
+		_, err := u.enqueueDelivery(ctx, &entity.WebhookDelivery{
+			WebhookID: hook.ID,
+			EventID:   envelope.ID,
+			Event:     event.Type,
+			Payload:   payload,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("webhook %d: %w", hook.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// enqueueDelivery 配信を記録して送信ジョブを積む
+func (u *webhookUseCase) enqueueDelivery(ctx context.Context, delivery *entity.WebhookDelivery) (*entity.WebhookDelivery, error) {
+	delivery.Status = entity.WebhookDeliveryPending
+	created, err := u.webhookRepo.CreateDelivery(ctx, delivery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	if _, err := u.jobs.Enqueue(ctx, JobTypeWebhookDelivery, webhookDeliveryJob{DeliveryID: created.ID}); err != nil {
+		created.Status = entity.WebhookDeliveryFailed
+		created.Error = err.Error()
+		if err := u.webhookRepo.UpdateDelivery(ctx, created); err != nil {
+			log.Printf("failed to record webhook delivery %d: %v", created.ID, err)
+		}
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// Deliver 配信ジョブを実行し、試行の結果を配信履歴に記録する
+// 失敗した場合はエラーを返してジョブキューに再試行させ、上限に達したら配信を失敗として確定する
+func (u *webhookUseCase) Deliver(ctx context.Context, job *jobqueue.Job) error {
+	var payload webhookDeliveryJob
+	if err := job.Decode(&payload); err != nil {
+		return jobqueue.Permanent(fmt.Errorf("invalid webhook delivery job: %w", err))
+	}
+
+	delivery, err := u.webhookRepo.GetDelivery(ctx, payload.DeliveryID)
+	if err != nil {
+		return err
+	}
+	if delivery == nil {
+		// 送信先ごと削除された
+		return jobqueue.Permanent(ErrWebhookDeliveryNotFound)
+	}
+	if delivery.Status != entity.WebhookDeliveryPending {
+		return nil
+	}
+
+	hook, err := u.webhookRepo.GetByID(ctx, delivery.WebhookID)
+	if err != nil {
+		return err
+	}
+	if hook == nil {
+		return jobqueue.Permanent(ErrWebhookNotFound)
+	}
+	if !hook.Active {
+		delivery.Status = entity.WebhookDeliveryFailed
+		delivery.Error = "webhook is disabled"
+		return u.recordAttempt(ctx, delivery)
+	}
+
+	result, sendErr := u.sender.Send(ctx, &webhook.Request{
+		URL:        hook.URL,
+		Secret:     hook.Secret,
+		Event:      delivery.Event,
+		DeliveryID: fmt.Sprint(delivery.ID),
+		Body:       delivery.Payload,
+	})
+
+	delivery.Attempts++
+	delivery.DurationMS = result.Duration.Milliseconds()
+	delivery.ResponseStatus = nil
+	if result.StatusCode != 0 {
+		delivery.ResponseStatus = &result.StatusCode
+	}
+	delivery.ResponseBody = result.Body
+	delivery.Error = ""
+
+	switch {
+	case sendErr == nil:
+		now := time.Now()
+		delivery.Status = entity.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+	case job.Attempts >= job.MaxAttempts:
+		delivery.Status = entity.WebhookDeliveryFailed
+		delivery.Error = sendErr.Error()
+	default:
+		delivery.Error = sendErr.Error()
+	}
+
+	if err := u.recordAttempt(ctx, delivery); err != nil {
+		return err
+	}
+	return sendErr
+}
+
+// recordAttempt 試行の結果を保存（ハンドラーの期限切れ後も記録する）
+func (u *webhookUseCase) recordAttempt(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	if err := u.webhookRepo.UpdateDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// validateWebhookURL 送信先のURLを検証
+func validateWebhookURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrWebhookInvalidURL
+	}
+	return nil
+}
+
+// normalizeWebhookEvents 購読するイベントを検証し、重複を除く
+func normalizeWebhookEvents(events []string) ([]string, error) {
+	seen := make(map[string]bool, len(events))
+	normalized := make([]string, 0, len(events))
+	for _, event := range events {
+		if !entity.IsWebhookEvent(event) {
+			return nil, fmt.Errorf("%w: %s", ErrWebhookInvalidEvent, event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			normalized = append(normalized, event)
+		}
+	}
+	return normalized, nil
+}
+
+// diffWebhook 送信先の変更されたフィールドを抽出
+func diffWebhook(before, after *entity.Webhook) map[string]entity.FieldChange {
+	changes := map[string]entity.FieldChange{}
+	if before.URL != after.URL {
+		changes["url"] = entity.FieldChange{Before: before.URL, After: after.URL}
+	}
+	if before.Description != after.Description {
+		changes["description"] = entity.FieldChange{Before: before.Description, After: after.Description}
+	}
+	if fmt.Sprint(before.Events) != fmt.Sprint(after.Events) {
+		changes["events"] = entity.FieldChange{Before: before.Events, After: after.Events}
+	}
+	if before.Active != after.Active {
+		changes["active"] = entity.FieldChange{Before: before.Active, After: after.Active}
+	}
+	return changes
+}
@@ -0,0 +1,320 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"app-template/internal/entity"
+	"app-template/pkg/jobqueue"
+	"app-template/pkg/outbox"
+	"app-template/pkg/webhook"
+)
+
+// receivedWebhook 受信側に届いたリクエスト
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+	at     time.Time
+}
+
+// webhookReceiver 指定した順に応答ステータスを返す受信側（使い切った後は200）
+type webhookReceiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedWebhook
+	notify   chan struct{}
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	t.Helper()
+	r := &webhookReceiver{statuses: statuses, notify: make(chan struct{}, 16)}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		status := http.StatusOK
+		if len(r.requests) < len(r.statuses) {
+			status = r.statuses[len(r.requests)]
+		}
+		r.requests = append(r.requests, receivedWebhook{header: req.Header.Clone(), body: body, at: time.Now()})
+		r.mu.Unlock()
+
+		w.WriteHeader(status)
+		r.notify <- struct{}{}
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+// wait n件のリクエストが届くまで待つ
+func (r *webhookReceiver) wait(t *testing.T, n int) []receivedWebhook {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		r.mu.Lock()
+		if len(r.requests) >= n {
+			requests := slices.Clone(r.requests)
+			r.mu.Unlock()
+			return requests
+		}
+		r.mu.Unlock()
+
+		select {
+		case <-r.notify:
+		case <-timeout:
+			t.Fatalf("received %d webhook requests, want %d", len(r.requests), n)
+		}
+	}
+}
+
+type webhookFixture struct {
+	repo  *memWebhookRepo
+	audit *memAudit
+	queue *jobqueue.Queue
+	uc    WebhookUseCase
+}
+
+// newWebhookFixture 短い間隔で再試行するジョブキューで配信する
+func newWebhookFixture(t *testing.T, maxAttempts int) *webhookFixture {
+	t.Helper()
+	f := &webhookFixture{
+		repo:  newMemWebhookRepo(),
+		audit: &memAudit{},
+		queue: jobqueue.New(jobqueue.NewMemory(), jobqueue.Options{
+			Workers:      1,
+			PollInterval: 5 * time.Millisecond,
+			MaxAttempts:  maxAttempts,
+			BaseBackoff:  20 * time.Millisecond,
+			MaxBackoff:   80 * time.Millisecond,
+		}),
+	}
+	f.uc = NewWebhookUseCase(f.repo, f.queue, webhook.NewSender(nil, "test"), f.audit)
+	f.queue.Register(JobTypeWebhookDelivery, f.uc.Deliver)
+	f.queue.Start()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		f.queue.Shutdown(ctx)
+	})
+	return f
+}
+
+// create 送信先を登録
+func (f *webhookFixture) create(t *testing.T, url string) *entity.CreatedWebhook {
+	t.Helper()
+	created, err := f.uc.Create(context.Background(), &entity.CreateWebhookRequest{URL: url, Events: []string{entity.EventUserCreated}})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return created
+}
+
+// dispatch user.created を配信
+func (f *webhookFixture) dispatch(t *testing.T) {
+	t.Helper()
+	err := f.uc.Dispatch(context.Background(), &outbox.Event{
+		ID:        101,
+		Type:      entity.EventUserCreated,
+		Payload:   json.RawMessage(`{"user":{"id":1}}`),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+}
+
+// waitDelivery 配信が pending 以外になるまで待つ
+func (f *webhookFixture) waitDelivery(t *testing.T, id int64) *entity.WebhookDelivery {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		delivery, _ := f.repo.GetDelivery(context.Background(), id)
+		if delivery != nil && delivery.Status != entity.WebhookDeliveryPending {
+			return delivery
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("delivery %d is still pending", id)
+	return nil
+}
+
+// deliveries 送信先の配信（作成順）
+func (f *webhookFixture) deliveries(webhookID int64) []*entity.WebhookDelivery {
+	f.repo.mu.Lock()
+	defer f.repo.mu.Unlock()
+	var list []*entity.WebhookDelivery
+	for _, d := range f.repo.deliveries {
+		if d.WebhookID == webhookID {
+			copied := *d
+			list = append(list, &copied)
+		}
+	}
+	slices.SortFunc(list, func(a, b *entity.WebhookDelivery) int { return int(a.ID - b.ID) })
+	return list
+}
+
+func TestWebhookDeliverySignsPayload(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+	f := newWebhookFixture(t, 3)
+	hook := f.create(t, receiver.URL)
+
+	f.dispatch(t)
+	got := receiver.wait(t, 1)[0]
+
+	if err := webhook.Verify(hook.Secret, got.header.Get(webhook.HeaderSignature), got.header.Get(webhook.HeaderTimestamp), got.body, time.Minute, time.Now()); err != nil {
+		t.Errorf("signature: %v", err)
+	}
+	if event := got.header.Get(webhook.HeaderEvent); event != entity.EventUserCreated {
+		t.Errorf("event header = %q", event)
+	}
+
+	var envelope entity.Event
+	if err := json.Unmarshal(got.body, &envelope); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if envelope.ID != "101" || envelope.Type != entity.EventUserCreated {
+		t.Errorf("envelope = %+v", envelope)
+	}
+
+	deliveries := f.deliveries(hook.ID)
+	if len(deliveries) != 1 {
+		t.Fatalf("deliveries = %d, want 1", len(deliveries))
+	}
+	delivery := f.waitDelivery(t, deliveries[0].ID)
+	if delivery.Status != entity.WebhookDeliverySucceeded || delivery.Attempts != 1 || delivery.DeliveredAt == nil {
+		t.Errorf("delivery = %+v", delivery)
+	}
+}
+
+func TestWebhookDeliveryRetriesServerErrors(t *testing.T) {
+	receiver := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusBadGateway)
+	f := newWebhookFixture(t, 5)
+	hook := f.create(t, receiver.URL)
+
+	f.dispatch(t)
+	requests := receiver.wait(t, 3)
+	delivery := f.waitDelivery(t, f.deliveries(hook.ID)[0].ID)
+
+	if delivery.Status != entity.WebhookDeliverySucceeded || delivery.Attempts != 3 {
+		t.Errorf("delivery = %+v, want succeeded after 3 attempts", delivery)
+	}
+	if delivery.ResponseStatus == nil || *delivery.ResponseStatus != http.StatusOK || delivery.Error != "" {
+		t.Errorf("last attempt = %v / %q, want 200 without error", delivery.ResponseStatus, delivery.Error)
+	}
+
+	// 再試行は指数バックオフ（揺らぎは半分まで）で間隔を空ける
+	if d := requests[1].at.Sub(requests[0].at); d < 10*time.Millisecond {
+		t.Errorf("first retry after %s, want at least 10ms", d)
+	}
+	if d := requests[2].at.Sub(requests[1].at); d < 20*time.Millisecond {
+		t.Errorf("second retry after %s, want at least 20ms", d)
+	}
+	// 再試行でも同じ配信IDと本文を送る
+	for _, r := range requests[1:] {
+		if r.header.Get(webhook.HeaderDelivery) != requests[0].header.Get(webhook.HeaderDelivery) || string(r.body) != string(requests[0].body) {
+			t.Errorf("retry differs from first attempt: %v %s", r.header, r.body)
+		}
+	}
+}
+
+func TestWebhookDeliveryFailsAfterMaxAttempts(t *testing.T) {
+	receiver := newWebhookReceiver(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	f := newWebhookFixture(t, 3)
+	hook := f.create(t, receiver.URL)
+
+	f.dispatch(t)
+	receiver.wait(t, 3)
+	delivery := f.waitDelivery(t, f.deliveries(hook.ID)[0].ID)
+
+	if delivery.Status != entity.WebhookDeliveryFailed || delivery.Attempts != 3 {
+		t.Errorf("delivery = %+v, want failed after 3 attempts", delivery)
+	}
+	if delivery.ResponseStatus == nil || *delivery.ResponseStatus != http.StatusServiceUnavailable || delivery.Error == "" {
+		t.Errorf("last attempt = %v / %q", delivery.ResponseStatus, delivery.Error)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		dead, err := f.queue.Dead(context.Background(), 10)
+		if err != nil {
+			t.Fatalf("Dead: %v", err)
+		}
+		if len(dead) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("dead jobs = %d, want 1", len(dead))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWebhookRedeliver(t *testing.T) {
+	receiver := newWebhookReceiver(t, http.StatusInternalServerError)
+	f := newWebhookFixture(t, 1)
+	hook := f.create(t, receiver.URL)
+
+	f.dispatch(t)
+	receiver.wait(t, 1)
+	original := f.waitDelivery(t, f.deliveries(hook.ID)[0].ID)
+	if original.Status != entity.WebhookDeliveryFailed {
+		t.Fatalf("original = %+v, want failed", original)
+	}
+
+	redelivery, err := f.uc.Redeliver(context.Background(), hook.ID, original.ID)
+	if err != nil {
+		t.Fatalf("Redeliver: %v", err)
+	}
+	if redelivery.ID == original.ID || redelivery.RedeliveryOf == nil || *redelivery.RedeliveryOf != original.ID {
+		t.Errorf("redelivery = %+v", redelivery)
+	}
+
+	requests := receiver.wait(t, 2)
+	done := f.waitDelivery(t, redelivery.ID)
+	if done.Status != entity.WebhookDeliverySucceeded || done.EventID != original.EventID {
+		t.Errorf("redelivery = %+v", done)
+	}
+
+	// 再送は新しい配信IDで、本文（イベントID）は同じ
+	if requests[1].header.Get(webhook.HeaderDelivery) == requests[0].header.Get(webhook.HeaderDelivery) {
+		t.Error("redelivery reused the delivery ID")
+	}
+	if string(requests[1].body) != string(requests[0].body) {
+		t.Errorf("redelivered body = %s, want %s", requests[1].body, requests[0].body)
+	}
+	if err := webhook.Verify(hook.Secret, requests[1].header.Get(webhook.HeaderSignature), requests[1].header.Get(webhook.HeaderTimestamp), requests[1].body, time.Minute, time.Now()); err != nil {
+		t.Errorf("redelivery signature: %v", err)
+	}
+
+	if actions := f.audit.actions(); !slices.Contains(actions, entity.AuditActionWebhookRedeliver) {
+		t.Errorf("audit actions = %v", actions)
+	}
+}
+
+func TestWebhookRedeliverOtherWebhook(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+	f := newWebhookFixture(t, 1)
+	hook := f.create(t, receiver.URL)
+	other := f.create(t, receiver.URL+"/other")
+
+	f.dispatch(t)
+	receiver.wait(t, 2)
+	delivery := f.deliveries(hook.ID)[0]
+
+	if _, err := f.uc.Redeliver(context.Background(), other.ID, delivery.ID); !errors.Is(err, ErrWebhookDeliveryNotFound) {
+		t.Errorf("err = %v, want ErrWebhookDeliveryNotFound", err)
+	}
+	if _, err := f.uc.Redeliver(context.Background(), hook.ID, 9999); !errors.Is(err, ErrWebhookDeliveryNotFound) {
+		t.Errorf("err = %v, want ErrWebhookDeliveryNotFound", err)
+	}
+}
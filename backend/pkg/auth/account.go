@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+// アカウント状態のエラー
+var (
+	ErrAccountNotFound  = errors.New("account no longer exists")
+	ErrAccountSuspended = errors.New("account is suspended")
+	ErrAccountDisabled  = errors.New("account is disabled")
+)
+
+// AccountState 認証時に確認するアカウントの現在の状態
+type AccountState struct {
+	Role   string
+	Status string
+}
+
+// AccountChecker 認証済み主体のアカウントを確認する
+// トークン発行後の停止・削除・ロール変更をリクエストごとに反映するために使用する
+type AccountChecker interface {
+	// CheckAccount 利用可能なら現在の状態を返す
+	// 削除済みならErrAccountNotFound、停止中ならErrAccountSuspended・ErrAccountDisabled
+	CheckAccount(ctx context.Context, userID int64) (*AccountState, error)
+}
+
+// IsAccountBlocked アカウントの停止・無効化によるエラーか判定
+func IsAccountBlocked(err error) bool {
+	return errors.Is(err, ErrAccountSuspended) || errors.Is(err, ErrAccountDisabled)
+}
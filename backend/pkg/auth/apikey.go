@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// APIKeyPrefix APIキーの識別用プレフィックス（シークレットスキャナーでの検出用）
+	APIKeyPrefix = "ak_"
+
+	// apiKeyDisplayLength 一覧表示用に保存するキー先頭部分の長さ
+	apiKeyDisplayLength = len(APIKeyPrefix) + 8
+
+	// apiKeySecretBytes キーのランダム部分のバイト数
+	apiKeySecretBytes = 32
+)
+
+// APIキー検証エラー
+var (
+	ErrAPIKeyInvalid = errors.New("api key is invalid")
+	ErrAPIKeyExpired = errors.New("api key is expired")
+	ErrAPIKeyRevoked = errors.New("api key is revoked")
+)
+
+// GenerateAPIKey 新しいAPIキーを生成（平文・表示用プレフィックス・ハッシュを返す）
+func GenerateAPIKey() (key, displayPrefix, hash string, err error) {
+	b := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, key[:apiKeyDisplayLength], HashAPIKey(key), nil
+}
+
+// HashAPIKey 保存・照合用のAPIキーハッシュ
+// キー自体が十分なエントロピーを持つため、ソルトなしのSHA-256で照合する
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey APIキー形式の資格情報か判定
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
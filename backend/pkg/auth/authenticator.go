@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// 認証方式
+const (
+	MethodJWT     = "jwt"
+	MethodAPIKey  = "api_key"
+	MethodSession = "session"
+)
+
+// ErrUnsupportedCredential 認証器が扱わない形式の資格情報（チェーンの次の認証器へ回す）
+var ErrUnsupportedCredential = errors.New("unsupported credential")
+
+// Principal 認証済みの主体
+type Principal struct {
+	UserID int64
+	Method string
+	// Role ユーザーのロール（権限の上限を決める）
+	Role string
+	// Scopes APIキーに付与されたスコープ（JWTの場合はnilで制限なし）
+	Scopes []string
+	// APIKeyID APIキーで認証した場合のキーID
+	APIKeyID int64
+	// Claims JWTで認証した場合の検証済みクレーム
+	Claims *Claims
+	// SessionID セッションCookieで認証した場合のセッションID
+	SessionID string
+}
+
+type principalContextKey struct{}
+
+// WithPrincipal コンテキストに認証済み主体を設定
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext コンテキストから認証済み主体を取得
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// Authenticator Bearer資格情報を検証する認証器
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*Principal, error)
+}
+
+// CookieAuthenticator Cookieで送られる資格情報（セッション）を検証する認証器
+// Cookieはブラウザが自動で送るため、変更系のリクエストではCSRFトークンも検証する
+type CookieAuthenticator interface {
+	Authenticator
+	// Credential リクエストのCookieから資格情報を取得（なければ空文字列）
+	Credential(r *http.Request) string
+	// VerifyCSRF リクエストのCSRFトークンを検証（不正ならErrCSRFTokenInvalid）
+	VerifyCSRF(r *http.Request, principal *Principal) error
+}
+
+// Chain 資格情報を扱える認証器を順に探して検証する認証器
+// どの認証器も扱えない形式ならErrTokenMalformedを返す
+type Chain []Authenticator
+
+// Authenticate 最初に資格情報を扱えた認証器の結果を返す
+func (c Chain) Authenticate(ctx context.Context, credential string) (*Principal, error) {
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(ctx, credential)
+		if errors.Is(err, ErrUnsupportedCredential) {
+			continue
+		}
+		return principal, err
+	}
+	return nil, ErrTokenMalformed
+}
+
+// Authenticate アクセストークンを検証（JWT形式でなければErrUnsupportedCredential）
+func (m *JWTManager) Authenticate(ctx context.Context, credential string) (*Principal, error) {
+	if strings.Count(credential, ".") != 2 {
+		return nil, ErrUnsupportedCredential
+	}
+
+	claims, err := m.Parse(credential)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, err
+	}
+
+	role := claims.Role
+	if role == "" {
+		role = RoleUser
+	}
+
+	return &Principal{
+		UserID: userID,
+		Method: MethodJWT,
+		Role:   role,
+		Claims: claims,
+	}, nil
+}
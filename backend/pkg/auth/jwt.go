@@ -0,0 +1,357 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// デフォルト値
+const (
+	defaultJWTSecret   = "default-secret-key"
+	defaultJWTIssuer   = "app-template"
+	defaultJWTAudience = "app-template-api"
+	defaultJWTTTL      = 24 * time.Hour
+	defaultJWTLeeway   = 30 * time.Second
+
+	// mfaChallengeTTL 二要素認証チャレンジトークンの有効期間
+	mfaChallengeTTL = 5 * time.Minute
+)
+
+// トークン種別
+const (
+	TokenTypeAccess       = "access"
+	TokenTypeMFAChallenge = "mfa_challenge"
+	TokenTypeInvitation   = "invitation"
+)
+
+// トークン検証エラー
+var (
+	ErrTokenMalformed        = errors.New("token is malformed")
+	ErrTokenSignatureInvalid = errors.New("token signature is invalid")
+	ErrTokenExpired          = errors.New("token is expired")
+	ErrTokenNotValidYet      = errors.New("token is not valid yet")
+	ErrTokenUsedBeforeIssued = errors.New("token used before issued")
+	ErrTokenInvalidIssuer    = errors.New("token has invalid issuer")
+	ErrTokenInvalidAudience  = errors.New("token has invalid audience")
+	ErrTokenInvalidSubject   = errors.New("token has invalid subject")
+	ErrTokenMissingClaim     = errors.New("token is missing required claim")
+	ErrTokenWrongType        = errors.New("token has wrong type")
+	ErrTokenInvalid          = errors.New("invalid token")
+)
+
+// JWTConfig JWTの発行・検証設定
+type JWTConfig struct {
+	Secret   string
+	Issuer   string
+	Audience string
+	TTL      time.Duration
+	Leeway   time.Duration
+}
+
+// LoadJWTConfig 環境変数からJWT設定を読み込む
+func LoadJWTConfig() JWTConfig {
+	cfg := JWTConfig{
+		Secret:   os.Getenv("JWT_SECRET"),
+		Issuer:   os.Getenv("JWT_ISSUER"),
+		Audience: os.Getenv("JWT_AUDIENCE"),
+		TTL:      durationFromEnv("JWT_TTL", defaultJWTTTL),
+		Leeway:   durationFromEnv("JWT_LEEWAY", defaultJWTLeeway),
+	}
+
+	if cfg.Secret == "" {
+		cfg.Secret = defaultJWTSecret
+	}
+	if cfg.Issuer == "" {
+		cfg.Issuer = defaultJWTIssuer
+	}
+	if cfg.Audience == "" {
+		cfg.Audience = defaultJWTAudience
+	}
+
+	return cfg
+}
+
+// Claims トークンのクレーム
+type Claims struct {
+	jwt.RegisteredClaims
+	// TokenType トークン種別（アクセストークンとMFAチャレンジを区別する）
+	TokenType string `json:"token_type"`
+	// Role 発行時点のユーザーのロール
+	Role string `json:"role,omitempty"`
+	// OrgID 組織を指定して発行したトークンのテナント（0なら指定なし）
+	OrgID int64 `json:"org_id,omitempty"`
+}
+
+// UserID subクレームからユーザーIDを取得
+func (c *Claims) UserID() (int64, error) {
+	id, err := strconv.ParseInt(c.Subject, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrTokenInvalidSubject
+	}
+	return id, nil
+}
+
+// JWTManager JWTの発行と検証を行う
+type JWTManager struct {
+	cfg JWTConfig
+	now func() time.Time
+}
+
+// NewJWTManager JWTManagerの新しいインスタンスを作成
+func NewJWTManager(cfg JWTConfig) *JWTManager {
+	return &JWTManager{
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+// Config 現在の設定を返す
+func (m *JWTManager) Config() JWTConfig {
+	return m.cfg
+}
+
+// Generate ユーザーに対するアクセストークンを発行
+func (m *JWTManager) Generate(userID int64, role string) (string, *Claims, error) {
+	return m.issue(userID, role, TokenTypeAccess, m.cfg.TTL)
+}
+
+// GenerateForOrganization 組織をテナントとして指定したアクセストークンを発行
+func (m *JWTManager) GenerateForOrganization(userID int64, role string, orgID int64) (string, *Claims, error) {
+	return m.issueWith(userID, role, TokenTypeAccess, m.cfg.TTL, func(claims *Claims) {
+		claims.OrgID = orgID
+	})
+}
+
+// Parse アクセストークンを検証してクレームを返す
+func (m *JWTManager) Parse(tokenString string) (*Claims, error) {
+	return m.parse(tokenString, TokenTypeAccess)
+}
+
+// GenerateMFAChallenge パスワード認証済み・二要素認証待ちを示す短命トークンを発行
+func (m *JWTManager) GenerateMFAChallenge(userID int64) (string, error) {
+	token, _, err := m.issue(userID, "", TokenTypeMFAChallenge, mfaChallengeTTL)
+	return token, err
+}
+
+// ParseMFAChallenge MFAチャレンジトークンを検証してクレームを返す
+func (m *JWTManager) ParseMFAChallenge(tokenString string) (*Claims, error) {
+	return m.parse(tokenString, TokenTypeMFAChallenge)
+}
+
+// GenerateInvitation 招待リンクのトークンを発行（subクレームは招待ID）
+func (m *JWTManager) GenerateInvitation(invitationID int64, ttl time.Duration) (string, *Claims, error) {
+	return m.issue(invitationID, "", TokenTypeInvitation, ttl)
+}
+
+// ParseInvitation 招待トークンを検証して招待IDを返す
+func (m *JWTManager) ParseInvitation(tokenString string) (int64, error) {
+	claims, err := m.parse(tokenString, TokenTypeInvitation)
+	if err != nil {
+		return 0, err
+	}
+	return claims.UserID()
+}
+
+// issue 指定種別のトークンを発行
+func (m *JWTManager) issue(userID int64, role, tokenType string, ttl time.Duration) (string, *Claims, error) {
+	return m.issueWith(userID, role, tokenType, ttl, nil)
+}
+
+// issueWith 指定種別のトークンを発行（customizeで追加のクレームを設定する）
+func (m *JWTManager) issueWith(userID int64, role, tokenType string, ttl time.Duration, customize func(claims *Claims)) (string, *Claims, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate token id: %w", err)
+	}
+
+	now := m.now()
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   strconv.FormatInt(userID, 10),
+			Issuer:    m.cfg.Issuer,
+			Audience:  jwt.ClaimStrings{m.cfg.Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		TokenType: tokenType,
+		Role:      role,
+	}
+	if customize != nil {
+		customize(claims)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(m.cfg.Secret))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return signed, claims, nil
+}
+
+// parse トークンを検証し、種別が一致する場合にクレームを返す
+func (m *JWTManager) parse(tokenString, tokenType string) (*Claims, error) {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(m.cfg.Issuer),
+		jwt.WithAudience(m.cfg.Audience),
+		jwt.WithLeeway(m.cfg.Leeway),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(m.now),
+	)
+
+	claims := &Claims{}
+	_, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(m.cfg.Secret), nil
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	if claims.Subject == "" {
+		return nil, ErrTokenMissingClaim
+	}
+	if _, err := claims.UserID(); err != nil {
+		return nil, err
+	}
+	if claims.TokenType != tokenType {
+		return nil, ErrTokenWrongType
+	}
+
+	return claims, nil
+}
+
+// tokenErrors パッケージが返すトークン検証エラーの一覧
+var tokenErrors = []error{
+	ErrTokenMalformed,
+	ErrTokenSignatureInvalid,
+	ErrTokenExpired,
+	ErrTokenNotValidYet,
+	ErrTokenUsedBeforeIssued,
+	ErrTokenInvalidIssuer,
+	ErrTokenInvalidAudience,
+	ErrTokenInvalidSubject,
+	ErrTokenMissingClaim,
+	ErrTokenWrongType,
+	ErrTokenInvalid,
+	ErrAPIKeyInvalid,
+	ErrAPIKeyExpired,
+	ErrAPIKeyRevoked,
+	ErrSessionInvalid,
+	ErrAccountNotFound,
+}
+
+// IsTokenError トークン検証エラーか判定
+func IsTokenError(err error) bool {
+	for _, target := range tokenErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorCode トークン検証エラーをAPIエラーコードに変換
+func ErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrTokenExpired):
+		return "TOKEN_EXPIRED"
+	case errors.Is(err, ErrTokenNotValidYet):
+		return "TOKEN_NOT_YET_VALID"
+	case errors.Is(err, ErrTokenUsedBeforeIssued):
+		return "TOKEN_USED_BEFORE_ISSUED"
+	case errors.Is(err, ErrTokenInvalidIssuer):
+		return "INVALID_TOKEN_ISSUER"
+	case errors.Is(err, ErrTokenInvalidAudience):
+		return "INVALID_TOKEN_AUDIENCE"
+	case errors.Is(err, ErrTokenInvalidSubject):
+		return "INVALID_TOKEN_SUBJECT"
+	case errors.Is(err, ErrTokenMissingClaim):
+		return "TOKEN_CLAIM_MISSING"
+	case errors.Is(err, ErrTokenWrongType):
+		return "INVALID_TOKEN_TYPE"
+	case errors.Is(err, ErrTokenSignatureInvalid):
+		return "INVALID_TOKEN_SIGNATURE"
+	case errors.Is(err, ErrTokenMalformed):
+		return "MALFORMED_TOKEN"
+	case errors.Is(err, ErrAccountNotFound):
+		return "ACCOUNT_NOT_FOUND"
+	case errors.Is(err, ErrAccountSuspended):
+		return "ACCOUNT_SUSPENDED"
+	case errors.Is(err, ErrAccountDisabled):
+		return "ACCOUNT_DISABLED"
+	case errors.Is(err, ErrAPIKeyExpired):
+		return "API_KEY_EXPIRED"
+	case errors.Is(err, ErrAPIKeyRevoked):
+		return "API_KEY_REVOKED"
+	case errors.Is(err, ErrAPIKeyInvalid):
+		return "INVALID_API_KEY"
+	case errors.Is(err, ErrSessionInvalid):
+		return "INVALID_SESSION"
+	case errors.Is(err, ErrCSRFTokenInvalid):
+		return "CSRF_TOKEN_INVALID"
+	default:
+		return "INVALID_TOKEN"
+	}
+}
+
+// translateError jwtライブラリのエラーをパッケージのエラーに変換
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return ErrTokenMalformed
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return ErrTokenSignatureInvalid
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ErrTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet):
+		return ErrTokenNotValidYet
+	case errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return ErrTokenUsedBeforeIssued
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return ErrTokenInvalidIssuer
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return ErrTokenInvalidAudience
+	case errors.Is(err, jwt.ErrTokenInvalidSubject):
+		return ErrTokenInvalidSubject
+	case errors.Is(err, jwt.ErrTokenRequiredClaimMissing):
+		return ErrTokenMissingClaim
+	default:
+		return ErrTokenInvalid
+	}
+}
+
+// newTokenID jtiクレーム用のランダムなIDを生成
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// durationFromEnv 環境変数から期間を読み込む（"24h" 形式または秒数）
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return d
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	return fallback
+}
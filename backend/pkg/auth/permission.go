@@ -0,0 +1,84 @@
+package auth
+
+// Permission 操作に必要な権限（APIキーのスコープとしても使う）
+type Permission string
+
+// 権限
+const (
+	PermUsersRead  Permission = "users:read"
+	PermUsersWrite Permission = "users:write"
+	PermUsersAdmin Permission = "users:admin"
+)
+
+// ロール
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// rolePermissions ロールごとに付与される権限
+var rolePermissions = map[string][]Permission{
+	RoleUser:  {PermUsersRead, PermUsersWrite},
+	RoleAdmin: {PermUsersRead, PermUsersWrite, PermUsersAdmin},
+}
+
+// Permissions 定義済みの権限一覧
+var Permissions = []Permission{
+	PermUsersRead,
+	PermUsersWrite,
+	PermUsersAdmin,
+}
+
+// IsRole 定義済みのロールか判定
+func IsRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// IsPermission 定義済みの権限か判定
+func IsPermission(permission string) bool {
+	for _, p := range Permissions {
+		if string(p) == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleGrants ロールが権限を持つか判定
+func RoleGrants(role string, permission Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Has 主体が権限を持つか判定
+// ロールの権限を上限とし、スコープ付きの資格情報（APIキー）はさらにスコープで絞り込む
+func (p *Principal) Has(permission Permission) bool {
+	if !RoleGrants(p.Role, permission) {
+		return false
+	}
+	if p.Scopes == nil {
+		return true
+	}
+	for _, scope := range p.Scopes {
+		if scope == string(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions 主体が持つ権限の一覧
+func (p *Principal) Permissions() []Permission {
+	granted := []Permission{}
+	for _, permission := range Permissions {
+		if p.Has(permission) {
+			granted = append(granted, permission)
+		}
+	}
+	return granted
+}
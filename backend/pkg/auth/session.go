@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// sessionTokenBytes セッショントークンのランダム部分のバイト数
+const sessionTokenBytes = 32
+
+// セッション検証エラー
+var (
+	ErrSessionInvalid   = errors.New("session is invalid or expired")
+	ErrCSRFTokenInvalid = errors.New("csrf token is missing or invalid")
+)
+
+// GenerateSessionToken 新しいセッショントークンを生成（Cookieに入れる平文と保存用のハッシュを返す）
+func GenerateSessionToken() (token, hash string, err error) {
+	b := make([]byte, sessionTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashSessionToken(token), nil
+}
+
+// HashSessionToken 保存・照合用のセッショントークンハッシュ（セッションIDとして使う）
+// 保存先が漏洩してもCookieの値を復元できないよう、平文は保存しない
+func HashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,349 @@
+// Package cors オリジン間リソース共有（CORS）のポリシー
+// オリジンのパターン（完全一致・サブドメイン・任意のポート）、パスごとのポリシー、
+// プリフライトの検証と結果のキャッシュ（Access-Control-Max-Age）を扱う
+package cors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSのヘッダー
+const (
+	HeaderOrigin           = "Origin"
+	HeaderRequestMethod    = "Access-Control-Request-Method"
+	HeaderRequestHeaders   = "Access-Control-Request-Headers"
+	HeaderAllowOrigin      = "Access-Control-Allow-Origin"
+	HeaderAllowCredentials = "Access-Control-Allow-Credentials"
+	HeaderAllowMethods     = "Access-Control-Allow-Methods"
+	HeaderAllowHeaders     = "Access-Control-Allow-Headers"
+	HeaderExposeHeaders    = "Access-Control-Expose-Headers"
+	HeaderMaxAge           = "Access-Control-Max-Age"
+)
+
+// 既定値
+var (
+	DefaultMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	DefaultHeaders = []string{"Accept", "Accept-Language", "Authorization", "Cache-Control", "Content-Type", "X-CSRF-Token", "X-Organization", "X-Request-ID", "X-Requested-With"}
+	DefaultExposed = []string{"X-Request-ID", "Content-Disposition"}
+	DefaultMaxAge  = 10 * time.Minute
+)
+
+// ErrWildcardCredentials 全オリジン（*）の許可とCookie等の資格情報の送信は併用できない
+var ErrWildcardCredentials = errors.New("cors: allow credentials cannot be combined with the * origin")
+
+// Policy オリジン間リクエストの許可内容
+type Policy struct {
+	// AllowedOrigins 許可するオリジン（空ならどのオリジンも許可しない）
+	// 完全一致・*（全オリジン）・https://*.example.com（サブドメイン）・http://localhost:*（任意のポート）
+	AllowedOrigins []string
+	// AllowedMethods 許可するメソッド（空なら DefaultMethods）
+	AllowedMethods []string
+	// AllowedHeaders 許可するリクエストヘッダー（空なら DefaultHeaders、* なら任意のヘッダー）
+	AllowedHeaders []string
+	// ExposedHeaders ブラウザのスクリプトに公開するレスポンスヘッダー
+	ExposedHeaders []string
+	// AllowCredentials Cookie等の資格情報付きのリクエストを許可する（* のオリジンとは併用不可）
+	AllowCredentials bool
+	// MaxAge プリフライトの結果をブラウザがキャッシュする時間（0なら送らずブラウザの既定）
+	MaxAge time.Duration
+}
+
+// Route パスごとのポリシー
+type Route struct {
+	// Path 対象のパス（* は1セグメント、末尾の /** は配下すべてに一致）
+	Path   string
+	Policy Policy
+}
+
+// Config CORSの設定（Routes を先頭から照合し、一致しなければ Default）
+type Config struct {
+	Default Policy
+	Routes  []Route
+}
+
+// LoadPolicy 環境変数から既定のポリシーを読み込む
+// CORS_ALLOWED_ORIGINS（カンマ区切り、未設定なら資格情報なしで全オリジン）・CORS_ALLOWED_METHODS・
+// CORS_ALLOWED_HEADERS・CORS_EXPOSED_HEADERS・CORS_ALLOW_CREDENTIALS（既定はオリジンを列挙していれば true）・
+// CORS_MAX_AGE（既定10分）
+func LoadPolicy() (Policy, error) {
+	policy := Policy{
+		AllowedOrigins: splitList(os.Getenv("CORS_ALLOWED_ORIGINS")),
+		AllowedMethods: splitList(os.Getenv("CORS_ALLOWED_METHODS")),
+		AllowedHeaders: splitList(os.Getenv("CORS_ALLOWED_HEADERS")),
+		ExposedHeaders: DefaultExposed,
+		MaxAge:         DefaultMaxAge,
+	}
+	if len(policy.AllowedOrigins) == 0 {
+		policy.AllowedOrigins = []string{"*"}
+	}
+	if value, ok := os.LookupEnv("CORS_EXPOSED_HEADERS"); ok {
+		policy.ExposedHeaders = splitList(value)
+	}
+
+	switch value := os.Getenv("CORS_ALLOW_CREDENTIALS"); value {
+	case "":
+		policy.AllowCredentials = !containsWildcard(policy.AllowedOrigins)
+	case "true", "1":
+		policy.AllowCredentials = true
+	case "false", "0":
+	default:
+		return Policy{}, fmt.Errorf("cors: invalid CORS_ALLOW_CREDENTIALS %q", value)
+	}
+
+	if value := os.Getenv("CORS_MAX_AGE"); value != "" {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil || maxAge < 0 {
+			return Policy{}, fmt.Errorf("cors: invalid CORS_MAX_AGE %q", value)
+		}
+		policy.MaxAge = maxAge
+	}
+
+	return policy, nil
+}
+
+// Engine 設定を検証・解析したCORSのポリシー
+type Engine struct {
+	routes   []route
+	fallback *policy
+}
+
+type route struct {
+	pattern string
+	prefix  string
+	policy  *policy
+}
+
+// policy 解析済みのポリシー
+type policy struct {
+	origins       []originPattern
+	anyOrigin     bool
+	methods       map[string]bool
+	allowMethods  string
+	headers       map[string]bool
+	anyHeader     bool
+	exposeHeaders string
+	credentials   bool
+	maxAge        string
+}
+
+// New 設定を検証してEngineを作成する
+func New(cfg Config) (*Engine, error) {
+	fallback, err := compile(cfg.Default)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Engine{fallback: fallback}
+	for _, r := range cfg.Routes {
+		if !strings.HasPrefix(r.Path, "/") {
+			return nil, fmt.Errorf("cors: invalid route path %q", r.Path)
+		}
+		if _, err := path.Match(r.Path, "/"); err != nil {
+			return nil, fmt.Errorf("cors: invalid route path %q: %w", r.Path, err)
+		}
+		p, err := compile(r.Policy)
+		if err != nil {
+			return nil, fmt.Errorf("%w (route %s)", err, r.Path)
+		}
+		compiled := route{pattern: r.Path, policy: p}
+		if prefix, ok := strings.CutSuffix(r.Path, "/**"); ok {
+			compiled.prefix = prefix + "/"
+		}
+		e.routes = append(e.routes, compiled)
+	}
+	return e, nil
+}
+
+func compile(p Policy) (*policy, error) {
+	compiled := &policy{
+		methods:     map[string]bool{},
+		headers:     map[string]bool{},
+		credentials: p.AllowCredentials,
+	}
+
+	for _, raw := range p.AllowedOrigins {
+		pattern, err := parseOriginPattern(raw)
+		if err != nil {
+			return nil, err
+		}
+		if pattern.any {
+			compiled.anyOrigin = true
+		}
+		compiled.origins = append(compiled.origins, pattern)
+	}
+	if compiled.anyOrigin && compiled.credentials {
+		return nil, ErrWildcardCredentials
+	}
+
+	methods := p.AllowedMethods
+	if len(methods) == 0 {
+		methods = DefaultMethods
+	}
+	for i, method := range methods {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if method == "" || method == "*" {
+			return nil, fmt.Errorf("cors: invalid method %q", methods[i])
+		}
+		compiled.methods[method] = true
+		if compiled.allowMethods != "" {
+			compiled.allowMethods += ", "
+		}
+		compiled.allowMethods += method
+	}
+
+	headers := p.AllowedHeaders
+	if len(headers) == 0 {
+		headers = DefaultHeaders
+	}
+	for _, header := range headers {
+		header = strings.ToLower(strings.TrimSpace(header))
+		if header == "*" {
+			compiled.anyHeader = true
+		}
+		compiled.headers[header] = true
+	}
+
+	compiled.exposeHeaders = strings.Join(p.ExposedHeaders, ", ")
+
+	if p.MaxAge < 0 {
+		return nil, fmt.Errorf("cors: invalid max age %s", p.MaxAge)
+	}
+	if p.MaxAge > 0 {
+		compiled.maxAge = strconv.Itoa(int(p.MaxAge / time.Second))
+	}
+	return compiled, nil
+}
+
+// IsPreflight プリフライトリクエスト（Origin と Access-Control-Request-Method 付きのOPTIONS）か
+func IsPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get(HeaderOrigin) != "" && r.Header.Get(HeaderRequestMethod) != ""
+}
+
+// Preflight プリフライトの応答ヘッダーを h に設定する
+// オリジン・メソッド・リクエストヘッダーのいずれかが許可されていなければ false（許可のヘッダーは設定しない）
+func (e *Engine) Preflight(h http.Header, r *http.Request) bool {
+	h.Add("Vary", HeaderOrigin)
+	h.Add("Vary", HeaderRequestMethod)
+	h.Add("Vary", HeaderRequestHeaders)
+
+	p := e.policyFor(r.URL.Path)
+	origin := r.Header.Get(HeaderOrigin)
+	if !p.allowsOrigin(origin) || !p.methods[r.Header.Get(HeaderRequestMethod)] {
+		return false
+	}
+
+	requested := requestedHeaders(r.Header.Values(HeaderRequestHeaders))
+	for _, header := range requested {
+		if !p.anyHeader && !p.headers[header] {
+			return false
+		}
+	}
+
+	p.writeOrigin(h, origin)
+	h.Set(HeaderAllowMethods, p.allowMethods)
+	if len(requested) > 0 {
+		// 資格情報付きでは * が文字どおりに扱われるため、要求されたヘッダーを列挙して返す
+		h.Set(HeaderAllowHeaders, strings.Join(requested, ", "))
+	}
+	if p.maxAge != "" {
+		h.Set(HeaderMaxAge, p.maxAge)
+	}
+	return true
+}
+
+// Apply プリフライト以外のリクエストの応答ヘッダーを h に設定する
+// 応答はOriginによって変わるため、Originのないリクエストにも Vary: Origin を付ける
+func (e *Engine) Apply(h http.Header, r *http.Request) {
+	h.Add("Vary", HeaderOrigin)
+
+	origin := r.Header.Get(HeaderOrigin)
+	if origin == "" {
+		return
+	}
+	p := e.policyFor(r.URL.Path)
+	if !p.allowsOrigin(origin) {
+		return
+	}
+
+	p.writeOrigin(h, origin)
+	if p.exposeHeaders != "" {
+		h.Set(HeaderExposeHeaders, p.exposeHeaders)
+	}
+}
+
+// policyFor パスに適用するポリシー
+func (e *Engine) policyFor(requestPath string) *policy {
+	for _, r := range e.routes {
+		if r.prefix != "" {
+			if strings.HasPrefix(requestPath, r.prefix) {
+				return r.policy
+			}
+			continue
+		}
+		if ok, _ := path.Match(r.pattern, requestPath); ok {
+			return r.policy
+		}
+	}
+	return e.fallback
+}
+
+func (p *policy) allowsOrigin(origin string) bool {
+	for _, pattern := range p.origins {
+		if pattern.match(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeOrigin 許可したオリジンと資格情報の可否を設定する
+func (p *policy) writeOrigin(h http.Header, origin string) {
+	if p.anyOrigin && !p.credentials {
+		h.Set(HeaderAllowOrigin, "*")
+		return
+	}
+	h.Set(HeaderAllowOrigin, origin)
+	if p.credentials {
+		h.Set(HeaderAllowCredentials, "true")
+	}
+}
+
+// requestedHeaders Access-Control-Request-Headers を小文字のヘッダー名に分解する
+func requestedHeaders(values []string) []string {
+	var headers []string
+	for _, value := range values {
+		for _, header := range strings.Split(value, ",") {
+			if header = strings.ToLower(strings.TrimSpace(header)); header != "" {
+				headers = append(headers, header)
+			}
+		}
+	}
+	return headers
+}
+
+// splitList カンマ区切りの値を分解する（空の要素は除く）
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func containsWildcard(origins []string) bool {
+	for _, origin := range origins {
+		if strings.TrimSpace(origin) == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,414 @@
+package cors
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+// credentialed 列挙したオリジンに資格情報付きで許可するポリシー
+var credentialed = Policy{
+	AllowedOrigins:   []string{"http://localhost:3000", "https://*.example.com", "http://127.0.0.1:*"},
+	ExposedHeaders:   []string{"X-Request-ID", "X-Total-Count"},
+	AllowCredentials: true,
+	MaxAge:           time.Hour,
+}
+
+// public 資格情報なしで全オリジンから取得だけを許可するポリシー
+var public = Policy{
+	AllowedOrigins: []string{"*"},
+	AllowedMethods: []string{http.MethodGet, http.MethodHead},
+	AllowedHeaders: []string{"Range"},
+	ExposedHeaders: []string{"Content-Range"},
+}
+
+func newEngine(t *testing.T, cfg Config) *Engine {
+	t.Helper()
+	e, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return e
+}
+
+// routedEngine アプリケーションと同じくアバター画像とファイルだけを公開するエンジン
+func routedEngine(t *testing.T) *Engine {
+	return newEngine(t, Config{
+		Default: credentialed,
+		Routes: []Route{
+			{Path: "/api/v1/users/*/avatar", Policy: public},
+			{Path: "/api/v1/files/**", Policy: public},
+		},
+	})
+}
+
+func request(method, target string, headers map[string]string) *http.Request {
+	r := httptest.NewRequest(method, target, nil)
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+	return r
+}
+
+// apply プリフライト以外のリクエストの応答ヘッダー
+func apply(e *Engine, method, target string, headers map[string]string) http.Header {
+	h := http.Header{}
+	e.Apply(h, request(method, target, headers))
+	return h
+}
+
+// preflight プリフライトの結果と応答ヘッダー
+func preflight(e *Engine, target, origin, method, requestHeaders string) (bool, http.Header) {
+	headers := map[string]string{HeaderOrigin: origin, HeaderRequestMethod: method}
+	if requestHeaders != "" {
+		headers[HeaderRequestHeaders] = requestHeaders
+	}
+	h := http.Header{}
+	ok := e.Preflight(h, request(http.MethodOptions, target, headers))
+	return ok, h
+}
+
+func TestApplyAllowedOrigin(t *testing.T) {
+	h := apply(routedEngine(t), http.MethodGet, "/api/v1/users", map[string]string{HeaderOrigin: "http://localhost:3000"})
+
+	want := map[string]string{
+		HeaderAllowOrigin:      "http://localhost:3000",
+		HeaderAllowCredentials: "true",
+		HeaderExposeHeaders:    "X-Request-ID, X-Total-Count",
+		HeaderAllowMethods:     "",
+		HeaderMaxAge:           "",
+	}
+	for name, value := range want {
+		if got := h.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	if got := h.Values("Vary"); !slices.Equal(got, []string{"Origin"}) {
+		t.Errorf("Vary = %v, want [Origin]", got)
+	}
+}
+
+func TestApplyOrigins(t *testing.T) {
+	e := routedEngine(t)
+	tests := map[string]bool{
+		"http://localhost:3000":        true,
+		"https://app.example.com":      true,
+		"https://a.b.example.com":      true,
+		"http://127.0.0.1:5173":        true,
+		"http://127.0.0.1":             true,
+		"https://example.com":          false,
+		"https://evil-example.com":     false,
+		"https://example.com.evil.io":  false,
+		"http://app.example.com":       false,
+		"https://app.example.com:8443": false,
+		"http://localhost:3001":        false,
+		"https://x.example.com/path":   false,
+		"null":                         false,
+		"http://evil.test":             false,
+	}
+	for origin, allowed := range tests {
+		h := apply(e, http.MethodGet, "/api/v1/users", map[string]string{HeaderOrigin: origin})
+		if allowed {
+			if h.Get(HeaderAllowOrigin) != origin || h.Get(HeaderAllowCredentials) != "true" {
+				t.Errorf("%s: headers = %v, want the origin echoed with credentials", origin, h)
+			}
+			continue
+		}
+		// 許可しないオリジンには CORS のヘッダーを付けない（Vary は付ける）
+		if len(h) != 1 || h.Get("Vary") != "Origin" {
+			t.Errorf("%s: headers = %v, want only Vary: Origin", origin, h)
+		}
+	}
+}
+
+func TestApplyWithoutOriginVaries(t *testing.T) {
+	// Origin のない応答がキャッシュされ、別オリジンに使い回されないようにする
+	h := apply(routedEngine(t), http.MethodGet, "/api/v1/users", nil)
+	if len(h) != 1 || h.Get("Vary") != "Origin" {
+		t.Errorf("headers = %v, want only Vary: Origin", h)
+	}
+}
+
+func TestApplyNullOrigin(t *testing.T) {
+	// 既定では null を許可しない
+	if h := apply(routedEngine(t), http.MethodGet, "/api/v1/users", map[string]string{HeaderOrigin: "null"}); h.Get(HeaderAllowOrigin) != "" {
+		t.Errorf("null origin allowed by default: %v", h)
+	}
+
+	e := newEngine(t, Config{Default: Policy{AllowedOrigins: []string{"null"}}})
+	if h := apply(e, http.MethodGet, "/", map[string]string{HeaderOrigin: "null"}); h.Get(HeaderAllowOrigin) != "null" {
+		t.Errorf("explicit null origin: headers = %v", h)
+	}
+	if h := apply(e, http.MethodGet, "/", map[string]string{HeaderOrigin: "https://app.example.com"}); h.Get(HeaderAllowOrigin) != "" {
+		t.Errorf("null policy allowed another origin: %v", h)
+	}
+}
+
+func TestApplyWildcardWithoutCredentials(t *testing.T) {
+	e := newEngine(t, Config{Default: Policy{AllowedOrigins: []string{"*"}}})
+	h := apply(e, http.MethodGet, "/api/v1/users", map[string]string{HeaderOrigin: "https://any.example"})
+	if h.Get(HeaderAllowOrigin) != "*" || h.Get(HeaderAllowCredentials) != "" {
+		t.Errorf("headers = %v, want * without credentials", h)
+	}
+}
+
+func TestZeroPolicyDeniesEverything(t *testing.T) {
+	e := newEngine(t, Config{})
+	if h := apply(e, http.MethodGet, "/", map[string]string{HeaderOrigin: "http://localhost:3000"}); h.Get(HeaderAllowOrigin) != "" {
+		t.Errorf("zero policy allowed an origin: %v", h)
+	}
+	if ok, _ := preflight(e, "/", "http://localhost:3000", http.MethodGet, ""); ok {
+		t.Error("zero policy allowed a preflight")
+	}
+}
+
+func TestPreflightAllowed(t *testing.T) {
+	ok, h := preflight(routedEngine(t), "/api/v1/users/1", "https://app.example.com", http.MethodPatch, "content-type,x-csrf-token, Authorization")
+	if !ok {
+		t.Fatalf("preflight denied: %v", h)
+	}
+
+	want := map[string]string{
+		HeaderAllowOrigin:      "https://app.example.com",
+		HeaderAllowCredentials: "true",
+		HeaderAllowMethods:     "GET, HEAD, POST, PUT, PATCH, DELETE",
+		// 要求されたヘッダーを小文字にして列挙する
+		HeaderAllowHeaders:  "content-type, x-csrf-token, authorization",
+		HeaderMaxAge:        "3600",
+		HeaderExposeHeaders: "",
+	}
+	for name, value := range want {
+		if got := h.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	if got, want := h.Values("Vary"), []string{HeaderOrigin, HeaderRequestMethod, HeaderRequestHeaders}; !slices.Equal(got, want) {
+		t.Errorf("Vary = %v, want %v", got, want)
+	}
+
+	// 要求されたヘッダーがなければ Access-Control-Allow-Headers は送らない
+	ok, h = preflight(routedEngine(t), "/api/v1/users", "http://localhost:3000", http.MethodGet, "")
+	if !ok || h.Get(HeaderAllowHeaders) != "" {
+		t.Errorf("preflight without headers = %v, %v", ok, h)
+	}
+}
+
+func TestPreflightDenied(t *testing.T) {
+	e := routedEngine(t)
+	tests := []struct {
+		name    string
+		origin  string
+		method  string
+		headers string
+	}{
+		{"origin", "https://evil.test", http.MethodGet, ""},
+		{"apex of subdomain pattern", "https://example.com", http.MethodGet, ""},
+		{"lookalike origin", "https://evil-example.com", http.MethodGet, ""},
+		{"null origin", "null", http.MethodGet, ""},
+		{"method", "https://app.example.com", "TRACE", ""},
+		// メソッドは大文字と小文字を区別する
+		{"lower case method", "https://app.example.com", "patch", ""},
+		{"header", "https://app.example.com", http.MethodPatch, "x-evil"},
+		{"one of the headers", "https://app.example.com", http.MethodPatch, "content-type, x-evil"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, h := preflight(e, "/api/v1/users/1", tt.origin, tt.method, tt.headers)
+			if ok {
+				t.Fatal("preflight allowed")
+			}
+			// 拒否した場合も Vary は付け、許可のヘッダーは付けない
+			for _, name := range []string{HeaderAllowOrigin, HeaderAllowCredentials, HeaderAllowMethods, HeaderAllowHeaders, HeaderMaxAge} {
+				if h.Get(name) != "" {
+					t.Errorf("%s = %q on a denied preflight", name, h.Get(name))
+				}
+			}
+			if got := h.Values("Vary"); len(got) != 3 {
+				t.Errorf("Vary = %v", got)
+			}
+		})
+	}
+}
+
+func TestPreflightAnyHeader(t *testing.T) {
+	e := newEngine(t, Config{Default: Policy{
+		AllowedOrigins:   []string{"http://a.test"},
+		AllowedHeaders:   []string{"*"},
+		AllowCredentials: true,
+	}})
+	// 資格情報付きでは * が文字どおりに扱われるため、要求されたヘッダーをそのまま返す
+	ok, h := preflight(e, "/", "http://a.test", http.MethodPatch, "X-Anything, x-other")
+	if !ok || h.Get(HeaderAllowHeaders) != "x-anything, x-other" {
+		t.Errorf("preflight = %v, %v", ok, h)
+	}
+}
+
+func TestIsPreflight(t *testing.T) {
+	tests := []struct {
+		method  string
+		headers map[string]string
+		want    bool
+	}{
+		{http.MethodOptions, map[string]string{HeaderOrigin: "http://a.test", HeaderRequestMethod: "GET"}, true},
+		{http.MethodOptions, map[string]string{HeaderOrigin: "http://a.test"}, false},
+		{http.MethodOptions, map[string]string{HeaderRequestMethod: "GET"}, false},
+		{http.MethodOptions, nil, false},
+		{http.MethodGet, map[string]string{HeaderOrigin: "http://a.test", HeaderRequestMethod: "GET"}, false},
+	}
+	for _, tt := range tests {
+		if got := IsPreflight(request(tt.method, "/", tt.headers)); got != tt.want {
+			t.Errorf("IsPreflight(%s %v) = %v, want %v", tt.method, tt.headers, got, tt.want)
+		}
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	e := routedEngine(t)
+	anyOrigin := map[string]string{HeaderOrigin: "https://whatever.test"}
+
+	// 公開するパスは資格情報なしで全オリジンに許可する
+	for _, target := range []string{"/api/v1/users/5/avatar", "/api/v1/files/avatars/1/abc/256", "/api/v1/files/a"} {
+		h := apply(e, http.MethodGet, target, anyOrigin)
+		if h.Get(HeaderAllowOrigin) != "*" || h.Get(HeaderAllowCredentials) != "" || h.Get(HeaderExposeHeaders) != "Content-Range" {
+			t.Errorf("%s: headers = %v", target, h)
+		}
+	}
+
+	// 一致しないパスは既定のポリシー
+	for _, target := range []string{"/api/v1/filesX", "/api/v1/files", "/api/v1/users/5/avatar/x", "/api/v1/me/avatar", "/api/v1/users/5"} {
+		if h := apply(e, http.MethodGet, target, anyOrigin); h.Get(HeaderAllowOrigin) != "" {
+			t.Errorf("%s: public policy applied: %v", target, h)
+		}
+	}
+
+	ok, h := preflight(e, "/api/v1/files/a", "https://whatever.test", http.MethodGet, "range")
+	if !ok || h.Get(HeaderAllowOrigin) != "*" || h.Get(HeaderAllowMethods) != "GET, HEAD" || h.Get(HeaderMaxAge) != "" {
+		t.Errorf("files preflight = %v, %v", ok, h)
+	}
+	if ok, _ := preflight(e, "/api/v1/files/a", "https://whatever.test", http.MethodDelete, ""); ok {
+		t.Error("files preflight allowed DELETE")
+	}
+	if ok, _ := preflight(e, "/api/v1/files/a", "https://whatever.test", http.MethodGet, "authorization"); ok {
+		t.Error("files preflight allowed the Authorization header")
+	}
+
+	// 自分のアバターの変更は既定のポリシー
+	if ok, _ := preflight(e, "/api/v1/me/avatar", "https://whatever.test", http.MethodPut, ""); ok {
+		t.Error("me/avatar preflight allowed an unknown origin")
+	}
+	if ok, h := preflight(e, "/api/v1/me/avatar", "http://localhost:3000", http.MethodPut, "content-type"); !ok || h.Get(HeaderAllowCredentials) != "true" {
+		t.Errorf("me/avatar preflight = %v, %v", ok, h)
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want error
+	}{
+		{"wildcard with credentials", Config{Default: Policy{AllowedOrigins: []string{"*"}, AllowCredentials: true}}, ErrWildcardCredentials},
+		{"wildcard with credentials in a list", Config{Default: Policy{AllowedOrigins: []string{"https://a.test", "*"}, AllowCredentials: true}}, ErrWildcardCredentials},
+		{"wildcard with credentials in a route", Config{Routes: []Route{{Path: "/a", Policy: Policy{AllowedOrigins: []string{"*"}, AllowCredentials: true}}}}, ErrWildcardCredentials},
+		{"invalid origin", Config{Default: Policy{AllowedOrigins: []string{"example.com"}}}, nil},
+		{"wildcard method", Config{Default: Policy{AllowedMethods: []string{"*"}}}, nil},
+		{"empty method", Config{Default: Policy{AllowedMethods: []string{" "}}}, nil},
+		{"negative max age", Config{Default: Policy{MaxAge: -time.Second}}, nil},
+		{"relative route", Config{Routes: []Route{{Path: "api", Policy: public}}}, nil},
+		{"invalid route glob", Config{Routes: []Route{{Path: "/[", Policy: public}}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg)
+			if err == nil {
+				t.Fatal("New succeeded")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// clearEnv CORS_* の環境変数をテストの間だけ未設定にする
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"CORS_ALLOWED_ORIGINS", "CORS_ALLOWED_METHODS", "CORS_ALLOWED_HEADERS", "CORS_EXPOSED_HEADERS", "CORS_ALLOW_CREDENTIALS", "CORS_MAX_AGE"} {
+		// t.Setenv で終了時に元の値に戻してから未設定にする
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		clearEnv(t)
+		p, err := LoadPolicy()
+		if err != nil {
+			t.Fatalf("LoadPolicy: %v", err)
+		}
+		// 未設定なら資格情報なしで全オリジン
+		if !slices.Equal(p.AllowedOrigins, []string{"*"}) || p.AllowCredentials || p.MaxAge != DefaultMaxAge || !slices.Equal(p.ExposedHeaders, DefaultExposed) {
+			t.Errorf("policy = %+v", p)
+		}
+		if _, err := New(Config{Default: p}); err != nil {
+			t.Errorf("New with the default policy: %v", err)
+		}
+	})
+
+	t.Run("listed origins", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("CORS_ALLOWED_ORIGINS", "http://localhost:3000, https://*.example.com,http://127.0.0.1:*,")
+		t.Setenv("CORS_EXPOSED_HEADERS", "X-Request-ID,X-Total-Count")
+		t.Setenv("CORS_MAX_AGE", "1h")
+		p, err := LoadPolicy()
+		if err != nil {
+			t.Fatalf("LoadPolicy: %v", err)
+		}
+		// オリジンを列挙すれば資格情報付きを既定で許可する
+		if len(p.AllowedOrigins) != 3 || !p.AllowCredentials || p.MaxAge != time.Hour || !slices.Equal(p.ExposedHeaders, []string{"X-Request-ID", "X-Total-Count"}) {
+			t.Errorf("policy = %+v", p)
+		}
+	})
+
+	t.Run("empty exposed headers", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("CORS_EXPOSED_HEADERS", "")
+		p, err := LoadPolicy()
+		if err != nil || len(p.ExposedHeaders) != 0 {
+			t.Errorf("policy = %+v, %v, want no exposed headers when set to empty", p, err)
+		}
+	})
+
+	t.Run("wildcard with credentials", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("CORS_ALLOWED_ORIGINS", "*")
+		t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+		p, err := LoadPolicy()
+		if err != nil {
+			t.Fatalf("LoadPolicy: %v", err)
+		}
+		// 起動時に New で拒否する
+		if _, err := New(Config{Default: p}); !errors.Is(err, ErrWildcardCredentials) {
+			t.Errorf("New err = %v, want ErrWildcardCredentials", err)
+		}
+	})
+
+	for name, env := range map[string][2]string{
+		"invalid credentials": {"CORS_ALLOW_CREDENTIALS", "maybe"},
+		"invalid max age":     {"CORS_MAX_AGE", "ten minutes"},
+		"negative max age":    {"CORS_MAX_AGE", "-1s"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			clearEnv(t)
+			t.Setenv(env[0], env[1])
+			if _, err := LoadPolicy(); err == nil {
+				t.Errorf("LoadPolicy with %s=%q succeeded", env[0], env[1])
+			}
+		})
+	}
+}
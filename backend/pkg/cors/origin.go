@@ -0,0 +1,101 @@
+package cors
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// originPattern 許可するオリジンのパターン
+// 完全一致（https://app.example.com）、全オリジン（*）、
+// サブドメイン（https://*.example.com）、任意のポート（http://localhost:*）に対応する
+type originPattern struct {
+	any    bool
+	exact  string
+	scheme string
+	// hostSuffix サブドメインのパターンで一致させる末尾（例: .example.com）
+	hostSuffix string
+	host       string
+	anyPort    bool
+	port       string
+}
+
+// parseOriginPattern オリジンのパターンを解析する
+func parseOriginPattern(raw string) (originPattern, error) {
+	pattern := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(raw), "/"))
+	switch {
+	case pattern == "*":
+		return originPattern{any: true}, nil
+	case pattern == "null":
+		// サンドボックス化されたiframeなどのオリジン（明示した場合のみ許可）
+		return originPattern{exact: pattern}, nil
+	}
+
+	scheme, rest, ok := strings.Cut(pattern, "://")
+	if !ok || scheme == "" || rest == "" || strings.ContainsAny(rest, "/?#@") {
+		return originPattern{}, fmt.Errorf("cors: invalid origin %q (expected scheme://host[:port])", raw)
+	}
+
+	if !strings.Contains(rest, "*") {
+		u, err := url.Parse(pattern)
+		if err != nil || u.Host == "" {
+			return originPattern{}, fmt.Errorf("cors: invalid origin %q", raw)
+		}
+		return originPattern{exact: pattern}, nil
+	}
+
+	host, port := splitHostPort(rest)
+	p := originPattern{scheme: scheme, host: host, port: port}
+	if strings.HasPrefix(host, "*.") {
+		p.hostSuffix = host[1:]
+		p.host = ""
+	}
+	if port == "*" {
+		p.anyPort = true
+		p.port = ""
+	}
+	if strings.Contains(p.host+p.hostSuffix+p.port, "*") || p.hostSuffix == "." || p.host+p.hostSuffix == "" {
+		return originPattern{}, fmt.Errorf("cors: invalid origin pattern %q (wildcards are allowed only as a leading subdomain or as the port)", raw)
+	}
+	return p, nil
+}
+
+// match オリジン（Originヘッダーの値）がパターンに一致するか
+func (p originPattern) match(origin string) bool {
+	if p.any {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if p.exact != "" {
+		return origin == p.exact
+	}
+
+	scheme, rest, ok := strings.Cut(origin, "://")
+	if !ok || scheme != p.scheme || rest == "" || strings.ContainsAny(rest, "/?#@*") {
+		return false
+	}
+
+	host, port := splitHostPort(rest)
+	if !p.anyPort && port != p.port {
+		return false
+	}
+	if p.hostSuffix != "" {
+		// example.com 自体や evil-example.com には一致させない
+		return len(host) > len(p.hostSuffix) && strings.HasSuffix(host, p.hostSuffix)
+	}
+	return host == p.host
+}
+
+// splitHostPort host[:port] をホストとポートに分ける（ポートがなければ空）
+func splitHostPort(hostport string) (string, string) {
+	if strings.HasPrefix(hostport, "[") {
+		// IPv6アドレス
+		end := strings.IndexByte(hostport, ']')
+		if end < 0 {
+			return hostport, ""
+		}
+		return hostport[:end+1], strings.TrimPrefix(hostport[end+1:], ":")
+	}
+	host, port, _ := strings.Cut(hostport, ":")
+	return host, port
+}
@@ -0,0 +1,103 @@
+package cors
+
+import "testing"
+
+func TestOriginPatternMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		origins map[string]bool
+	}{
+		{"https://app.example.com", map[string]bool{
+			"https://app.example.com":      true,
+			"https://APP.Example.com":      true,
+			"http://app.example.com":       false,
+			"https://app.example.com:443":  false,
+			"https://app.example.com.evil": false,
+			"null":                         false,
+		}},
+		{"https://*.example.com", map[string]bool{
+			"https://app.example.com":      true,
+			"https://a.b.example.com":      true,
+			"https://APP.Example.com":      true,
+			"https://example.com":          false,
+			"https://.example.com":         false,
+			"https://evil-example.com":     false,
+			"https://example.com.evil.io":  false,
+			"http://app.example.com":       false,
+			"https://app.example.com:8443": false,
+			"https://x.example.com/path":   false,
+			"https://user@x.example.com":   false,
+			"https://*.example.com":        false,
+			"null":                         false,
+		}},
+		{"http://localhost:*", map[string]bool{
+			"http://localhost":          true,
+			"http://localhost:3000":     true,
+			"http://localhost:5173":     true,
+			"https://localhost:3000":    false,
+			"http://localhost.evil:80":  false,
+			"http://evil-localhost:300": false,
+		}},
+		{"https://*.example.com:*", map[string]bool{
+			"https://app.example.com:8443": true,
+			"https://app.example.com":      true,
+			"https://example.com:8443":     false,
+		}},
+		{"http://[::1]:*", map[string]bool{
+			"http://[::1]:3000": true,
+			"http://[::1]":      true,
+			"http://[::2]:3000": false,
+		}},
+		// サンドボックス化されたiframeなどの null は明示した場合のみ
+		{"null", map[string]bool{
+			"null":                    true,
+			"https://app.example.com": false,
+		}},
+		{"*", map[string]bool{
+			"https://anything.test": true,
+			"null":                  true,
+		}},
+	}
+	for _, tt := range tests {
+		pattern, err := parseOriginPattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("parseOriginPattern(%q): %v", tt.pattern, err)
+		}
+		for origin, want := range tt.origins {
+			if got := pattern.match(origin); got != want {
+				t.Errorf("%q match %q = %v, want %v", tt.pattern, origin, got, want)
+			}
+		}
+	}
+}
+
+func TestParseOriginPatternRejectsInvalid(t *testing.T) {
+	for _, raw := range []string{
+		"example.com",
+		"ftp//x",
+		"https://",
+		"https://a.com/path",
+		"https://a.com?x=1",
+		"https://user@a.com",
+		"https://app*.example.com",
+		"https://*",
+		"https://*.",
+		"https://*.*.a.com",
+		"https://a.*.com",
+		"http://localhost:3*",
+	} {
+		if _, err := parseOriginPattern(raw); err == nil {
+			t.Errorf("parseOriginPattern(%q) succeeded", raw)
+		}
+	}
+}
+
+func TestParseOriginPatternNormalizes(t *testing.T) {
+	pattern, err := parseOriginPattern("  HTTPS://App.Example.com/ ")
+	if err != nil {
+		t.Fatalf("parseOriginPattern: %v", err)
+	}
+	if !pattern.match("https://app.example.com") {
+		t.Error("pattern with upper case and a trailing slash does not match")
+	}
+}
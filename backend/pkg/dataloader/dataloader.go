@@ -0,0 +1,157 @@
+// Package dataloader 同じリクエスト内の個別の読み込みをまとめて1回のバッチ処理にする
+// Loadは読み込みを予約して結果を返す関数（thunk）を返し、最初にthunkが呼ばれた時点で
+// それまでに予約されたキーをまとめて読み込む。結果はローダーの生存期間中キャッシュされるため、
+// ローダーはリクエストごとに作成する
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNotFound バッチ処理の結果にキーが含まれていなかった
+var ErrNotFound = errors.New("not found")
+
+// BatchFunc キーをまとめて読み込む関数（見つからないキーはマップに含めない）
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Thunk 読み込み結果を返す関数（初回呼び出しで予約中のバッチを実行する）
+type Thunk[V any] func() (V, error)
+
+// Loader キーごとの読み込みをまとめるローダー
+type Loader[K comparable, V any] struct {
+	fetch    BatchFunc[K, V]
+	maxBatch int
+
+	mu      sync.Mutex
+	pending *batch[K, V]
+	entries map[K]*entry[V]
+}
+
+// entry 1キー分の読み込み結果
+type entry[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// batch 1回のバッチ処理で読み込むキーの集まり
+type batch[K comparable, V any] struct {
+	ctx     context.Context
+	keys    []K
+	entries []*entry[V]
+	once    sync.Once
+}
+
+// New ローダーを作成（maxBatchが0以下なら件数の上限なし）
+func New[K comparable, V any](fetch BatchFunc[K, V], maxBatch int) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:    fetch,
+		maxBatch: maxBatch,
+		entries:  make(map[K]*entry[V]),
+	}
+}
+
+// Load キーの読み込みを予約
+func (l *Loader[K, V]) Load(ctx context.Context, key K) Thunk[V] {
+	l.mu.Lock()
+	e, ok := l.entries[key]
+	if ok {
+		b := l.pending
+		l.mu.Unlock()
+		return l.thunk(b, e)
+	}
+
+	e = &entry[V]{done: make(chan struct{})}
+	l.entries[key] = e
+
+	if l.pending == nil {
+		l.pending = &batch[K, V]{ctx: ctx}
+	}
+	b := l.pending
+	b.keys = append(b.keys, key)
+	b.entries = append(b.entries, e)
+
+	// 上限に達したバッチは以降のキーを受け付けない
+	if l.maxBatch > 0 && len(b.keys) >= l.maxBatch {
+		l.pending = nil
+	}
+	l.mu.Unlock()
+
+	return l.thunk(b, e)
+}
+
+// LoadMany 複数キーの読み込みを予約（見つからないキーは結果から除く）
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) Thunk[[]V] {
+	thunks := make([]Thunk[V], len(keys))
+	for i, key := range keys {
+		thunks[i] = l.Load(ctx, key)
+	}
+
+	return func() ([]V, error) {
+		values := make([]V, 0, len(thunks))
+		for _, thunk := range thunks {
+			value, err := thunk()
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+}
+
+// thunk 結果を待つ関数（キャッシュ済みでbatchがnilの場合もある）
+func (l *Loader[K, V]) thunk(b *batch[K, V], e *entry[V]) Thunk[V] {
+	return func() (V, error) {
+		select {
+		case <-e.done:
+		default:
+			if b != nil {
+				l.dispatch(b)
+			}
+			// 別のバッチで予約済みのキーはそのバッチの完了を待つ
+			l.flush()
+			<-e.done
+		}
+		return e.value, e.err
+	}
+}
+
+// flush 予約中のバッチを実行
+func (l *Loader[K, V]) flush() {
+	l.mu.Lock()
+	b := l.pending
+	l.mu.Unlock()
+	if b != nil {
+		l.dispatch(b)
+	}
+}
+
+// dispatch バッチを1回だけ実行して各キーの結果を設定
+func (l *Loader[K, V]) dispatch(b *batch[K, V]) {
+	b.once.Do(func() {
+		l.mu.Lock()
+		if l.pending == b {
+			l.pending = nil
+		}
+		l.mu.Unlock()
+
+		values, err := l.fetch(b.ctx, b.keys)
+		for i, key := range b.keys {
+			e := b.entries[i]
+			if err != nil {
+				e.err = err
+			} else if value, ok := values[key]; ok {
+				e.value = value
+			} else {
+				e.err = ErrNotFound
+			}
+			close(e.done)
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// recorder 呼び出しごとのキーを記録するバッチ関数
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]int
+	missing map[int]bool
+	err     error
+}
+
+func (r *recorder) fetch(_ context.Context, keys []int) (map[int]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, slices.Clone(keys))
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	values := make(map[int]string, len(keys))
+	for _, key := range keys {
+		if !r.missing[key] {
+			values[key] = fmt.Sprintf("user-%d", key)
+		}
+	}
+	return values, nil
+}
+
+func (r *recorder) calls() [][]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.batches)
+}
+
+func TestLoadBatchesKeysIntoOneFetch(t *testing.T) {
+	rec := &recorder{}
+	loader := New(rec.fetch, 0)
+	ctx := context.Background()
+
+	thunks := make([]Thunk[string], 0, 10)
+	for id := 1; id <= 10; id++ {
+		thunks = append(thunks, loader.Load(ctx, id))
+	}
+	if got := rec.calls(); len(got) != 0 {
+		t.Fatalf("fetch called before any thunk: %v", got)
+	}
+
+	for i, thunk := range thunks {
+		value, err := thunk()
+		if err != nil {
+			t.Fatalf("thunk %d: %v", i, err)
+		}
+		if want := fmt.Sprintf("user-%d", i+1); value != want {
+			t.Errorf("thunk %d = %q, want %q", i, value, want)
+		}
+	}
+
+	got := rec.calls()
+	if len(got) != 1 {
+		t.Fatalf("fetch called %d times, want 1: %v", len(got), got)
+	}
+	if want := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}; !slices.Equal(got[0], want) {
+		t.Errorf("keys = %v, want %v", got[0], want)
+	}
+}
+
+func TestLoadDeduplicatesAndCaches(t *testing.T) {
+	rec := &recorder{}
+	loader := New(rec.fetch, 0)
+	ctx := context.Background()
+
+	first := loader.Load(ctx, 1)
+	second := loader.Load(ctx, 1)
+	if _, err := first(); err != nil {
+		t.Fatalf("first: %v", err)
+	}
+	if _, err := second(); err != nil {
+		t.Fatalf("second: %v", err)
+	}
+
+	// 読み込み済みのキーは再び読み込まない
+	if value, err := loader.Load(ctx, 1)(); err != nil || value != "user-1" {
+		t.Fatalf("cached = %q, %v", value, err)
+	}
+
+	got := rec.calls()
+	if len(got) != 1 || !slices.Equal(got[0], []int{1}) {
+		t.Errorf("batches = %v, want [[1]]", got)
+	}
+}
+
+func TestLoadSplitsAtMaxBatch(t *testing.T) {
+	rec := &recorder{}
+	loader := New(rec.fetch, 3)
+	ctx := context.Background()
+
+	thunks := make([]Thunk[string], 0, 7)
+	for id := 1; id <= 7; id++ {
+		thunks = append(thunks, loader.Load(ctx, id))
+	}
+	for i, thunk := range thunks {
+		if _, err := thunk(); err != nil {
+			t.Fatalf("thunk %d: %v", i, err)
+		}
+	}
+
+	// 待ち合わせ中に予約済みのバッチも実行するため、実行順は問わない
+	want := [][]int{{1, 2, 3}, {4, 5, 6}, {7}}
+	got := rec.calls()
+	slices.SortFunc(got, func(a, b []int) int { return a[0] - b[0] })
+	if len(got) != len(want) {
+		t.Fatalf("batches = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !slices.Equal(got[i], want[i]) {
+			t.Errorf("batch %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLoadConcurrentThunks(t *testing.T) {
+	rec := &recorder{}
+	loader := New(rec.fetch, 0)
+	ctx := context.Background()
+
+	thunks := make([]Thunk[string], 0, 50)
+	for id := 1; id <= 50; id++ {
+		thunks = append(thunks, loader.Load(ctx, id))
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(thunks))
+	for _, thunk := range thunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := thunk(); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("thunk: %v", err)
+	}
+
+	if got := rec.calls(); len(got) != 1 {
+		t.Errorf("fetch called %d times, want 1", len(got))
+	}
+}
+
+func TestLoadMissingKey(t *testing.T) {
+	rec := &recorder{missing: map[int]bool{2: true}}
+	loader := New(rec.fetch, 0)
+	ctx := context.Background()
+
+	found := loader.Load(ctx, 1)
+	missing := loader.Load(ctx, 2)
+
+	if _, err := missing(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing err = %v, want ErrNotFound", err)
+	}
+	if value, err := found(); err != nil || value != "user-1" {
+		t.Errorf("found = %q, %v", value, err)
+	}
+}
+
+func TestLoadFetchError(t *testing.T) {
+	fetchErr := errors.New("db down")
+	rec := &recorder{err: fetchErr}
+	loader := New(rec.fetch, 0)
+	ctx := context.Background()
+
+	thunks := []Thunk[string]{loader.Load(ctx, 1), loader.Load(ctx, 2)}
+	for i, thunk := range thunks {
+		if _, err := thunk(); !errors.Is(err, fetchErr) {
+			t.Errorf("thunk %d err = %v, want %v", i, err, fetchErr)
+		}
+	}
+	if got := rec.calls(); len(got) != 1 {
+		t.Errorf("fetch called %d times, want 1", len(got))
+	}
+}
+
+func TestLoadMany(t *testing.T) {
+	rec := &recorder{missing: map[int]bool{3: true}}
+	loader := New(rec.fetch, 0)
+
+	values, err := loader.LoadMany(context.Background(), []int{1, 2, 3, 4})()
+	if err != nil {
+		t.Fatalf("LoadMany: %v", err)
+	}
+	if want := []string{"user-1", "user-2", "user-4"}; !slices.Equal(values, want) {
+		t.Errorf("values = %v, want %v", values, want)
+	}
+	if got := rec.calls(); len(got) != 1 {
+		t.Errorf("fetch called %d times, want 1", len(got))
+	}
+}
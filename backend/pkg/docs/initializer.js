@@ -0,0 +1,34 @@
+// Swagger UIの初期化
+// 仕様の servers の先頭に表示中のオリジンを追加し、"Try it out" がこのサーバーに送られるようにする
+window.onload = function () {
+  var specURL = document.getElementById("swagger-ui").dataset.specUrl;
+
+  fetch(specURL)
+    .then(function (response) {
+      if (!response.ok) {
+        throw new Error("failed to load " + specURL + ": " + response.status);
+      }
+      return response.json();
+    })
+    .then(function (spec) {
+      var origin = window.location.origin;
+      var servers = (spec.servers || []).filter(function (server) {
+        return server.url !== origin;
+      });
+      spec.servers = [{ url: origin, description: "このサーバー" }].concat(servers);
+
+      window.ui = SwaggerUIBundle({
+        spec: spec,
+        dom_id: "#swagger-ui",
+        deepLinking: true,
+        // Authorize で入力したBearerトークンをリロード後も保持する
+        persistAuthorization: true,
+        presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+        plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+        layout: "BaseLayout",
+      });
+    })
+    .catch(function (err) {
+      document.getElementById("swagger-ui").textContent = err.message;
+    });
+};
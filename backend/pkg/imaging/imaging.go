@@ -0,0 +1,85 @@
+// Package imaging アップロードされた画像の読み込みと縮小（標準ライブラリのみで実装）
+// JPEG・PNG・GIF を読み込み、EXIFの向き（Orientation）を反映したRGBA画像として扱う。
+// 読み込んだ画素から再エンコードするため、EXIFなどのメタデータは出力に残らない
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"net/http"
+
+	// 読み込みに対応する形式を登録
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+var (
+	// ErrUnsupportedFormat 対応していない形式（内容から判定）
+	ErrUnsupportedFormat = errors.New("imaging: unsupported image format")
+	// ErrTooLarge 画素数が上限を超えている
+	ErrTooLarge = errors.New("imaging: image dimensions too large")
+)
+
+// 対応する形式（Content-Type）
+const (
+	TypeJPEG = "image/jpeg"
+	TypePNG  = "image/png"
+	TypeGIF  = "image/gif"
+)
+
+// Sniff 先頭のバイト列から形式を判定する（拡張子やリクエストのContent-Typeは信用しない）
+func Sniff(data []byte) (string, error) {
+	switch contentType := http.DetectContentType(data); contentType {
+	case TypeJPEG, TypePNG, TypeGIF:
+		return contentType, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, contentType)
+	}
+}
+
+// Decode 画像を読み込み、EXIFの向きを反映したRGBA画像にする（GIFは最初のフレーム）
+// 展開前にヘッダーの幅・高さを確認し、maxPixels を超える画像は展開しない
+func Decode(data []byte, maxPixels int) (*image.RGBA, error) {
+	if _, err := Sniff(data); err != nil {
+		return nil, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("imaging: failed to read image header: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrTooLarge, config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("imaging: failed to decode image: %w", err)
+	}
+
+	return Orient(toRGBA(img), Orientation(data)), nil
+}
+
+// CropSquare 中央を正方形に切り抜く
+func CropSquare(img *image.RGBA) *image.RGBA {
+	b := img.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	x := b.Min.X + (b.Dx()-side)/2
+	y := b.Min.Y + (b.Dy()-side)/2
+	return img.SubImage(image.Rect(x, y, x+side, y+side)).(*image.RGBA)
+}
+
+// toRGBA 任意の画像をRGBA（原点が(0,0)）に変換
+func toRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
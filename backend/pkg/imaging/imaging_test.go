@@ -0,0 +1,305 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// testImage 左上の1/4が赤、それ以外が青の w×h の画像（向きの確認用）
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 && y < h/2 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// withExif JPEG の SOI の直後に Orientation を含む APP1（Exif）を挿入する
+func withExif(jpegData []byte, order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 0x2A)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	entry := tiff[10:]
+	order.PutUint16(entry[0:], orientationTag)
+	order.PutUint16(entry[2:], 3)
+	order.PutUint32(entry[4:], 1)
+	order.PutUint16(entry[8:], orientation)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	out := append([]byte{}, jpegData[:2]...)
+	out = append(out, app1...)
+	return append(out, jpegData[2:]...)
+}
+
+// pngHeader 指定した大きさを宣言するだけの PNG（IHDR まで。画素データはない）
+func pngHeader(w, h uint32) []byte {
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], w)
+	binary.BigEndian.PutUint32(ihdr[4:], h)
+	ihdr[8] = 8 // ビット深度
+	ihdr[9] = 6 // RGBA
+
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(ihdr)))
+	chunk = append(chunk, "IHDR"...)
+	chunk = append(chunk, ihdr...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+	return append([]byte("\x89PNG\r\n\x1a\n"), chunk...)
+}
+
+func TestSniff(t *testing.T) {
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, testImage(2, 2), nil); err != nil {
+		t.Fatalf("gif.Encode: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"jpeg", encodeJPEG(t, testImage(4, 4)), TypeJPEG},
+		{"png", encodePNG(t, testImage(4, 4)), TypePNG},
+		{"gif", gifData.Bytes(), TypeGIF},
+		{"text", []byte("hello, world"), ""},
+		{"html", []byte("<html><body><img src=x onerror=alert(1)></body></html>"), ""},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`), ""},
+		{"pdf", []byte("%PDF-1.4\n"), ""},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Sniff(tt.data)
+			if tt.want == "" {
+				if !errors.Is(err, ErrUnsupportedFormat) {
+					t.Errorf("Sniff = %q, %v, want ErrUnsupportedFormat", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Sniff = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"not an image", []byte("GIF89 but not really an image"), ErrUnsupportedFormat},
+		// 小さなファイルで巨大な画像を宣言しても展開しない
+		{"too many pixels", pngHeader(10000, 10000), ErrTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.data, 1_000_000); !errors.Is(err, tt.want) {
+				t.Errorf("Decode err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// 形式は正しいが壊れている
+	broken := encodePNG(t, testImage(8, 8))
+	for name, data := range map[string][]byte{
+		"truncated":  broken[:len(broken)-20],
+		"zero width": pngHeader(0, 10),
+	} {
+		if _, err := Decode(data, 1_000_000); err == nil {
+			t.Errorf("Decode of a %s PNG succeeded", name)
+		}
+	}
+}
+
+func TestDecodeLimitIsInclusive(t *testing.T) {
+	data := encodePNG(t, testImage(10, 10))
+	if _, err := Decode(data, 100); err != nil {
+		t.Errorf("Decode at the limit: %v", err)
+	}
+	if _, err := Decode(data, 99); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Decode over the limit err = %v, want ErrTooLarge", err)
+	}
+}
+
+func TestDecodeAppliesExifOrientation(t *testing.T) {
+	base := encodeJPEG(t, testImage(40, 20))
+	tests := []struct {
+		orientation uint16
+		order       binary.ByteOrder
+		width       int
+		height      int
+		redX, redY  int
+	}{
+		{1, binary.BigEndian, 40, 20, 0, 0},
+		{3, binary.LittleEndian, 40, 20, 39, 19},
+		{6, binary.BigEndian, 20, 40, 19, 0},
+		{6, binary.LittleEndian, 20, 40, 19, 0},
+		{8, binary.BigEndian, 20, 40, 0, 39},
+	}
+	for _, tt := range tests {
+		data := withExif(base, tt.order, tt.orientation)
+		if got := Orientation(data); got != int(tt.orientation) {
+			t.Errorf("Orientation = %d, want %d", got, tt.orientation)
+		}
+
+		img, err := Decode(data, 1_000_000)
+		if err != nil {
+			t.Fatalf("Decode(orientation %d): %v", tt.orientation, err)
+		}
+		if b := img.Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+			t.Errorf("orientation %d: size = %dx%d, want %dx%d", tt.orientation, b.Dx(), b.Dy(), tt.width, tt.height)
+		}
+		// 元の左上の赤い部分が回転後の位置にある（JPEG の誤差を許す）
+		if r, _, b, _ := img.At(tt.redX, tt.redY).RGBA(); r>>8 < 128 || b>>8 > 128 {
+			t.Errorf("orientation %d: pixel (%d,%d) is not red", tt.orientation, tt.redX, tt.redY)
+		}
+	}
+}
+
+func TestOrientationIgnoresBrokenExif(t *testing.T) {
+	base := encodeJPEG(t, testImage(4, 4))
+	tests := map[string][]byte{
+		"no exif":      base,
+		"png":          encodePNG(t, testImage(4, 4)),
+		"out of range": withExif(base, binary.BigEndian, 9),
+		"truncated":    withExif(base, binary.BigEndian, 6)[:12],
+		"empty":        nil,
+	}
+	for name, data := range tests {
+		if got := Orientation(data); got != 1 {
+			t.Errorf("%s: Orientation = %d, want 1", name, got)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	src := testImage(3, 2)
+	for orientation := 1; orientation <= 8; orientation++ {
+		dst := Orient(src, orientation)
+		w, h := 3, 2
+		if orientation >= 5 {
+			w, h = 2, 3
+		}
+		if b := dst.Bounds(); b.Dx() != w || b.Dy() != h {
+			t.Errorf("orientation %d: size = %dx%d, want %dx%d", orientation, b.Dx(), b.Dy(), w, h)
+		}
+	}
+	// 向きを変えない場合は同じ画像を返す
+	if Orient(src, 1) != src || Orient(src, 0) != src {
+		t.Error("Orient with orientation 1 copied the image")
+	}
+}
+
+func TestCropSquare(t *testing.T) {
+	tests := []struct {
+		width, height int
+		want          image.Rectangle
+	}{
+		{300, 200, image.Rect(50, 0, 250, 200)},
+		{200, 301, image.Rect(0, 50, 200, 250)},
+		{64, 64, image.Rect(0, 0, 64, 64)},
+		{1, 500, image.Rect(0, 249, 1, 250)},
+	}
+	for _, tt := range tests {
+		got := CropSquare(image.NewRGBA(image.Rect(0, 0, tt.width, tt.height))).Bounds()
+		if got != tt.want {
+			t.Errorf("CropSquare(%dx%d) = %v, want %v", tt.width, tt.height, got, tt.want)
+		}
+	}
+}
+
+func TestResize(t *testing.T) {
+	tests := []struct {
+		name          string
+		src           *image.RGBA
+		width, height int
+	}{
+		{"shrink", testImage(1000, 1000), 64, 64},
+		{"enlarge", testImage(10, 10), 256, 256},
+		{"non square", testImage(300, 100), 30, 10},
+		// 切り抜いた画像（原点が(0,0)でない）
+		{"sub image", CropSquare(testImage(500, 300)), 128, 128},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := Resize(tt.src, tt.width, tt.height)
+			if b := dst.Bounds(); b != image.Rect(0, 0, tt.width, tt.height) {
+				t.Fatalf("bounds = %v, want %dx%d", b, tt.width, tt.height)
+			}
+			// 単色の部分は色を保つ
+			if r, g, b, a := dst.At(tt.width-1, tt.height-1).RGBA(); r>>8 != 0 || g>>8 != 0 || b>>8 != 255 || a>>8 != 255 {
+				t.Errorf("bottom right = (%d,%d,%d,%d), want opaque blue", r>>8, g>>8, b>>8, a>>8)
+			}
+		})
+	}
+
+	if b := Resize(testImage(10, 10), 0, 10).Bounds(); !b.Empty() {
+		t.Errorf("Resize to zero width = %v", b)
+	}
+}
+
+func TestResizeKeepsTransparency(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	for y := 0; y < 100; y++ {
+		for x := 50; x < 100; x++ {
+			src.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	dst := Resize(src, 10, 10)
+	if _, _, _, a := dst.At(0, 5).RGBA(); a != 0 {
+		t.Errorf("transparent side alpha = %d, want 0", a>>8)
+	}
+	if r, _, _, a := dst.At(9, 5).RGBA(); r>>8 != 255 || a>>8 != 255 {
+		t.Errorf("opaque side = (%d, alpha %d), want opaque red", r>>8, a>>8)
+	}
+	// アルファ乗算済みの値がアルファを超えない
+	for i := 0; i < len(dst.Pix); i += 4 {
+		if p := dst.Pix[i : i+4]; p[0] > p[3] || p[1] > p[3] || p[2] > p[3] {
+			t.Fatalf("pixel %d = %v exceeds its alpha", i/4, p)
+		}
+	}
+}
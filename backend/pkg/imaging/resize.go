@@ -0,0 +1,107 @@
+package imaging
+
+import (
+	"image"
+	"math"
+)
+
+// weight 出力の1画素に寄与する入力の画素と重み
+type weight struct {
+	index  int
+	weight float64
+}
+
+// Resize 指定した大きさに拡大・縮小する
+// 縮小率に合わせて幅を広げた三角フィルター（双線形）で縦横を別々に補間するため、大きく縮小してもエイリアスが出にくい
+func Resize(img *image.RGBA, width, height int) *image.RGBA {
+	b := img.Bounds()
+	srcW, srcH := b.Dx(), b.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	if srcW == 0 || srcH == 0 || width <= 0 || height <= 0 {
+		return dst
+	}
+
+	// 横方向: srcH 行 × width 列（RGBAはアルファ乗算済みのため、そのまま平均してよい）
+	xWeights := weights(srcW, width)
+	tmp := make([]float64, srcH*width*4)
+	for y := 0; y < srcH; y++ {
+		row := img.Pix[img.PixOffset(b.Min.X, b.Min.Y+y):]
+		for x, ws := range xWeights {
+			var r, g, bl, a float64
+			for _, w := range ws {
+				p := row[w.index*4 : w.index*4+4]
+				r += float64(p[0]) * w.weight
+				g += float64(p[1]) * w.weight
+				bl += float64(p[2]) * w.weight
+				a += float64(p[3]) * w.weight
+			}
+			t := tmp[(y*width+x)*4:]
+			t[0], t[1], t[2], t[3] = r, g, bl, a
+		}
+	}
+
+	// 縦方向: height 行 × width 列
+	yWeights := weights(srcH, height)
+	for y, ws := range yWeights {
+		for x := 0; x < width; x++ {
+			var r, g, bl, a float64
+			for _, w := range ws {
+				t := tmp[(w.index*width+x)*4:]
+				r += t[0] * w.weight
+				g += t[1] * w.weight
+				bl += t[2] * w.weight
+				a += t[3] * w.weight
+			}
+			d := dst.Pix[dst.PixOffset(x, y):]
+			d[3] = clamp(a)
+			// アルファ乗算済みの値がアルファを超えないようにする
+			d[0], d[1], d[2] = min(clamp(r), d[3]), min(clamp(g), d[3]), min(clamp(bl), d[3])
+		}
+	}
+
+	return dst
+}
+
+// weights 入力 srcSize 画素を出力 dstSize 画素にするときの、出力の各画素の重み
+func weights(srcSize, dstSize int) [][]weight {
+	scale := float64(srcSize) / float64(dstSize)
+	// 縮小時はフィルターの幅を縮小率に合わせて広げる
+	support := math.Max(scale, 1)
+
+	result := make([][]weight, dstSize)
+	for i := range result {
+		center := (float64(i)+0.5)*scale - 0.5
+		lo := int(math.Floor(center - support))
+		hi := int(math.Ceil(center + support))
+
+		var ws []weight
+		var total float64
+		for j := lo; j <= hi; j++ {
+			w := 1 - math.Abs(float64(j)-center)/support
+			if w <= 0 {
+				continue
+			}
+			// 端の外側は端の画素で代用する
+			index := min(max(j, 0), srcSize-1)
+			ws = append(ws, weight{index: index, weight: w})
+			total += w
+		}
+		for k := range ws {
+			ws[k].weight /= total
+		}
+		result[i] = ws
+	}
+	return result
+}
+
+// clamp 0〜255 に丸める
+func clamp(v float64) uint8 {
+	v = math.Round(v)
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
@@ -0,0 +1,86 @@
+// Package jobqueue リクエスト処理から切り離して非同期に実行するジョブキュー
+// ジョブは種類ごとに登録したハンドラーがプロセス内のワーカーで実行する。
+// 失敗したジョブは指数バックオフで再試行し、上限回数に達したものはデッドレターとして残す。
+// 保存先（バックエンド）はMySQL・Redis・メモリから選べる
+package jobqueue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"app-template/pkg/kvstore"
+)
+
+var (
+	// ErrNoJob 実行できるジョブがない
+	ErrNoJob = errors.New("jobqueue: no job available")
+	// ErrJobNotFound 指定したジョブがデッドレターにない
+	ErrJobNotFound = errors.New("jobqueue: job not found")
+)
+
+// Job キューに積まれたジョブ
+type Job struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+	// Attempts 実行を開始した回数（取り出すたびに増える）
+	Attempts    int `json:"attempts"`
+	MaxAttempts int `json:"max_attempts"`
+	// RunAt この日時以降に実行する
+	RunAt time.Time `json:"run_at"`
+	// LastError 直近の失敗の理由
+	LastError string    `json:"last_error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Decode ペイロードを値に読み込む
+func (j *Job) Decode(v interface{}) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// Backend ジョブの保存先
+// 取り出したジョブはリース期間中は他のワーカーに渡さず、期間内に完了・再試行・デッドレターのいずれにもならなければ
+// （プロセスの異常終了など）再び取り出せるようになる
+type Backend interface {
+	// Enqueue ジョブを追加
+	Enqueue(ctx context.Context, job *Job) error
+	// Reserve 実行時刻を過ぎたジョブを1件取り出し、Attemptsを増やす（なければErrNoJob）
+	Reserve(ctx context.Context, lease time.Duration) (*Job, error)
+	// Complete 成功したジョブを削除
+	Complete(ctx context.Context, job *Job) error
+	// Retry 失敗したジョブをdelay後に再実行する
+	Retry(ctx context.Context, job *Job, delay time.Duration) error
+	// Bury 失敗したジョブをデッドレターに移す
+	Bury(ctx context.Context, job *Job) error
+	// Dead デッドレターのジョブを新しい順に取得
+	Dead(ctx context.Context, limit int) ([]*Job, error)
+	// Requeue デッドレターのジョブを試行回数を戻して再実行する（なければErrJobNotFound）
+	Requeue(ctx context.Context, id string) error
+}
+
+// Connect 環境変数 JOB_QUEUE_BACKEND（mysql / redis / memory、既定は mysql）でバックエンドを選ぶ
+// redis は kvstore と同じ REDIS_HOST などの設定で接続する
+func Connect(ctx context.Context, db *sql.DB) (Backend, error) {
+	switch backend := os.Getenv("JOB_QUEUE_BACKEND"); backend {
+	case "", "mysql":
+		return NewMySQL(db), nil
+	case "redis":
+		client, err := kvstore.ConnectRedis(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if client == nil {
+			return nil, errors.New("jobqueue: REDIS_HOST is required for the redis backend")
+		}
+		return NewRedis(client, "jobs"), nil
+	case "memory":
+		return NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("jobqueue: unknown backend %q", backend)
+	}
+}
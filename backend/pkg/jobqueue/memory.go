@@ -0,0 +1,135 @@
+package jobqueue
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// memoryBackend プロセス内メモリを使ったBackendの実装（開発・テスト用、再起動でジョブは失われる）
+type memoryBackend struct {
+	mu   sync.Mutex
+	jobs map[string]*memoryJob
+	// dead デッドレター（古い順）
+	dead []*Job
+	now  func() time.Time
+}
+
+// memoryJob 待機中・実行中のジョブ
+type memoryJob struct {
+	job *Job
+	// lockedUntil 実行中のリース期限（ゼロ値なら待機中）
+	lockedUntil time.Time
+}
+
+// NewMemory メモリ上のBackendを作成
+func NewMemory() Backend {
+	return &memoryBackend{
+		jobs: make(map[string]*memoryJob),
+		now:  time.Now,
+	}
+}
+
+// Enqueue ジョブを追加
+func (b *memoryBackend) Enqueue(ctx context.Context, job *Job) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.jobs[job.ID] = &memoryJob{job: copyJob(job)}
+	return nil
+}
+
+// Reserve 実行時刻が最も早いジョブを取り出す（リース切れの実行中ジョブも対象）
+func (b *memoryBackend) Reserve(ctx context.Context, lease time.Duration) (*Job, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	var next *memoryJob
+	for _, entry := range b.jobs {
+		if !entry.lockedUntil.IsZero() && now.Before(entry.lockedUntil) {
+			continue
+		}
+		if entry.job.RunAt.After(now) {
+			continue
+		}
+		if next == nil || entry.job.RunAt.Before(next.job.RunAt) {
+			next = entry
+		}
+	}
+	if next == nil {
+		return nil, ErrNoJob
+	}
+
+	next.job.Attempts++
+	next.lockedUntil = now.Add(lease)
+	return copyJob(next.job), nil
+}
+
+// Complete ジョブを削除
+func (b *memoryBackend) Complete(ctx context.Context, job *Job) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.jobs, job.ID)
+	return nil
+}
+
+// Retry delay後に再実行する
+func (b *memoryBackend) Retry(ctx context.Context, job *Job, delay time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	retry := copyJob(job)
+	retry.RunAt = b.now().Add(delay)
+	b.jobs[job.ID] = &memoryJob{job: retry}
+	return nil
+}
+
+// Bury デッドレターに移す
+func (b *memoryBackend) Bury(ctx context.Context, job *Job) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.jobs, job.ID)
+	b.dead = append(b.dead, copyJob(job))
+	return nil
+}
+
+// Dead デッドレターを新しい順に取得
+func (b *memoryBackend) Dead(ctx context.Context, limit int) ([]*Job, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	jobs := make([]*Job, 0, min(limit, len(b.dead)))
+	for i := len(b.dead) - 1; i >= 0 && len(jobs) < limit; i-- {
+		jobs = append(jobs, copyJob(b.dead[i]))
+	}
+	return jobs, nil
+}
+
+// Requeue デッドレターのジョブを再実行する
+func (b *memoryBackend) Requeue(ctx context.Context, id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	i := slices.IndexFunc(b.dead, func(job *Job) bool { return job.ID == id })
+	if i < 0 {
+		return ErrJobNotFound
+	}
+
+	job := b.dead[i]
+	b.dead = append(b.dead[:i], b.dead[i+1:]...)
+	job.Attempts = 0
+	job.RunAt = b.now()
+	b.jobs[job.ID] = &memoryJob{job: job}
+	return nil
+}
+
+// copyJob 呼び出し側との共有を避けるためジョブを複製
+func copyJob(job *Job) *Job {
+	c := *job
+	c.Payload = append([]byte(nil), job.Payload...)
+	return &c
+}
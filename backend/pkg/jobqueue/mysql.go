@@ -0,0 +1,204 @@
+package jobqueue
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ジョブの状態（jobs.status）
+const (
+	statusPending = "pending"
+	statusRunning = "running"
+	statusDead    = "dead"
+)
+
+// mysqlBackend MySQLのjobsテーブルを使ったBackendの実装
+// 時刻の比較は複数のサーバー間で揃うようデータベースの時刻で行う
+type mysqlBackend struct {
+	db *sql.DB
+}
+
+// NewMySQL データベース接続を使ったBackendを作成
+func NewMySQL(db *sql.DB) Backend {
+	return &mysqlBackend{
+		db: db,
+	}
+}
+
+// jobColumns 取得する列
+const jobColumns = "id, type, payload, attempts, max_attempts, run_at, last_error, created_at"
+
+// Enqueue ジョブを追加
+func (b *mysqlBackend) Enqueue(ctx context.Context, job *Job) error {
+	query := `
+		INSERT INTO jobs (id, type, payload, status, attempts, max_attempts, run_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, DATE_ADD(NOW(3), INTERVAL ? MICROSECOND), NOW(3), NOW(3))
+	`
+
+	_, err := b.db.ExecContext(ctx, query,
+		job.ID,
+		job.Type,
+		string(job.Payload),
+		statusPending,
+		job.Attempts,
+		job.MaxAttempts,
+		untilMicroseconds(job.RunAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert job: %w", err)
+	}
+	return nil
+}
+
+// Reserve 実行時刻が最も早いジョブを1件取り出す（他のワーカーがロック中の行は飛ばす）
+func (b *mysqlBackend) Reserve(ctx context.Context, lease time.Duration) (*Job, error) {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT ` + jobColumns + `
+		FROM jobs
+		WHERE (status = ? AND run_at <= NOW(3))
+			OR (status = ? AND locked_until <= NOW(3))
+		ORDER BY run_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`
+	job, err := scanJob(tx.QueryRowContext(ctx, query, statusPending, statusRunning))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoJob
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to select job: %w", err)
+	}
+
+	update := `
+		UPDATE jobs
+		SET status = ?, attempts = attempts + 1, locked_until = DATE_ADD(NOW(3), INTERVAL ? MICROSECOND), updated_at = NOW(3)
+		WHERE id = ?
+	`
+	if _, err := tx.ExecContext(ctx, update, statusRunning, lease.Microseconds(), job.ID); err != nil {
+		return nil, fmt.Errorf("failed to lock job: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	job.Attempts++
+	return job, nil
+}
+
+// Complete ジョブを削除
+func (b *mysqlBackend) Complete(ctx context.Context, job *Job) error {
+	if _, err := b.db.ExecContext(ctx, "DELETE FROM jobs WHERE id = ?", job.ID); err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+	return nil
+}
+
+// Retry delay後に再実行する
+func (b *mysqlBackend) Retry(ctx context.Context, job *Job, delay time.Duration) error {
+	query := `
+		UPDATE jobs
+		SET status = ?, run_at = DATE_ADD(NOW(3), INTERVAL ? MICROSECOND), locked_until = NULL, last_error = ?, updated_at = NOW(3)
+		WHERE id = ?
+	`
+	if _, err := b.db.ExecContext(ctx, query, statusPending, delay.Microseconds(), job.LastError, job.ID); err != nil {
+		return fmt.Errorf("failed to reschedule job: %w", err)
+	}
+	return nil
+}
+
+// Bury デッドレターに移す
+func (b *mysqlBackend) Bury(ctx context.Context, job *Job) error {
+	query := `
+		UPDATE jobs
+		SET status = ?, locked_until = NULL, last_error = ?, updated_at = NOW(3)
+		WHERE id = ?
+	`
+	if _, err := b.db.ExecContext(ctx, query, statusDead, job.LastError, job.ID); err != nil {
+		return fmt.Errorf("failed to bury job: %w", err)
+	}
+	return nil
+}
+
+// Dead デッドレターを新しい順に取得
+func (b *mysqlBackend) Dead(ctx context.Context, limit int) ([]*Job, error) {
+	query := `
+		SELECT ` + jobColumns + `
+		FROM jobs
+		WHERE status = ?
+		ORDER BY updated_at DESC
+		LIMIT ?
+	`
+	rows, err := b.db.QueryContext(ctx, query, statusDead, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []*Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// Requeue デッドレターのジョブを再実行する
+func (b *mysqlBackend) Requeue(ctx context.Context, id string) error {
+	query := `
+		UPDATE jobs
+		SET status = ?, attempts = 0, run_at = NOW(3), updated_at = NOW(3)
+		WHERE id = ? AND status = ?
+	`
+	result, err := b.db.ExecContext(ctx, query, statusPending, id, statusDead)
+	if err != nil {
+		return fmt.Errorf("failed to requeue job: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
+// scanJob 1行をジョブに読み込む
+func scanJob(row interface{ Scan(dest ...any) error }) (*Job, error) {
+	var job Job
+	var payload []byte
+	var lastError sql.NullString
+	err := row.Scan(
+		&job.ID,
+		&job.Type,
+		&payload,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.RunAt,
+		&lastError,
+		&job.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	job.Payload = payload
+	job.LastError = lastError.String
+	return &job, nil
+}
+
+// untilMicroseconds 指定日時までのマイクロ秒（過ぎていれば0）
+func untilMicroseconds(t time.Time) int64 {
+	return max(time.Until(t), 0).Microseconds()
+}
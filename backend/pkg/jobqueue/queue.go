@@ -0,0 +1,341 @@
+package jobqueue
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Handler ジョブを実行する関数（エラーを返すと再試行、Permanentで包むと再試行しない）
+type Handler func(ctx context.Context, job *Job) error
+
+// Options ワーカーの設定
+type Options struct {
+	// Workers 同時に実行するジョブの数
+	Workers int
+	// PollInterval 実行できるジョブがないときに次を確認するまでの間隔
+	PollInterval time.Duration
+	// Lease 1回の実行の制限時間（超えるとハンドラーのコンテキストを取り消す）
+	Lease time.Duration
+	// MaxAttempts 既定の最大試行回数（Enqueue時に指定がない場合）
+	MaxAttempts int
+	// BaseBackoff 1回目の再試行までの待ち時間（以降は2倍ずつ増える）
+	BaseBackoff time.Duration
+	// MaxBackoff 再試行までの待ち時間の上限
+	MaxBackoff time.Duration
+}
+
+// DefaultOptions 既定の設定
+var DefaultOptions = Options{
+	Workers:      4,
+	PollInterval: time.Second,
+	Lease:        5 * time.Minute,
+	MaxAttempts:  5,
+	BaseBackoff:  10 * time.Second,
+	MaxBackoff:   time.Hour,
+}
+
+// Queue ジョブの追加とワーカーによる実行
+type Queue struct {
+	backend Backend
+	opts    Options
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+
+	// wake 即時実行のジョブが追加されたことを待機中のワーカーに知らせる
+	wake chan struct{}
+	stop chan struct{}
+	// ctx 強制終了時に実行中のハンドラーを取り消すためのコンテキスト
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// New キューを作成（0の設定項目は既定値を使う）
+func New(backend Backend, opts Options) *Queue {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultOptions.Workers
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultOptions.PollInterval
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = DefaultOptions.Lease
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultOptions.MaxAttempts
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = DefaultOptions.BaseBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultOptions.MaxBackoff
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Queue{
+		backend:  backend,
+		opts:     opts,
+		handlers: make(map[string]Handler),
+		wake:     make(chan struct{}, opts.Workers),
+		stop:     make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Register ジョブの種類にハンドラーを登録
+func (q *Queue) Register(jobType string, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = handler
+}
+
+// EnqueueOption ジョブ追加時の指定
+type EnqueueOption func(*Job)
+
+// Delay 指定した時間の後に実行する
+func Delay(d time.Duration) EnqueueOption {
+	return func(job *Job) {
+		job.RunAt = job.RunAt.Add(d)
+	}
+}
+
+// At 指定した日時以降に実行する
+func At(t time.Time) EnqueueOption {
+	return func(job *Job) {
+		job.RunAt = t
+	}
+}
+
+// MaxAttempts 最大試行回数を指定する
+func MaxAttempts(n int) EnqueueOption {
+	return func(job *Job) {
+		job.MaxAttempts = n
+	}
+}
+
+// Enqueue ジョブを追加（ペイロードはJSONで保存する）
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...EnqueueOption) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	now := time.Now()
+	job := &Job{
+		ID:          newJobID(),
+		Type:        jobType,
+		Payload:     data,
+		MaxAttempts: q.opts.MaxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = 1
+	}
+
+	if err := q.backend.Enqueue(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	if !job.RunAt.After(now) {
+		select {
+		case q.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	return job, nil
+}
+
+// Dead デッドレターのジョブを新しい順に取得
+func (q *Queue) Dead(ctx context.Context, limit int) ([]*Job, error) {
+	return q.backend.Dead(ctx, limit)
+}
+
+// Requeue デッドレターのジョブを再実行する
+func (q *Queue) Requeue(ctx context.Context, id string) error {
+	if err := q.backend.Requeue(ctx, id); err != nil {
+		return err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start ワーカーを起動
+func (q *Queue) Start() {
+	q.startOnce.Do(func() {
+		for i := 0; i < q.opts.Workers; i++ {
+			q.wg.Add(1)
+			go q.work()
+		}
+	})
+}
+
+// Shutdown 新しいジョブの取り出しを止め、実行中のジョブの完了を待つ
+// ctxの期限までに終わらなければ実行中のハンドラーを取り消し（ジョブは再試行される）、終了を待ってctxのエラーを返す
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.stopOnce.Do(func() {
+		close(q.stop)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// work ジョブを取り出して実行し続ける
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	for {
+		select {
+		case <-q.stop:
+			return
+		default:
+		}
+
+		job, err := q.backend.Reserve(q.ctx, q.opts.Lease)
+		if err == nil {
+			q.process(job)
+			continue
+		}
+		if !errors.Is(err, ErrNoJob) && q.ctx.Err() == nil {
+			log.Printf("jobqueue: failed to reserve job: %v", err)
+		}
+
+		select {
+		case <-q.stop:
+			return
+		case <-q.wake:
+		case <-time.After(q.opts.PollInterval):
+		}
+	}
+}
+
+// process ジョブを実行し、結果に応じて完了・再試行・デッドレターにする
+func (q *Queue) process(job *Job) {
+	q.mu.RLock()
+	handler, ok := q.handlers[job.Type]
+	q.mu.RUnlock()
+
+	var err error
+	if ok {
+		ctx, cancel := context.WithTimeout(q.ctx, q.opts.Lease)
+		err = runHandler(ctx, handler, job)
+		cancel()
+	} else {
+		err = Permanent(fmt.Errorf("no handler registered for job type %q", job.Type))
+	}
+
+	// 強制終了でハンドラーを取り消した後も結果は記録する
+	ctx := context.WithoutCancel(q.ctx)
+	if err == nil {
+		if err := q.backend.Complete(ctx, job); err != nil {
+			log.Printf("jobqueue: failed to complete job %s: %v", job.ID, err)
+		}
+		return
+	}
+
+	job.LastError = err.Error()
+	if IsPermanent(err) || job.Attempts >= job.MaxAttempts {
+		log.Printf("jobqueue: job %s (%s) moved to dead letter after %d attempts: %v", job.ID, job.Type, job.Attempts, err)
+		if err := q.backend.Bury(ctx, job); err != nil {
+			log.Printf("jobqueue: failed to bury job %s: %v", job.ID, err)
+		}
+		return
+	}
+
+	delay := q.backoff(job.Attempts)
+	log.Printf("jobqueue: job %s (%s) failed (attempt %d/%d), retrying in %s: %v", job.ID, job.Type, job.Attempts, job.MaxAttempts, delay, err)
+	if err := q.backend.Retry(ctx, job, delay); err != nil {
+		log.Printf("jobqueue: failed to retry job %s: %v", job.ID, err)
+	}
+}
+
+// runHandler ハンドラーを実行（panicはエラーとして扱う）
+func runHandler(ctx context.Context, handler Handler, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// backoff attempt回目の失敗後の待ち時間（指数バックオフ、同時に失敗したジョブが集中しないよう半分までの揺らぎを入れる）
+func (q *Queue) backoff(attempt int) time.Duration {
+	delay := q.opts.MaxBackoff
+	if shift := attempt - 1; shift < 32 {
+		if d := q.opts.BaseBackoff << shift; d > 0 && d < delay {
+			delay = d
+		}
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// permanentError 再試行しないエラー
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent 再試行せずにデッドレターにするエラー（入力が不正なジョブなど）
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent 再試行しないエラーか判定
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// newJobID ジョブIDを生成（16バイトの乱数の16進数表記）
+func newJobID() string {
+	b := make([]byte, 16)
+	if _, err := cryptorand.Read(b); err != nil {
+		panic(fmt.Sprintf("jobqueue: failed to generate job id: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,150 @@
+package jobqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	q := New(NewMemory(), Options{BaseBackoff: time.Second, MaxBackoff: time.Minute})
+
+	tests := []struct {
+		attempt int
+		full    time.Duration
+	}{
+		{attempt: 1, full: time.Second},
+		{attempt: 2, full: 2 * time.Second},
+		{attempt: 3, full: 4 * time.Second},
+		{attempt: 6, full: 32 * time.Second},
+		{attempt: 7, full: time.Minute},
+		{attempt: 100, full: time.Minute},
+	}
+	for _, tt := range tests {
+		for range 20 {
+			// 揺らぎは待ち時間の半分まで
+			if d := q.backoff(tt.attempt); d < tt.full/2 || d > tt.full {
+				t.Errorf("backoff(%d) = %s, want between %s and %s", tt.attempt, d, tt.full/2, tt.full)
+			}
+		}
+	}
+}
+
+// startQueue 短い間隔で再試行するキューを起動
+func startQueue(t *testing.T, backend Backend) *Queue {
+	t.Helper()
+	q := New(backend, Options{
+		Workers:      1,
+		PollInterval: 5 * time.Millisecond,
+		BaseBackoff:  20 * time.Millisecond,
+		MaxBackoff:   80 * time.Millisecond,
+	})
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		q.Shutdown(ctx)
+	})
+	return q
+}
+
+func TestQueueRetriesWithBackoff(t *testing.T) {
+	q := startQueue(t, NewMemory())
+
+	var mu sync.Mutex
+	var attempts []time.Time
+	done := make(chan struct{})
+	q.Register("flaky", func(ctx context.Context, job *Job) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts = append(attempts, time.Now())
+		if len(attempts) < 3 {
+			return errors.New("temporary failure")
+		}
+		close(done)
+		return nil
+	})
+	q.Start()
+
+	if _, err := q.Enqueue(context.Background(), "flaky", nil); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not succeed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// 1回目の失敗後は BaseBackoff/2 以上、2回目の失敗後は BaseBackoff 以上待つ
+	if d := attempts[1].Sub(attempts[0]); d < 10*time.Millisecond {
+		t.Errorf("first retry after %s, want at least 10ms", d)
+	}
+	if d := attempts[2].Sub(attempts[1]); d < 20*time.Millisecond {
+		t.Errorf("second retry after %s, want at least 20ms", d)
+	}
+}
+
+func TestQueueBuriesAfterMaxAttempts(t *testing.T) {
+	q := startQueue(t, NewMemory())
+
+	var mu sync.Mutex
+	calls := 0
+	q.Register("broken", func(ctx context.Context, job *Job) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return errors.New("always fails")
+	})
+	q.Start()
+
+	job, err := q.Enqueue(context.Background(), "broken", nil, MaxAttempts(3))
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	dead := waitDead(t, q)
+	if dead.ID != job.ID || dead.Attempts != 3 || dead.LastError != "always fails" {
+		t.Errorf("dead job = %+v", dead)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 3 {
+		t.Errorf("handler called %d times, want 3", calls)
+	}
+}
+
+func TestQueuePermanentErrorIsNotRetried(t *testing.T) {
+	q := startQueue(t, NewMemory())
+	q.Register("invalid", func(ctx context.Context, job *Job) error {
+		return Permanent(errors.New("bad payload"))
+	})
+	q.Start()
+
+	if _, err := q.Enqueue(context.Background(), "invalid", nil); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if dead := waitDead(t, q); dead.Attempts != 1 {
+		t.Errorf("attempts = %d, want 1", dead.Attempts)
+	}
+}
+
+// waitDead デッドレターにジョブが入るまで待つ
+func waitDead(t *testing.T, q *Queue) *Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		jobs, err := q.Dead(context.Background(), 10)
+		if err != nil {
+			t.Fatalf("Dead: %v", err)
+		}
+		if len(jobs) > 0 {
+			return jobs[0]
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("job was not moved to dead letter")
+	return nil
+}
@@ -0,0 +1,224 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisBackend Redisを使ったBackendの実装
+// ジョブ本体は prefix:job:<id> にJSONで保存し、状態ごとにIDを次のキーで管理する
+//   - prefix:ready      すぐに実行できるジョブ（リスト、左から追加して右から取り出す）
+//   - prefix:delayed    実行時刻待ちのジョブ（実行時刻をスコアとするソート済みセット）
+//   - prefix:processing 実行中のジョブ（リース期限をスコアとするソート済みセット）
+//   - prefix:dead       デッドレター（リスト、新しいものが左）
+type redisBackend struct {
+	client *redis.Client
+	prefix string
+}
+
+// reserveScript 実行時刻を過ぎたジョブとリース切れのジョブを実行待ちに戻してから1件取り出す
+// 時刻はサーバー間の時計のずれを避けるためRedisの時刻を使う
+var reserveScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local due = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', now, 'LIMIT', 0, 100)
+for _, id in ipairs(due) do
+	redis.call('ZREM', KEYS[2], id)
+	redis.call('LPUSH', KEYS[1], id)
+end
+
+local expired = redis.call('ZRANGEBYSCORE', KEYS[3], '-inf', now, 'LIMIT', 0, 100)
+for _, id in ipairs(expired) do
+	redis.call('ZREM', KEYS[3], id)
+	redis.call('RPUSH', KEYS[1], id)
+end
+
+local id = redis.call('RPOP', KEYS[1])
+if not id then
+	return false
+end
+redis.call('ZADD', KEYS[3], now + tonumber(ARGV[1]), id)
+return id
+`)
+
+// NewRedis Redisクライアントを使ったBackendを作成（キーはprefixで始まる）
+func NewRedis(client *redis.Client, prefix string) Backend {
+	return &redisBackend{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (b *redisBackend) key(name string) string {
+	return b.prefix + ":" + name
+}
+
+func (b *redisBackend) jobKey(id string) string {
+	return b.prefix + ":job:" + id
+}
+
+// Enqueue ジョブを保存し、実行時刻に応じて実行待ちか時刻待ちに入れる
+func (b *redisBackend) Enqueue(ctx context.Context, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, b.jobKey(job.ID), data, 0)
+		if job.RunAt.After(time.Now()) {
+			pipe.ZAdd(ctx, b.key("delayed"), redis.Z{Score: float64(job.RunAt.UnixMilli()), Member: job.ID})
+		} else {
+			pipe.LPush(ctx, b.key("ready"), job.ID)
+		}
+		return nil
+	})
+	return err
+}
+
+// Reserve ジョブを1件取り出す
+func (b *redisBackend) Reserve(ctx context.Context, lease time.Duration) (*Job, error) {
+	keys := []string{b.key("ready"), b.key("delayed"), b.key("processing")}
+	for {
+		id, err := reserveScript.Run(ctx, b.client, keys, lease.Milliseconds()).Text()
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrNoJob
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := b.client.Get(ctx, b.jobKey(id)).Bytes()
+		if errors.Is(err, redis.Nil) {
+			// 本体が失われたジョブは取り除いて次を探す
+			if err := b.client.ZRem(ctx, b.key("processing"), id).Err(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			return nil, err
+		}
+		job.Attempts++
+		if err := b.save(ctx, b.client, &job); err != nil {
+			return nil, err
+		}
+		return &job, nil
+	}
+}
+
+// Complete ジョブを削除
+func (b *redisBackend) Complete(ctx context.Context, job *Job) error {
+	_, err := b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, b.key("processing"), job.ID)
+		pipe.Del(ctx, b.jobKey(job.ID))
+		return nil
+	})
+	return err
+}
+
+// Retry delay後に再実行する
+func (b *redisBackend) Retry(ctx context.Context, job *Job, delay time.Duration) error {
+	retry := copyJob(job)
+	retry.RunAt = time.Now().Add(delay)
+
+	_, err := b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if err := b.save(ctx, pipe, retry); err != nil {
+			return err
+		}
+		pipe.ZRem(ctx, b.key("processing"), job.ID)
+		pipe.ZAdd(ctx, b.key("delayed"), redis.Z{Score: float64(retry.RunAt.UnixMilli()), Member: job.ID})
+		return nil
+	})
+	return err
+}
+
+// Bury デッドレターに移す
+func (b *redisBackend) Bury(ctx context.Context, job *Job) error {
+	_, err := b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if err := b.save(ctx, pipe, job); err != nil {
+			return err
+		}
+		pipe.ZRem(ctx, b.key("processing"), job.ID)
+		pipe.LPush(ctx, b.key("dead"), job.ID)
+		return nil
+	})
+	return err
+}
+
+// Dead デッドレターを新しい順に取得
+func (b *redisBackend) Dead(ctx context.Context, limit int) ([]*Job, error) {
+	ids, err := b.client.LRange(ctx, b.key("dead"), 0, int64(limit)-1).Result()
+	if err != nil || len(ids) == 0 {
+		return []*Job{}, err
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = b.jobKey(id)
+	}
+	values, err := b.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*Job, 0, len(values))
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var job Job
+		if err := json.Unmarshal([]byte(data), &job); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
+
+// Requeue デッドレターのジョブを再実行する
+func (b *redisBackend) Requeue(ctx context.Context, id string) error {
+	removed, err := b.client.LRem(ctx, b.key("dead"), 1, id).Result()
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return ErrJobNotFound
+	}
+
+	data, err := b.client.Get(ctx, b.jobKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return ErrJobNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return err
+	}
+	job.Attempts = 0
+	job.RunAt = time.Now()
+	return b.Enqueue(ctx, &job)
+}
+
+// save ジョブ本体を保存
+func (b *redisBackend) save(ctx context.Context, client redis.Cmdable, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return client.Set(ctx, b.jobKey(job.ID), data, 0).Err()
+}
@@ -0,0 +1,68 @@
+package kvstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNotFound キーが存在しない、または期限切れ
+var ErrNotFound = errors.New("kvstore: key not found")
+
+// Store 有効期限付きのキーバリューストア（セッション・チャレンジ・キャッシュ用）
+type Store interface {
+	// Set 値を保存（ttlが0以下なら期限なし）
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Get 値を取得（存在しなければErrNotFound）
+	Get(ctx context.Context, key string) ([]byte, error)
+	// GetDel 値を取得して削除（一度きりのチャレンジ消費に使用）
+	GetDel(ctx context.Context, key string) ([]byte, error)
+	// Delete 値を削除
+	Delete(ctx context.Context, key string) error
+	// Incr 整数の値を1増やして増やした後の値を返す（キーがなければ1で作成し、作成時のみttlを設定。試行回数の制限に使用）
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+}
+
+// Connect 環境変数の設定でRedisに接続する
+// REDIS_HOST が未設定の場合はプロセス内メモリのストアを返す
+func Connect(ctx context.Context) (Store, error) {
+	client, err := ConnectRedis(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return NewMemory(), nil
+	}
+
+	return NewRedis(client), nil
+}
+
+// ConnectRedis 環境変数（REDIS_HOST / REDIS_PORT / REDIS_PASSWORD）の設定でRedisに接続する
+// REDIS_HOST が未設定の場合はnilを返す
+func ConnectRedis(ctx context.Context) (*redis.Client, error) {
+	host := os.Getenv("REDIS_HOST")
+	if host == "" {
+		return nil, nil
+	}
+
+	port := os.Getenv("REDIS_PORT")
+	if port == "" {
+		port = "6379"
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     host + ":" + port,
+		Password: os.Getenv("REDIS_PASSWORD"),
+	})
+
+	// 接続テスト
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to ping redis: %w", err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,66 @@
+package kvstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// incrScript 値を増やし、作成した場合のみ有効期限を設定する（INCRとPEXPIREを不可分に実行）
+var incrScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 and tonumber(ARGV[1]) > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n
+`)
+
+// redisStore Redisを使ったStoreの実装
+type redisStore struct {
+	client *redis.Client
+}
+
+// NewRedis Redisクライアントを使ったStoreを作成
+func NewRedis(client *redis.Client) Store {
+	return &redisStore{
+		client: client,
+	}
+}
+
+// Set 値を保存
+func (s *redisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Get 値を取得
+func (s *redisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+// GetDel 値を取得して削除
+func (s *redisStore) GetDel(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.client.GetDel(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+// Delete 値を削除
+func (s *redisStore) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, key).Err()
+}
+
+// Incr 整数の値を1増やす
+func (s *redisStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return incrScript.Run(ctx, s.client, []string{key}, ttl.Milliseconds()).Int64()
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/pkg/auth"
+	"app-template/pkg/problem"
+)
+
+// Policy ルートの認可ポリシー
+type Policy struct {
+	// Permissions 常に必要な権限
+	Permissions []auth.Permission `json:"permissions"`
+	// SelfParam 指定したパスパラメータが本人のIDでない場合はOthersも必要
+	SelfParam string            `json:"self_param,omitempty"`
+	Others    []auth.Permission `json:"others,omitempty"`
+}
+
+// Authenticated 認証だけを必要とし、権限を問わないポリシー（ログイン中ユーザー自身のリソースなど）
+func Authenticated() Policy {
+	return Policy{}
+}
+
+// Require 指定した権限をすべて必要とするポリシー
+func Require(permissions ...auth.Permission) Policy {
+	return Policy{Permissions: permissions}
+}
+
+// OthersRequire 本人以外のリソースを操作する場合に追加で必要な権限を指定
+func (p Policy) OthersRequire(param string, permissions ...auth.Permission) Policy {
+	p.SelfParam = param
+	p.Others = permissions
+	return p
+}
+
+// RequirePermission 指定した権限をすべて必要とする認可ミドルウェア
+func RequirePermission(permissions ...auth.Permission) gin.HandlerFunc {
+	return Authorize(Require(permissions...))
+}
+
+// Authorize ポリシーによる認可ミドルウェア（Authenticateの後に使う）
+func Authorize(policy Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			problem.Abort(c, http.StatusUnauthorized, "UNAUTHORIZED", "Authentication required")
+			return
+		}
+
+		required := policy.Permissions
+		if policy.SelfParam != "" && !isSelf(c, principal, policy.SelfParam) {
+			required = append(append([]auth.Permission{}, required...), policy.Others...)
+		}
+
+		for _, permission := range required {
+			if !principal.Has(permission) {
+				problem.Abort(c, http.StatusForbidden, "FORBIDDEN", "Missing permission: "+string(permission))
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// isSelf パスパラメータが認証済みユーザー本人のIDか判定
+func isSelf(c *gin.Context, principal *auth.Principal, param string) bool {
+	id, err := strconv.ParseInt(c.Param(param), 10, 64)
+	return err == nil && id == principal.UserID
+}
+
+// RoutePermission ルートと必要権限の対応
+type RoutePermission struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Authenticated ポリシーを記録したルート（認証が必要）か。権限が空でも認証不要のルートと区別する
+	Authenticated bool `json:"authenticated"`
+	Policy
+}
+
+// RouteMatrix ルートごとの認可ポリシーを記録する（監査用の一覧出力に使う）
+type RouteMatrix struct {
+	policies map[string]Policy
+}
+
+// NewRouteMatrix RouteMatrixの新しいインスタンスを作成
+func NewRouteMatrix() *RouteMatrix {
+	return &RouteMatrix{
+		policies: make(map[string]Policy),
+	}
+}
+
+// Handle ポリシーを記録し、認可ミドルウェア付きでルートを登録
+func (m *RouteMatrix) Handle(group *gin.RouterGroup, method, relativePath string, policy Policy, handlers ...gin.HandlerFunc) {
+	group.Handle(method, relativePath, append([]gin.HandlerFunc{Authorize(policy)}, handlers...)...)
+	m.policies[method+" "+path.Join(group.BasePath(), relativePath)] = policy
+}
+
+// Routes 登録済みの全ルートと必要権限の一覧（ポリシーのないルートは認証不要・権限なし）
+func (m *RouteMatrix) Routes(routes gin.RoutesInfo) []RoutePermission {
+	matrix := make([]RoutePermission, 0, len(routes))
+	for _, route := range routes {
+		policy, authenticated := m.policies[route.Method+" "+route.Path]
+		if policy.Permissions == nil {
+			policy.Permissions = []auth.Permission{}
+		}
+		matrix = append(matrix, RoutePermission{
+			Method:        route.Method,
+			Path:          route.Path,
+			Authenticated: authenticated,
+			Policy:        policy,
+		})
+	}
+
+	sort.Slice(matrix, func(i, j int) bool {
+		if matrix[i].Path != matrix[j].Path {
+			return matrix[i].Path < matrix[j].Path
+		}
+		return matrix[i].Method < matrix[j].Method
+	})
+
+	return matrix
+}
+
+// Handler ルートと必要権限の一覧を返すハンドラー
+func (m *RouteMatrix) Handler(engine *gin.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"routes": m.Routes(engine.Routes()),
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/pkg/auth"
+)
+
+func TestRouteMatrixRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	routes := NewRouteMatrix()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	r.POST("/api/v1/auth/login", ok)
+	me := r.Group("/api/v1/me")
+	routes.Handle(me, http.MethodGet, "/mfa", Authenticated(), ok)
+	users := r.Group("/api/v1/users")
+	routes.Handle(users, http.MethodPut, "/:id", Require(auth.PermUsersWrite).OthersRequire("id", auth.PermUsersAdmin), ok)
+
+	got := map[string]RoutePermission{}
+	for _, route := range routes.Routes(r.Routes()) {
+		got[route.Method+" "+route.Path] = route
+	}
+
+	// 認証不要のルートと、認証だけが必要なルートはどちらも権限が空だが区別できる
+	if login := got["POST /api/v1/auth/login"]; login.Authenticated || len(login.Permissions) != 0 {
+		t.Errorf("login = %+v, want a public route", login)
+	}
+	if mfa := got["GET /api/v1/me/mfa"]; !mfa.Authenticated || mfa.Permissions == nil || len(mfa.Permissions) != 0 {
+		t.Errorf("me/mfa = %+v, want authenticated without permissions", mfa)
+	}
+	update := got["PUT /api/v1/users/:id"]
+	if !update.Authenticated || len(update.Permissions) != 1 || update.Permissions[0] != auth.PermUsersWrite || update.SelfParam != "id" {
+		t.Errorf("users/:id = %+v", update)
+	}
+
+	// 認証だけが必要なルートも主体がなければ 401
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/me/mfa", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("me/mfa without a principal: status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
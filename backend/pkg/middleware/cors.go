@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/pkg/cors"
+)
+
+// CORS CORSミドルウェア（ルーティング前にプリフライトへ応答するため r.Use で登録する）
+// プリフライトは許可されていれば204、オリジン・メソッド・ヘッダーのいずれかが許可されていなければ403で応答する。
+// それ以外のリクエストは許可されたオリジンにのみCORSのヘッダーを付けて続行する
+func CORS(engine *cors.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if cors.IsPreflight(c.Request) {
+			if !engine.Preflight(c.Writer.Header(), c.Request) {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		engine.Apply(c.Writer.Header(), c.Request)
+		c.Next()
+	}
+}
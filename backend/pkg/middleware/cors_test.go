@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/pkg/cors"
+)
+
+func corsRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	engine, err := cors.New(cors.Config{Default: cors.Policy{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowCredentials: true,
+	}})
+	if err != nil {
+		t.Fatalf("cors.New: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CORS(engine))
+	r.PATCH("/api/v1/users/:id", func(c *gin.Context) { c.String(http.StatusOK, "patched") })
+	r.GET("/api/v1/users", func(c *gin.Context) { c.String(http.StatusOK, "users") })
+	return r
+}
+
+func TestCORS(t *testing.T) {
+	r := corsRouter(t)
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		headers    map[string]string
+		wantStatus int
+		wantOrigin string
+		wantBody   string
+	}{
+		{
+			name: "allowed preflight", method: http.MethodOptions, path: "/api/v1/users/1",
+			headers:    map[string]string{"Origin": "https://app.example.com", "Access-Control-Request-Method": "PATCH", "Access-Control-Request-Headers": "content-type"},
+			wantStatus: http.StatusNoContent, wantOrigin: "https://app.example.com",
+		},
+		{
+			name: "denied preflight", method: http.MethodOptions, path: "/api/v1/users/1",
+			headers:    map[string]string{"Origin": "https://evil.test", "Access-Control-Request-Method": "PATCH"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "denied preflight header", method: http.MethodOptions, path: "/api/v1/users/1",
+			headers:    map[string]string{"Origin": "https://app.example.com", "Access-Control-Request-Method": "PATCH", "Access-Control-Request-Headers": "x-evil"},
+			wantStatus: http.StatusForbidden,
+		},
+		// プリフライトでない OPTIONS はルーティングに任せる
+		{
+			name: "plain options", method: http.MethodOptions, path: "/api/v1/users/1",
+			headers:    map[string]string{"Origin": "https://app.example.com"},
+			wantStatus: http.StatusNotFound, wantOrigin: "https://app.example.com",
+		},
+		{
+			name: "allowed request", method: http.MethodPatch, path: "/api/v1/users/1",
+			headers:    map[string]string{"Origin": "https://app.example.com"},
+			wantStatus: http.StatusOK, wantOrigin: "https://app.example.com", wantBody: "patched",
+		},
+		// 許可しないオリジンのリクエストも処理は続け、CORS のヘッダーを付けない（ブラウザが読み取りを拒否する）
+		{
+			name: "disallowed request", method: http.MethodGet, path: "/api/v1/users",
+			headers:    map[string]string{"Origin": "https://evil.test"},
+			wantStatus: http.StatusOK, wantBody: "users",
+		},
+		{
+			name: "same origin request", method: http.MethodGet, path: "/api/v1/users",
+			wantStatus: http.StatusOK, wantBody: "users",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			if tt.wantStatus == http.StatusNoContent && w.Body.Len() != 0 {
+				t.Errorf("preflight body = %q", w.Body.String())
+			}
+			if w.Header().Get("Vary") == "" {
+				t.Error("Vary is missing")
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"app-template/pkg/problem"
+)
+
+// ErrorFormat エラーレスポンスの形式を設定するミドルウェア
+// 設定に関係なく、Accept: application/problem+json を送ったクライアントには problem+json で返す
+func ErrorFormat(opts problem.Options) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		problem.WithOptions(c, opts)
+		c.Next()
+	}
+}
@@ -2,114 +2,192 @@ package middleware
 
 import (
 	"net/http"
-	"os"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+
+	"app-template/pkg/auth"
+	"app-template/pkg/problem"
 )
 
-// CORS CORSミドルウェア
-func CORS() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		origin := c.Request.Header.Get("Origin")
-		allowedOrigins := strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",")
-		
-		// 許可されたオリジンかチェック
-		allowed := false
-		for _, allowedOrigin := range allowedOrigins {
-			if origin == strings.TrimSpace(allowedOrigin) {
-				allowed = true
-				break
-			}
-		}
+// RequestLogger リクエストログミドルウェア
+func RequestLogger() gin.HandlerFunc {
+	return gin.Logger()
+}
 
-		if allowed || len(allowedOrigins) == 0 {
-			c.Header("Access-Control-Allow-Origin", origin)
-		}
+// principalContextKey 認証済み主体を保持するコンテキストキー
+const principalContextKey = "auth_principal"
 
-		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+// JWTAuth JWT認証ミドルウェア
+// AuthorizationヘッダーのJWT、または sessions のセッションCookie（nilならヘッダーのみ）で認証する
+func JWTAuth(tokens *auth.JWTManager, accounts auth.AccountChecker, sessions auth.CookieAuthenticator) gin.HandlerFunc {
+	if sessions == nil {
+		return Authenticate(accounts, tokens)
+	}
+	return Authenticate(accounts, tokens, sessions)
+}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+// Authenticate 認証器チェーンによる認証ミドルウェア
+// Authorizationヘッダーの資格情報を各認証器に順に渡し、最初に扱えた認証器の結果を採用する
+// ヘッダーがなければ auth.CookieAuthenticator の認証器でCookieのセッションを検証する（変更系のリクエストはCSRFトークンも検証する）
+// 認証後はアカウントの現在の状態を確認し、停止中なら拒否、ロールは最新の値に置き換える
+func Authenticate(accounts auth.AccountChecker, authenticators ...auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasCredential(c, authenticators) {
+			problem.Abort(c, http.StatusUnauthorized, "MISSING_AUTH_HEADER", "Authorization header required")
 			return
 		}
 
+		if !authenticateRequest(c, accounts, authenticators) {
+			return
+		}
 		c.Next()
 	}
 }
 
-// RequestLogger リクエストログミドルウェア
-func RequestLogger() gin.HandlerFunc {
-	return gin.Logger()
-}
-
-// JWTAuth JWT認証ミドルウェア
-func JWTAuth() gin.HandlerFunc {
+// OptionalAuthenticate Authorizationヘッダー（またはセッションCookie）がある場合のみ認証するミドルウェア
+// 資格情報がなければ未認証のまま続行し、資格情報が不正な場合は Authenticate と同じく拒否する
+// （認可はハンドラー側で行う。GraphQLのように公開・要認証の操作が同じエンドポイントにある場合に使う）
+func OptionalAuthenticate(accounts auth.AccountChecker, authenticators ...auth.Authenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authorization header required",
-				"code":  "MISSING_AUTH_HEADER",
-			})
-			c.Abort()
+		if hasCredential(c, authenticators) && !authenticateRequest(c, accounts, authenticators) {
 			return
 		}
+		c.Next()
+	}
+}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Bearer token required",
-				"code":  "INVALID_AUTH_FORMAT",
-			})
-			c.Abort()
-			return
-		}
+// hasCredential Authorizationヘッダー、またはセッションCookieがあるか
+func hasCredential(c *gin.Context, authenticators []auth.Authenticator) bool {
+	if c.GetHeader("Authorization") != "" {
+		return true
+	}
+	_, credential := cookieCredential(c, authenticators)
+	return credential != ""
+}
 
-		jwtSecret := os.Getenv("JWT_SECRET")
-		if jwtSecret == "" {
-			jwtSecret = "default-secret-key"
+// authenticateRequest Authorizationヘッダー（なければセッションCookie）の資格情報を検証し、認証済み主体をコンテキストに設定
+// 失敗した場合はレスポンスを書いて中断し、falseを返す
+func authenticateRequest(c *gin.Context, accounts auth.AccountChecker, authenticators []auth.Authenticator) bool {
+	var principal *auth.Principal
+	var err error
+	if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+		credential := strings.TrimPrefix(authHeader, "Bearer ")
+		if credential == authHeader {
+			problem.Abort(c, http.StatusUnauthorized, "INVALID_AUTH_FORMAT", "Bearer token required")
+			return false
 		}
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
+		principal, err = authenticate(c, headerAuthenticators(authenticators), credential)
+	} else {
+		sessions, credential := cookieCredential(c, authenticators)
+		principal, err = sessions.Authenticate(c.Request.Context(), credential)
+		// Cookieはブラウザが自動で送るため、変更系のリクエストはCSRFトークンを確認する
+		if err == nil && !isSafeMethod(c.Request.Method) {
+			if err := sessions.VerifyCSRF(c.Request, principal); err != nil {
+				problem.Abort(c, http.StatusForbidden, auth.ErrorCode(err), err.Error())
+				return false
 			}
-			return []byte(jwtSecret), nil
-		})
-
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid token",
-				"code":  "INVALID_TOKEN",
-			})
-			c.Abort()
-			return
 		}
+	}
+	if err == nil {
+		err = checkAccount(c, accounts, principal)
+	}
+	if err != nil {
+		abortAuthError(c, err)
+		return false
+	}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			// トークンの有効期限をチェック
-			if exp, ok := claims["exp"].(float64); ok {
-				if time.Now().Unix() > int64(exp) {
-					c.JSON(http.StatusUnauthorized, gin.H{
-						"error": "Token expired",
-						"code":  "TOKEN_EXPIRED",
-					})
-					c.Abort()
-					return
-				}
-			}
+	// 認証済み主体をコンテキストに設定（ユースケース層からも参照できるようにする）
+	c.Set(principalContextKey, principal)
+	c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+	return true
+}
+
+// checkAccount アカウントの状態を確認して主体のロールを更新
+func checkAccount(c *gin.Context, accounts auth.AccountChecker, principal *auth.Principal) error {
+	state, err := accounts.CheckAccount(c.Request.Context(), principal.UserID)
+	if err != nil {
+		return err
+	}
+	principal.Role = state.Role
+	return nil
+}
+
+// abortAuthError 認証エラーをレスポンスに変換して中断
+func abortAuthError(c *gin.Context, err error) {
+	switch {
+	case auth.IsAccountBlocked(err):
+		problem.Write(c, http.StatusForbidden, auth.ErrorCode(err), err.Error())
+	case auth.IsTokenError(err):
+		problem.Write(c, http.StatusUnauthorized, auth.ErrorCode(err), err.Error())
+	default:
+		problem.Write(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to authenticate request")
+	}
+	c.Abort()
+}
+
+// headerAuthenticators Authorizationヘッダーの資格情報を扱う認証器（Cookieの認証器を除く）
+func headerAuthenticators(authenticators []auth.Authenticator) []auth.Authenticator {
+	result := make([]auth.Authenticator, 0, len(authenticators))
+	for _, authenticator := range authenticators {
+		if _, ok := authenticator.(auth.CookieAuthenticator); !ok {
+			result = append(result, authenticator)
+		}
+	}
+	return result
+}
 
-			// ユーザーIDをコンテキストに設定
-			if userID, ok := claims["user_id"].(float64); ok {
-				c.Set("user_id", int64(userID))
+// cookieCredential セッションCookieを扱う認証器とCookieの資格情報を取得（なければ空文字列）
+func cookieCredential(c *gin.Context, authenticators []auth.Authenticator) (auth.CookieAuthenticator, string) {
+	for _, authenticator := range authenticators {
+		if sessions, ok := authenticator.(auth.CookieAuthenticator); ok {
+			if credential := sessions.Credential(c.Request); credential != "" {
+				return sessions, credential
 			}
 		}
+	}
+	return nil, ""
+}
 
-		c.Next()
+// isSafeMethod 状態を変更しないメソッドか（CSRFトークンを確認しない）
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// authenticate 資格情報を扱える認証器を探して検証
+func authenticate(c *gin.Context, authenticators []auth.Authenticator, credential string) (*auth.Principal, error) {
+	return auth.Chain(authenticators).Authenticate(c.Request.Context(), credential)
+}
+
+// GetPrincipal 認証済み主体を取得
+func GetPrincipal(c *gin.Context) (*auth.Principal, bool) {
+	value, exists := c.Get(principalContextKey)
+	if !exists {
+		return nil, false
 	}
-} 
\ No newline at end of file
+	principal, ok := value.(*auth.Principal)
+	return principal, ok
+}
+
+// GetClaims JWTで認証した場合の検証済みクレームを取得
+func GetClaims(c *gin.Context) (*auth.Claims, bool) {
+	principal, ok := GetPrincipal(c)
+	if !ok || principal.Claims == nil {
+		return nil, false
+	}
+	return principal.Claims, true
+}
+
+// CurrentUserID 認証済みユーザーのIDを取得
+func CurrentUserID(c *gin.Context) (int64, bool) {
+	principal, ok := GetPrincipal(c)
+	if !ok {
+		return 0, false
+	}
+	return principal.UserID, true
+}
@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+
+	"app-template/pkg/problem"
+)
+
+// OpenAPIOptions OpenAPI検証ミドルウェアの設定
+type OpenAPIOptions struct {
+	// ValidateResponses レスポンスも仕様と照合し、一致しなければ500を返す（開発・テスト用）
+	ValidateResponses bool
+}
+
+// OpenAPIValidator リクエストをOpenAPI仕様と照合するミドルウェア
+// 仕様に定義されていないルートは検証せずに通す。認証の検証は認証ミドルウェアに任せる
+func OpenAPIValidator(doc *openapi3.T, opts OpenAPIOptions) (gin.HandlerFunc, error) {
+	// サーバーURLのホストに関係なくパスだけで照合する
+	doc.Servers = nil
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build openapi router: %w", err)
+	}
+
+	filterOptions := &openapi3filter.Options{
+		AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+		SkipSettingDefaults: true,
+	}
+	filterOptions.WithCustomSchemaErrorFunc(schemaErrorMessage)
+
+	return func(c *gin.Context) {
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    filterOptions,
+		}
+		// JSON以外の本文（画像・CSVなど）は読み込まずにハンドラーに渡し、ハンドラーの形式・サイズの検証に任せる
+		if c.Request.ContentLength != 0 && !isJSON(c.ContentType()) {
+			bodyless := *filterOptions
+			bodyless.ExcludeRequestBody = true
+			input.Options = &bodyless
+		}
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			problem.Abort(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), requestFieldErrors(err)...)
+			return
+		}
+
+		if !opts.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		// レスポンスを保留して照合し、一致した場合のみ送信する
+		writer := &bufferedResponseWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		// ストリーミングのレスポンスは送信済みのため本文を除いて照合し、一致しなければログに残す
+		if writer.streaming {
+			streamOptions := *filterOptions
+			streamOptions.ExcludeResponseBody = true
+			err = openapi3filter.ValidateResponse(c.Request.Context(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 writer.status,
+				Header:                 writer.Header(),
+				Body:                   http.NoBody,
+				Options:                &streamOptions,
+			})
+			if err != nil {
+				log.Printf("openapi: %s %s streamed %d, which does not match the spec: %v", c.Request.Method, route.Path, writer.status, err)
+			}
+			return
+		}
+
+		// problem+json は仕様に定義していないため照合しない
+		if strings.HasPrefix(writer.Header().Get("Content-Type"), problem.ContentType) {
+			writer.flush()
+			return
+		}
+
+		err = openapi3filter.ValidateResponse(c.Request.Context(), &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 writer.status,
+			Header:                 writer.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(writer.body.Bytes())),
+			Options:                filterOptions,
+		})
+		if err != nil {
+			log.Printf("openapi: %s %s responded %d, which does not match the spec: %v", c.Request.Method, route.Path, writer.status, err)
+			problem.Write(c, http.StatusInternalServerError, "RESPONSE_VALIDATION_ERROR", "Response does not match the OpenAPI spec: "+err.Error())
+			return
+		}
+
+		writer.flush()
+	}, nil
+}
+
+// isJSON JSONのメディアタイプか（application/json, application/merge-patch+json など）
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// schemaErrorMessage スキーマエラーを位置と理由だけの短いメッセージにする（スキーマ全体は含めない）
+func schemaErrorMessage(err *openapi3.SchemaError) string {
+	if pointer := err.JSONPointer(); len(pointer) > 0 {
+		return fmt.Sprintf("Error at %q: %s", "/"+strings.Join(pointer, "/"), err.Reason)
+	}
+	return err.Reason
+}
+
+// requestFieldErrors リクエストの検証エラーから項目ごとのエラーを取り出す
+func requestFieldErrors(err error) []problem.FieldError {
+	var requestErr *openapi3filter.RequestError
+	if !errors.As(err, &requestErr) {
+		return nil
+	}
+
+	message := requestErr.Reason
+	var schemaErr *openapi3.SchemaError
+	if errors.As(requestErr.Err, &schemaErr) {
+		message = schemaErr.Reason
+	} else if message == "" && requestErr.Err != nil {
+		message = requestErr.Err.Error()
+	}
+
+	switch {
+	case requestErr.Parameter != nil:
+		return []problem.FieldError{{Field: requestErr.Parameter.Name, Message: message}}
+	case schemaErr != nil && len(schemaErr.JSONPointer()) > 0:
+		return []problem.FieldError{{Field: strings.Join(schemaErr.JSONPointer(), "."), Message: message}}
+	default:
+		return nil
+	}
+}
+
+// bufferedResponseWriter ステータスと本文を送信せずに保持するResponseWriter
+// ハンドラーが Flush した場合はストリーミングとみなし、保持した分を送信して以降はそのまま書き込む
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	status    int
+	written   bool
+	streaming bool
+	body      bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *bufferedResponseWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	w.written = true
+	if w.streaming {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *bufferedResponseWriter) WriteString(s string) (int, error) {
+	w.written = true
+	if w.streaming {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.body.WriteString(s)
+}
+
+// Flush 保持したレスポンスを送信してストリーミングに切り替える（エクスポートなどを全件メモリに保持しない）
+func (w *bufferedResponseWriter) Flush() {
+	if !w.streaming {
+		w.streaming = true
+		w.flush()
+		w.body = bytes.Buffer{}
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *bufferedResponseWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedResponseWriter) Size() int {
+	if w.streaming {
+		return w.ResponseWriter.Size()
+	}
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedResponseWriter) Written() bool {
+	return w.written
+}
+
+// flush 保持したレスポンスを送信
+func (w *bufferedResponseWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.WriteHeaderNow()
+	if w.body.Len() > 0 {
+		w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
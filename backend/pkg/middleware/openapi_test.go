@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/pkg/openapi"
+)
+
+// exportPath 仕様でCSV・NDJSONを返すと定義したエクスポートのパス
+const exportPath = "/api/v1/admin/users/export"
+
+// validatedRouter レスポンスも照合する検証ミドルウェアを通して handler を呼ぶルーター
+func validatedRouter(t *testing.T, handler gin.HandlerFunc) *gin.Engine {
+	t.Helper()
+	spec, err := openapi.Load(context.Background())
+	if err != nil {
+		t.Fatalf("openapi.Load: %v", err)
+	}
+	validator, err := OpenAPIValidator(spec, OpenAPIOptions{ValidateResponses: true})
+	if err != nil {
+		t.Fatalf("OpenAPIValidator: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(validator)
+	r.GET(exportPath, handler)
+	return r
+}
+
+// ndjsonLines n 行のNDJSON
+func ndjsonLines(from, to int) string {
+	var b strings.Builder
+	for i := from; i < to; i++ {
+		fmt.Fprintf(&b, "{\"id\":%d,\"email\":\"user%d@example.com\"}\n", i, i)
+	}
+	return b.String()
+}
+
+func TestOpenAPIValidatorStreamsNDJSONExport(t *testing.T) {
+	const total, interval = 250, 100
+	w := httptest.NewRecorder()
+	var sentBeforeEnd []int
+
+	r := validatedRouter(t, func(c *gin.Context) {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(http.StatusOK)
+		for from := 0; from < total; from += interval {
+			c.Writer.WriteString(ndjsonLines(from, min(from+interval, total)))
+			c.Writer.Flush()
+			// Flush した分は検証を待たずにクライアントへ送られている
+			sentBeforeEnd = append(sentBeforeEnd, strings.Count(w.Body.String(), "\n"))
+		}
+	})
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, exportPath+"?format=ndjson", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if want := ndjsonLines(0, total); w.Body.String() != want {
+		t.Errorf("body has %d lines, want %d", strings.Count(w.Body.String(), "\n"), total)
+	}
+	if want := []int{100, 200, 250}; fmt.Sprint(sentBeforeEnd) != fmt.Sprint(want) {
+		t.Errorf("lines sent after each flush = %v, want %v", sentBeforeEnd, want)
+	}
+}
+
+func TestOpenAPIValidatorChecksBufferedResponses(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{name: "ndjson", contentType: "application/x-ndjson", body: ndjsonLines(0, 3), wantStatus: http.StatusOK},
+		{name: "csv", contentType: "text/csv; charset=utf-8", body: "id,email\n1,user1@example.com\n", wantStatus: http.StatusOK},
+		{name: "undeclared content type", contentType: "application/xml", body: "<users/>", wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := validatedRouter(t, func(c *gin.Context) {
+				c.Data(http.StatusOK, tt.contentType, []byte(tt.body))
+			})
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, exportPath, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && w.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.body)
+			}
+		})
+	}
+}
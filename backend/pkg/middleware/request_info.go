@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"app-template/pkg/requestinfo"
+)
+
+// RequestIDHeader リクエストIDのヘッダー名
+const RequestIDHeader = "X-Request-ID"
+
+// RequestInfo リクエストID・IP・User-Agentをリクエストのコンテキストに設定するミドルウェア
+// 上流（ロードバランサー等）が付与したX-Request-IDがあれば引き継ぐ
+func RequestInfo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestinfo.ValidID(requestID) {
+			requestID = requestinfo.NewID()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		ctx := requestinfo.WithInfo(c.Request.Context(), requestinfo.Info{
+			RequestID: requestID,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
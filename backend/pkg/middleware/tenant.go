@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/pkg/problem"
+	"app-template/pkg/tenant"
+)
+
+// Tenant テナント解決ミドルウェア（Authenticate・OptionalAuthenticateの後に使う）
+// JWTのorg_idクレーム・ヘッダー・サブドメイン（指定がなければ所属する唯一の組織、所属がなければ個人のテナント）から組織を解決し、メンバーであればコンテキストに設定する。
+// 以降のリポジトリのクエリはこのテナントで絞り込まれる。未認証のリクエストはテナントなしで続行する
+func Tenant(resolver tenant.Resolver, opts tenant.Options) gin.HandlerFunc {
+	if opts.Header == "" {
+		opts.Header = tenant.DefaultHeader
+	}
+
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		t, err := tenant.Resolve(c.Request.Context(), resolver, opts, principal, c.GetHeader(opts.Header), c.Request.Host)
+		if err != nil {
+			abortTenantError(c, err)
+			return
+		}
+		if t != nil {
+			c.Request = c.Request.WithContext(tenant.WithTenant(c.Request.Context(), t))
+		}
+
+		c.Next()
+	}
+}
+
+// abortTenantError テナント解決のエラーをレスポンスに変換して中断
+func abortTenantError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, tenant.ErrNotFound):
+		problem.Abort(c, http.StatusNotFound, "ORGANIZATION_NOT_FOUND", err.Error())
+	case errors.Is(err, tenant.ErrNotMember):
+		problem.Abort(c, http.StatusForbidden, "NOT_ORGANIZATION_MEMBER", err.Error())
+	case errors.Is(err, tenant.ErrMismatch):
+		problem.Abort(c, http.StatusForbidden, "TENANT_MISMATCH", err.Error())
+	case errors.Is(err, tenant.ErrRequired):
+		problem.Abort(c, http.StatusBadRequest, "TENANT_REQUIRED", err.Error())
+	default:
+		problem.Abort(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to resolve organization")
+	}
+}
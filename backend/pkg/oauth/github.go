@@ -0,0 +1,135 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+// GitHubProvider GitHub OAuth2プロバイダー（OIDC非対応のためユーザーAPIで情報を取得）
+type GitHubProvider struct {
+	config oauth2.Config
+	apiURL string
+}
+
+// NewGitHubProvider GitHubプロバイダーを作成
+func NewGitHubProvider(cfg ProviderConfig) *GitHubProvider {
+	endpoint := github.Endpoint
+	if cfg.AuthURL != "" {
+		endpoint.AuthURL = cfg.AuthURL
+	}
+	if cfg.TokenURL != "" {
+		endpoint.TokenURL = cfg.TokenURL
+	}
+
+	apiURL := strings.TrimRight(cfg.APIURL, "/")
+	if apiURL == "" {
+		apiURL = "https://api.github.com"
+	}
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"read:user", "user:email"}
+	}
+
+	return &GitHubProvider{
+		config: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     endpoint,
+			Scopes:       scopes,
+		},
+		apiURL: apiURL,
+	}
+}
+
+// Name プロバイダー名
+func (p *GitHubProvider) Name() string {
+	return ProviderGitHub
+}
+
+// AuthCodeURL 認可エンドポイントのURLを生成（nonceはOIDC専用のため未使用）
+func (p *GitHubProvider) AuthCodeURL(state, verifier, nonce string) string {
+	return p.config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier))
+}
+
+// Exchange 認可コードを交換しユーザー情報を取得
+func (p *GitHubProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error) {
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, errProvider(ProviderGitHub, "failed to exchange code", err)
+	}
+
+	client := p.config.Client(ctx, token)
+
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := p.getJSON(ctx, client, "/user", &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, errProvider(ProviderGitHub, "user id missing from response", nil)
+	}
+
+	// 公開メールアドレスは検証状態が分からないため、メール一覧から主アドレスを取得
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.getJSON(ctx, client, "/user/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Provider: ProviderGitHub,
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Name:     user.Name,
+	}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+	for _, e := range emails {
+		if e.Primary {
+			identity.Email = e.Email
+			identity.EmailVerified = e.Verified
+			break
+		}
+	}
+
+	return identity, nil
+}
+
+// getJSON GitHub APIを呼び出しJSONをデコード
+func (p *GitHubProvider) getJSON(ctx context.Context, client *http.Client, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.apiURL+path, nil)
+	if err != nil {
+		return errProvider(ProviderGitHub, "failed to build request", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return errProvider(ProviderGitHub, "request failed", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errProvider(ProviderGitHub, fmt.Sprintf("GET %s returned %d", path, resp.StatusCode), nil)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errProvider(ProviderGitHub, "failed to decode response", err)
+	}
+	return nil
+}
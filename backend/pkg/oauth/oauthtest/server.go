@@ -0,0 +1,288 @@
+// Package oauthtest テスト用のOAuth 2.0 / OpenID Connectプロバイダー
+// ディスカバリー・JWKS・トークンエンドポイント（PKCEを検証する）と、GitHub形式のユーザーAPIを提供する
+package oauthtest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"app-template/pkg/oauth"
+)
+
+// クライアントの認証情報
+const (
+	ClientID     = "test-client"
+	ClientSecret = "test-secret"
+	RedirectURL  = "http://localhost/callback"
+)
+
+const keyID = "test-key"
+
+// User プロバイダーのユーザー
+type User struct {
+	// Subject ユーザーID（GitHub形式のAPIでは数値）
+	Subject       string
+	Login         string
+	Name          string
+	Email         string
+	EmailVerified bool
+}
+
+// grant 認可コードに紐付く情報
+type grant struct {
+	user        User
+	challenge   string
+	nonce       string
+	redirectURI string
+}
+
+// Server テスト用のプロバイダー
+type Server struct {
+	*httptest.Server
+
+	// NonceOverride 空でなければIDトークンのnonceをこの値にする（nonce不一致の検証用）
+	NonceOverride string
+
+	key    *rsa.PrivateKey
+	mu     sync.Mutex
+	codes  map[string]grant
+	tokens map[string]User
+}
+
+// NewServer プロバイダーを起動する（終了時に Close する）
+func NewServer() *Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+
+	s := &Server{
+		key:    key,
+		codes:  make(map[string]grant),
+		tokens: make(map[string]User),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/jwks", s.jwks)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/user", s.user)
+	mux.HandleFunc("/user/emails", s.emails)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// OIDCConfig OIDCプロバイダー（Google形式）の接続設定
+func (s *Server) OIDCConfig() oauth.ProviderConfig {
+	return oauth.ProviderConfig{
+		ClientID:     ClientID,
+		ClientSecret: ClientSecret,
+		RedirectURL:  RedirectURL,
+		IssuerURL:    s.URL,
+	}
+}
+
+// GitHubConfig GitHub形式のプロバイダーの接続設定
+func (s *Server) GitHubConfig() oauth.ProviderConfig {
+	return oauth.ProviderConfig{
+		ClientID:     ClientID,
+		ClientSecret: ClientSecret,
+		RedirectURL:  RedirectURL,
+		AuthURL:      s.URL + "/authorize",
+		TokenURL:     s.URL + "/token",
+		APIURL:       s.URL,
+	}
+}
+
+// Authorize 認可エンドポイントでユーザーが同意したものとして認可コードを発行する
+// 認可URLのパラメータ（client_id・PKCE S256）を検証し、認可コードとstateを返す
+func (s *Server) Authorize(authURL string, user User) (code, state string, err error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	q := u.Query()
+	if q.Get("client_id") != ClientID || q.Get("response_type") != "code" {
+		return "", "", errors.New("oauthtest: invalid client_id or response_type")
+	}
+	if q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		return "", "", errors.New("oauthtest: PKCE S256 challenge is required")
+	}
+
+	code = randomString()
+	s.mu.Lock()
+	s.codes[code] = grant{
+		user:        user,
+		challenge:   q.Get("code_challenge"),
+		nonce:       q.Get("nonce"),
+		redirectURI: q.Get("redirect_uri"),
+	}
+	s.mu.Unlock()
+	return code, q.Get("state"), nil
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/jwks",
+		"userinfo_endpoint":                     s.URL + "/userinfo",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := s.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+// token 認可コードをトークンに交換する（クライアント認証・redirect_uri・PKCEのverifierを検証）
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != ClientID || clientSecret != ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	s.mu.Lock()
+	g, ok := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	s.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || g.redirectURI != r.PostForm.Get("redirect_uri") || base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	accessToken := randomString()
+	s.mu.Lock()
+	s.tokens[accessToken] = g.user
+	s.mu.Unlock()
+
+	nonce := g.nonce
+	if s.NonceOverride != "" {
+		nonce = s.NonceOverride
+	}
+	idToken, err := s.idToken(g.user, nonce)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+// idToken RS256で署名したIDトークンを発行する
+func (s *Server) idToken(user User, nonce string) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            s.URL,
+		"sub":            user.Subject,
+		"aud":            ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"email":          user.Email,
+		"email_verified": user.EmailVerified,
+		"name":           user.Name,
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	return token.SignedString(s.key)
+}
+
+// user GitHub形式のユーザーAPI
+func (s *Server) user(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.authorized(r)
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "Bad credentials"})
+		return
+	}
+	id, _ := strconv.ParseInt(user.Subject, 10, 64)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":    id,
+		"login": user.Login,
+		"name":  user.Name,
+	})
+}
+
+// emails GitHub形式のメールアドレス一覧API（主アドレスの前に別のアドレスを返す）
+func (s *Server) emails(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.authorized(r)
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "Bad credentials"})
+		return
+	}
+	writeJSON(w, http.StatusOK, []map[string]interface{}{
+		{"email": "secondary-" + user.Email, "primary": false, "verified": true},
+		{"email": user.Email, "primary": true, "verified": user.EmailVerified},
+	})
+}
+
+func (s *Server) authorized(r *http.Request) (User, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return User{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.tokens[token]
+	return user, ok
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,136 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// プロバイダー名
+const (
+	ProviderGoogle = "google"
+	ProviderGitHub = "github"
+)
+
+// Identity 外部プロバイダーから取得したユーザー情報
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider 認可コードフロー（PKCE）を提供する外部プロバイダー
+type Provider interface {
+	// Name プロバイダー名
+	Name() string
+	// AuthCodeURL 認可エンドポイントのURLを生成
+	AuthCodeURL(state, verifier, nonce string) string
+	// Exchange 認可コードをトークンに交換してユーザー情報を取得
+	Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error)
+}
+
+// ProviderConfig プロバイダーの接続設定
+type ProviderConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// IssuerURL OIDCディスカバリーに使用するIssuer
+	IssuerURL string
+	// AuthURL, TokenURL, APIURL OIDC非対応プロバイダーのエンドポイント（空ならデフォルト）
+	AuthURL  string
+	TokenURL string
+	APIURL   string
+}
+
+// Registry 利用可能なプロバイダーの一覧
+type Registry struct {
+	providers map[string]Provider
+}
+
+// NewRegistry プロバイダー一覧の新しいインスタンスを作成
+func NewRegistry(providers ...Provider) *Registry {
+	r := &Registry{
+		providers: make(map[string]Provider),
+	}
+	for _, p := range providers {
+		r.providers[p.Name()] = p
+	}
+	return r
+}
+
+// Get 名前でプロバイダーを取得
+func (r *Registry) Get(name string) (Provider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Names 登録済みプロバイダー名を返す
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadRegistry 環境変数から設定されているプロバイダーを読み込む
+// クライアントIDが未設定のプロバイダーは無効として扱う
+func LoadRegistry(ctx context.Context) *Registry {
+	redirectBase := strings.TrimRight(os.Getenv("OAUTH_REDIRECT_BASE_URL"), "/")
+	if redirectBase == "" {
+		redirectBase = "http://localhost:8080/api/v1/auth/oauth"
+	}
+
+	var providers []Provider
+
+	if cfg, ok := providerConfigFromEnv("GOOGLE", redirectBase+"/"+ProviderGoogle+"/callback"); ok {
+		if cfg.IssuerURL == "" {
+			cfg.IssuerURL = "https://accounts.google.com"
+		}
+		p, err := NewOIDCProvider(ctx, ProviderGoogle, cfg)
+		if err != nil {
+			log.Printf("OAuth provider %s disabled: %v", ProviderGoogle, err)
+		} else {
+			providers = append(providers, p)
+		}
+	}
+
+	if cfg, ok := providerConfigFromEnv("GITHUB", redirectBase+"/"+ProviderGitHub+"/callback"); ok {
+		providers = append(providers, NewGitHubProvider(cfg))
+	}
+
+	return NewRegistry(providers...)
+}
+
+// providerConfigFromEnv OAUTH_<NAME>_* 環境変数から設定を読み込む
+func providerConfigFromEnv(name, redirectURL string) (ProviderConfig, bool) {
+	prefix := "OAUTH_" + name + "_"
+	cfg := ProviderConfig{
+		ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+		ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+		RedirectURL:  redirectURL,
+		IssuerURL:    os.Getenv(prefix + "ISSUER"),
+		AuthURL:      os.Getenv(prefix + "AUTH_URL"),
+		TokenURL:     os.Getenv(prefix + "TOKEN_URL"),
+		APIURL:       os.Getenv(prefix + "API_URL"),
+	}
+	if scopes := os.Getenv(prefix + "SCOPES"); scopes != "" {
+		cfg.Scopes = strings.Fields(strings.ReplaceAll(scopes, ",", " "))
+	}
+	return cfg, cfg.ClientID != ""
+}
+
+// errProvider プロバイダーのエラーを包む
+func errProvider(provider, msg string, err error) error {
+	if err == nil {
+		return fmt.Errorf("%s: %s", provider, msg)
+	}
+	return fmt.Errorf("%s: %s: %w", provider, msg, err)
+}
@@ -0,0 +1,243 @@
+package oauth_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"app-template/pkg/oauth"
+	"app-template/pkg/oauth/oauthtest"
+)
+
+var googleUser = oauthtest.User{
+	Subject:       "google-123",
+	Name:          "Alice",
+	Email:         "alice@example.com",
+	EmailVerified: true,
+}
+
+var githubUser = oauthtest.User{
+	Subject:       "4242",
+	Login:         "octocat",
+	Email:         "octo@example.com",
+	EmailVerified: true,
+}
+
+func newOIDCProvider(t *testing.T, srv *oauthtest.Server) *oauth.OIDCProvider {
+	t.Helper()
+	p, err := oauth.NewOIDCProvider(context.Background(), oauth.ProviderGoogle, srv.OIDCConfig())
+	if err != nil {
+		t.Fatalf("NewOIDCProvider: %v", err)
+	}
+	return p
+}
+
+func newState(t *testing.T) *oauth.State {
+	t.Helper()
+	st, err := oauth.NewStateCodec("secret", time.Minute).New(oauth.ProviderGoogle, 0)
+	if err != nil {
+		t.Fatalf("New state: %v", err)
+	}
+	return st
+}
+
+func TestAuthCodeURLSendsPKCEChallengeAndNonce(t *testing.T) {
+	srv := oauthtest.NewServer()
+	defer srv.Close()
+
+	st := newState(t)
+	authURL, err := url.Parse(newOIDCProvider(t, srv).AuthCodeURL(st.State, st.Verifier, st.Nonce))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := authURL.Query()
+
+	sum := sha256.Sum256([]byte(st.Verifier))
+	if got, want := q.Get("code_challenge"), base64.RawURLEncoding.EncodeToString(sum[:]); got != want {
+		t.Errorf("code_challenge = %q, want %q", got, want)
+	}
+	if q.Get("code_challenge_method") != "S256" {
+		t.Errorf("code_challenge_method = %q, want S256", q.Get("code_challenge_method"))
+	}
+	if q.Get("state") != st.State || q.Get("nonce") != st.Nonce {
+		t.Errorf("state/nonce not forwarded: %v", q)
+	}
+	if strings.Contains(authURL.String(), st.Verifier) {
+		t.Error("authorization URL leaks the PKCE verifier")
+	}
+}
+
+func TestOIDCProviderExchange(t *testing.T) {
+	srv := oauthtest.NewServer()
+	defer srv.Close()
+
+	p := newOIDCProvider(t, srv)
+	st := newState(t)
+	code, _, err := srv.Authorize(p.AuthCodeURL(st.State, st.Verifier, st.Nonce), googleUser)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	identity, err := p.Exchange(context.Background(), code, st.Verifier, st.Nonce)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	want := oauth.Identity{
+		Provider:      oauth.ProviderGoogle,
+		Subject:       googleUser.Subject,
+		Email:         googleUser.Email,
+		EmailVerified: true,
+		Name:          googleUser.Name,
+	}
+	if *identity != want {
+		t.Errorf("identity = %+v, want %+v", *identity, want)
+	}
+}
+
+func TestOIDCProviderRejectsWrongVerifier(t *testing.T) {
+	srv := oauthtest.NewServer()
+	defer srv.Close()
+
+	p := newOIDCProvider(t, srv)
+	st := newState(t)
+	code, _, err := srv.Authorize(p.AuthCodeURL(st.State, st.Verifier, st.Nonce), googleUser)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	if _, err := p.Exchange(context.Background(), code, newState(t).Verifier, st.Nonce); err == nil {
+		t.Fatal("Exchange with another verifier succeeded")
+	}
+}
+
+func TestOIDCProviderRejectsReusedCode(t *testing.T) {
+	srv := oauthtest.NewServer()
+	defer srv.Close()
+
+	p := newOIDCProvider(t, srv)
+	st := newState(t)
+	code, _, err := srv.Authorize(p.AuthCodeURL(st.State, st.Verifier, st.Nonce), googleUser)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	if _, err := p.Exchange(context.Background(), code, st.Verifier, st.Nonce); err != nil {
+		t.Fatalf("first Exchange: %v", err)
+	}
+	if _, err := p.Exchange(context.Background(), code, st.Verifier, st.Nonce); err == nil {
+		t.Fatal("second Exchange with the same code succeeded")
+	}
+}
+
+func TestOIDCProviderRejectsNonceMismatch(t *testing.T) {
+	srv := oauthtest.NewServer()
+	defer srv.Close()
+	srv.NonceOverride = "replayed-nonce"
+
+	p := newOIDCProvider(t, srv)
+	st := newState(t)
+	code, _, err := srv.Authorize(p.AuthCodeURL(st.State, st.Verifier, st.Nonce), googleUser)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	_, err = p.Exchange(context.Background(), code, st.Verifier, st.Nonce)
+	if err == nil || !strings.Contains(err.Error(), "nonce mismatch") {
+		t.Fatalf("Exchange error = %v, want nonce mismatch", err)
+	}
+}
+
+func TestGitHubProviderExchange(t *testing.T) {
+	srv := oauthtest.NewServer()
+	defer srv.Close()
+
+	p := oauth.NewGitHubProvider(srv.GitHubConfig())
+	st := newState(t)
+	code, _, err := srv.Authorize(p.AuthCodeURL(st.State, st.Verifier, st.Nonce), githubUser)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	identity, err := p.Exchange(context.Background(), code, st.Verifier, st.Nonce)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	// 名前がなければログイン名、メールアドレスは一覧の主アドレス
+	want := oauth.Identity{
+		Provider:      oauth.ProviderGitHub,
+		Subject:       "4242",
+		Email:         githubUser.Email,
+		EmailVerified: true,
+		Name:          "octocat",
+	}
+	if *identity != want {
+		t.Errorf("identity = %+v, want %+v", *identity, want)
+	}
+}
+
+func TestGitHubProviderRejectsWrongVerifier(t *testing.T) {
+	srv := oauthtest.NewServer()
+	defer srv.Close()
+
+	p := oauth.NewGitHubProvider(srv.GitHubConfig())
+	st := newState(t)
+	code, _, err := srv.Authorize(p.AuthCodeURL(st.State, st.Verifier, st.Nonce), githubUser)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+
+	if _, err := p.Exchange(context.Background(), code, "wrong-verifier-wrong-verifier-wrong-verifier", st.Nonce); err == nil {
+		t.Fatal("Exchange with a wrong verifier succeeded")
+	}
+}
+
+func TestStateCodec(t *testing.T) {
+	codec := oauth.NewStateCodec("secret", time.Minute)
+	st, err := codec.New(oauth.ProviderGitHub, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := codec.Encode(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := codec.Decode(encoded)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if *decoded != *st {
+		t.Errorf("decoded = %+v, want %+v", *decoded, *st)
+	}
+
+	payload, signature, _ := strings.Cut(encoded, ".")
+	tampered := map[string]string{
+		"other secret":  mustEncode(t, oauth.NewStateCodec("other", time.Minute), st),
+		"bad signature": payload + "." + strings.Repeat("A", len(signature)),
+		"no signature":  payload,
+	}
+	for name, value := range tampered {
+		if _, err := codec.Decode(value); err != oauth.ErrInvalidState {
+			t.Errorf("%s: Decode error = %v, want ErrInvalidState", name, err)
+		}
+	}
+
+	expired := *st
+	expired.ExpiresAt = time.Now().Add(-time.Second).Unix()
+	if _, err := codec.Decode(mustEncode(t, codec, &expired)); err != oauth.ErrInvalidState {
+		t.Errorf("expired: Decode error = %v, want ErrInvalidState", err)
+	}
+}
+
+func mustEncode(t *testing.T, codec *oauth.StateCodec, st *oauth.State) string {
+	t.Helper()
+	encoded, err := codec.Encode(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
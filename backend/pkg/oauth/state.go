@@ -0,0 +1,117 @@
+package oauth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrInvalidState 認可リクエストの状態が不正または期限切れ
+var ErrInvalidState = errors.New("invalid or expired oauth state")
+
+// State 認可リクエストからコールバックまで保持する情報
+type State struct {
+	Provider string `json:"p"`
+	State    string `json:"s"`
+	Verifier string `json:"v"`
+	Nonce    string `json:"n"`
+	// LinkUserID アカウント連携の場合の対象ユーザーID（ログイン時は0）
+	LinkUserID int64 `json:"u,omitempty"`
+	ExpiresAt  int64 `json:"e"`
+}
+
+// StateCodec Stateを署名付き文字列に変換する
+type StateCodec struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewStateCodec StateCodecの新しいインスタンスを作成
+func NewStateCodec(secret string, ttl time.Duration) *StateCodec {
+	return &StateCodec{
+		secret: []byte(secret),
+		ttl:    ttl,
+	}
+}
+
+// TTL Stateの有効期間
+func (c *StateCodec) TTL() time.Duration {
+	return c.ttl
+}
+
+// New state・PKCE verifier・nonceを生成して新しいStateを作成
+func (c *StateCodec) New(provider string, linkUserID int64) (*State, error) {
+	state, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	return &State{
+		Provider:   provider,
+		State:      state,
+		Verifier:   oauth2.GenerateVerifier(),
+		Nonce:      nonce,
+		LinkUserID: linkUserID,
+		ExpiresAt:  time.Now().Add(c.ttl).Unix(),
+	}, nil
+}
+
+// Encode Stateを署名付き文字列に変換
+func (c *StateCodec) Encode(st *State) (string, error) {
+	payload, err := json.Marshal(st)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + c.sign(encoded), nil
+}
+
+// Decode 署名と有効期限を検証してStateを復元
+func (c *StateCodec) Decode(value string) (*State, error) {
+	encoded, signature, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(c.sign(encoded))) {
+		return nil, ErrInvalidState
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidState
+	}
+
+	var st State
+	if err := json.Unmarshal(payload, &st); err != nil {
+		return nil, ErrInvalidState
+	}
+	if time.Now().Unix() > st.ExpiresAt {
+		return nil, ErrInvalidState
+	}
+
+	return &st, nil
+}
+
+// sign HMAC-SHA256署名を計算
+func (c *StateCodec) sign(encoded string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte("oauth-state:" + encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// randomString URLセーフなランダム文字列を生成
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
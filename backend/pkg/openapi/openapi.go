@@ -0,0 +1,45 @@
+// Package openapi api/ の分割された仕様をマージしたOpenAPI仕様を埋め込んで提供する
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+)
+
+//go:generate go run ../../cmd/openapi merge -in ../../../api/index.yml ../../../api/openapi.yml openapi.json
+
+//go:embed openapi.json
+var spec []byte
+
+// JSON マージ済みの仕様（JSON）
+func JSON() []byte {
+	return spec
+}
+
+// Load 埋め込まれた仕様を読み込んで検証
+// 呼び出しごとに新しいドキュメントを返すため、呼び出し側で変更してよい
+func Load(ctx context.Context) (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	loader.Context = ctx
+
+	doc, err := loader.LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load openapi spec: %w", err)
+	}
+	if err := doc.Validate(ctx); err != nil {
+		return nil, fmt.Errorf("invalid openapi spec: %w", err)
+	}
+
+	return doc, nil
+}
+
+func init() {
+	// format: email は既定では検証されないため登録する
+	openapi3.DefineStringFormatValidator("email", openapi3.NewRegexpFormatValidator(openapi3.FormatOfStringForEmail))
+	// NDJSON（一括取り込み・エクスポート）は仕様で文字列として定義しているため、本文をそのまま文字列として照合する
+	openapi3filter.RegisterBodyDecoder("application/x-ndjson", openapi3filter.PlainBodyDecoder)
+}
@@ -0,0 +1,260 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// テスト用のMySQLの代わり（リレーが発行するクエリだけを解釈するdatabase/sqlのドライバー）
+
+// fakeRow outbox_events の1行
+type fakeRow struct {
+	id            int64
+	aggregateType string
+	aggregateID   string
+	eventType     string
+	payload       []byte
+	attempts      int
+	lastError     string
+	nextAttemptAt time.Time
+	publishedAt   *time.Time
+	createdAt     time.Time
+}
+
+// fakeDB outbox_events テーブルと名前付きロック
+type fakeDB struct {
+	mu     sync.Mutex
+	nextID int64
+	rows   []*fakeRow
+	// locks ロック名ごとの保持している接続
+	locks map[string]*fakeConn
+}
+
+var fakeDBSeq atomic.Int64
+
+// fakeDrivers データソース名ごとのテーブル
+var fakeDrivers sync.Map
+
+func init() {
+	sql.Register("outboxfake", fakeDriver{})
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	db, ok := fakeDrivers.Load(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown fake database %q", name)
+	}
+	return &fakeConn{db: db.(*fakeDB)}, nil
+}
+
+// newFakeDB 空のテーブルに接続する *sql.DB を作成
+func newFakeDB(t *testing.T) (*sql.DB, *fakeDB) {
+	t.Helper()
+	fake := &fakeDB{locks: make(map[string]*fakeConn)}
+	name := fmt.Sprintf("outbox-%d", fakeDBSeq.Add(1))
+	fakeDrivers.Store(name, fake)
+
+	db, err := sql.Open("outboxfake", name)
+	if err != nil {
+		t.Fatalf("open fake db: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		fakeDrivers.Delete(name)
+	})
+	return db, fake
+}
+
+// row IDの行（複製）
+func (d *fakeDB) row(id int64) fakeRow {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, row := range d.rows {
+		if row.id == id {
+			return *row
+		}
+	}
+	return fakeRow{}
+}
+
+// unpublished 未配信の件数
+func (d *fakeDB) unpublished() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := 0
+	for _, row := range d.rows {
+		if row.publishedAt == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// fakeConn 1本の接続（名前付きロックは接続ごとに保持する）
+type fakeConn struct {
+	db *fakeDB
+}
+
+var (
+	_ driver.ExecerContext  = (*fakeConn)(nil)
+	_ driver.QueryerContext = (*fakeConn)(nil)
+)
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("fake db: prepared statements are not supported")
+}
+
+// Close 接続が切れると保持していたロックも外れる
+func (c *fakeConn) Close() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	for name, holder := range c.db.locks {
+		if holder == c {
+			delete(c.db.locks, name)
+		}
+	}
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("fake db: transactions are not supported")
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	d := c.db
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+
+	switch {
+	case strings.Contains(query, "INSERT INTO outbox_events"):
+		d.nextID++
+		d.rows = append(d.rows, &fakeRow{
+			id:            d.nextID,
+			aggregateType: args[0].Value.(string),
+			aggregateID:   args[1].Value.(string),
+			eventType:     args[2].Value.(string),
+			payload:       []byte(args[3].Value.(string)),
+			nextAttemptAt: now,
+			createdAt:     now,
+		})
+		return driver.RowsAffected(1), nil
+
+	case strings.Contains(query, "SET published_at"):
+		id := args[0].Value.(int64)
+		for _, row := range d.rows {
+			if row.id == id {
+				row.publishedAt = &now
+				return driver.RowsAffected(1), nil
+			}
+		}
+		return driver.RowsAffected(0), nil
+
+	case strings.Contains(query, "SET attempts"):
+		id := args[3].Value.(int64)
+		for _, row := range d.rows {
+			if row.id == id {
+				row.attempts = int(args[0].Value.(int64))
+				row.lastError = args[1].Value.(string)
+				row.nextAttemptAt = now.Add(time.Duration(args[2].Value.(int64)) * time.Microsecond)
+				return driver.RowsAffected(1), nil
+			}
+		}
+		return driver.RowsAffected(0), nil
+
+	case strings.Contains(query, "RELEASE_LOCK"):
+		name := args[0].Value.(string)
+		if d.locks[name] == c {
+			delete(d.locks, name)
+		}
+		return driver.RowsAffected(0), nil
+
+	case strings.Contains(query, "DELETE FROM outbox_events"):
+		return driver.RowsAffected(0), nil
+	}
+	return nil, fmt.Errorf("fake db: unsupported exec %q", query)
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	d := c.db
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch {
+	case strings.Contains(query, "IS_USED_LOCK"):
+		return &fakeRows{columns: []string{"held"}, values: [][]driver.Value{{d.locks[args[0].Value.(string)] == c}}}, nil
+
+	case strings.Contains(query, "GET_LOCK"):
+		name := args[0].Value.(string)
+		acquired := int64(0)
+		if holder, ok := d.locks[name]; !ok || holder == c {
+			d.locks[name] = c
+			acquired = 1
+		}
+		return &fakeRows{columns: []string{"acquired"}, values: [][]driver.Value{{acquired}}}, nil
+
+	case strings.Contains(query, "FROM outbox_events"):
+		afterID := args[0].Value.(int64)
+		limit := int(args[1].Value.(int64))
+		now := time.Now()
+
+		pending := make([]*fakeRow, 0)
+		for _, row := range d.rows {
+			if row.publishedAt == nil && row.id > afterID {
+				pending = append(pending, row)
+			}
+		}
+		sort.Slice(pending, func(i, j int) bool { return pending[i].id < pending[j].id })
+		if len(pending) > limit {
+			pending = pending[:limit]
+		}
+
+		rows := &fakeRows{columns: []string{"id", "aggregate_type", "aggregate_id", "event_type", "payload", "attempts", "created_at", "due"}}
+		for _, row := range pending {
+			rows.values = append(rows.values, []driver.Value{
+				row.id, row.aggregateType, row.aggregateID, row.eventType, row.payload,
+				int64(row.attempts), row.createdAt, !row.nextAttemptAt.After(now),
+			})
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("fake db: unsupported query %q", query)
+}
+
+// fakeRows クエリの結果
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
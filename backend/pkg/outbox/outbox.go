@@ -0,0 +1,89 @@
+// Package outbox トランザクショナルアウトボックス
+// ドメインイベントは変更と同じトランザクションで outbox_events テーブルに書き込み（Write）、
+// リレー（Relay）がテーブルを読み取って各送信先（Sink）へ配信する。
+// 配信は少なくとも1回（重複はイベントIDで排除する）で、同じ集約のイベントは書き込んだ順に配信する
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Event アウトボックスに書き込まれたドメインイベント
+type Event struct {
+	// ID 書き込み順に増えるイベントID（受信側の重複排除に使う）
+	ID int64 `json:"id"`
+	// Type イベントの種類（例: user.created）
+	Type string `json:"type"`
+	// AggregateType・AggregateID 変更された集約（例: user / 42）。同じ集約のイベントは順番に配信する
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"data"`
+	CreatedAt     time.Time       `json:"occurred_at"`
+	// Attempts これまでに配信に失敗した回数
+	Attempts int `json:"-"`
+}
+
+// Execer 書き込みに使う接続（*sql.Tx で変更と同じトランザクションにする）
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Write イベントをアウトボックスに書き込む（payloadはJSONで保存する）
+func Write(ctx context.Context, exec Execer, aggregateType, aggregateID, eventType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	query := `
+		INSERT INTO outbox_events (aggregate_type, aggregate_id, event_type, payload, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, NOW(3), NOW(3))
+	`
+	// JSONカラムにはバイナリ文字セットの値を保存できないため文字列で渡す
+	if _, err := exec.ExecContext(ctx, query, aggregateType, aggregateID, eventType, string(data)); err != nil {
+		return fmt.Errorf("failed to write %s event to outbox: %w", eventType, err)
+	}
+	return nil
+}
+
+// Sink イベントの送信先
+// エラーを返したイベントは時間をおいて再配信し、それまで同じ集約の後続のイベントは配信しない
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, event *Event) error
+}
+
+// funcSink 関数を送信先にする
+type funcSink struct {
+	name string
+	send func(ctx context.Context, event *Event) error
+}
+
+// SinkFunc 関数を送信先にする
+func SinkFunc(name string, send func(ctx context.Context, event *Event) error) Sink {
+	return &funcSink{name: name, send: send}
+}
+
+func (s *funcSink) Name() string {
+	return s.name
+}
+
+func (s *funcSink) Send(ctx context.Context, event *Event) error {
+	return s.send(ctx, event)
+}
+
+// dispatch すべての送信先に送る（一部が失敗した場合は成功した送信先にも再配信される）
+func dispatch(ctx context.Context, sinks []Sink, event *Event) error {
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.Send(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
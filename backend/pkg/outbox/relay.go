@@ -0,0 +1,374 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// relayLockName 同時に1つのリレーだけが配信するためのMySQLの名前付きロック
+const relayLockName = "outbox_relay"
+
+// maxErrorLength 記録する失敗理由の最大長
+const maxErrorLength = 1000
+
+// Options リレーの設定
+type Options struct {
+	// PollInterval 未配信のイベントがないときに次を確認するまでの間隔
+	PollInterval time.Duration
+	// BatchSize 1回に読み込むイベントの数
+	BatchSize int
+	// MaxScan 1回の確認で読み進めるイベントの上限（配信を待つ集約のイベントを読み飛ばすため）
+	MaxScan int
+	// SendTimeout 1件の配信の制限時間
+	SendTimeout time.Duration
+	// BaseBackoff 1回目の再配信までの待ち時間（以降は2倍ずつ増える）
+	BaseBackoff time.Duration
+	// MaxBackoff 再配信までの待ち時間の上限
+	MaxBackoff time.Duration
+	// Retention 配信済みのイベントを残す期間（0なら削除しない）
+	Retention time.Duration
+}
+
+// DefaultOptions 既定の設定
+var DefaultOptions = Options{
+	PollInterval: 500 * time.Millisecond,
+	BatchSize:    100,
+	MaxScan:      1000,
+	SendTimeout:  30 * time.Second,
+	BaseBackoff:  time.Second,
+	MaxBackoff:   10 * time.Minute,
+	Retention:    7 * 24 * time.Hour,
+}
+
+// Relay アウトボックスのイベントを送信先へ配信する
+// 複数のプロセスで起動しても名前付きロックを取れた1つだけが配信し、他は待機する
+type Relay struct {
+	db    *sql.DB
+	sinks []Sink
+	opts  Options
+
+	stop     chan struct{}
+	done     chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+	once     sync.Once
+	stopOnce sync.Once
+
+	// conn 名前付きロックを保持している接続（ロック未取得ならnil）
+	conn       *sql.Conn
+	lastPurge  time.Time
+	lockWaited bool
+}
+
+// NewRelay リレーを作成（0の設定項目は既定値を使う）
+func NewRelay(db *sql.DB, sinks []Sink, opts Options) *Relay {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultOptions.PollInterval
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultOptions.BatchSize
+	}
+	if opts.MaxScan < opts.BatchSize {
+		opts.MaxScan = max(DefaultOptions.MaxScan, opts.BatchSize)
+	}
+	if opts.SendTimeout <= 0 {
+		opts.SendTimeout = DefaultOptions.SendTimeout
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = DefaultOptions.BaseBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultOptions.MaxBackoff
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Relay{
+		db:     db,
+		sinks:  sinks,
+		opts:   opts,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start 配信を開始
+func (r *Relay) Start() {
+	r.once.Do(func() {
+		go r.run()
+	})
+}
+
+// Shutdown 新しい配信を止め、配信中のイベントの完了を待つ
+// ctxの期限までに終わらなければ配信を取り消し（イベントは次の起動時に再配信される）、ctxのエラーを返す
+func (r *Relay) Shutdown(ctx context.Context) error {
+	r.Start()
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+
+	select {
+	case <-r.done:
+		r.cancel()
+		return nil
+	case <-ctx.Done():
+		r.cancel()
+		<-r.done
+		return ctx.Err()
+	}
+}
+
+// run 未配信のイベントを配信し続ける
+func (r *Relay) run() {
+	defer close(r.done)
+	defer r.release()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		default:
+		}
+
+		full, err := r.relayOnce()
+		if err != nil && r.ctx.Err() == nil {
+			log.Printf("outbox: relay failed: %v", err)
+		}
+		if full && err == nil {
+			continue
+		}
+
+		select {
+		case <-r.stop:
+			return
+		case <-time.After(r.opts.PollInterval):
+		}
+	}
+}
+
+// relayOnce ロックを確認して未配信のイベントを配信する（読み込みの上限まで配信した場合はtrue）
+func (r *Relay) relayOnce() (bool, error) {
+	ok, err := r.acquire()
+	if err != nil || !ok {
+		return false, err
+	}
+
+	full, err := r.relayPending()
+	if err != nil {
+		return false, err
+	}
+
+	if r.opts.Retention > 0 && time.Since(r.lastPurge) > time.Hour {
+		r.lastPurge = time.Now()
+		if err := r.purge(); err != nil {
+			log.Printf("outbox: failed to purge published events: %v", err)
+		}
+	}
+	return full, nil
+}
+
+// acquire 名前付きロックを取得・確認する（他のリレーが保持している場合はfalse）
+func (r *Relay) acquire() (bool, error) {
+	if r.conn != nil {
+		// 接続が切れるとロックも外れるため、毎回保持していることを確かめる
+		var held sql.NullBool
+		err := r.conn.QueryRowContext(r.ctx, "SELECT IS_USED_LOCK(?) = CONNECTION_ID()", relayLockName).Scan(&held)
+		if err == nil && held.Valid && held.Bool {
+			return true, nil
+		}
+		r.release()
+		if err != nil {
+			return false, fmt.Errorf("failed to check relay lock: %w", err)
+		}
+	}
+
+	conn, err := r.db.Conn(r.ctx)
+	if err != nil {
+		return false, err
+	}
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(r.ctx, "SELECT GET_LOCK(?, 0)", relayLockName).Scan(&acquired); err != nil {
+		conn.Close()
+		return false, fmt.Errorf("failed to acquire relay lock: %w", err)
+	}
+	if acquired.Int64 != 1 {
+		conn.Close()
+		if !r.lockWaited {
+			r.lockWaited = true
+			log.Printf("outbox: another relay is running; standing by")
+		}
+		return false, nil
+	}
+
+	r.conn = conn
+	r.lockWaited = false
+	return true, nil
+}
+
+// release 名前付きロックを外す
+func (r *Relay) release() {
+	if r.conn == nil {
+		return
+	}
+	r.conn.ExecContext(context.Background(), "DO RELEASE_LOCK(?)", relayLockName)
+	r.conn.Close()
+	r.conn = nil
+}
+
+// pendingEvent 未配信のイベントと配信時刻に達したか
+type pendingEvent struct {
+	*Event
+	due bool
+}
+
+// relayPending 未配信のイベントをID順に配信する
+// 配信に失敗した・再配信の時刻を待っている集約の後続のイベントは読み飛ばして順序を保つ
+func (r *Relay) relayPending() (bool, error) {
+	blocked := make(map[string]bool)
+	var afterID int64
+	sent := 0
+	for scanned := 0; scanned < r.opts.MaxScan; {
+		events, err := r.fetch(afterID)
+		if err != nil {
+			return false, err
+		}
+
+		for _, event := range events {
+			afterID = event.ID
+			key := event.AggregateType + "/" + event.AggregateID
+			if blocked[key] {
+				continue
+			}
+			if !event.due {
+				blocked[key] = true
+				continue
+			}
+
+			select {
+			case <-r.stop:
+				return false, nil
+			default:
+			}
+
+			if err := r.send(event.Event); err != nil {
+				blocked[key] = true
+				if r.ctx.Err() != nil {
+					return false, nil
+				}
+				if err := r.markFailed(event.Event, err); err != nil {
+					return false, err
+				}
+				continue
+			}
+			if err := r.markPublished(event.ID); err != nil {
+				return false, err
+			}
+			sent++
+		}
+
+		scanned += len(events)
+		if len(events) < r.opts.BatchSize {
+			return false, nil
+		}
+	}
+	return sent > 0, nil
+}
+
+// send すべての送信先に配信する
+func (r *Relay) send(event *Event) error {
+	ctx, cancel := context.WithTimeout(r.ctx, r.opts.SendTimeout)
+	defer cancel()
+	return dispatch(ctx, r.sinks, event)
+}
+
+// fetch afterIDより後の未配信のイベントを読み込む
+func (r *Relay) fetch(afterID int64) ([]*pendingEvent, error) {
+	query := `
+		SELECT id, aggregate_type, aggregate_id, event_type, payload, attempts, created_at, next_attempt_at <= NOW(3)
+		FROM outbox_events
+		WHERE published_at IS NULL AND id > ?
+		ORDER BY id
+		LIMIT ?
+	`
+	rows, err := r.conn.QueryContext(r.ctx, query, afterID, r.opts.BatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*pendingEvent
+	for rows.Next() {
+		event := &pendingEvent{Event: &Event{}}
+		var payload []byte
+		err := rows.Scan(
+			&event.ID,
+			&event.AggregateType,
+			&event.AggregateID,
+			&event.Type,
+			&payload,
+			&event.Attempts,
+			&event.CreatedAt,
+			&event.due,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// markPublished 配信済みにする
+func (r *Relay) markPublished(id int64) error {
+	if _, err := r.conn.ExecContext(context.WithoutCancel(r.ctx), "UPDATE outbox_events SET published_at = NOW(3) WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to mark outbox event %d published: %w", id, err)
+	}
+	return nil
+}
+
+// markFailed 失敗を記録し、指数バックオフで再配信の時刻を決める
+func (r *Relay) markFailed(event *Event, sendErr error) error {
+	attempts := event.Attempts + 1
+	delay := r.opts.MaxBackoff
+	if shift := attempts - 1; shift < 32 {
+		if d := r.opts.BaseBackoff << shift; d > 0 && d < delay {
+			delay = d
+		}
+	}
+
+	message := sendErr.Error()
+	if len(message) > maxErrorLength {
+		message = message[:maxErrorLength]
+	}
+	log.Printf("outbox: failed to relay event %d (%s, attempt %d), retrying in %s: %v", event.ID, event.Type, attempts, delay, sendErr)
+
+	query := `
+		UPDATE outbox_events
+		SET attempts = ?, last_error = ?, next_attempt_at = DATE_ADD(NOW(3), INTERVAL ? MICROSECOND)
+		WHERE id = ?
+	`
+	if _, err := r.conn.ExecContext(context.WithoutCancel(r.ctx), query, attempts, message, delay.Microseconds(), event.ID); err != nil {
+		return fmt.Errorf("failed to record outbox event %d failure: %w", event.ID, err)
+	}
+	return nil
+}
+
+// purge 保持期間を過ぎた配信済みのイベントを削除
+func (r *Relay) purge() error {
+	query := `DELETE FROM outbox_events WHERE published_at < DATE_SUB(NOW(3), INTERVAL ? SECOND) LIMIT 1000`
+	for {
+		result, err := r.conn.ExecContext(r.ctx, query, int64(r.opts.Retention.Seconds()))
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil || n < 1000 {
+			return err
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// delivery 送信先が受け取ったイベント
+type delivery struct {
+	id        int64
+	aggregate string
+	at        time.Time
+	err       error
+}
+
+// recordingSink 受け取ったイベントを記録する送信先（failがエラーを返すとその配信は失敗する）
+type recordingSink struct {
+	name string
+	fail func(event *Event, attempt int) error
+
+	mu         sync.Mutex
+	deliveries []delivery
+	attempts   map[int64]int
+}
+
+func newRecordingSink(name string, fail func(event *Event, attempt int) error) *recordingSink {
+	return &recordingSink{name: name, fail: fail, attempts: make(map[int64]int)}
+}
+
+func (s *recordingSink) Name() string {
+	return s.name
+}
+
+func (s *recordingSink) Send(ctx context.Context, event *Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts[event.ID]++
+
+	var err error
+	if s.fail != nil {
+		err = s.fail(event, s.attempts[event.ID])
+	}
+	s.deliveries = append(s.deliveries, delivery{
+		id:        event.ID,
+		aggregate: event.AggregateType + "/" + event.AggregateID,
+		at:        time.Now(),
+		err:       err,
+	})
+	return err
+}
+
+// delivered 成功した配信のイベントID（aggregateが空なら全集約）
+func (s *recordingSink) delivered(aggregate string) []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []int64
+	for _, d := range s.deliveries {
+		if d.err == nil && (aggregate == "" || d.aggregate == aggregate) {
+			ids = append(ids, d.id)
+		}
+	}
+	return ids
+}
+
+// history 失敗も含めた配信の記録
+func (s *recordingSink) history() []delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.deliveries)
+}
+
+// testOptions 短い間隔で確認・再配信する設定
+var testOptions = Options{
+	PollInterval: 5 * time.Millisecond,
+	BatchSize:    2,
+	SendTimeout:  time.Second,
+	BaseBackoff:  30 * time.Millisecond,
+	MaxBackoff:   100 * time.Millisecond,
+}
+
+// startRelay リレーを起動（テストの終了時に停止する）
+func startRelay(t *testing.T, db *sql.DB, sinks ...Sink) {
+	t.Helper()
+	relay := NewRelay(db, sinks, testOptions)
+	relay.Start()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		relay.Shutdown(ctx)
+	})
+}
+
+// write イベントを書き込む
+func write(t *testing.T, db *sql.DB, aggregateID, eventType string) {
+	t.Helper()
+	if err := Write(context.Background(), db, "user", aggregateID, eventType, map[string]string{"id": aggregateID}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+}
+
+// waitPublished すべてのイベントが配信済みになるまで待つ
+func waitPublished(t *testing.T, fake *fakeDB) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for fake.unpublished() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d events are still unpublished", fake.unpublished())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRelayPreservesOrderPerAggregate(t *testing.T) {
+	db, fake := newFakeDB(t)
+	write(t, db, "1", "user.created") // 1
+	write(t, db, "2", "user.created") // 2
+	write(t, db, "1", "user.updated") // 3
+	write(t, db, "2", "user.updated") // 4
+	write(t, db, "1", "user.deleted") // 5
+
+	// user/1 の最初のイベントだけ1回失敗させる
+	sink := newRecordingSink("test", func(event *Event, attempt int) error {
+		if event.ID == 1 && attempt == 1 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+	startRelay(t, db, sink)
+	waitPublished(t, fake)
+
+	if got := sink.delivered("user/1"); !slices.Equal(got, []int64{1, 3, 5}) {
+		t.Errorf("user/1 delivered %v, want [1 3 5]", got)
+	}
+	if got := sink.delivered("user/2"); !slices.Equal(got, []int64{2, 4}) {
+		t.Errorf("user/2 delivered %v, want [2 4]", got)
+	}
+
+	// 失敗したイベントの再配信を待つ間も、他の集約のイベントは先に配信する
+	history := sink.history()
+	retry := slices.IndexFunc(history, func(d delivery) bool { return d.id == 1 && d.err == nil })
+	for _, id := range []int64{2, 4} {
+		if i := slices.IndexFunc(history, func(d delivery) bool { return d.id == id }); i > retry {
+			t.Errorf("event %d was held back behind the failed event of another aggregate: %v", id, history)
+		}
+	}
+	// 同じ集約の後続のイベントは、失敗したイベントが配信されるまで送らない
+	for _, id := range []int64{3, 5} {
+		if i := slices.IndexFunc(history, func(d delivery) bool { return d.id == id }); i < retry {
+			t.Errorf("event %d was sent before event 1 was delivered: %v", id, history)
+		}
+	}
+}
+
+func TestRelayRedeliversAfterSinkFailure(t *testing.T) {
+	db, fake := newFakeDB(t)
+	write(t, db, "1", "user.created")
+
+	// 一部の送信先が失敗すると、成功した送信先にも再配信する（少なくとも1回）
+	ok := newRecordingSink("ok", nil)
+	flaky := newRecordingSink("flaky", func(event *Event, attempt int) error {
+		if attempt <= 2 {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+	startRelay(t, db, ok, flaky)
+	waitPublished(t, fake)
+
+	if got := flaky.delivered(""); !slices.Equal(got, []int64{1}) {
+		t.Errorf("flaky delivered %v, want [1]", got)
+	}
+	if got := ok.delivered(""); !slices.Equal(got, []int64{1, 1, 1}) {
+		t.Errorf("ok delivered %v, want the same event 3 times", got)
+	}
+
+	row := fake.row(1)
+	if row.attempts != 2 || !strings.Contains(row.lastError, "flaky: unavailable") {
+		t.Errorf("attempts = %d, last error = %q", row.attempts, row.lastError)
+	}
+
+	// 再配信は指数バックオフで間隔を空ける
+	history := flaky.history()
+	if d := history[1].at.Sub(history[0].at); d < testOptions.BaseBackoff {
+		t.Errorf("first redelivery after %s, want at least %s", d, testOptions.BaseBackoff)
+	}
+	if d := history[2].at.Sub(history[1].at); d < 2*testOptions.BaseBackoff {
+		t.Errorf("second redelivery after %s, want at least %s", d, 2*testOptions.BaseBackoff)
+	}
+}
+
+func TestRelayRedeliversAfterRestart(t *testing.T) {
+	db, fake := newFakeDB(t)
+	write(t, db, "1", "user.created")
+
+	failing := newRecordingSink("down", func(*Event, int) error { return errors.New("down") })
+	relay := NewRelay(db, []Sink{failing}, testOptions)
+	relay.Start()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(failing.history()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("event was not sent")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := relay.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if fake.unpublished() != 1 {
+		t.Fatal("failed event was marked as published")
+	}
+
+	// 配信できなかったイベントは次に起動したリレーが配信する
+	sink := newRecordingSink("up", nil)
+	startRelay(t, db, sink)
+	waitPublished(t, fake)
+	if got := sink.delivered(""); !slices.Equal(got, []int64{1}) {
+		t.Errorf("delivered %v, want [1]", got)
+	}
+}
+
+func TestRelayOnlyLockHolderDelivers(t *testing.T) {
+	db, fake := newFakeDB(t)
+	sink := newRecordingSink("test", nil)
+
+	first := NewRelay(db, []Sink{sink}, testOptions)
+	first.Start()
+	write(t, db, "1", "user.created")
+	waitPublished(t, fake)
+
+	startRelay(t, db, sink)
+	for i := range 10 {
+		write(t, db, string(rune('a'+i)), "user.created")
+	}
+	waitPublished(t, fake)
+
+	// ロックを外すと待機していたリレーが引き継ぐ
+	if err := first.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	write(t, db, "z", "user.created")
+	waitPublished(t, fake)
+
+	got := sink.delivered("")
+	seen := make(map[int64]bool)
+	for _, id := range got {
+		if seen[id] {
+			t.Errorf("event %d was delivered twice: %v", id, got)
+		}
+		seen[id] = true
+	}
+	if len(got) != 12 {
+		t.Errorf("delivered %d events, want 12", len(got))
+	}
+}
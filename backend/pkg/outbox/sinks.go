@@ -0,0 +1,124 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/redis/go-redis/v9"
+)
+
+// logSink イベントをログに出力する送信先（開発・動作確認用）
+type logSink struct {
+	logger *log.Logger
+}
+
+// NewLogSink イベントをログに出力する送信先を作成（loggerがnilなら標準のロガー）
+func NewLogSink(logger *log.Logger) Sink {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &logSink{logger: logger}
+}
+
+func (s *logSink) Name() string {
+	return "log"
+}
+
+func (s *logSink) Send(ctx context.Context, event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	s.logger.Printf("outbox event: %s", data)
+	return nil
+}
+
+// redisStreamSink イベントをRedis Streamsに追加する送信先
+// 1つのストリームに書き込み順で追加するため、読み手は同じ集約のイベントを順番に受け取れる
+type redisStreamSink struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisStreamSink Redis Streamsの送信先を作成（maxLenが0より大きければ古いエントリをおおよそその件数まで削る）
+func NewRedisStreamSink(client *redis.Client, stream string, maxLen int64) Sink {
+	return &redisStreamSink{
+		client: client,
+		stream: stream,
+		maxLen: maxLen,
+	}
+}
+
+func (s *redisStreamSink) Name() string {
+	return "redis"
+}
+
+func (s *redisStreamSink) Send(ctx context.Context, event *Event) error {
+	args := &redis.XAddArgs{
+		Stream: s.stream,
+		Values: map[string]interface{}{
+			"id":             strconv.FormatInt(event.ID, 10),
+			"type":           event.Type,
+			"aggregate_type": event.AggregateType,
+			"aggregate_id":   event.AggregateID,
+			"occurred_at":    event.CreatedAt.UTC().Format(time.RFC3339Nano),
+			"data":           string(event.Payload),
+		},
+	}
+	if s.maxLen > 0 {
+		args.MaxLen = s.maxLen
+		args.Approx = true
+	}
+	return s.client.XAdd(ctx, args).Err()
+}
+
+// natsSink イベントをNATSに発行する送信先
+// 件名は "<prefix>.<イベントの種類>"（例: events.user.created）。
+// Nats-Msg-Id ヘッダーにイベントIDを付けるため、JetStreamのストリームで受けると再配信の重複が排除される
+type natsSink struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+// NewNATSSink NATSの送信先を作成
+func NewNATSSink(conn *nats.Conn, prefix string) Sink {
+	return &natsSink{
+		conn:   conn,
+		prefix: prefix,
+	}
+}
+
+func (s *natsSink) Name() string {
+	return "nats"
+}
+
+func (s *natsSink) Send(ctx context.Context, event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(s.prefix + "." + event.Type)
+	msg.Header.Set(nats.MsgIdHdr, strconv.FormatInt(event.ID, 10))
+	msg.Data = data
+	if err := s.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+
+	// サーバーに届いたことを確かめてから配信済みにする（FlushWithContextは期限のないctxを受け付けない）
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultOptions.SendTimeout)
+		defer cancel()
+	}
+	if err := s.conn.FlushWithContext(ctx); err != nil {
+		return fmt.Errorf("failed to flush nats connection: %w", err)
+	}
+	return nil
+}
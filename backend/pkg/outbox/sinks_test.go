@@ -0,0 +1,239 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/redis/go-redis/v9"
+)
+
+// testEvent 送信するイベント
+func testEvent(id int64, aggregateID, eventType string) *Event {
+	return &Event{
+		ID:            id,
+		Type:          eventType,
+		AggregateType: "user",
+		AggregateID:   aggregateID,
+		Payload:       json.RawMessage(`{"user":{"id":` + aggregateID + `}}`),
+		CreatedAt:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func newRedisClient(t *testing.T) *redis.Client {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestRedisStreamSink(t *testing.T) {
+	client := newRedisClient(t)
+	sink := NewRedisStreamSink(client, "events", 0)
+	ctx := context.Background()
+
+	if err := sink.Send(ctx, testEvent(7, "42", "user.created")); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	entries, err := client.XRange(ctx, "events", "-", "+").Result()
+	if err != nil {
+		t.Fatalf("XRange: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(entries))
+	}
+	want := map[string]interface{}{
+		"id":             "7",
+		"type":           "user.created",
+		"aggregate_type": "user",
+		"aggregate_id":   "42",
+		"occurred_at":    "2024-01-02T03:04:05Z",
+		"data":           `{"user":{"id":42}}`,
+	}
+	for key, value := range want {
+		if entries[0].Values[key] != value {
+			t.Errorf("%s = %v, want %v", key, entries[0].Values[key], value)
+		}
+	}
+}
+
+func TestRedisStreamSinkTrims(t *testing.T) {
+	client := newRedisClient(t)
+	sink := NewRedisStreamSink(client, "events", 3)
+	ctx := context.Background()
+
+	for id := int64(1); id <= 5; id++ {
+		if err := sink.Send(ctx, testEvent(id, "1", "user.updated")); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+
+	// MAXLEN ~ はおおよその件数のため、上限を超えた分が削られていることだけ確かめる
+	n, err := client.XLen(ctx, "events").Result()
+	if err != nil {
+		t.Fatalf("XLen: %v", err)
+	}
+	if n > 3 {
+		t.Errorf("stream length = %d, want at most 3", n)
+	}
+}
+
+func TestRelayToRedisStream(t *testing.T) {
+	client := newRedisClient(t)
+	db, fake := newFakeDB(t)
+	write(t, db, "1", "user.created")
+	write(t, db, "2", "user.created")
+	write(t, db, "1", "user.updated")
+
+	startRelay(t, db, NewRedisStreamSink(client, "events", 0))
+	waitPublished(t, fake)
+
+	// ストリームには書き込み順に追加される
+	entries, err := client.XRange(context.Background(), "events", "-", "+").Result()
+	if err != nil {
+		t.Fatalf("XRange: %v", err)
+	}
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Values["id"].(string)+":"+entry.Values["type"].(string))
+	}
+	want := []string{"1:user.created", "2:user.created", "3:user.updated"}
+	if len(got) != len(want) {
+		t.Fatalf("entries = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRedisStreamSinkUnavailable(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	mr.Close()
+
+	if err := NewRedisStreamSink(client, "events", 0).Send(context.Background(), testEvent(1, "1", "user.created")); err == nil {
+		t.Error("Send succeeded while redis is down")
+	}
+}
+
+// runNATS JetStreamを有効にした組み込みのNATSサーバーに接続
+func runNATS(t *testing.T) *nats.Conn {
+	t.Helper()
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatalf("start nats server: %v", err)
+	}
+	go srv.Start()
+	t.Cleanup(srv.Shutdown)
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server is not ready")
+	}
+
+	conn, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("connect nats: %v", err)
+	}
+	t.Cleanup(conn.Close)
+	return conn
+}
+
+func TestNATSSink(t *testing.T) {
+	conn := runNATS(t)
+	sub, err := conn.SubscribeSync("events.>")
+	if err != nil {
+		t.Fatalf("SubscribeSync: %v", err)
+	}
+
+	event := testEvent(7, "42", "user.created")
+	if err := NewNATSSink(conn, "events").Send(context.Background(), event); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	msg, err := sub.NextMsg(time.Second)
+	if err != nil {
+		t.Fatalf("NextMsg: %v", err)
+	}
+	if msg.Subject != "events.user.created" {
+		t.Errorf("subject = %q", msg.Subject)
+	}
+	if id := msg.Header.Get(nats.MsgIdHdr); id != "7" {
+		t.Errorf("%s = %q, want 7", nats.MsgIdHdr, id)
+	}
+
+	var got Event
+	if err := json.Unmarshal(msg.Data, &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.ID != 7 || got.Type != event.Type || got.AggregateID != "42" || string(got.Payload) != string(event.Payload) || !got.CreatedAt.Equal(event.CreatedAt) {
+		t.Errorf("event = %+v", got)
+	}
+}
+
+func TestNATSSinkDeduplicatedByJetStream(t *testing.T) {
+	conn := runNATS(t)
+	js, err := conn.JetStream()
+	if err != nil {
+		t.Fatalf("JetStream: %v", err)
+	}
+	if _, err := js.AddStream(&nats.StreamConfig{Name: "EVENTS", Subjects: []string{"events.>"}, Duplicates: time.Minute}); err != nil {
+		t.Fatalf("AddStream: %v", err)
+	}
+
+	// 再配信された同じイベントはストリームに1件だけ残る
+	sink := NewNATSSink(conn, "events")
+	ctx := context.Background()
+	for _, event := range []*Event{testEvent(1, "1", "user.created"), testEvent(1, "1", "user.created"), testEvent(2, "1", "user.updated")} {
+		if err := sink.Send(ctx, event); err != nil {
+			t.Fatalf("Send %d: %v", event.ID, err)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		info, err := js.StreamInfo("EVENTS")
+		if err != nil {
+			t.Fatalf("StreamInfo: %v", err)
+		}
+		if info.State.Msgs == 2 {
+			break
+		}
+		if info.State.Msgs > 2 || time.Now().After(deadline) {
+			t.Fatalf("stream has %d messages, want 2", info.State.Msgs)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	msg, err := js.GetLastMsg("EVENTS", "events.user.updated")
+	if err != nil {
+		t.Fatalf("GetLastMsg: %v", err)
+	}
+	if id := msg.Header.Get(nats.MsgIdHdr); id != strconv.Itoa(2) {
+		t.Errorf("%s = %q, want 2", nats.MsgIdHdr, id)
+	}
+}
+
+func TestNATSSinkClosedConnection(t *testing.T) {
+	conn := runNATS(t)
+	conn.Close()
+
+	if err := NewNATSSink(conn, "events").Send(context.Background(), testEvent(1, "1", "user.created")); err == nil {
+		t.Error("Send succeeded on a closed connection")
+	}
+}
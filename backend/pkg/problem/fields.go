@@ -0,0 +1,106 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// 検証エラーの項目名を構造体のフィールド名ではなくJSONの名前にする
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(jsonFieldName)
+	}
+}
+
+// FieldErrors リクエストのバインドエラーから項目ごとのエラーを取り出す
+// 項目に対応しないエラー（JSONの構文エラーなど）では空を返す
+func FieldErrors(err error) []FieldError {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fields := make([]FieldError, 0, len(validationErrors))
+		for _, fe := range validationErrors {
+			fields = append(fields, FieldError{
+				Field:   fieldPath(fe.Namespace()),
+				Message: validationMessage(fe),
+			})
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{
+			Field:   typeErr.Field,
+			Message: "must be " + typeErr.Type.String(),
+		}}
+	}
+
+	return nil
+}
+
+// fieldPath 先頭の構造体名を除いた項目のパス（例: RegisterRequest.email -> email）
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// validationMessage 検証タグに応じたメッセージ
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		return fmt.Sprintf("must be at least %s", lengthOrValue(fe))
+	case "max":
+		return fmt.Sprintf("must be at most %s", lengthOrValue(fe))
+	case "len":
+		return fmt.Sprintf("must be exactly %s", lengthOrValue(fe))
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "bcp47_language_tag", "eq=|bcp47_language_tag":
+		return "must be a BCP 47 language tag"
+	case "timezone", "eq=|timezone":
+		return "must be an IANA time zone name"
+	default:
+		if fe.Param() != "" {
+			return fmt.Sprintf("failed on %q validation (%s)", fe.Tag(), fe.Param())
+		}
+		return fmt.Sprintf("failed on %q validation", fe.Tag())
+	}
+}
+
+// lengthOrValue min/max の基準（文字列・配列なら長さ）
+func lengthOrValue(fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return fe.Param() + " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return fe.Param() + " items"
+	default:
+		return fe.Param()
+	}
+}
+
+// jsonFieldName json・formタグの名前（未指定ならフィールド名、"-" なら空）
+func jsonFieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		name := strings.SplitN(field.Tag.Get(key), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
@@ -0,0 +1,137 @@
+// Package problem エラーレスポンスを出力する
+// 既定は従来の {error, code} 形式で、Acceptヘッダーまたは設定により RFC 7807 の problem+json を返す
+package problem
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/pkg/requestinfo"
+)
+
+// ContentType RFC 7807 のメディアタイプ
+const ContentType = "application/problem+json"
+
+// Format エラーレスポンスの形式
+type Format string
+
+const (
+	// FormatLegacy 従来の {error, code} 形式
+	FormatLegacy Format = "legacy"
+	// FormatProblem RFC 7807 の problem+json 形式
+	FormatProblem Format = "problem"
+)
+
+// ParseFormat 設定値から形式を取得（空なら従来形式）
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(value))) {
+	case "", FormatLegacy:
+		return FormatLegacy, nil
+	case FormatProblem:
+		return FormatProblem, nil
+	default:
+		return "", fmt.Errorf("unknown error format %q (expected %q or %q)", value, FormatLegacy, FormatProblem)
+	}
+}
+
+// Options エラーレスポンスの設定
+type Options struct {
+	// Format Acceptで problem+json が要求されなかったときの形式
+	Format Format
+	// TypeBaseURL type URI の基底URL（空なら about:blank）
+	TypeBaseURL string
+}
+
+// Response 従来形式のエラーレスポンス
+type Response struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// Details RFC 7807 の問題詳細
+type Details struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Code 従来形式と同じエラーコード（拡張メンバー）
+	Code string `json:"code"`
+	// Errors 項目ごとのエラー（拡張メンバー）
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError 項目ごとの検証エラー
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+const optionsKey = "problem_options"
+
+// WithOptions リクエストにエラーレスポンスの設定を適用
+func WithOptions(c *gin.Context, opts Options) {
+	c.Set(optionsKey, opts)
+}
+
+// Write エラーレスポンスを出力
+// 従来形式では fields を出力しない
+func Write(c *gin.Context, status int, code, detail string, fields ...FieldError) {
+	opts := options(c)
+	if !wantsProblem(c, opts) {
+		c.JSON(status, Response{Error: detail, Code: code})
+		return
+	}
+
+	// c.JSON は設定済みの Content-Type を上書きしない
+	c.Header("Content-Type", ContentType)
+	c.JSON(status, &Details{
+		Type:     typeURI(opts.TypeBaseURL, code),
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: requestinfo.FromContext(c.Request.Context()).RequestID,
+		Code:     code,
+		Errors:   fields,
+	})
+}
+
+// Abort エラーレスポンスを出力して後続のハンドラーを中断
+func Abort(c *gin.Context, status int, code, detail string, fields ...FieldError) {
+	Write(c, status, code, detail, fields...)
+	c.Abort()
+}
+
+// options リクエストに適用された設定（未設定なら従来形式）
+func options(c *gin.Context) Options {
+	if value, ok := c.Get(optionsKey); ok {
+		if opts, ok := value.(Options); ok {
+			return opts
+		}
+	}
+	return Options{Format: FormatLegacy}
+}
+
+// wantsProblem problem+json で返すか（Acceptでの明示的な要求は設定より優先）
+func wantsProblem(c *gin.Context, opts Options) bool {
+	for _, accept := range strings.Split(c.GetHeader("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err == nil && mediaType == ContentType && params["q"] != "0" {
+			return true
+		}
+	}
+	return opts.Format == FormatProblem
+}
+
+// typeURI エラーコードから type URI を作成（例: VALIDATION_ERROR -> <base>/validation-error）
+func typeURI(base, code string) string {
+	if base == "" || code == "" {
+		return "about:blank"
+	}
+	slug := strings.ReplaceAll(strings.ToLower(code), "_", "-")
+	return strings.TrimSuffix(base, "/") + "/" + slug
+}
@@ -0,0 +1,71 @@
+package requestinfo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxIDLength 受け付けるリクエストIDの最大長
+const maxIDLength = 128
+
+// Info 監査ログ等に記録するリクエストのメタデータ
+type Info struct {
+	RequestID string
+	IP        string
+	UserAgent string
+}
+
+type contextKey struct{}
+
+// WithInfo コンテキストにリクエスト情報を設定
+func WithInfo(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+// FromContext コンテキストからリクエスト情報を取得（未設定ならゼロ値）
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(contextKey{}).(Info)
+	return info
+}
+
+// ValidID ログに安全に記録できるリクエストIDか判定
+func ValidID(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// NewID ランダムなリクエストIDを生成
+func NewID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// Truncate ヘッダー由来の値をDBの文字数制限のある列に保存できるようにする
+// 不正なUTF-8を置換文字に置き換え、maxChars文字を超える分を切り捨てる
+func Truncate(value string, maxChars int) string {
+	value = strings.ToValidUTF8(value, "\uFFFD")
+	if utf8.RuneCountInString(value) <= maxChars {
+		return value
+	}
+	n := 0
+	for i := range value {
+		if n == maxChars {
+			return value[:i]
+		}
+		n++
+	}
+	return value
+}
@@ -0,0 +1,34 @@
+package requestinfo
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		maxChars int
+		want     string
+	}{
+		{"short", "curl/8.0", 512, "curl/8.0"},
+		{"exact", strings.Repeat("a", 4), 4, "aaaa"},
+		{"long", strings.Repeat("a", 600), 512, strings.Repeat("a", 512)},
+		{"counts characters not bytes", strings.Repeat("あ", 5), 3, "あああ"},
+		{"invalid utf-8", "ok\xff\xfe", 10, "ok�"},
+		{"empty", "", 10, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Truncate(tt.value, tt.maxChars)
+			if got != tt.want {
+				t.Errorf("Truncate(%q, %d) = %q, want %q", tt.value, tt.maxChars, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("Truncate(%q, %d) returned invalid UTF-8", tt.value, tt.maxChars)
+			}
+		})
+	}
+}
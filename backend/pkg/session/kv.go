@@ -0,0 +1,102 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"app-template/pkg/kvstore"
+)
+
+// kvBackend kvstore（Redis・メモリ）を使ったBackendの実装
+// セッションは session:<ID> にJSONで保存し、有効期限をキーの期限にする
+// キーを列挙できないため、ユーザーのセッションの削除は session_revoked:<ユーザーID> に削除した時刻を記録し、
+// それ以前に作成したセッションを取得時に無効とする
+type kvBackend struct {
+	store kvstore.Store
+	now   func() time.Time
+}
+
+// NewKV キーバリューストアを使ったBackendを作成
+func NewKV(store kvstore.Store) Backend {
+	return &kvBackend{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+func (b *kvBackend) key(id string) string {
+	return "session:" + id
+}
+
+func (b *kvBackend) revokedKey(userID int64) string {
+	return "session_revoked:" + strconv.FormatInt(userID, 10)
+}
+
+// Create セッションを保存
+func (b *kvBackend) Create(ctx context.Context, session *Session) error {
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return b.store.Set(ctx, b.key(session.ID), data, ttl)
+}
+
+// Get セッションを取得
+func (b *kvBackend) Get(ctx context.Context, id string) (*Session, error) {
+	data, err := b.store.Get(ctx, b.key(id))
+	if errors.Is(err, kvstore.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+
+	revoked, err := b.revokedAt(ctx, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !session.CreatedAt.After(revoked) {
+		return nil, ErrNotFound
+	}
+	return &session, nil
+}
+
+// Delete セッションを削除
+func (b *kvBackend) Delete(ctx context.Context, id string) error {
+	return b.store.Delete(ctx, b.key(id))
+}
+
+// DeleteUser 削除した時刻を記録し、それ以前に作成したユーザーのセッションを無効にする
+func (b *kvBackend) DeleteUser(ctx context.Context, userID int64) error {
+	value := strconv.FormatInt(b.now().UnixNano(), 10)
+	return b.store.Set(ctx, b.revokedKey(userID), []byte(value), 0)
+}
+
+// revokedAt ユーザーのセッションを削除した時刻（削除していなければゼロ値）
+func (b *kvBackend) revokedAt(ctx context.Context, userID int64) (time.Time, error) {
+	data, err := b.store.Get(ctx, b.revokedKey(userID))
+	if errors.Is(err, kvstore.ErrNotFound) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	nanos, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, nanos), nil
+}
@@ -0,0 +1,213 @@
+package session
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/pkg/auth"
+	"app-template/pkg/requestinfo"
+)
+
+const (
+	// DefaultCookieName セッションCookieの既定の名前
+	DefaultCookieName = "session"
+	// DefaultTTL セッションの既定の有効期間
+	DefaultTTL = 7 * 24 * time.Hour
+
+	// CSRFCookieName CSRFトークンのCookie名（JavaScriptから読めるよう HttpOnly にしない）
+	CSRFCookieName = "csrf_token"
+	// CSRFHeader 変更系のリクエストでCSRFトークンを送るヘッダー
+	CSRFHeader = "X-CSRF-Token"
+
+	// maxUserAgentLength 保存するUser-Agentの最大文字数（sessions.user_agent）
+	maxUserAgentLength = 255
+)
+
+// Config セッションCookieの設定
+type Config struct {
+	// CookieMode ログインでアクセストークンの代わりにセッションCookieを発行する（AUTH_MODE=cookie）
+	CookieMode bool
+	// CookieName セッションCookieの名前（省略時は DefaultCookieName）
+	CookieName string
+	// TTL セッションの有効期間（省略時は DefaultTTL）
+	TTL time.Duration
+	// Domain Cookieのドメイン（省略時はリクエストのホストのみ）
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// Manager セッションの発行・検証を行う
+// セッションCookieは HttpOnly、CSRFトークンのCookieはJavaScriptから読めるようにして、
+// 変更系のリクエストでは X-CSRF-Token ヘッダーとCSRFトークンのCookieの一致（double-submit）を確認する。
+// CSRFトークンはセッションIDのHMACのため、別のセッションのトークンを差し込まれても一致しない
+type Manager struct {
+	backend Backend
+	secret  []byte
+	cfg     Config
+	now     func() time.Time
+}
+
+var _ auth.CookieAuthenticator = (*Manager)(nil)
+
+// NewManager 保存先とCSRFトークンの署名の鍵でManagerを作成
+func NewManager(backend Backend, secret string, cfg Config) *Manager {
+	if cfg.CookieName == "" {
+		cfg.CookieName = DefaultCookieName
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultTTL
+	}
+	return &Manager{
+		backend: backend,
+		secret:  []byte(secret),
+		cfg:     cfg,
+		now:     time.Now,
+	}
+}
+
+// CookieMode ログインでセッションCookieを発行するか
+func (m *Manager) CookieMode() bool {
+	return m.cfg.CookieMode
+}
+
+// Login セッションを作成してセッションCookieとCSRFトークンのCookieを設定し、CSRFトークンを返す
+// リクエストにセッションCookieがあればそのセッションは削除する（ログインのたびにセッションIDを替え、固定化を防ぐ）
+func (m *Manager) Login(c *gin.Context, userID int64) (string, error) {
+	token, id, err := auth.GenerateSessionToken()
+	if err != nil {
+		return "", err
+	}
+
+	if previous := m.Credential(c.Request); previous != "" {
+		if err := m.backend.Delete(c.Request.Context(), auth.HashSessionToken(previous)); err != nil {
+			return "", fmt.Errorf("failed to delete previous session: %w", err)
+		}
+	}
+
+	now := m.now()
+	session := &Session{
+		ID:        id,
+		UserID:    userID,
+		UserAgent: requestinfo.Truncate(c.Request.UserAgent(), maxUserAgentLength),
+		IPAddress: c.ClientIP(),
+		ExpiresAt: now.Add(m.cfg.TTL),
+		CreatedAt: now,
+	}
+	if err := m.backend.Create(c.Request.Context(), session); err != nil {
+		return "", fmt.Errorf("failed to create session: %w", err)
+	}
+
+	csrfToken := m.csrfToken(id)
+	maxAge := int(m.cfg.TTL / time.Second)
+	m.setCookie(c, m.cfg.CookieName, token, maxAge, true)
+	m.setCookie(c, CSRFCookieName, csrfToken, maxAge, false)
+	return csrfToken, nil
+}
+
+// Logout セッションを削除してCookieを消去する（セッションCookieがなければ何もしない）
+// ログアウトを強制させる攻撃を防ぐため、CSRFトークンが一致しなければErrCSRFTokenInvalid
+func (m *Manager) Logout(c *gin.Context) error {
+	token := m.Credential(c.Request)
+	if token == "" {
+		return nil
+	}
+
+	id := auth.HashSessionToken(token)
+	if !m.validCSRF(c.Request, id) {
+		return auth.ErrCSRFTokenInvalid
+	}
+	if err := m.backend.Delete(c.Request.Context(), id); err != nil {
+		return err
+	}
+
+	m.setCookie(c, m.cfg.CookieName, "", -1, true)
+	m.setCookie(c, CSRFCookieName, "", -1, false)
+	return nil
+}
+
+// RevokeUser ユーザーのセッションをすべて削除（パスワードの再設定など）
+func (m *Manager) RevokeUser(ctx context.Context, userID int64) error {
+	if err := m.backend.DeleteUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// Authenticate セッショントークンを検証（存在しない・期限切れならErrSessionInvalid）
+func (m *Manager) Authenticate(ctx context.Context, credential string) (*auth.Principal, error) {
+	session, err := m.backend.Get(ctx, auth.HashSessionToken(credential))
+	if errors.Is(err, ErrNotFound) {
+		return nil, auth.ErrSessionInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if !m.now().Before(session.ExpiresAt) {
+		return nil, auth.ErrSessionInvalid
+	}
+
+	return &auth.Principal{
+		UserID:    session.UserID,
+		Method:    auth.MethodSession,
+		Role:      auth.RoleUser,
+		SessionID: session.ID,
+	}, nil
+}
+
+// Credential セッションCookieの値を取得
+func (m *Manager) Credential(r *http.Request) string {
+	cookie, err := r.Cookie(m.cfg.CookieName)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// VerifyCSRF X-CSRF-Token ヘッダーがCSRFトークンのCookie・セッションのトークンと一致するか検証
+func (m *Manager) VerifyCSRF(r *http.Request, principal *auth.Principal) error {
+	if principal == nil || !m.validCSRF(r, principal.SessionID) {
+		return auth.ErrCSRFTokenInvalid
+	}
+	return nil
+}
+
+// validCSRF ヘッダーとCookieのCSRFトークンがセッションIDのトークンと一致するか
+func (m *Manager) validCSRF(r *http.Request, sessionID string) bool {
+	header := r.Header.Get(CSRFHeader)
+	cookie, err := r.Cookie(CSRFCookieName)
+	if header == "" || err != nil {
+		return false
+	}
+	expected := []byte(m.csrfToken(sessionID))
+	return hmac.Equal([]byte(header), []byte(cookie.Value)) && hmac.Equal([]byte(header), expected)
+}
+
+// csrfToken セッションIDに結び付いたCSRFトークン（他の用途の署名と区別するため先頭に用途を含める）
+func (m *Manager) csrfToken(sessionID string) string {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte("session-csrf\n" + sessionID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// setCookie Cookieを設定（maxAgeが負なら削除）
+func (m *Manager) setCookie(c *gin.Context, name, value string, maxAge int, httpOnly bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   m.cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   m.cfg.Secure,
+		HttpOnly: httpOnly,
+		SameSite: m.cfg.SameSite,
+	})
+}
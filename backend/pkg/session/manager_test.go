@@ -0,0 +1,232 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/pkg/auth"
+	"app-template/pkg/kvstore"
+)
+
+// testClock 進められる時計
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+// newTestManager メモリのバックエンドを使い、時計を差し替えたManager
+func newTestManager(t *testing.T) (*Manager, *testClock) {
+	t.Helper()
+	clock := &testClock{now: time.Now()}
+	backend := NewKV(kvstore.NewMemory()).(*kvBackend)
+	backend.now = clock.Now
+	m := NewManager(backend, "secret", Config{CookieMode: true})
+	m.now = clock.Now
+	return m, clock
+}
+
+// login セッションを発行し、設定されたCookieとCSRFトークンを返す（cookies は送信するCookie）
+func login(t *testing.T, m *Manager, userID int64, cookies ...*http.Cookie) (map[string]*http.Cookie, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", nil)
+	for _, cookie := range cookies {
+		c.Request.AddCookie(cookie)
+	}
+
+	csrfToken, err := m.Login(c, userID)
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	set := map[string]*http.Cookie{}
+	for _, cookie := range w.Result().Cookies() {
+		set[cookie.Name] = cookie
+	}
+	return set, csrfToken
+}
+
+// authenticated セッションCookieの値で認証できたユーザー（できなければ0）
+func authenticated(t *testing.T, m *Manager, token string) int64 {
+	t.Helper()
+	principal, err := m.Authenticate(context.Background(), token)
+	if errors.Is(err, auth.ErrSessionInvalid) {
+		return 0
+	}
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	return principal.UserID
+}
+
+func TestManagerLoginIssuesSession(t *testing.T) {
+	m, clock := newTestManager(t)
+	cookies, csrfToken := login(t, m, 1)
+
+	session, csrf := cookies[DefaultCookieName], cookies[CSRFCookieName]
+	if session == nil || csrf == nil {
+		t.Fatalf("cookies = %v", cookies)
+	}
+	if !session.HttpOnly || csrf.HttpOnly {
+		t.Errorf("HttpOnly: session = %v, csrf = %v", session.HttpOnly, csrf.HttpOnly)
+	}
+	if csrf.Value != csrfToken || csrfToken == "" {
+		t.Errorf("csrf cookie = %q, returned %q", csrf.Value, csrfToken)
+	}
+	if session.MaxAge != int(DefaultTTL/time.Second) {
+		t.Errorf("MaxAge = %d", session.MaxAge)
+	}
+
+	principal, err := m.Authenticate(context.Background(), session.Value)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if principal.UserID != 1 || principal.Method != auth.MethodSession || principal.SessionID != auth.HashSessionToken(session.Value) {
+		t.Errorf("principal = %+v", principal)
+	}
+	if got := authenticated(t, m, "unknown"); got != 0 {
+		t.Errorf("unknown token authenticated as %d", got)
+	}
+
+	// 有効期間を過ぎると認証できない
+	clock.now = clock.now.Add(DefaultTTL)
+	if got := authenticated(t, m, session.Value); got != 0 {
+		t.Errorf("expired session authenticated as %d", got)
+	}
+}
+
+func TestManagerLoginRotatesSession(t *testing.T) {
+	m, _ := newTestManager(t)
+	first, _ := login(t, m, 1)
+	second, _ := login(t, m, 1, first[DefaultCookieName], first[CSRFCookieName])
+
+	if first[DefaultCookieName].Value == second[DefaultCookieName].Value {
+		t.Fatal("login reused the session token")
+	}
+	if got := authenticated(t, m, first[DefaultCookieName].Value); got != 0 {
+		t.Errorf("previous session still authenticates as %d", got)
+	}
+	if got := authenticated(t, m, second[DefaultCookieName].Value); got != 1 {
+		t.Errorf("new session authenticates as %d, want 1", got)
+	}
+}
+
+func TestManagerRevokeUser(t *testing.T) {
+	m, clock := newTestManager(t)
+	browser, _ := login(t, m, 1)
+	phone, _ := login(t, m, 1)
+	other, _ := login(t, m, 2)
+
+	clock.now = clock.now.Add(time.Second)
+	if err := m.RevokeUser(context.Background(), 1); err != nil {
+		t.Fatalf("RevokeUser: %v", err)
+	}
+	for name, cookies := range map[string]map[string]*http.Cookie{"browser": browser, "phone": phone} {
+		if got := authenticated(t, m, cookies[DefaultCookieName].Value); got != 0 {
+			t.Errorf("%s session survived revocation", name)
+		}
+	}
+	if got := authenticated(t, m, other[DefaultCookieName].Value); got != 2 {
+		t.Errorf("other user's session authenticates as %d, want 2", got)
+	}
+
+	// 失効後のログインは有効
+	clock.now = clock.now.Add(time.Second)
+	again, _ := login(t, m, 1)
+	if got := authenticated(t, m, again[DefaultCookieName].Value); got != 1 {
+		t.Errorf("session after revocation authenticates as %d, want 1", got)
+	}
+}
+
+func TestManagerVerifyCSRF(t *testing.T) {
+	m, _ := newTestManager(t)
+	cookies, csrfToken := login(t, m, 1)
+	principal, err := m.Authenticate(context.Background(), cookies[DefaultCookieName].Value)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	_, otherToken := login(t, m, 2)
+
+	tests := []struct {
+		name      string
+		header    string
+		cookie    string
+		principal *auth.Principal
+		wantErr   bool
+	}{
+		{name: "matching header and cookie", header: csrfToken, cookie: csrfToken, principal: principal},
+		{name: "missing header", cookie: csrfToken, principal: principal, wantErr: true},
+		{name: "missing cookie", header: csrfToken, principal: principal, wantErr: true},
+		{name: "header differs from cookie", header: csrfToken, cookie: otherToken, principal: principal, wantErr: true},
+		// 別のセッションのトークンをヘッダーとCookieの両方に差し込んでも通らない
+		{name: "token of another session", header: otherToken, cookie: otherToken, principal: principal, wantErr: true},
+		{name: "no principal", header: csrfToken, cookie: csrfToken, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/me", nil)
+			if tt.header != "" {
+				r.Header.Set(CSRFHeader, tt.header)
+			}
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: CSRFCookieName, Value: tt.cookie})
+			}
+
+			err := m.VerifyCSRF(r, tt.principal)
+			if tt.wantErr && !errors.Is(err, auth.ErrCSRFTokenInvalid) {
+				t.Errorf("error = %v, want ErrCSRFTokenInvalid", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("error = %v", err)
+			}
+		})
+	}
+}
+
+func TestManagerLogout(t *testing.T) {
+	m, _ := newTestManager(t)
+	cookies, csrfToken := login(t, m, 1)
+
+	logout := func(header string) (*httptest.ResponseRecorder, error) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+		c.Request.AddCookie(cookies[DefaultCookieName])
+		c.Request.AddCookie(cookies[CSRFCookieName])
+		if header != "" {
+			c.Request.Header.Set(CSRFHeader, header)
+		}
+		return w, m.Logout(c)
+	}
+
+	// ログアウトを強制させる攻撃を防ぐため、CSRFトークンがなければセッションを残す
+	if _, err := logout(""); !errors.Is(err, auth.ErrCSRFTokenInvalid) {
+		t.Fatalf("logout without csrf: error = %v", err)
+	}
+	if got := authenticated(t, m, cookies[DefaultCookieName].Value); got != 1 {
+		t.Fatal("session was deleted without a csrf token")
+	}
+
+	w, err := logout(csrfToken)
+	if err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if got := authenticated(t, m, cookies[DefaultCookieName].Value); got != 0 {
+		t.Error("session survived logout")
+	}
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.MaxAge >= 0 {
+			t.Errorf("cookie %s was not cleared", cookie.Name)
+		}
+	}
+}
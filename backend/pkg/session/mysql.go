@@ -0,0 +1,93 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// mysqlBackend MySQLのsessionsテーブルを使ったBackendの実装
+type mysqlBackend struct {
+	db *sql.DB
+}
+
+// NewMySQL データベース接続を使ったBackendを作成
+func NewMySQL(db *sql.DB) Backend {
+	return &mysqlBackend{
+		db: db,
+	}
+}
+
+// Create セッションを保存し、同じユーザーの期限切れのセッションを削除する
+func (b *mysqlBackend) Create(ctx context.Context, session *Session) error {
+	query := `
+		INSERT INTO sessions (id, user_id, user_agent, ip_address, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	_, err := b.db.ExecContext(ctx, query,
+		session.ID,
+		session.UserID,
+		nullString(session.UserAgent),
+		nullString(session.IPAddress),
+		session.ExpiresAt,
+		session.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert session: %w", err)
+	}
+
+	if _, err := b.db.ExecContext(ctx, "DELETE FROM sessions WHERE user_id = ? AND expires_at <= ?", session.UserID, session.CreatedAt); err != nil {
+		return fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+	return nil
+}
+
+// Get 有効期限内のセッションを取得
+func (b *mysqlBackend) Get(ctx context.Context, id string) (*Session, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip_address, expires_at, created_at
+		FROM sessions
+		WHERE id = ? AND expires_at > NOW()
+	`
+	var session Session
+	var userAgent, ipAddress sql.NullString
+	err := b.db.QueryRowContext(ctx, query, id).Scan(
+		&session.ID,
+		&session.UserID,
+		&userAgent,
+		&ipAddress,
+		&session.ExpiresAt,
+		&session.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	session.UserAgent = userAgent.String
+	session.IPAddress = ipAddress.String
+	return &session, nil
+}
+
+// Delete セッションを削除
+func (b *mysqlBackend) Delete(ctx context.Context, id string) error {
+	if _, err := b.db.ExecContext(ctx, "DELETE FROM sessions WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// DeleteUser ユーザーのセッションをすべて削除
+func (b *mysqlBackend) DeleteUser(ctx context.Context, userID int64) error {
+	if _, err := b.db.ExecContext(ctx, "DELETE FROM sessions WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to delete user sessions: %w", err)
+	}
+	return nil
+}
+
+// nullString 空文字列をNULLとして保存する
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
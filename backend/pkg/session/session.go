@@ -0,0 +1,63 @@
+// Package session Cookie認証モードのサーバー側セッション
+// ログインで発行したセッショントークンを HttpOnly のCookieに入れ、保存先にはトークンのハッシュをIDとして保存する。
+// 変更系のリクエストはCSRFトークン（double-submit）で保護する。保存先（バックエンド）はMySQL・Redis・メモリから選べる
+package session
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"app-template/pkg/kvstore"
+)
+
+// ErrNotFound セッションが存在しない、または期限切れ
+var ErrNotFound = errors.New("session: not found")
+
+// Session ログインセッション
+type Session struct {
+	// ID セッショントークンのハッシュ（auth.HashSessionToken）
+	ID        string    `json:"id"`
+	UserID    int64     `json:"user_id"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	IPAddress string    `json:"ip_address,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Backend セッションの保存先
+type Backend interface {
+	// Create セッションを保存
+	Create(ctx context.Context, session *Session) error
+	// Get 有効期限内のセッションを取得（存在しない・期限切れならErrNotFound）
+	Get(ctx context.Context, id string) (*Session, error)
+	// Delete セッションを削除（存在しなくてもエラーにしない）
+	Delete(ctx context.Context, id string) error
+	// DeleteUser ユーザーのセッションをすべて削除
+	DeleteUser(ctx context.Context, userID int64) error
+}
+
+// Connect 環境変数 SESSION_BACKEND（mysql / redis / memory、既定は mysql）でバックエンドを選ぶ
+// redis は kvstore と同じ REDIS_HOST などの設定で接続する
+func Connect(ctx context.Context, db *sql.DB) (Backend, error) {
+	switch backend := os.Getenv("SESSION_BACKEND"); backend {
+	case "", "mysql":
+		return NewMySQL(db), nil
+	case "redis":
+		client, err := kvstore.ConnectRedis(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if client == nil {
+			return nil, errors.New("session: REDIS_HOST is required for the redis backend")
+		}
+		return NewKV(kvstore.NewRedis(client)), nil
+	case "memory":
+		return NewKV(kvstore.NewMemory()), nil
+	default:
+		return nil, fmt.Errorf("session: unknown backend %q", backend)
+	}
+}
@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// uploadPrefix 書き込み中の一時ファイルの名前の接頭辞（一覧には含めない）
+const uploadPrefix = ".upload-"
+
+// localStorage ローカルのファイルシステムに保存するバックエンド
+// キーはルートディレクトリからの相対パスになる。Content-Type は保存せず拡張子から決める
+type localStorage struct {
+	root   string
+	signer *URLSigner
+}
+
+// NewLocal ルートディレクトリ（なければ作成する）に保存するバックエンドを作成
+// 署名付きURLは signer で署名する（nilなら PresignGet はエラー）
+func NewLocal(root string, signer *URLSigner) (Storage, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to resolve %s: %w", root, err)
+	}
+	if err := os.MkdirAll(abs, 0o755); err != nil {
+		return nil, fmt.Errorf("storage: failed to create %s: %w", abs, err)
+	}
+	return &localStorage{root: abs, signer: signer}, nil
+}
+
+// Put 一時ファイルに書き込んでから置き換える（読み込み中のオブジェクトが途中の内容にならない）
+func (s *localStorage) Put(ctx context.Context, key string, body io.Reader, opts PutOptions) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("storage: failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), uploadPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("storage: failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("storage: failed to write %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("storage: failed to write %s: %w", key, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("storage: failed to write %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("storage: failed to write %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get ファイルを開く
+func (s *localStorage) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("storage: failed to open %s: %w", key, err)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("storage: failed to stat %s: %w", key, err)
+	}
+	if stat.IsDir() {
+		file.Close()
+		return nil, nil, ErrNotFound
+	}
+
+	return file, s.info(key, stat), nil
+}
+
+// Stat ファイルの情報を取得
+func (s *localStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(name)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && stat.IsDir()) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to stat %s: %w", key, err)
+	}
+	return s.info(key, stat), nil
+}
+
+// Delete ファイルを削除
+func (s *localStorage) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage: failed to delete %s: %w", key, err)
+	}
+
+	// 空になったディレクトリをルートの手前まで削除する（空でなければ削除されない）
+	for dir := filepath.Dir(name); dir != s.root && strings.HasPrefix(dir, s.root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// List prefix で始まるキーのファイルを取得（prefix のディレクトリ以下だけをたどる）
+func (s *localStorage) List(ctx context.Context, prefix string) ([]*ObjectInfo, error) {
+	prefix = strings.TrimPrefix(prefix, "/")
+	start := s.root
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		dir := prefix[:i]
+		if _, err := cleanKey(dir); err != nil {
+			return nil, ErrInvalidKey
+		}
+		start = filepath.Join(s.root, filepath.FromSlash(dir))
+	}
+
+	objects := []*ObjectInfo{}
+	err := filepath.WalkDir(start, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), uploadPrefix) {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, name)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		stat, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		objects = append(objects, s.info(key, stat))
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to list %s: %w", prefix, err)
+	}
+
+	// WalkDir はディレクトリごとの名前順のため、キー全体の順に並べ直す
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// PresignGet DownloadHandler で配信する署名付きURLを発行
+func (s *localStorage) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return presignLocal(s.signer, key, expires)
+}
+
+// path キーに対応するファイルのパス
+func (s *localStorage) path(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// info ファイルの情報からオブジェクトの情報を作る
+func (s *localStorage) info(key string, stat fs.FileInfo) *ObjectInfo {
+	key, _ = cleanKey(key)
+	return &ObjectInfo{
+		Key:         key,
+		Size:        stat.Size(),
+		ContentType: mime.TypeByExtension(path.Ext(key)),
+		ModTime:     stat.ModTime(),
+	}
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryObject メモリに保存したオブジェクト
+type memoryObject struct {
+	data        []byte
+	contentType string
+	modTime     time.Time
+}
+
+// memoryStorage プロセス内のメモリに保存するバックエンド（開発・テスト用。再起動で消える）
+type memoryStorage struct {
+	mu      sync.RWMutex
+	objects map[string]*memoryObject
+	signer  *URLSigner
+}
+
+// NewMemory メモリに保存するバックエンドを作成
+// 署名付きURLは signer で署名する（nilなら PresignGet はエラー）
+func NewMemory(signer *URLSigner) Storage {
+	return &memoryStorage{
+		objects: make(map[string]*memoryObject),
+		signer:  signer,
+	}
+}
+
+// Put 内容を読み込んで保存
+func (s *memoryStorage) Put(ctx context.Context, key string, body io.Reader, opts PutOptions) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("storage: failed to read %s: %w", key, err)
+	}
+
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(key))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = &memoryObject{data: data, contentType: contentType, modTime: time.Now()}
+	return nil
+}
+
+// Get 保存した内容を返す（保存後に置き換えられても、返した内容は変わらない）
+func (s *memoryStorage) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	object, ok := s.objects[key]
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+	return readSeekNopCloser{bytes.NewReader(object.data)}, object.info(key), nil
+}
+
+// Stat オブジェクトの情報を取得
+func (s *memoryStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	object, ok := s.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return object.info(key), nil
+}
+
+// Delete オブジェクトを削除
+func (s *memoryStorage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+// List prefix で始まるキーのオブジェクトを取得
+func (s *memoryStorage) List(ctx context.Context, prefix string) ([]*ObjectInfo, error) {
+	prefix = strings.TrimPrefix(prefix, "/")
+
+	s.mu.RLock()
+	objects := []*ObjectInfo{}
+	for key, object := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, object.info(key))
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// PresignGet DownloadHandler で配信する署名付きURLを発行
+func (s *memoryStorage) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return presignLocal(s.signer, key, expires)
+}
+
+// info オブジェクトの情報
+func (o *memoryObject) info(key string) *ObjectInfo {
+	return &ObjectInfo{
+		Key:         key,
+		Size:        int64(len(o.data)),
+		ContentType: o.contentType,
+		ModTime:     o.modTime,
+	}
+}
+
+// readSeekNopCloser Closeで何もしない io.ReadSeekCloser（DownloadHandler でシークできるようにする）
+type readSeekNopCloser struct {
+	io.ReadSeeker
+}
+
+// Close 何もしない
+func (readSeekNopCloser) Close() error {
+	return nil
+}
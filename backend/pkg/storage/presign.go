@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"app-template/pkg/problem"
+)
+
+// DefaultDownloadPath DownloadHandler を配置する既定のパス
+const DefaultDownloadPath = "/api/v1/files"
+
+var (
+	// ErrInvalidSignature 署名付きURLの署名が一致しない
+	ErrInvalidSignature = errors.New("storage: invalid signature")
+	// ErrURLExpired 署名付きURLの有効期限を過ぎている
+	ErrURLExpired = errors.New("storage: url expired")
+)
+
+// URLSigner ローカル・メモリのバックエンドの署名付きURLをHMAC-SHA256で署名・検証する
+// URLは {baseURL}/{key}?expires={UNIX時刻}&signature={署名} の形式で、キーと有効期限を署名する
+type URLSigner struct {
+	secret  []byte
+	baseURL string
+}
+
+// NewURLSigner 署名の鍵とダウンロードのURL（DownloadHandler を配置したパス、またはその絶対URL）で作成
+func NewURLSigner(secret, baseURL string) *URLSigner {
+	return &URLSigner{
+		secret:  []byte(secret),
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// LoadURLSigner 環境変数 STORAGE_BASE_URL（既定は DefaultDownloadPath）のURLで、secret を鍵とする
+func LoadURLSigner(secret string) *URLSigner {
+	baseURL := os.Getenv("STORAGE_BASE_URL")
+	if baseURL == "" {
+		baseURL = DefaultDownloadPath
+	}
+	return NewURLSigner(secret, baseURL)
+}
+
+// Sign expiresAt まで有効なキーのダウンロードURLを作成
+func (s *URLSigner) Sign(key string, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	return s.baseURL + "/" + uriEscape(key, false) + "?expires=" + expires + "&signature=" + s.signature(key, expires)
+}
+
+// Verify 署名と有効期限を検証
+func (s *URLSigner) Verify(key, expires, signature string, now time.Time) error {
+	if !hmac.Equal([]byte(signature), []byte(s.signature(key, expires))) {
+		return ErrInvalidSignature
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if now.Unix() >= expiresAt {
+		return ErrURLExpired
+	}
+	return nil
+}
+
+// signature キーと有効期限の署名（他の用途の署名と区別するため先頭に用途を含める）
+func (s *URLSigner) signature(key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("storage-download\n" + key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// presignLocal ローカル・メモリのバックエンドの署名付きURLを発行
+func presignLocal(signer *URLSigner, key string, expires time.Duration) (string, error) {
+	if signer == nil {
+		return "", errors.New("storage: presigned urls require a URLSigner")
+	}
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if err := checkExpiry(expires); err != nil {
+		return "", err
+	}
+	return signer.Sign(key, time.Now().Add(expires)), nil
+}
+
+// DownloadHandler URLSigner で署名したURLのオブジェクトを配信するハンドラー（GET / HEAD）
+// キーはパスパラメータ key（"/files/*key" のように配置する）から取得し、署名と有効期限を検証してから返す
+func DownloadHandler(store Storage, signer *URLSigner) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := cleanKey(c.Param("key"))
+		if err != nil {
+			problem.Write(c, http.StatusNotFound, "FILE_NOT_FOUND", "file not found")
+			return
+		}
+
+		err = signer.Verify(key, c.Query("expires"), c.Query("signature"), time.Now())
+		switch {
+		case errors.Is(err, ErrURLExpired):
+			problem.Write(c, http.StatusForbidden, "URL_EXPIRED", "download url has expired")
+			return
+		case err != nil:
+			problem.Write(c, http.StatusForbidden, "INVALID_SIGNATURE", "download url signature is invalid")
+			return
+		}
+
+		body, info, err := store.Get(c.Request.Context(), key)
+		if errors.Is(err, ErrNotFound) {
+			problem.Write(c, http.StatusNotFound, "FILE_NOT_FOUND", "file not found")
+			return
+		}
+		if err != nil {
+			problem.Write(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+			return
+		}
+		defer body.Close()
+
+		if info.ContentType != "" {
+			c.Header("Content-Type", info.ContentType)
+		}
+		// URLは署名した本人向けのため共有キャッシュには残さない
+		c.Header("Cache-Control", "private, no-store")
+		c.Header("X-Content-Type-Options", "nosniff")
+
+		// シークできる内容（ファイル・メモリ）は Range や条件付きリクエストに対応する
+		if seeker, ok := body.(io.ReadSeeker); ok {
+			http.ServeContent(c.Writer, c.Request, "", info.ModTime, seeker)
+			return
+		}
+
+		if info.ContentType == "" {
+			c.Header("Content-Type", "application/octet-stream")
+		}
+		c.Header("Content-Length", strconv.FormatInt(info.Size, 10))
+		if !info.ModTime.IsZero() {
+			c.Header("Last-Modified", info.ModTime.UTC().Format(http.TimeFormat))
+		}
+		c.Status(http.StatusOK)
+		if c.Request.Method != http.MethodHead {
+			io.Copy(c.Writer, body)
+		}
+	}
+}
@@ -0,0 +1,531 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// emptyPayloadHash 本文なしのリクエストの x-amz-content-sha256
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	// unsignedPayload 署名付きURLでは本文を署名しない
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	// signingAlgorithm 署名バージョン4のアルゴリズム名
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+)
+
+// S3Config S3互換ストレージの接続設定
+type S3Config struct {
+	// Endpoint 例: http://localhost:9000（MinIO）。省略時は AWS の https://s3.{Region}.amazonaws.com
+	Endpoint string
+	// PublicEndpoint 署名付きURLに使うエンドポイント（省略時は Endpoint。コンテナ内とブラウザでホスト名が違う場合に指定する）
+	PublicEndpoint  string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PathStyle バケットをホスト名ではなくパスで指定する（MinIO などで使う）
+	PathStyle  bool
+	HTTPClient *http.Client
+}
+
+// LoadS3Config 環境変数（S3_ENDPOINT / S3_PUBLIC_ENDPOINT / S3_REGION / S3_BUCKET / S3_ACCESS_KEY_ID / S3_SECRET_ACCESS_KEY / S3_PATH_STYLE）から設定を読み込む
+func LoadS3Config() S3Config {
+	region := os.Getenv("S3_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+	pathStyle, _ := strconv.ParseBool(os.Getenv("S3_PATH_STYLE"))
+
+	return S3Config{
+		Endpoint:        os.Getenv("S3_ENDPOINT"),
+		PublicEndpoint:  os.Getenv("S3_PUBLIC_ENDPOINT"),
+		Region:          region,
+		Bucket:          os.Getenv("S3_BUCKET"),
+		AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		PathStyle:       pathStyle,
+	}
+}
+
+// s3Storage S3互換のオブジェクトストレージに保存するバックエンド
+// リクエストは署名バージョン4（AWS SigV4）で署名する
+type s3Storage struct {
+	cfg            S3Config
+	endpoint       *url.URL
+	publicEndpoint *url.URL
+	client         *http.Client
+	now            func() time.Time
+}
+
+// NewS3 S3互換ストレージのバックエンドを作成
+func NewS3(cfg S3Config) (Storage, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("storage: S3_BUCKET is required for the s3 backend")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("storage: S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required for the s3 backend")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+
+	endpoint, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("storage: invalid S3_ENDPOINT %q", cfg.Endpoint)
+	}
+	publicEndpoint := endpoint
+	if cfg.PublicEndpoint != "" {
+		publicEndpoint, err = url.Parse(strings.TrimSuffix(cfg.PublicEndpoint, "/"))
+		if err != nil || publicEndpoint.Host == "" {
+			return nil, fmt.Errorf("storage: invalid S3_PUBLIC_ENDPOINT %q", cfg.PublicEndpoint)
+		}
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+
+	return &s3Storage{cfg: cfg, endpoint: endpoint, publicEndpoint: publicEndpoint, client: client, now: time.Now}, nil
+}
+
+// Put オブジェクトをアップロード（署名に本文のハッシュが必要なため、シークできない本文はメモリに読み込む）
+func (s *s3Storage) Put(ctx context.Context, key string, body io.Reader, opts PutOptions) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	seeker, ok := body.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return fmt.Errorf("storage: failed to read %s: %w", key, err)
+		}
+		seeker = bytes.NewReader(data)
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("storage: failed to read %s: %w", key, err)
+	}
+	hash := sha256.New()
+	size, err := io.Copy(hash, seeker)
+	if err != nil {
+		return fmt.Errorf("storage: failed to read %s: %w", key, err)
+	}
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return fmt.Errorf("storage: failed to read %s: %w", key, err)
+	}
+
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(key))
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPut, s.endpoint, key, nil)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Body = io.NopCloser(seeker)
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req, hex.EncodeToString(hash.Sum(nil)))
+	if err != nil {
+		return fmt.Errorf("storage: failed to put %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("storage: failed to put %s: %w", key, s3Error(resp))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Get オブジェクトをダウンロード
+func (s *s3Storage) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.newRequest(ctx, http.MethodGet, s.endpoint, key, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("storage: failed to get %s: %w", key, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, objectInfo(key, resp.Header), nil
+	case http.StatusNotFound:
+		// バケットがない場合はキーがない場合と区別する
+		err := s3Error(resp)
+		resp.Body.Close()
+		if isNoSuchBucket(err) {
+			return nil, nil, fmt.Errorf("storage: failed to get %s: %w", key, err)
+		}
+		return nil, nil, ErrNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, nil, fmt.Errorf("storage: failed to get %s: %w", key, s3Error(resp))
+	}
+}
+
+// Stat HEAD リクエストでオブジェクトの情報を取得
+func (s *s3Storage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.newRequest(ctx, http.MethodHead, s.endpoint, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to stat %s: %w", key, err)
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return objectInfo(key, resp.Header), nil
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		// HEAD のレスポンスには本文がないためステータスのみ返す
+		return nil, fmt.Errorf("storage: failed to stat %s: unexpected status %d", key, resp.StatusCode)
+	}
+}
+
+// Delete オブジェクトを削除（S3は存在しないキーの削除も成功として扱う。バケットがなければエラー）
+func (s *s3Storage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	req, err := s.newRequest(ctx, http.MethodDelete, s.endpoint, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return fmt.Errorf("storage: failed to delete %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	case http.StatusNotFound:
+		// キーがない場合は成功とし、バケットがない場合はエラーにする
+		if err := s3Error(resp); isNoSuchBucket(err) {
+			return fmt.Errorf("storage: failed to delete %s: %w", key, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("storage: failed to delete %s: %w", key, s3Error(resp))
+	}
+}
+
+// List ListObjectsV2 で prefix で始まるキーを最後のページまで取得（Content-Type は含まない）
+func (s *s3Storage) List(ctx context.Context, prefix string) ([]*ObjectInfo, error) {
+	prefix = strings.TrimPrefix(prefix, "/")
+
+	objects := []*ObjectInfo{}
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		req, err := s.newRequest(ctx, http.MethodGet, s.endpoint, "", query)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.do(req, emptyPayloadHash)
+		if err != nil {
+			return nil, fmt.Errorf("storage: failed to list %s: %w", prefix, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := s3Error(resp)
+			resp.Body.Close()
+			return nil, fmt.Errorf("storage: failed to list %s: %w", prefix, err)
+		}
+
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("storage: failed to decode list of %s: %w", prefix, err)
+		}
+
+		for _, content := range result.Contents {
+			objects = append(objects, &ObjectInfo{
+				Key:     content.Key,
+				Size:    content.Size,
+				ModTime: content.LastModified,
+			})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// PresignGet クエリ文字列で署名したダウンロードURLを発行（PublicEndpoint のホストで署名する）
+func (s *s3Storage) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if err := checkExpiry(expires); err != nil {
+		return "", err
+	}
+
+	now := s.now().UTC()
+	query := url.Values{
+		"X-Amz-Algorithm":     {signingAlgorithm},
+		"X-Amz-Credential":    {s.cfg.AccessKeyID + "/" + s.scope(now)},
+		"X-Amz-Date":          {now.Format("20060102T150405Z")},
+		"X-Amz-Expires":       {strconv.Itoa(int(expires / time.Second))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	req, err := s.newRequest(ctx, http.MethodGet, s.publicEndpoint, key, query)
+	if err != nil {
+		return "", err
+	}
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+	signature := s.signature(now, s.stringToSign(now, canonicalRequest))
+
+	return req.URL.String() + "&X-Amz-Signature=" + signature, nil
+}
+
+// newRequest エンドポイントのキー（空ならバケット自体）へのリクエストを作成
+func (s *s3Storage) newRequest(ctx context.Context, method string, endpoint *url.URL, key string, query url.Values) (*http.Request, error) {
+	u := *endpoint
+	if s.cfg.PathStyle {
+		u.Path = endpoint.Path + "/" + s.cfg.Bucket
+		u.RawPath = endpoint.EscapedPath() + "/" + uriEscape(s.cfg.Bucket, true)
+	} else {
+		u.Host = s.cfg.Bucket + "." + endpoint.Host
+		u.Path = endpoint.Path
+		u.RawPath = endpoint.EscapedPath()
+	}
+	if key != "" || !s.cfg.PathStyle {
+		u.Path += "/" + key
+		u.RawPath += "/" + uriEscape(key, false)
+	}
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to create request: %w", err)
+	}
+	return req, nil
+}
+
+// do リクエストに署名して送信
+func (s *s3Storage) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	s.sign(req, payloadHash, s.now().UTC())
+	return s.client.Do(req)
+}
+
+// sign Authorization ヘッダーで署名する（host / x-amz-* / Content-Type ヘッダーを署名対象にする）
+func (s *s3Storage) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	signature := s.signature(now, s.stringToSign(now, canonicalRequest))
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgorithm, s.cfg.AccessKeyID, s.scope(now), signedHeaders, signature,
+	))
+}
+
+// stringToSign 署名対象の文字列
+func (s *s3Storage) stringToSign(now time.Time, canonicalRequest string) string {
+	return signingAlgorithm + "\n" + now.Format("20060102T150405Z") + "\n" + s.scope(now) + "\n" + sha256Hex(canonicalRequest)
+}
+
+// scope 署名の対象範囲（日付/リージョン/s3/aws4_request）
+func (s *s3Storage) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.cfg.Region + "/s3/aws4_request"
+}
+
+// signature 日付・リージョンから導出した鍵で署名対象の文字列に署名する
+func (s *s3Storage) signature(now time.Time, stringToSign string) string {
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// objectInfo レスポンスヘッダーからオブジェクトの情報を作る
+func objectInfo(key string, header http.Header) *ObjectInfo {
+	info := &ObjectInfo{
+		Key:         key,
+		ContentType: header.Get("Content-Type"),
+	}
+	if size, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64); err == nil {
+		info.Size = size
+	}
+	if modTime, err := http.ParseTime(header.Get("Last-Modified")); err == nil {
+		info.ModTime = modTime
+	}
+	return info
+}
+
+// s3ListResult ListObjectsV2 のレスポンス
+type s3ListResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// s3ErrorResponse S3のエラーレスポンス
+type s3ErrorResponse struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// s3APIError S3が返したエラー
+type s3APIError struct {
+	Code       string
+	Message    string
+	StatusCode int
+}
+
+func (e *s3APIError) Error() string {
+	return fmt.Sprintf("%s: %s (status %d)", e.Code, e.Message, e.StatusCode)
+}
+
+// s3Error エラーレスポンスの内容をエラーにする
+func s3Error(resp *http.Response) error {
+	var body s3ErrorResponse
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err := xml.Unmarshal(data, &body); err != nil || body.Code == "" {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return &s3APIError{Code: body.Code, Message: body.Message, StatusCode: resp.StatusCode}
+}
+
+// isNoSuchBucket バケットが存在しないエラーか
+func isNoSuchBucket(err error) bool {
+	var apiErr *s3APIError
+	return errors.As(err, &apiErr) && apiErr.Code == "NoSuchBucket"
+}
+
+// canonicalQuery キーの順に並べてエンコードしたクエリ文字列
+func canonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, uriEscape(key, true)+"="+uriEscape(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// hmacSHA256 HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// sha256Hex SHA-256 の16進表記
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
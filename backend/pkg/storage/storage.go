@@ -0,0 +1,117 @@
+// Package storage アバター画像・エクスポート・添付ファイルなどのファイル（オブジェクト）の保存先
+// オブジェクトは "avatars/1/abc/256" のようなスラッシュ区切りのキーで扱う。
+// 保存先（バックエンド）はローカルのファイルシステム・メモリ・S3互換のオブジェクトストレージから選べる
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// MaxPresignExpiry 署名付きURLの有効期間の上限（S3と同じ7日）
+const MaxPresignExpiry = 7 * 24 * time.Hour
+
+var (
+	// ErrNotFound オブジェクトが存在しない
+	ErrNotFound = errors.New("storage: object not found")
+	// ErrInvalidKey キーが空、または "." / ".." を含むなど不正
+	ErrInvalidKey = errors.New("storage: invalid key")
+	// ErrInvalidExpiry 署名付きURLの有効期間が1秒未満、またはMaxPresignExpiryを超えている
+	ErrInvalidExpiry = errors.New("storage: presign expiry must be between 1s and 7 days")
+)
+
+// ObjectInfo オブジェクトの情報
+type ObjectInfo struct {
+	Key  string
+	Size int64
+	// ContentType 不明なら空（S3の一覧では取得しない）
+	ContentType string
+	ModTime     time.Time
+}
+
+// PutOptions 保存時のオプション
+type PutOptions struct {
+	// ContentType 省略時はキーの拡張子から決める（ローカルのバックエンドは保存せず、常に拡張子から決める）
+	ContentType string
+}
+
+// Storage オブジェクトの保存先
+type Storage interface {
+	// Put オブジェクトを保存（同じキーがあれば置き換える）
+	Put(ctx context.Context, key string, body io.Reader, opts PutOptions) error
+	// Get オブジェクトの内容を取得（存在しなければErrNotFound。呼び出し側でCloseする）
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	// Stat オブジェクトの情報を取得（存在しなければErrNotFound）
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// Delete オブジェクトを削除（存在しなくてもエラーにしない）
+	Delete(ctx context.Context, key string) error
+	// List prefix で始まるキーのオブジェクトをキーの順に取得
+	List(ctx context.Context, prefix string) ([]*ObjectInfo, error)
+	// PresignGet 認証なしで expires の間だけダウンロードできるURLを発行
+	PresignGet(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+// Connect 環境変数 STORAGE_BACKEND（local / memory / s3、既定は local）でバックエンドを選ぶ
+// local は STORAGE_LOCAL_DIR（既定は ./storage）、s3 は S3_* の設定を使う。
+// local・memory の署名付きURLは signer で署名し、DownloadHandler で配信する
+func Connect(signer *URLSigner) (Storage, error) {
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "./storage"
+		}
+		return NewLocal(dir, signer)
+	case "memory":
+		return NewMemory(signer), nil
+	case "s3":
+		return NewS3(LoadS3Config())
+	default:
+		return nil, fmt.Errorf("storage: unknown STORAGE_BACKEND %q", backend)
+	}
+}
+
+// cleanKey キーを検証して正規化する（先頭の "/" は取り除く）
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || strings.Contains(key, "\\") || strings.ContainsRune(key, 0) {
+		return "", ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+	return path.Clean(key), nil
+}
+
+// checkExpiry 署名付きURLの有効期間を検証
+func checkExpiry(expires time.Duration) error {
+	if expires < time.Second || expires > MaxPresignExpiry {
+		return ErrInvalidExpiry
+	}
+	return nil
+}
+
+// uriEscape RFC 3986 の規則でエンコードする（英数字と -_.~ 以外。encodeSlash が false なら "/" は残す）
+func uriEscape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,147 @@
+// Package tenant マルチテナントのテナント（組織）コンテキスト
+// リクエストのテナントは JWT の org_id クレーム・ヘッダー・サブドメインから解決してコンテキストに設定し、
+// リポジトリはコンテキストのテナントでクエリを自動的に絞り込む
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"app-template/pkg/auth"
+)
+
+// DefaultHeader テナントを指定するヘッダー（組織IDまたはスラッグ）
+const DefaultHeader = "X-Organization"
+
+// テナント解決のエラー
+var (
+	ErrNotFound  = errors.New("organization not found")
+	ErrNotMember = errors.New("not a member of the organization")
+	ErrMismatch  = errors.New("requested organization does not match the token")
+	ErrRequired  = errors.New("organization is required")
+)
+
+// Tenant リクエストのテナント
+type Tenant struct {
+	ID   int64
+	Slug string
+	// Role 組織でのロール（メンバーでないシステム管理者は空）
+	Role string
+	// UserID 個人のテナントの主体（組織に所属しない主体は自分自身だけを対象にする。IDは0）
+	UserID int64
+}
+
+// Personal 組織に所属しない主体の個人のテナント
+func Personal(userID int64) *Tenant {
+	return &Tenant{UserID: userID}
+}
+
+// IsPersonal 個人のテナントか
+func (t *Tenant) IsPersonal() bool {
+	return t.ID == 0 && t.UserID != 0
+}
+
+type tenantContextKey struct{}
+
+// WithTenant コンテキストにテナントを設定
+func WithTenant(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, t)
+}
+
+// FromContext コンテキストからテナントを取得
+func FromContext(ctx context.Context) (*Tenant, bool) {
+	t, ok := ctx.Value(tenantContextKey{}).(*Tenant)
+	return t, ok && t != nil
+}
+
+// Resolver 組織の参照（IDまたはスラッグ）を主体のテナントとして解決する
+type Resolver interface {
+	// Resolve 組織が存在しなければErrNotFound、主体がメンバーでなければErrNotMember
+	Resolve(ctx context.Context, principal *auth.Principal, ref string) (*Tenant, error)
+	// Default 指定がない場合の主体のテナント
+	// 所属する組織が1つだけならその組織、所属がなければ Personal の個人のテナント、複数ならnil
+	Default(ctx context.Context, principal *auth.Principal) (*Tenant, error)
+}
+
+// Options テナント解決の設定
+type Options struct {
+	// Header テナントを指定するヘッダー
+	Header string
+	// BaseDomain サブドメインでテナントを指定する場合のドメイン（例: example.com なら acme.example.com は acme）
+	BaseDomain string
+	// Optional テナントを決められない場合・組織に所属しない場合に全組織を対象にする
+	// （単一テナントでの運用向け。既定では複数の組織に所属すれば ErrRequired、所属がなければ個人のテナント）
+	Optional bool
+}
+
+// LoadOptions 環境変数（TENANT_BASE_DOMAIN / TENANT_REQUIRED）から設定を読み込む
+// TENANT_REQUIRED は未設定なら必須で、false または 0 で任意にする
+func LoadOptions() Options {
+	required := os.Getenv("TENANT_REQUIRED")
+	return Options{
+		Header:     DefaultHeader,
+		BaseDomain: strings.ToLower(strings.TrimPrefix(os.Getenv("TENANT_BASE_DOMAIN"), ".")),
+		Optional:   required == "false" || required == "0",
+	}
+}
+
+// Resolve 主体のテナントを解決する
+// JWTのorg_idクレームを優先し、ヘッダー・サブドメインの指定がクレームの組織と異なればErrMismatch。
+// 指定がなければシステム管理者以外は所属する唯一の組織、所属がなければ個人のテナントにし、
+// 複数の組織に所属していればErrRequired（システム管理者と Optional の場合はnilで全組織が対象）
+func Resolve(ctx context.Context, resolver Resolver, opts Options, principal *auth.Principal, header, host string) (*Tenant, error) {
+	requested := strings.TrimSpace(header)
+	if requested == "" {
+		requested = opts.subdomain(host)
+	}
+
+	if principal.Claims != nil && principal.Claims.OrgID != 0 {
+		t, err := resolver.Resolve(ctx, principal, strconv.FormatInt(principal.Claims.OrgID, 10))
+		if err != nil {
+			return nil, err
+		}
+		if requested != "" && requested != strconv.FormatInt(t.ID, 10) && !strings.EqualFold(requested, t.Slug) {
+			return nil, ErrMismatch
+		}
+		return t, nil
+	}
+
+	if requested != "" {
+		return resolver.Resolve(ctx, principal, requested)
+	}
+
+	// システム管理者は組織をまたいで操作できる
+	if principal.Role == auth.RoleAdmin {
+		return nil, nil
+	}
+	t, err := resolver.Default(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Optional && (t == nil || t.IsPersonal()) {
+		return nil, nil
+	}
+	if t == nil {
+		return nil, ErrRequired
+	}
+	return t, nil
+}
+
+// subdomain ホスト名からテナントのサブドメインを取り出す
+func (o Options) subdomain(host string) string {
+	if o.BaseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	sub, ok := strings.CutSuffix(strings.ToLower(host), "."+o.BaseDomain)
+	if !ok || sub == "" || strings.Contains(sub, ".") {
+		return ""
+	}
+	return sub
+}
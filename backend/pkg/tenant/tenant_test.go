@@ -0,0 +1,137 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"app-template/pkg/auth"
+)
+
+// fakeResolver 組織とメンバーの一覧からテナントを解決する
+type fakeResolver struct {
+	orgs    map[int64]string
+	members map[int64][]int64
+}
+
+func (r *fakeResolver) Resolve(ctx context.Context, principal *auth.Principal, ref string) (*Tenant, error) {
+	for id, slug := range r.orgs {
+		if ref != slug && ref != strconv.FormatInt(id, 10) {
+			continue
+		}
+		for _, orgID := range r.members[principal.UserID] {
+			if orgID == id {
+				return &Tenant{ID: id, Slug: slug, Role: "member"}, nil
+			}
+		}
+		if principal.Role == auth.RoleAdmin {
+			return &Tenant{ID: id, Slug: slug}, nil
+		}
+		return nil, ErrNotMember
+	}
+	return nil, ErrNotFound
+}
+
+func (r *fakeResolver) Default(ctx context.Context, principal *auth.Principal) (*Tenant, error) {
+	orgs := r.members[principal.UserID]
+	if len(orgs) == 0 {
+		return Personal(principal.UserID), nil
+	}
+	if len(orgs) != 1 {
+		return nil, nil
+	}
+	return &Tenant{ID: orgs[0], Slug: r.orgs[orgs[0]], Role: "member"}, nil
+}
+
+func TestResolve(t *testing.T) {
+	resolver := &fakeResolver{
+		orgs: map[int64]string{1: "acme", 2: "globex"},
+		members: map[int64][]int64{
+			10: {1},    // 1つの組織に所属
+			11: {1, 2}, // 複数の組織に所属
+			12: nil,    // 所属なし
+		},
+	}
+	single := &auth.Principal{UserID: 10, Role: auth.RoleUser}
+	multi := &auth.Principal{UserID: 11, Role: auth.RoleUser}
+	none := &auth.Principal{UserID: 12, Role: auth.RoleUser}
+	admin := &auth.Principal{UserID: 1, Role: auth.RoleAdmin}
+	scoped := &auth.Principal{UserID: 11, Role: auth.RoleUser, Claims: &auth.Claims{OrgID: 2}}
+	subdomains := Options{BaseDomain: "example.com"}
+
+	tests := []struct {
+		name      string
+		opts      Options
+		principal *auth.Principal
+		header    string
+		host      string
+		wantID    int64
+		// wantUser 個人のテナントの主体
+		wantUser int64
+		wantErr  error
+	}{
+		{name: "header by slug", principal: multi, header: "globex", wantID: 2},
+		{name: "header by id", principal: multi, header: "1", wantID: 1},
+		{name: "subdomain", opts: subdomains, principal: multi, host: "globex.example.com:8080", wantID: 2},
+		{name: "header wins over subdomain", opts: subdomains, principal: multi, header: "acme", host: "globex.example.com", wantID: 1},
+		{name: "nested subdomain is ignored", opts: subdomains, principal: single, host: "a.globex.example.com", wantID: 1},
+		{name: "not a member", principal: single, header: "globex", wantErr: ErrNotMember},
+		{name: "unknown organization", principal: single, header: "initech", wantErr: ErrNotFound},
+		{name: "claim", principal: scoped, wantID: 2},
+		{name: "claim matches header", principal: scoped, header: "globex", wantID: 2},
+		{name: "claim mismatch", principal: scoped, header: "acme", wantErr: ErrMismatch},
+
+		// 指定がない場合
+		{name: "defaults to the only organization", principal: single, wantID: 1},
+		{name: "several organizations require a tenant", principal: multi, wantErr: ErrRequired},
+		{name: "no organization is scoped to the user", principal: none, wantUser: 12},
+		{name: "optional without organization spans organizations", opts: Options{Optional: true}, principal: none},
+		{name: "optional falls back to all organizations", opts: Options{Optional: true}, principal: multi},
+		{name: "optional still defaults to the only organization", opts: Options{Optional: true}, principal: single, wantID: 1},
+		{name: "admin spans organizations", principal: admin},
+		{name: "admin can pick any organization", principal: admin, header: "acme", wantID: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(context.Background(), resolver, tt.opts, tt.principal, tt.header, tt.host)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantUser != 0 {
+				if got == nil || !got.IsPersonal() || got.UserID != tt.wantUser {
+					t.Errorf("tenant = %+v, want personal tenant of user %d", got, tt.wantUser)
+				}
+				return
+			}
+			if tt.wantID == 0 {
+				if got != nil {
+					t.Errorf("tenant = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.ID != tt.wantID {
+				t.Errorf("tenant = %+v, want organization %d", got, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestLoadOptions(t *testing.T) {
+	tests := []struct {
+		value        string
+		wantOptional bool
+	}{
+		{value: "", wantOptional: false},
+		{value: "true", wantOptional: false},
+		{value: "false", wantOptional: true},
+		{value: "0", wantOptional: true},
+	}
+	for _, tt := range tests {
+		t.Setenv("TENANT_REQUIRED", tt.value)
+		if got := LoadOptions().Optional; got != tt.wantOptional {
+			t.Errorf("TENANT_REQUIRED=%q: Optional = %v, want %v", tt.value, got, tt.wantOptional)
+		}
+	}
+}
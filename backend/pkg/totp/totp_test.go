@@ -0,0 +1,78 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret RFC 6238 Appendix B の SHA1 の鍵 "12345678901234567890" のBase32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238Vectors(t *testing.T) {
+	// Appendix B の8桁の値の下6桁
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code(%d): %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+
+	// 小文字のシークレットも受け付ける
+	if got, err := Code("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", Step(time.Unix(59, 0))); err != nil || got != "287082" {
+		t.Errorf("lower-case secret = %q, %v", got, err)
+	}
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("Code accepted an invalid secret")
+	}
+}
+
+func TestValidateSkewWindow(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+	codeAt := func(delta int64) string {
+		code, err := Code(rfcSecret, current+delta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	for _, delta := range []int64{-1, 0, 1} {
+		step, ok := Validate(rfcSecret, codeAt(delta), now, 1)
+		if !ok || step != current+delta {
+			t.Errorf("delta %d: Validate = %d, %v, want %d, true", delta, step, ok, current+delta)
+		}
+	}
+	for _, delta := range []int64{-2, 2} {
+		if _, ok := Validate(rfcSecret, codeAt(delta), now, 1); ok {
+			t.Errorf("delta %d: accepted outside the skew window", delta)
+		}
+	}
+	if _, ok := Validate(rfcSecret, codeAt(1), now, 0); ok {
+		t.Error("skew 0 accepted the next step")
+	}
+
+	// 前後の空白は無視し、桁数の違うコードは拒否する
+	if _, ok := Validate(rfcSecret, " "+codeAt(0)+"\n", now, 1); !ok {
+		t.Error("Validate rejected a code with surrounding spaces")
+	}
+	for _, code := range []string{"", "05047", "0504711", "abcdef"} {
+		if _, ok := Validate(rfcSecret, code, now, 1); ok {
+			t.Errorf("Validate accepted %q", code)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Package webhook Webhookの署名・検証と送信
+// 受信側は X-Webhook-Timestamp と本文から署名を計算し、X-Webhook-Signature と比較して送信元と改ざんの有無を確認する
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 送信時に付けるヘッダー
+const (
+	// HeaderEvent イベントの種類（例: user.created）
+	HeaderEvent = "X-Webhook-Event"
+	// HeaderDelivery 配信ID（再送では新しいIDになる。重複の排除には本文のイベントIDを使う）
+	HeaderDelivery = "X-Webhook-Delivery"
+	// HeaderTimestamp 署名した日時（Unix秒）
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature "v1=" + HMAC-SHA256(secret, timestamp + "." + body) の16進数表記
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// signatureVersion 署名方式の接頭辞
+const signatureVersion = "v1="
+
+// maxResponseBody 配信履歴に残すレスポンス本文の最大バイト数
+const maxResponseBody = 4096
+
+var (
+	// ErrInvalidSignature 署名が一致しない
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+	// ErrTimestampOutOfRange 署名日時が許容範囲外（再送攻撃の防止）
+	ErrTimestampOutOfRange = errors.New("webhook: timestamp out of range")
+)
+
+// GenerateSecret 署名用の秘密鍵を生成
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Sign 本文の署名を計算
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signatureVersion + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 受信したWebhookの署名を検証（toleranceより古い・未来の署名日時は拒否する）
+func Verify(secret, signature, timestamp string, body []byte, tolerance time.Duration, now time.Time) error {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrTimestampOutOfRange
+	}
+	signedAt := time.Unix(unix, 0)
+	if d := now.Sub(signedAt); d > tolerance || d < -tolerance {
+		return ErrTimestampOutOfRange
+	}
+
+	expected := Sign(secret, signedAt, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Request 1回の送信内容
+type Request struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID string
+	Body       []byte
+}
+
+// Result 送信結果（接続できなかった場合はStatusCodeが0）
+type Result struct {
+	StatusCode int
+	// Body レスポンス本文（先頭の一部のみ）
+	Body     string
+	Duration time.Duration
+}
+
+// StatusError 2xx以外の応答
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook: unexpected response status %d", e.StatusCode)
+}
+
+// Sender 署名付きでWebhookを送信する
+type Sender struct {
+	client    *http.Client
+	userAgent string
+	now       func() time.Time
+}
+
+// NewSender Senderを作成（clientがnilなら10秒でタイムアウトし、リダイレクトに従わないクライアントを使う）
+func NewSender(client *http.Client, userAgent string) *Sender {
+	if client == nil {
+		client = &http.Client{
+			Timeout: 10 * time.Second,
+			// 署名付きの本文を登録先以外へ送らないようリダイレクトには従わない
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+	return &Sender{
+		client:    client,
+		userAgent: userAgent,
+		now:       time.Now,
+	}
+}
+
+// Send 本文をPOSTする。2xx以外の応答は *StatusError を返す（結果は応答がなくても返す）
+func (s *Sender) Send(ctx context.Context, req *Request) (*Result, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return &Result{}, err
+	}
+
+	now := s.now()
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", s.userAgent)
+	httpReq.Header.Set(HeaderEvent, req.Event)
+	httpReq.Header.Set(HeaderDelivery, req.DeliveryID)
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, now, req.Body))
+
+	start := time.Now()
+	resp, err := s.client.Do(httpReq)
+	result := &Result{Duration: time.Since(start)}
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	// 接続を再利用できるよう残りを読み捨てる
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	result.StatusCode = resp.StatusCode
+	result.Body = strings.ToValidUTF8(string(body), "")
+	result.Duration = time.Since(start)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return result, &StatusError{StatusCode: resp.StatusCode}
+	}
+	return result, nil
+}
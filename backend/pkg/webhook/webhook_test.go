@@ -0,0 +1,151 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"1","type":"user.created"}`)
+	signature := Sign("secret", now, body)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		timestamp string
+		body      []byte
+		now       time.Time
+		want      error
+	}{
+		{name: "valid", secret: "secret", signature: signature, timestamp: timestamp, body: body, now: now},
+		{name: "within tolerance", secret: "secret", signature: signature, timestamp: timestamp, body: body, now: now.Add(4 * time.Minute)},
+		{name: "wrong secret", secret: "other", signature: signature, timestamp: timestamp, body: body, now: now, want: ErrInvalidSignature},
+		{name: "tampered body", secret: "secret", signature: signature, timestamp: timestamp, body: []byte(`{"id":"2"}`), now: now, want: ErrInvalidSignature},
+		{name: "timestamp changed", secret: "secret", signature: signature, timestamp: strconv.FormatInt(now.Unix()+1, 10), body: body, now: now, want: ErrInvalidSignature},
+		{name: "too old", secret: "secret", signature: signature, timestamp: timestamp, body: body, now: now.Add(6 * time.Minute), want: ErrTimestampOutOfRange},
+		{name: "in the future", secret: "secret", signature: signature, timestamp: timestamp, body: body, now: now.Add(-6 * time.Minute), want: ErrTimestampOutOfRange},
+		{name: "malformed timestamp", secret: "secret", signature: signature, timestamp: "yesterday", body: body, now: now, want: ErrTimestampOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.signature, tt.timestamp, tt.body, 5*time.Minute, tt.now)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Verify = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSenderSignsRequest(t *testing.T) {
+	body := []byte(`{"id":"42","type":"user.created","data":{}}`)
+	received := make(chan *http.Request, 1)
+	receivedBody := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		received <- r
+		receivedBody <- b
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	sender := NewSender(nil, "test-agent/1.0")
+	result, err := sender.Send(context.Background(), &Request{
+		URL:        srv.URL,
+		Secret:     "whsec_test",
+		Event:      "user.created",
+		DeliveryID: "7",
+		Body:       body,
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if result.StatusCode != http.StatusOK || result.Body != "ok" {
+		t.Errorf("result = %+v", result)
+	}
+
+	r := <-received
+	got := <-receivedBody
+	if string(got) != string(body) {
+		t.Errorf("body = %s, want %s", got, body)
+	}
+	for header, want := range map[string]string{
+		"Content-Type": "application/json",
+		"User-Agent":   "test-agent/1.0",
+		HeaderEvent:    "user.created",
+		HeaderDelivery: "7",
+	} {
+		if v := r.Header.Get(header); v != want {
+			t.Errorf("%s = %q, want %q", header, v, want)
+		}
+	}
+
+	// 受信側と同じ手順で署名を検証できる
+	if err := Verify("whsec_test", r.Header.Get(HeaderSignature), r.Header.Get(HeaderTimestamp), got, time.Minute, time.Now()); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	if err := Verify("whsec_other", r.Header.Get(HeaderSignature), r.Header.Get(HeaderTimestamp), got, time.Minute, time.Now()); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify with another secret = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestSenderReportsNon2xx(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("try later"))
+	}))
+	defer srv.Close()
+
+	result, err := NewSender(nil, "test").Send(context.Background(), &Request{URL: srv.URL, Secret: "s", Body: []byte(`{}`)})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want StatusError 503", err)
+	}
+	if result.StatusCode != http.StatusServiceUnavailable || result.Body != "try later" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestSenderDoesNotFollowRedirects(t *testing.T) {
+	followed := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed = true
+	}))
+	defer target.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer srv.Close()
+
+	result, err := NewSender(nil, "test").Send(context.Background(), &Request{URL: srv.URL, Secret: "s", Body: []byte(`{}`)})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || result.StatusCode != http.StatusTemporaryRedirect {
+		t.Fatalf("result = %+v, err = %v", result, err)
+	}
+	if followed {
+		t.Error("redirect was followed")
+	}
+}
+
+func TestSenderConnectionError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	result, err := NewSender(nil, "test").Send(context.Background(), &Request{URL: url, Secret: "s", Body: []byte(`{}`)})
+	if err == nil {
+		t.Fatal("Send succeeded against a closed server")
+	}
+	if result == nil || result.StatusCode != 0 {
+		t.Errorf("result = %+v, want StatusCode 0", result)
+	}
+}